@@ -18,6 +18,28 @@ func main() {
 	cfg.Limiter.RPS = c.RateLimiter.RPS
 	cfg.Limiter.Burst = c.RateLimiter.Burst
 	cfg.Limiter.Enabled = c.RateLimiter.Enabled
+	cfg.Security.SecretKey = c.Security.SecretKey
+	cfg.Registry.URL = c.Registry.URL
+	cfg.Registry.Fake = c.Registry.Fake
+	cfg.Storage.Driver = c.Storage.Driver
+	cfg.Storage.LocalPath = c.Storage.LocalPath
+	cfg.Storage.PublicURL = c.Storage.PublicURL
+	cfg.Storage.S3.Endpoint = c.Storage.S3Endpoint
+	cfg.Storage.S3.Region = c.Storage.S3Region
+	cfg.Storage.S3.Bucket = c.Storage.S3Bucket
+	cfg.Storage.S3.AccessKey = c.Storage.S3AccessKey
+	cfg.Storage.S3.SecretKey = c.Storage.S3SecretKey
+	cfg.Storage.S3.PathStyle = c.Storage.S3PathStyle
+	cfg.PublicURL = c.Server.PublicURL
+	cfg.Mail.Driver = c.Mail.Driver
+	cfg.Mail.Host = c.Mail.Host
+	cfg.Mail.Port = c.Mail.Port
+	cfg.Mail.Username = c.Mail.Username
+	cfg.Mail.Password = c.Mail.Password
+	cfg.Mail.Sender = c.Mail.Sender
+	cfg.Geo.Driver = c.Geo.Driver
+	cfg.Geo.DatasetPath = c.Geo.DatasetPath
+	cfg.Webhook.AllowPrivate = c.Webhook.AllowPrivate
 
 	app := api.NewApp(cfg)
 	err := app.Server()
@@ -16,6 +16,7 @@ type application struct {
 	config config.Config
 	Logger *jsonlog.Logger
 	wg     sync.WaitGroup
+	quit   chan struct{}
 	db     *sql.DB
 }
 
@@ -47,6 +48,7 @@ func NewApp(cfg config.Config) *application {
 	return &application{
 		config: cfg,
 		Logger: logger,
+		quit:   make(chan struct{}),
 		db:     db,
 	}
 }
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+// background runs fn every interval until the server starts shutting down.
+// Jobs are tracked by app.wg so graceful shutdown waits for the current run.
+func (app *application) background(name string, interval time.Duration, fn func() error) {
+	app.wg.Add(1)
+
+	go func() {
+		defer app.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-app.quit:
+				return
+			case <-ticker.C:
+				app.runJob(name, fn)
+			}
+		}
+	}()
+}
+
+func (app *application) runJob(name string, fn func() error) {
+	defer func() {
+		if err := recover(); err != nil {
+			app.Logger.PrintError(fmt.Errorf("%s", err), map[string]string{
+				"job": name,
+			})
+		}
+	}()
+
+	if err := fn(); err != nil {
+		app.Logger.PrintError(err, map[string]string{
+			"job": name,
+		})
+	}
+}
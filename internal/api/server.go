@@ -22,6 +22,14 @@ func (app *application) Server() error {
 		app.config,
 	)
 
+	app.background("erase_due_users", time.Hour, r.Service.Privacy.EraseDue)
+	app.background("parse_curriculum_drafts", 15*time.Second, r.Service.CurriculumDraft.ProcessPending)
+	app.background("match_jobs", 30*time.Second, r.Service.JobMatch.ProcessUnmatched)
+	app.background("send_job_alerts", time.Minute, r.Service.SavedSearch.SendDue)
+	app.background("notify_unread_messages", time.Minute, r.Service.Message.NotifyUnread)
+	app.background("send_emails", 10*time.Second, r.Service.Email.SendPending)
+	app.background("deliver_webhooks", 5*time.Second, r.Service.Webhook.DeliverPending)
+
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", app.config.Port),
 		Handler:      r.RegisterRoutes(),
@@ -30,6 +38,9 @@ func (app *application) Server() error {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
+	// Shutdown waits for requests to finish, which notification streams
+	// never do on their own.
+	srv.RegisterOnShutdown(r.Service.Notification.Close)
 
 	shutdownError := make(chan error)
 
@@ -56,6 +67,7 @@ func (app *application) Server() error {
 			"addr": srv.Addr,
 		})
 
+		close(app.quit)
 		app.wg.Wait()
 		shutdownError <- nil
 	}()
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+type TTLCache[K comparable, V any] struct {
+	mu       sync.Mutex
+	items    map[K]entry[V]
+	ttl      time.Duration
+	maxItems int
+}
+
+func New[K comparable, V any](ttl time.Duration, maxItems int) *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		items:    make(map[K]entry[V]),
+		ttl:      ttl,
+		maxItems: maxItems,
+	}
+}
+
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, found := c.items[key]
+	if !found {
+		var zero V
+		return zero, false
+	}
+
+	if time.Now().After(item.expiresAt) {
+		delete(c.items, key)
+		var zero V
+		return zero, false
+	}
+
+	return item.value, true
+}
+
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.items) >= c.maxItems {
+		c.evictExpired()
+	}
+
+	if len(c.items) >= c.maxItems {
+		for k := range c.items {
+			delete(c.items, k)
+			break
+		}
+	}
+
+	c.items[key] = entry[V]{
+		value:     value,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *TTLCache[K, V]) evictExpired() {
+	now := time.Now()
+	for k, item := range c.items {
+		if now.After(item.expiresAt) {
+			delete(c.items, k)
+		}
+	}
+}
@@ -26,6 +26,41 @@ type Config struct {
 	Security struct {
 		SecretKey string
 	}
+	Registry struct {
+		URL  string
+		Fake bool
+	}
+	Storage struct {
+		Driver    string
+		LocalPath string
+		PublicURL string
+		S3        struct {
+			Endpoint  string
+			Region    string
+			Bucket    string
+			AccessKey string
+			SecretKey string
+			PathStyle bool
+		}
+	}
+	// PublicURL is where the API is reached from outside, for links sent
+	// by email.
+	PublicURL string
+	Mail      struct {
+		Driver   string
+		Host     string
+		Port     int
+		Username string
+		Password string
+		Sender   string
+	}
+	Geo struct {
+		Driver      string
+		DatasetPath string
+	}
+	Webhook struct {
+		AllowPrivate bool
+	}
 }
 
 type Conf struct {
@@ -33,11 +68,17 @@ type Conf struct {
 	DB          ConfDB
 	RateLimiter ConfRL
 	Security    ConfSecurity
+	Registry    ConfRegistry
+	Storage     ConfStorage
+	Mail        ConfMail
+	Geo         ConfGeo
+	Webhook     ConfWebhook
 }
 
 type ConfServer struct {
-	Port  int  `env:"SERVER_PORT,required"`
-	Debug bool `env:"SERVER_DEBUG,required"`
+	Port      int    `env:"SERVER_PORT,required"`
+	Debug     bool   `env:"SERVER_DEBUG,required"`
+	PublicURL string `env:"SERVER_PUBLIC_URL,default=http://localhost:8080"`
 }
 
 type ConfDB struct {
@@ -57,6 +98,46 @@ type ConfSecurity struct {
 	SecretKey string `env:"SECRET_KEY,required"`
 }
 
+type ConfRegistry struct {
+	URL  string `env:"CNPJ_REGISTRY_URL,default=https://brasilapi.com.br/api/cnpj/v1"`
+	Fake bool   `env:"CNPJ_REGISTRY_FAKE,default=false"`
+}
+
+type ConfStorage struct {
+	Driver      string `env:"STORAGE_DRIVER,default=local"`
+	LocalPath   string `env:"STORAGE_LOCAL_PATH,default=./uploads"`
+	PublicURL   string `env:"STORAGE_PUBLIC_URL,default=http://localhost:8080"`
+	S3Endpoint  string `env:"S3_ENDPOINT"`
+	S3Region    string `env:"S3_REGION,default=us-east-1"`
+	S3Bucket    string `env:"S3_BUCKET"`
+	S3AccessKey string `env:"S3_ACCESS_KEY"`
+	S3SecretKey string `env:"S3_SECRET_KEY"`
+	S3PathStyle bool   `env:"S3_PATH_STYLE,default=true"`
+}
+
+type ConfMail struct {
+	Driver   string `env:"MAIL_DRIVER,default=log"`
+	Host     string `env:"SMTP_HOST"`
+	Port     int    `env:"SMTP_PORT,default=587"`
+	Username string `env:"SMTP_USERNAME"`
+	Password string `env:"SMTP_PASSWORD"`
+	Sender   string `env:"MAIL_SENDER,default=Meu Job <no-reply@meujob.com.br>"`
+}
+
+// ConfGeo picks the CEP resolver: "dataset" loads the CSV at
+// CEP_DATASET_PATH, "fake" answers from a few built-in CEPs.
+type ConfGeo struct {
+	Driver      string `env:"CEP_RESOLVER,default=fake"`
+	DatasetPath string `env:"CEP_DATASET_PATH,default=./data/ceps.csv"`
+}
+
+// ConfWebhook lets webhooks reach private and loopback addresses over
+// plain http, to try them out locally; otherwise only public https
+// endpoints are called.
+type ConfWebhook struct {
+	AllowPrivate bool `env:"WEBHOOK_ALLOW_PRIVATE,default=false"`
+}
+
 func New() *Conf {
 	var c Conf
 	if err := envdecode.StrictDecode(&c); err != nil {
@@ -4,11 +4,17 @@ import (
 	"context"
 	"meu_job/internal/models"
 	"net/http"
+	"time"
 )
 
 type contextKey string
 
-const userContextKey = contextKey("user")
+const (
+	userContextKey    = contextKey("user")
+	apiKeyContextKey  = contextKey("api_key")
+	sessionContextKey = contextKey("session")
+	credsContextKey   = contextKey("credentials")
+)
 
 func ContextSetUser(r *http.Request, user *models.User) *http.Request {
 	ctx := context.WithValue(r.Context(), userContextKey, user)
@@ -22,3 +28,51 @@ func ContextGetUser(r *http.Request) *models.User {
 	}
 	return user
 }
+
+func ContextSetAPIKey(r *http.Request, key *models.APIKey) *http.Request {
+	ctx := context.WithValue(r.Context(), apiKeyContextKey, key)
+	return r.WithContext(ctx)
+}
+
+func ContextGetAPIKey(r *http.Request) *models.APIKey {
+	key, ok := r.Context().Value(apiKeyContextKey).(*models.APIKey)
+	if !ok {
+		return nil
+	}
+	return key
+}
+
+func ContextSetSession(r *http.Request, session *models.Session) *http.Request {
+	ctx := context.WithValue(r.Context(), sessionContextKey, session)
+	return r.WithContext(ctx)
+}
+
+func ContextGetSession(r *http.Request) *models.Session {
+	session, ok := r.Context().Value(sessionContextKey).(*models.Session)
+	if !ok {
+		return nil
+	}
+	return session
+}
+
+// Credentials let a request that outlives its authentication, such as a
+// stream, find out when what it was made with stops being good.
+type Credentials struct {
+	// ExpiresAt is nil for credentials that don't expire on their own.
+	ExpiresAt *time.Time
+	// Check authenticates again with the same credentials.
+	Check func() error
+}
+
+func ContextSetCredentials(r *http.Request, creds *Credentials) *http.Request {
+	ctx := context.WithValue(r.Context(), credsContextKey, creds)
+	return r.WithContext(ctx)
+}
+
+func ContextGetCredentials(r *http.Request) *Credentials {
+	creds, ok := r.Context().Value(credsContextKey).(*Credentials)
+	if !ok {
+		return nil
+	}
+	return creds
+}
@@ -0,0 +1,75 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// docxText reads the paragraphs of the main document part. Headers,
+// footers and text boxes live in other parts and are left out.
+func docxText(content []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return "", ErrUnsupported
+	}
+
+	var part *zip.File
+	for _, f := range archive.File {
+		if f.Name == "word/document.xml" {
+			part = f
+			break
+		}
+	}
+	if part == nil {
+		return "", ErrUnsupported
+	}
+
+	r, err := part.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	var text strings.Builder
+	dec := xml.NewDecoder(io.LimitReader(r, 50<<20))
+	inText := false
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				text.WriteByte('\t')
+			case "br", "cr":
+				text.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+
+	return text.String(), nil
+}
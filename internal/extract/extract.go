@@ -0,0 +1,84 @@
+// Package extract pulls the plain text out of uploaded documents, one line
+// of the document per line of text. Layout is otherwise lost; making sense
+// of the text is up to the caller.
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrUnsupported = errors.New("unsupported document type")
+	ErrEncrypted   = errors.New("encrypted documents are not supported")
+	// ErrNoText is returned for documents that are only images, such as
+	// scanned résumés. There is no OCR.
+	ErrNoText = errors.New("document has no extractable text")
+	// ErrMalformed is returned for documents the parsers choke on.
+	ErrMalformed = errors.New("document is malformed")
+)
+
+const (
+	mimePDF  = "application/pdf"
+	mimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+)
+
+// Text never panics: uploads are untrusted, and a parser bug on a crafted
+// document is reported as ErrMalformed.
+func Text(content []byte, contentType string) (text string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			text, err = "", fmt.Errorf("%w: %v", ErrMalformed, r)
+		}
+	}()
+
+	switch contentType {
+	case mimePDF:
+		text, err = pdfText(content)
+	case mimeDOCX:
+		text, err = docxText(content)
+	default:
+		return "", ErrUnsupported
+	}
+	if err != nil {
+		return "", err
+	}
+
+	text = clean(text)
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	if letters < 20 {
+		return "", ErrNoText
+	}
+
+	return text, nil
+}
+
+var ligatures = strings.NewReplacer("ﬀ", "ff", "ﬁ", "fi", "ﬂ", "fl", "ﬃ", "ffi", "ﬄ", "ffl")
+
+// clean trims every line, collapses runs of blanks inside them, spells out
+// ligatures and drops the control characters some generators leave behind.
+func clean(text string) string {
+	lines := strings.Split(ligatures.Replace(text), "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Map(func(r rune) rune {
+			switch {
+			case r == '\t', r == ' ':
+				return ' '
+			case unicode.IsControl(r), r == unicode.ReplacementChar:
+				return -1
+			default:
+				return r
+			}
+		}, line)
+		out = append(out, strings.Join(strings.Fields(line), " "))
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
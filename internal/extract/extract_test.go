@@ -0,0 +1,211 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const loremText = "Maria Silva\nDesenvolvedora Go com experiência em sistemas distribuídos"
+
+// buildPDF writes a document with the given objects, numbered from 1. The
+// cross-reference table is left out; the parser doesn't read it.
+func buildPDF(objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	b.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+func streamObject(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func deflate(data []byte) []byte {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write(data)
+	w.Close()
+	return b.Bytes()
+}
+
+// textPDF is a one-page document showing the lines of text, its content
+// stream encoded with the filter given, if any.
+func textPDF(text, filter string) []byte {
+	var content bytes.Buffer
+	content.WriteString("BT /F1 12 Tf 72 720 Td 14 TL\n")
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			content.WriteString("T*\n")
+		}
+		// WinAnsiEncoding matches Latin-1 for the accented letters.
+		content.WriteByte('(')
+		for _, r := range line {
+			content.WriteByte(byte(r))
+		}
+		content.WriteString(") Tj\n")
+	}
+	content.WriteString("ET")
+
+	data := content.Bytes()
+	dict := ""
+	switch filter {
+	case "FlateDecode":
+		data = deflate(data)
+		dict = "/Filter /FlateDecode"
+	case "chain":
+		// Decoded right to left from how it was encoded.
+		data = []byte(hex.EncodeToString(deflate(data)) + ">")
+		dict = "/Filter [/ASCIIHexDecode /FlateDecode]"
+	}
+
+	return buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		streamObject(dict, data),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	)
+}
+
+func docxFile(paragraphs ...string) []byte {
+	var body strings.Builder
+	for _, p := range paragraphs {
+		fmt.Fprintf(&body, "<w:p><w:r><w:t>%s</w:t></w:r></w:p>", p)
+	}
+
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	w, _ := zw.Create("word/document.xml")
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>`+
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">`+
+		`<w:body>%s</w:body></w:document>`, body.String())
+	zw.Close()
+	return b.Bytes()
+}
+
+var (
+	encryptedPDF = buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		streamObject("", []byte("\x8a\x1f\x93\xc4\x02\x7e")),
+		"<< /Filter /Standard /V 2 /R 3 /O <00> /U <00> /P -4 >>",
+		"<< /Root 1 0 R /Encrypt 5 0 R >>",
+	)
+
+	imageOnlyPDF = buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Im1 5 0 R >> >> /Contents 4 0 R >>",
+		streamObject("", []byte("q 595 0 0 842 0 0 cm /Im1 Do Q")),
+		streamObject("/Type /XObject /Subtype /Image /Width 2 /Height 2 /ColorSpace /DeviceGray /BitsPerComponent 8",
+			[]byte("\x00\xff\xff\x00")),
+	)
+)
+
+// bombPDF spreads streams that inflate to size bytes each over the pages,
+// every one under the budget on its own.
+func bombPDF(pages, size int) []byte {
+	bomb := deflate(make([]byte, size))
+
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", ""}
+	var kids []string
+	for i := 0; i < pages; i++ {
+		page := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R >>", page+1),
+			streamObject("/Filter /FlateDecode", bomb),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages)
+	return buildPDF(objects...)
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     []byte
+		contentType string
+		want        string
+		wantErr     error
+	}{
+		{"pdf", textPDF(loremText, ""), mimePDF, loremText, nil},
+		{"pdf flate", textPDF(loremText, "FlateDecode"), mimePDF, loremText, nil},
+		{"pdf chained filters", textPDF(loremText, "chain"), mimePDF, loremText, nil},
+		{"docx", docxFile("Maria Silva", "Desenvolvedora Go com experiência em sistemas distribuídos"), mimeDOCX, loremText, nil},
+		{"too little text", textPDF("Maria", ""), mimePDF, "", ErrNoText},
+		{"image only", imageOnlyPDF, mimePDF, "", ErrNoText},
+		{"encrypted", encryptedPDF, mimePDF, "", ErrEncrypted},
+		{"not a pdf", []byte("GIF89a"), mimePDF, "", ErrUnsupported},
+		{"unsupported type", []byte("texto"), "text/plain", "", ErrUnsupported},
+		{"truncated pdf", textPDF(loremText, "FlateDecode")[:200], mimePDF, "", ErrNoText},
+		{"garbage after header", []byte("%PDF-1.4\n1 0 obj << /Type /Pages /Kids [1 0 R] >> stream\n\xff\xfe"), mimePDF, "", ErrNoText},
+		{"docx not a zip", []byte("PK\x03\x04 not really a zip"), mimeDOCX, "", ErrUnsupported},
+		{"decompression bomb", bombPDF(3, maxDecoded/2), mimePDF, "", ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Text(tt.content, tt.contentType)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Text() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Text() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeBudget(t *testing.T) {
+	d := &document{objects: map[int]any{}}
+	s := &stream{dict: dict{"Filter": name("FlateDecode")}, data: deflate(make([]byte, maxDecoded/4))}
+
+	for i := 0; i < 4; i++ {
+		if _, err := d.decode(s); err != nil {
+			t.Fatalf("decode %d: %v", i+1, err)
+		}
+	}
+	if _, err := d.decode(s); !errors.Is(err, errTooLarge) {
+		t.Fatalf("decode past the budget: error = %v, want %v", err, errTooLarge)
+	}
+}
+
+func FuzzText(f *testing.F) {
+	f.Add(textPDF(loremText, ""), true)
+	f.Add(textPDF(loremText, "FlateDecode"), true)
+	f.Add(textPDF(loremText, "chain"), true)
+	f.Add(encryptedPDF, true)
+	f.Add(imageOnlyPDF, true)
+	f.Add([]byte("%PDF-1.4\n1 0 obj << /Type /ObjStm /N 99 /First -3 >> stream\n0 0 endstream"), true)
+	f.Add([]byte("%PDF-1.4\n1 0 obj [[[[[[ (unterminated"), true)
+	f.Add(docxFile("Maria Silva", "Desenvolvedora"), false)
+
+	f.Fuzz(func(t *testing.T, content []byte, pdf bool) {
+		contentType := mimeDOCX
+		if pdf {
+			contentType = mimePDF
+		}
+
+		text, err := Text(content, contentType)
+		if err != nil && text != "" {
+			t.Errorf("Text() = %q along with error %v", text, err)
+		}
+	})
+}
@@ -0,0 +1,509 @@
+package extract
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// PDF values: strings are []byte, numbers float64, booleans bool and null
+// is nil.
+type (
+	name    string
+	keyword string
+	array   []any
+	dict    map[name]any
+	ref     struct{ num, gen int }
+	stream  struct {
+		dict dict
+		data []byte
+	}
+)
+
+type lexer struct {
+	b   []byte
+	pos int
+}
+
+func isSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *lexer) skip() {
+	for l.pos < len(l.b) {
+		switch c := l.b[l.pos]; {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.b) && l.b[l.pos] != '\n' && l.b[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// token reads one lexical token. Brackets and dictionary delimiters come
+// back as keywords.
+func (l *lexer) token() (any, error) {
+	l.skip()
+	if l.pos >= len(l.b) {
+		return nil, io.EOF
+	}
+
+	c := l.b[l.pos]
+	switch c {
+	case '(':
+		return l.literal(), nil
+	case '<':
+		if l.pos+1 < len(l.b) && l.b[l.pos+1] == '<' {
+			l.pos += 2
+			return keyword("<<"), nil
+		}
+		return l.hexString(), nil
+	case '>':
+		if l.pos+1 < len(l.b) && l.b[l.pos+1] == '>' {
+			l.pos += 2
+			return keyword(">>"), nil
+		}
+		l.pos++
+		return keyword(">"), nil
+	case '[', ']', '{', '}', ')':
+		l.pos++
+		return keyword(c), nil
+	case '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.b) && !isSpace(l.b[l.pos]) && !isDelimiter(l.b[l.pos]) {
+			l.pos++
+		}
+		return name(unescapeName(l.b[start:l.pos])), nil
+	}
+
+	start := l.pos
+	for l.pos < len(l.b) && !isSpace(l.b[l.pos]) && !isDelimiter(l.b[l.pos]) {
+		l.pos++
+	}
+	word := string(l.b[start:l.pos])
+
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	if c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9') {
+		if f, err := strconv.ParseFloat(word, 64); err == nil {
+			return f, nil
+		}
+	}
+	return keyword(word), nil
+}
+
+func unescapeName(b []byte) string {
+	if !bytes.ContainsRune(b, '#') {
+		return string(b)
+	}
+
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] == '#' && i+2 < len(b) {
+			if v, err := strconv.ParseUint(string(b[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, b[i])
+	}
+	return string(out)
+}
+
+// literal reads a (string), tolerating a missing closing parenthesis.
+func (l *lexer) literal() []byte {
+	l.pos++
+	out := []byte{}
+	depth := 1
+
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		l.pos++
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.b) {
+				return out
+			}
+			c = l.b[l.pos]
+			l.pos++
+
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.b) && l.b[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				v := int(c - '0')
+				for i := 0; i < 2 && l.pos < len(l.b) && l.b[l.pos] >= '0' && l.b[l.pos] <= '7'; i++ {
+					v = v*8 + int(l.b[l.pos]-'0')
+					l.pos++
+				}
+				c = byte(v)
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (l *lexer) hexString() []byte {
+	l.pos++
+	digits := []byte{}
+	for l.pos < len(l.b) && l.b[l.pos] != '>' {
+		if c := l.b[l.pos]; !isSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	n, _ := hex.Decode(out, digits)
+	return out[:n]
+}
+
+func (l *lexer) object() (any, error) {
+	tok, err := l.token()
+	if err != nil {
+		return nil, err
+	}
+	return l.build(tok)
+}
+
+// build turns a token into a value, reading the rest of arrays and
+// dictionaries and looking ahead for "num gen R" references.
+func (l *lexer) build(tok any) (any, error) {
+	switch t := tok.(type) {
+	case keyword:
+		switch t {
+		case "[":
+			arr := array{}
+			for {
+				tok, err := l.token()
+				if err != nil || tok == keyword("]") {
+					return arr, err
+				}
+				v, err := l.build(tok)
+				if err != nil {
+					return arr, err
+				}
+				arr = append(arr, v)
+			}
+		case "<<":
+			d := dict{}
+			for {
+				tok, err := l.token()
+				if err != nil || tok == keyword(">>") {
+					return d, err
+				}
+				key, ok := tok.(name)
+				if !ok {
+					continue
+				}
+				v, err := l.object()
+				if err != nil {
+					return d, err
+				}
+				d[key] = v
+			}
+		}
+	case float64:
+		if t >= 0 && t == math.Trunc(t) {
+			save := l.pos
+			if gen, err := l.token(); err == nil {
+				if g, ok := gen.(float64); ok && g >= 0 && g == math.Trunc(g) {
+					if r, err := l.token(); err == nil && r == keyword("R") {
+						return ref{int(t), int(g)}, nil
+					}
+				}
+			}
+			l.pos = save
+		}
+	}
+	return tok, nil
+}
+
+// maxDecoded is how much the streams of a document may decode to in all,
+// counting the output of every filter in a chain and every time a stream is
+// decoded again. A small file can otherwise inflate to gigabytes, whether
+// in one stream or spread over many.
+const maxDecoded = 64 << 20
+
+var errTooLarge = fmt.Errorf("%w: streams decode to over %d MB", ErrMalformed, maxDecoded>>20)
+
+type document struct {
+	objects map[int]any
+	// decoded counts the bytes decode produced, against maxDecoded.
+	decoded int
+}
+
+var objectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// parseDocument finds the objects by scanning for their headers instead of
+// trusting the cross-reference table, which is often broken in files that
+// went through a few tools. Later definitions win, as with incremental
+// updates.
+func parseDocument(b []byte) *document {
+	d := &document{objects: map[int]any{}}
+
+	for _, m := range objectHeader.FindAllSubmatchIndex(b, -1) {
+		num, err := strconv.Atoi(string(b[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+
+		l := &lexer{b: b, pos: m[1]}
+		v, err := l.object()
+		if err != nil && v == nil {
+			continue
+		}
+
+		if dd, ok := v.(dict); ok {
+			if data, ok := streamData(b, l.pos, dd); ok {
+				v = &stream{dict: dd, data: data}
+			}
+		}
+		d.objects[num] = v
+	}
+
+	var objectStreams []*stream
+	for _, num := range d.numbers() {
+		if s, ok := d.objects[num].(*stream); ok && s.dict["Type"] == name("ObjStm") {
+			objectStreams = append(objectStreams, s)
+		}
+	}
+	for _, s := range objectStreams {
+		d.expand(s)
+	}
+
+	return d
+}
+
+func (d *document) numbers() []int {
+	nums := make([]int, 0, len(d.objects))
+	for num := range d.objects {
+		nums = append(nums, num)
+	}
+	slices.Sort(nums)
+	return nums
+}
+
+func streamData(b []byte, pos int, d dict) ([]byte, bool) {
+	for pos < len(b) && isSpace(b[pos]) {
+		pos++
+	}
+	if pos >= len(b) || !bytes.HasPrefix(b[pos:], []byte("stream")) {
+		return nil, false
+	}
+
+	pos += len("stream")
+	if pos < len(b) && b[pos] == '\r' {
+		pos++
+	}
+	if pos < len(b) && b[pos] == '\n' {
+		pos++
+	}
+
+	if n, ok := d["Length"].(float64); ok && n >= 0 && pos+int(n) <= len(b) {
+		end := pos + int(n)
+		if bytes.HasPrefix(bytes.TrimLeft(b[end:], "\r\n\t "), []byte("endstream")) {
+			return b[pos:end], true
+		}
+	}
+
+	end := bytes.Index(b[pos:], []byte("endstream"))
+	if end < 0 {
+		return b[pos:], true
+	}
+	data := b[pos : pos+end]
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	return data, true
+}
+
+// expand adds the objects compressed in an object stream. Objects written
+// out directly take precedence.
+func (d *document) expand(s *stream) {
+	data, err := d.decode(s)
+	if err != nil {
+		return
+	}
+
+	n, _ := d.resolve(s.dict["N"]).(float64)
+	first, _ := d.resolve(s.dict["First"]).(float64)
+
+	header := &lexer{b: data}
+	for i := 0; i < int(n); i++ {
+		num, err1 := header.token()
+		offset, err2 := header.token()
+		if err1 != nil || err2 != nil {
+			return
+		}
+
+		objNum, ok1 := num.(float64)
+		objOffset, ok2 := offset.(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+
+		if _, exists := d.objects[int(objNum)]; exists {
+			continue
+		}
+
+		pos := int(first) + int(objOffset)
+		if pos < 0 || pos >= len(data) {
+			continue
+		}
+		l := &lexer{b: data, pos: pos}
+		if v, err := l.object(); err == nil {
+			d.objects[int(objNum)] = v
+		}
+	}
+}
+
+func (d *document) resolve(v any) any {
+	for i := 0; i < 10; i++ {
+		r, ok := v.(ref)
+		if !ok {
+			return v
+		}
+		v = d.objects[r.num]
+	}
+	return nil
+}
+
+// dictOf resolves v to a dictionary, taking the dictionary of streams.
+func (d *document) dictOf(v any) dict {
+	switch t := d.resolve(v).(type) {
+	case dict:
+		return t
+	case *stream:
+		return t.dict
+	}
+	return nil
+}
+
+var errUnsupportedFilter = errors.New("unsupported stream filter")
+
+func (d *document) decode(s *stream) ([]byte, error) {
+	var filters []any
+	switch f := d.resolve(s.dict["Filter"]).(type) {
+	case name:
+		filters = []any{f}
+	case array:
+		filters = f
+	}
+
+	data := s.data
+	for _, f := range filters {
+		if d.decoded > maxDecoded {
+			return nil, errTooLarge
+		}
+
+		var err error
+		switch d.resolve(f) {
+		case name("FlateDecode"), name("Fl"):
+			data, err = inflate(data, maxDecoded-d.decoded+1)
+		case name("ASCIIHexDecode"), name("AHx"):
+			l := &lexer{b: append(append([]byte{'<'}, data...), '>')}
+			data = l.hexString()
+		case name("ASCII85Decode"), name("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			return nil, errUnsupportedFilter
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if d.decoded += len(data); d.decoded > maxDecoded {
+			return nil, errTooLarge
+		}
+	}
+	return data, nil
+}
+
+// inflate keeps whatever it could read from truncated or slightly corrupt
+// streams, which are common enough. It stops after limit bytes.
+func inflate(data []byte, limit int) ([]byte, error) {
+	var r io.ReadCloser
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)))
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+
+	out := make([]byte, 4*len(data)/5+4)
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, err
+	}
+	return out[:n], nil
+}
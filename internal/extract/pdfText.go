@@ -0,0 +1,531 @@
+package extract
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+func pdfText(content []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(content, "\x00\t\n\f\r "), []byte("%PDF")) {
+		return "", ErrUnsupported
+	}
+
+	d := parseDocument(content)
+	for _, num := range d.numbers() {
+		if dd := d.dictOf(d.objects[num]); dd != nil && dd["Encrypt"] != nil {
+			return "", ErrEncrypted
+		}
+	}
+
+	w := &textWriter{}
+	for _, p := range d.pages() {
+		var data [][]byte
+		switch contents := d.resolve(p.dict["Contents"]).(type) {
+		case *stream:
+			if b, err := d.decode(contents); err == nil {
+				data = append(data, b)
+			}
+		case array:
+			for _, c := range contents {
+				if s, ok := d.resolve(c).(*stream); ok {
+					if b, err := d.decode(s); err == nil {
+						data = append(data, b)
+					}
+				}
+			}
+		}
+
+		d.run(bytes.Join(data, []byte("\n")), p.resources, w, 0)
+		w.newline()
+	}
+
+	// Streams past the budget were skipped, so the text would be partial.
+	if d.decoded > maxDecoded {
+		return "", errTooLarge
+	}
+
+	return w.String(), nil
+}
+
+type page struct {
+	dict      dict
+	resources dict
+}
+
+// pages lists the pages in reading order by walking the page tree. Files
+// without a usable catalog fall back to object order.
+func (d *document) pages() []page {
+	var root dict
+	for _, num := range d.numbers() {
+		if dd := d.dictOf(d.objects[num]); dd["Type"] == name("Catalog") {
+			root = dd
+		}
+	}
+
+	var out []page
+	if root != nil {
+		d.walkPages(root["Pages"], nil, &out, 0)
+	}
+	if len(out) > 0 {
+		return out
+	}
+
+	for _, num := range d.numbers() {
+		if dd, ok := d.objects[num].(dict); ok && dd["Type"] == name("Page") {
+			out = append(out, page{dict: dd, resources: d.dictOf(dd["Resources"])})
+		}
+	}
+	return out
+}
+
+func (d *document) walkPages(node any, resources dict, out *[]page, depth int) {
+	dd := d.dictOf(node)
+	if dd == nil || depth > 20 {
+		return
+	}
+
+	if own := d.dictOf(dd["Resources"]); own != nil {
+		resources = own
+	}
+
+	if kids, ok := d.resolve(dd["Kids"]).(array); ok {
+		for _, kid := range kids {
+			d.walkPages(kid, resources, out, depth+1)
+		}
+		return
+	}
+
+	*out = append(*out, page{dict: dd, resources: resources})
+}
+
+// textWriter starts a new line whenever the text moves vertically and puts
+// a space between runs placed separately on the same line. Only the
+// vertical position is tracked; runs are assumed to come in reading order.
+type textWriter struct {
+	strings.Builder
+	lastY float64
+	hasY  bool
+	moved bool
+}
+
+func (w *textWriter) newline() {
+	if s := w.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		w.WriteByte('\n')
+	}
+}
+
+func (w *textWriter) write(s string, y float64) {
+	if s == "" {
+		return
+	}
+
+	switch {
+	case w.hasY && math.Abs(y-w.lastY) > 1:
+		w.newline()
+	case w.moved:
+		if out := w.String(); out != "" && !strings.HasSuffix(out, " ") && !strings.HasSuffix(out, "\n") {
+			w.WriteByte(' ')
+		}
+	}
+
+	w.WriteString(s)
+	w.lastY = y
+	w.hasY = true
+	w.moved = false
+}
+
+func number(operands []any, i int) float64 {
+	if i < len(operands) {
+		if f, ok := operands[i].(float64); ok {
+			return f
+		}
+	}
+	return 0
+}
+
+// run interprets a content stream, following only what affects the text:
+// fonts, text positioning and text showing. Form XObjects are entered.
+func (d *document) run(content []byte, resources dict, w *textWriter, depth int) {
+	fonts := map[name]*font{}
+	fontDicts := d.dictOf(resources["Font"])
+	fontFor := func(n name) *font {
+		if f, ok := fonts[n]; ok {
+			return f
+		}
+		f := d.font(fontDicts[n])
+		fonts[n] = f
+		return f
+	}
+
+	current := defaultFont
+	var y, leading float64
+	var operands []any
+
+	l := &lexer{b: content}
+	for {
+		tok, err := l.token()
+		if err != nil {
+			return
+		}
+
+		kw, ok := tok.(keyword)
+		if !ok || kw == "[" || kw == "<<" {
+			v, _ := l.build(tok)
+			operands = append(operands, v)
+			continue
+		}
+
+		switch kw {
+		case "BT":
+			y = 0
+			w.moved = true
+		case "Tf":
+			if len(operands) >= 2 {
+				if n, ok := operands[len(operands)-2].(name); ok {
+					current = fontFor(n)
+				}
+			}
+		case "TL":
+			leading = number(operands, 0)
+		case "Td", "TD":
+			y += number(operands, 1)
+			if kw == "TD" {
+				leading = -number(operands, 1)
+			}
+			w.moved = true
+		case "Tm":
+			y = number(operands, 5)
+			w.moved = true
+		case "T*", "'", "\"":
+			y -= leading
+			w.newline()
+			w.moved = true
+			if kw != "T*" && len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].([]byte); ok {
+					w.write(current.decode(s), y)
+				}
+			}
+		case "Tj":
+			if s, ok := lastString(operands); ok {
+				w.write(current.decode(s), y)
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				if arr, ok := operands[len(operands)-1].(array); ok {
+					var b strings.Builder
+					for _, item := range arr {
+						switch t := item.(type) {
+						case []byte:
+							b.WriteString(current.decode(t))
+						case float64:
+							// Large negative adjustments are how many
+							// generators write a space.
+							if t < -250 {
+								b.WriteByte(' ')
+							}
+						}
+					}
+					w.write(b.String(), y)
+				}
+			}
+		case "Do":
+			if depth < 5 && len(operands) > 0 {
+				if n, ok := operands[len(operands)-1].(name); ok {
+					d.runForm(d.dictOf(resources["XObject"])[n], resources, w, depth)
+				}
+			}
+		case "ID":
+			l.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+func lastString(operands []any) ([]byte, bool) {
+	if len(operands) == 0 {
+		return nil, false
+	}
+	s, ok := operands[len(operands)-1].([]byte)
+	return s, ok
+}
+
+func (d *document) runForm(v any, resources dict, w *textWriter, depth int) {
+	s, ok := d.resolve(v).(*stream)
+	if !ok || s.dict["Subtype"] != name("Form") {
+		return
+	}
+
+	content, err := d.decode(s)
+	if err != nil {
+		return
+	}
+
+	if own := d.dictOf(s.dict["Resources"]); own != nil {
+		resources = own
+	}
+	d.run(content, resources, w, depth+1)
+}
+
+// skipInlineImage jumps over the binary data between ID and EI.
+func (l *lexer) skipInlineImage() {
+	l.pos++
+	for i := l.pos; i+2 <= len(l.b); i++ {
+		if l.b[i] == 'E' && l.b[i+1] == 'I' && (i == 0 || isSpace(l.b[i-1])) && (i+2 == len(l.b) || isSpace(l.b[i+2])) {
+			l.pos = i + 2
+			return
+		}
+	}
+	l.pos = len(l.b)
+}
+
+// font maps character codes to text, preferring the ToUnicode map that
+// most generators embed and falling back to the font's encoding.
+type font struct {
+	width     int
+	toUnicode map[uint32]string
+	encoding  *[256]rune
+}
+
+var defaultFont = &font{width: 1, encoding: &winAnsiEncoding}
+
+func (d *document) font(v any) *font {
+	fd := d.dictOf(v)
+	if fd == nil {
+		return defaultFont
+	}
+
+	f := &font{width: 1, encoding: &winAnsiEncoding}
+	if fd["Subtype"] == name("Type0") {
+		f.width = 2
+	}
+
+	if s, ok := d.resolve(fd["ToUnicode"]).(*stream); ok {
+		if data, err := d.decode(s); err == nil {
+			var width int
+			f.toUnicode, width = parseCMap(data)
+			if width > 0 {
+				f.width = width
+			}
+		}
+	}
+
+	switch enc := d.resolve(fd["Encoding"]).(type) {
+	case dict:
+		table := winAnsiEncoding
+		code := 0
+		if diffs, ok := d.resolve(enc["Differences"]).(array); ok {
+			for _, item := range diffs {
+				switch t := d.resolve(item).(type) {
+				case float64:
+					code = int(t)
+				case name:
+					if code >= 0 && code < 256 {
+						if r := glyphRune(string(t)); r != 0 {
+							table[code] = r
+						}
+					}
+					code++
+				}
+			}
+		}
+		f.encoding = &table
+	}
+
+	return f
+}
+
+func (f *font) decode(s []byte) string {
+	var b strings.Builder
+	for i := 0; i+f.width <= len(s); i += f.width {
+		code := uint32(s[i])
+		if f.width == 2 {
+			code = code<<8 | uint32(s[i+1])
+		}
+
+		if text, ok := f.toUnicode[code]; ok {
+			b.WriteString(text)
+			continue
+		}
+		if f.width == 1 {
+			if r := f.encoding[code]; r != 0 {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// parseCMap reads the bfchar and bfrange mappings of a ToUnicode CMap and
+// the code width declared by its codespace range.
+func parseCMap(data []byte) (map[uint32]string, int) {
+	m := map[uint32]string{}
+	width := 0
+	l := &lexer{b: data}
+
+	code := func(b []byte) uint32 {
+		var c uint32
+		for _, x := range b {
+			c = c<<8 | uint32(x)
+		}
+		return c
+	}
+
+	for {
+		tok, err := l.token()
+		if err != nil {
+			return m, width
+		}
+
+		switch tok {
+		case keyword("begincodespacerange"):
+			if lo, err := l.token(); err == nil {
+				if b, ok := lo.([]byte); ok && width == 0 {
+					width = len(b)
+				}
+			}
+		case keyword("beginbfchar"):
+			for {
+				src, err := l.token()
+				if err != nil || src == keyword("endbfchar") {
+					break
+				}
+				dst, err := l.token()
+				if err != nil {
+					break
+				}
+				s, ok1 := src.([]byte)
+				t, ok2 := dst.([]byte)
+				if ok1 && ok2 {
+					m[code(s)] = utf16BE(t)
+				}
+			}
+		case keyword("beginbfrange"):
+			for {
+				lo, err := l.token()
+				if err != nil || lo == keyword("endbfrange") {
+					break
+				}
+				hi, err1 := l.token()
+				dst, err2 := l.object()
+				if err1 != nil || err2 != nil {
+					break
+				}
+
+				loBytes, ok1 := lo.([]byte)
+				hiBytes, ok2 := hi.([]byte)
+				if !ok1 || !ok2 {
+					continue
+				}
+				first, last := code(loBytes), code(hiBytes)
+				if last < first || last-first > 0xFFFF {
+					continue
+				}
+
+				switch t := dst.(type) {
+				case []byte:
+					units := utf16Units(t)
+					if len(units) == 0 {
+						continue
+					}
+					for c := first; c <= last; c++ {
+						shifted := slices.Clone(units)
+						shifted[len(shifted)-1] += uint16(c - first)
+						m[c] = string(utf16.Decode(shifted))
+					}
+				case array:
+					for i, item := range t {
+						if b, ok := item.([]byte); ok && first+uint32(i) <= last {
+							m[first+uint32(i)] = utf16BE(b)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func utf16Units(b []byte) []uint16 {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return units
+}
+
+func utf16BE(b []byte) string {
+	if len(b) == 1 {
+		return string(rune(b[0]))
+	}
+	return string(utf16.Decode(utf16Units(b)))
+}
+
+// winAnsiEncoding also stands in for the Standard and MacRoman encodings:
+// they agree on ASCII, which is what matters most here.
+var winAnsiEncoding = func() [256]rune {
+	var t [256]rune
+	for c := 32; c < 127; c++ {
+		t[c] = rune(c)
+	}
+	for c := 0xA0; c <= 0xFF; c++ {
+		t[c] = rune(c)
+	}
+	for c, r := range []rune("€\x00‚ƒ„…†‡ˆ‰Š‹Œ\x00Ž\x00\x00‘’“”•–—˜™š›œ\x00žŸ") {
+		t[0x80+c] = r
+	}
+	return t
+}()
+
+var latin1Glyphs = strings.Fields(`
+	Agrave Aacute Acircumflex Atilde Adieresis Aring AE Ccedilla
+	Egrave Eacute Ecircumflex Edieresis Igrave Iacute Icircumflex Idieresis
+	Eth Ntilde Ograve Oacute Ocircumflex Otilde Odieresis multiply
+	Oslash Ugrave Uacute Ucircumflex Udieresis Yacute Thorn germandbls
+	agrave aacute acircumflex atilde adieresis aring ae ccedilla
+	egrave eacute ecircumflex edieresis igrave iacute icircumflex idieresis
+	eth ntilde ograve oacute ocircumflex otilde odieresis divide
+	oslash ugrave uacute ucircumflex udieresis yacute thorn ydieresis`)
+
+var asciiGlyphs = strings.Fields(`
+	space exclam quotedbl numbersign dollar percent ampersand quotesingle
+	parenleft parenright asterisk plus comma hyphen period slash
+	zero one two three four five six seven eight nine colon semicolon
+	less equal greater question at`)
+
+var otherGlyphs = map[string]rune{
+	"bracketleft": '[', "backslash": '\\', "bracketright": ']', "asciicircum": '^',
+	"underscore": '_', "grave": '`', "braceleft": '{', "bar": '|', "braceright": '}',
+	"asciitilde": '~', "quoteright": '’', "quoteleft": '‘', "quotedblleft": '“',
+	"quotedblright": '”', "bullet": '•', "endash": '–', "emdash": '—',
+	"ellipsis": '…', "fi": 'ﬁ', "fl": 'ﬂ', "ordfeminine": 'ª', "ordmasculine": 'º',
+	"degree": '°', "section": '§', "copyright": '©', "registered": '®',
+	"trademark": '™', "Euro": '€', "minus": '-', "periodcentered": '·',
+}
+
+func glyphRune(glyph string) rune {
+	if len(glyph) == 1 {
+		return rune(glyph[0])
+	}
+	for i, g := range asciiGlyphs {
+		if g == glyph {
+			return rune(32 + i)
+		}
+	}
+	for i, g := range latin1Glyphs {
+		if g == glyph {
+			return rune(0xC0 + i)
+		}
+	}
+	if r, ok := otherGlyphs[glyph]; ok {
+		return r
+	}
+	if strings.HasPrefix(glyph, "uni") && len(glyph) >= 7 {
+		if v, err := strconv.ParseUint(glyph[3:7], 16, 32); err == nil {
+			return rune(v)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,148 @@
+package geo
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"meu_job/internal/models"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// datasetColumns are the columns a dataset must have, in any order and
+// among any others; the header names them.
+var datasetColumns = []string{"cep", "logradouro", "bairro", "cidade", "uf", "latitude", "longitude"}
+
+// datasetResolver answers from a CEP dataset kept in memory. Cities are
+// located at the mean of their CEPs' coordinates.
+type datasetResolver struct {
+	ceps   map[string]*models.Place
+	cities map[string]*models.Coordinates
+}
+
+// NewDatasetResolver loads a CSV dataset, comma or semicolon separated,
+// with a header naming at least datasetColumns. Rows without coordinates
+// are skipped.
+func NewDatasetResolver(path string) (*datasetResolver, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return loadDataset(f)
+}
+
+func loadDataset(r io.Reader) (*datasetResolver, error) {
+	// The header is read ahead to tell the separator.
+	br := bufio.NewReader(r)
+	header, err := br.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	reader := csv.NewReader(io.MultiReader(strings.NewReader(header), br))
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';'
+	}
+	reader.ReuseRecord = true
+
+	record, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading the dataset header: %w", err)
+	}
+	index := map[string]int{}
+	for i, name := range record {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	columns := make([]int, len(datasetColumns))
+	for i, name := range datasetColumns {
+		column, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("the dataset has no %q column", name)
+		}
+		columns[i] = column
+	}
+
+	d := &datasetResolver{
+		ceps:   map[string]*models.Place{},
+		cities: map[string]*models.Coordinates{},
+	}
+	counts := map[string]int{}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(i int) string {
+			return strings.TrimSpace(record[columns[i]])
+		}
+		latitude, errLat := strconv.ParseFloat(field(5), 64)
+		longitude, errLng := strconv.ParseFloat(field(6), 64)
+		if errLat != nil || errLng != nil {
+			continue
+		}
+
+		a := &models.Place{
+			CEP:         models.NormalizeCEP(field(0)),
+			Street:      field(1),
+			District:    field(2),
+			City:        field(3),
+			UF:          strings.ToUpper(field(4)),
+			Coordinates: &models.Coordinates{Latitude: latitude, Longitude: longitude},
+		}
+		d.ceps[a.CEP] = a
+
+		key := cityKey(a.City, a.UF)
+		city, ok := d.cities[key]
+		if !ok {
+			city = &models.Coordinates{}
+			d.cities[key] = city
+		}
+		city.Latitude += latitude
+		city.Longitude += longitude
+		counts[key]++
+	}
+
+	for key, city := range d.cities {
+		city.Latitude /= float64(counts[key])
+		city.Longitude /= float64(counts[key])
+	}
+
+	return d, nil
+}
+
+func cityKey(city, uf string) string {
+	return models.Slugify(city) + "/" + strings.ToUpper(uf)
+}
+
+// Resolve returns a copy, so callers may fill in the rest of the address.
+func (d *datasetResolver) Resolve(cep string) (*models.Place, error) {
+	a, ok := d.ceps[cep]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	found := *a
+	coordinates := *a.Coordinates
+	found.Coordinates = &coordinates
+	return &found, nil
+}
+
+func (d *datasetResolver) Locate(city, uf string) (*models.Coordinates, error) {
+	c, ok := d.cities[cityKey(city, uf)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	found := *c
+	return &found, nil
+}
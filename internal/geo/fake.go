@@ -0,0 +1,23 @@
+package geo
+
+import (
+	"bytes"
+	_ "embed"
+)
+
+// fixtures is a handful of central CEPs of state capitals and their
+// neighbors, with approximate coordinates.
+//
+//go:embed fixtures/ceps.csv
+var fixtures []byte
+
+// NewFakeResolver answers from the embedded fixtures, so local and test
+// environments work without the full dataset. It panics on a malformed
+// fixture, which is a programming error.
+func NewFakeResolver() *datasetResolver {
+	d, err := loadDataset(bytes.NewReader(fixtures))
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
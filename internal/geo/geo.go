@@ -0,0 +1,18 @@
+// Package geo places Brazilian addresses on the map from their CEP, so
+// jobs and candidates can be searched by distance.
+package geo
+
+import (
+	"errors"
+	"meu_job/internal/models"
+)
+
+var ErrNotFound = errors.New("cep not found")
+
+// CEPResolver looks CEPs up. Resolve takes a normalized CEP and returns
+// what is known of its address, with the coordinates; Locate finds a
+// city's coordinates, for places given without a CEP.
+type CEPResolver interface {
+	Resolve(cep string) (*models.Place, error)
+	Locate(city, uf string) (*models.Coordinates, error)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+type apiKeyHandler struct {
+	apiKey services.APIKeyServiceInterface
+	errRsp e.ErrorResponseInterface
+}
+
+type APIKeyHandlerInterface interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	FindAll(w http.ResponseWriter, r *http.Request)
+	Revoke(w http.ResponseWriter, r *http.Request)
+}
+
+func NewAPIKeyHandler(
+	apiKey services.APIKeyServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *apiKeyHandler {
+	return &apiKeyHandler{
+		apiKey: apiKey,
+		errRsp: errRsp,
+	}
+}
+
+func (h *apiKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var dto models.APIKeySaveDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	key := dto.ToModel()
+
+	if err := h.apiKey.Create(key, user, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"api_key": key.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *apiKeyHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	keys, err := h.apiKey.FindAll(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	dtos := make([]*models.APIKeyDTO, len(keys))
+	for i, key := range keys {
+		dtos[i] = key.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"api_keys": dtos}, nil, h.errRsp)
+}
+
+func (h *apiKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	if err := h.apiKey.Revoke(id, user.ID); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, nil, nil, h.errRsp)
+}
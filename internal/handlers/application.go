@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+type applicationHandler struct {
+	application services.ApplicationServiceInterface
+	errRsp      e.ErrorResponseInterface
+}
+
+type ApplicationHandlerInterface interface {
+	FindAll(w http.ResponseWriter, r *http.Request)
+	FindByID(w http.ResponseWriter, r *http.Request)
+	Apply(w http.ResponseWriter, r *http.Request)
+	Withdraw(w http.ResponseWriter, r *http.Request)
+	FindAllByJob(w http.ResponseWriter, r *http.Request)
+	FindForBusiness(w http.ResponseWriter, r *http.Request)
+	SetStage(w http.ResponseWriter, r *http.Request)
+}
+
+func NewApplicationHandler(
+	application services.ApplicationServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *applicationHandler {
+	return &applicationHandler{
+		application: application,
+		errRsp:      errRsp,
+	}
+}
+
+// parseApplicationID reads the business from {id} and the application from
+// {applicationID}.
+func parseApplicationID(
+	w http.ResponseWriter,
+	r *http.Request,
+	errRsp e.ErrorResponseInterface,
+) (int64, int64, bool) {
+	businessID, ok := parseID(w, r, errRsp)
+	if !ok {
+		return 0, 0, false
+	}
+
+	applicationID, err := utils.ReadIntPathVariable(r, "applicationID")
+	if err != nil {
+		errRsp.BadRequestResponse(w, r, err)
+		return 0, 0, false
+	}
+	return businessID, applicationID, true
+}
+
+func (h *applicationHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	applications, err := h.application.FindAll(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	dtos := make([]*models.ApplicationDTO, len(applications))
+	for i, a := range applications {
+		dtos[i] = a.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"applications": dtos}, nil, h.errRsp)
+}
+
+func (h *applicationHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	a, err := h.application.FindByID(id, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"application": a.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *applicationHandler) Apply(w http.ResponseWriter, r *http.Request) {
+	var dto models.ApplyDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	a := dto.ToModel()
+	if err := h.application.Apply(a, user.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"application": a.ToDTO()}, nil, h.errRsp)
+}
+
+// Withdraw takes an optional reason along with the version last read.
+func (h *applicationHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Reason  string `json:"reason"`
+		Version int    `json:"version"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	a, err := h.application.Withdraw(id, user.ID, input.Reason, input.Version, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"application": a.ToDTO()}, nil, h.errRsp)
+}
+
+// FindAllByJob lists the applications to a job; ?stage= narrows them down,
+// and is the only way to list the withdrawn ones.
+func (h *applicationHandler) FindAllByJob(w http.ResponseWriter, r *http.Request) {
+	businessID, jobID, ok := parseJobID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		stage string
+		filters.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+	input.stage = utils.ReadString(qs, "stage", "")
+	input.Filters.Page = utils.ReadInt(qs, "page", 1, v)
+	input.Filters.PageSize = utils.ReadInt(qs, "page_size", 20, v)
+	input.Filters.Sort = utils.ReadString(qs, "sort", "-created_at")
+	input.Filters.SortSafelist = []string{"created_at", "stage_changed_at", "-created_at", "-stage_changed_at"}
+
+	v.Check(input.stage == "" || validator.In(input.stage, models.ApplicationStages...), "stage", "invalid stage")
+	if filters.ValidateFilters(v, input.Filters); !v.Valid() {
+		h.errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	applications, metadata, err := h.application.FindAllByJob(jobID, businessID, user.ID, input.stage, input.Filters)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	dtos := make([]*models.ApplicationDTO, len(applications))
+	for i, a := range applications {
+		dtos[i] = a.ToBusinessDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"applications": dtos, "metadata": metadata}, nil, h.errRsp)
+}
+
+func (h *applicationHandler) FindForBusiness(w http.ResponseWriter, r *http.Request) {
+	businessID, applicationID, ok := parseApplicationID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	a, err := h.application.FindForBusiness(applicationID, businessID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"application": a.ToBusinessDTO()}, nil, h.errRsp)
+}
+
+func (h *applicationHandler) SetStage(w http.ResponseWriter, r *http.Request) {
+	businessID, applicationID, ok := parseApplicationID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var dto models.StageChangeDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	a, err := h.application.SetStage(applicationID, businessID, user.ID, dto, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"application": a.ToBusinessDTO()}, nil, h.errRsp)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"meu_job/internal/models"
 	"meu_job/internal/services"
 	"meu_job/utils"
 	"meu_job/utils/errors"
@@ -36,8 +37,13 @@ func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	session := &models.Session{
+		UserAgent: r.UserAgent(),
+		IP:        utils.ClientIP(r),
+	}
+
 	v := validator.New()
-	token, err := h.auth.Login(v, input.Email, input.Password)
+	token, err := h.auth.Login(v, input.Email, input.Password, session)
 	if err != nil {
 		h.errorResponse.HandlerErrorResponse(w, r, err, v)
 		return
@@ -20,6 +20,8 @@ type businessHandler struct {
 type BusinessHandlerInterface interface {
 	FindAll(w http.ResponseWriter, r *http.Request)
 	AddUserInBusiness(w http.ResponseWriter, r *http.Request)
+	SetVisibility(w http.ResponseWriter, r *http.Request)
+	UploadLogo(w http.ResponseWriter, r *http.Request)
 	GenericHandlerInterface[
 		models.Business,
 		models.BusinessDTO,
@@ -68,6 +70,60 @@ func (h *businessHandler) AddUserInBusiness(w http.ResponseWriter, r *http.Reque
 	)
 }
 
+func (h *businessHandler) SetVisibility(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Public *bool `json:"public"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if v.Check(input.Public != nil, "public", "must be provided"); !v.Valid() {
+		h.errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	if err := h.business.SetPublic(id, user.ID, *input.Public); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"public": *input.Public}, nil, h.errRsp)
+}
+
+// UploadLogo expects a multipart form with the image in the "logo" field.
+func (h *businessHandler) UploadLogo(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	file, content, err := readUpload(w, r, "logo")
+	if err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	business, err := h.business.SetLogo(id, user.ID, file, content, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"business": business.ToDTO()}, nil, h.errRsp)
+}
+
 func (h *businessHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 	var input struct {
 		name, cnpj, email string
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+// companyHandler serves the public company profiles. Nothing here requires
+// authentication, so only CompanyProfileDTO may leave these handlers.
+type companyHandler struct {
+	business services.BusinessServiceInterface
+	file     services.FileServiceInterface
+	errRsp   e.ErrorResponseInterface
+}
+
+type CompanyHandlerInterface interface {
+	FindAll(w http.ResponseWriter, r *http.Request)
+	FindBySlug(w http.ResponseWriter, r *http.Request)
+	Logo(w http.ResponseWriter, r *http.Request)
+}
+
+func NewCompanyHandler(
+	business services.BusinessServiceInterface,
+	file services.FileServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *companyHandler {
+	return &companyHandler{
+		business: business,
+		file:     file,
+		errRsp:   errRsp,
+	}
+}
+
+func (h *companyHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		name, sector, city string
+		filters.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+	input.name = utils.ReadString(qs, "name", "")
+	input.sector = utils.ReadString(qs, "sector", "")
+	input.city = utils.ReadString(qs, "city", "")
+	input.Filters.Page = utils.ReadInt(qs, "page", 1, v)
+	input.Filters.PageSize = utils.ReadInt(qs, "page_size", 20, v)
+	input.Filters.Sort = utils.ReadString(qs, "sort", "name")
+	input.Filters.SortSafelist = []string{"name", "-name", "created_at", "-created_at"}
+
+	if filters.ValidateFilters(v, input.Filters); !v.Valid() {
+		h.errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return
+	}
+
+	businessList, metadata, err := h.business.FindAllPublic(
+		input.name,
+		input.sector,
+		input.city,
+		input.Filters,
+	)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	companies := make([]*models.CompanyProfileDTO, len(businessList))
+	for i, business := range businessList {
+		companies[i] = business.ToProfileDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"companies": companies, "metadata": metadata}, nil, h.errRsp)
+}
+
+func (h *companyHandler) FindBySlug(w http.ResponseWriter, r *http.Request) {
+	slug, err := utils.ReadStringPathVariable(r, "slug")
+	if err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	business, err := h.business.FindPublicBySlug(slug)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"company": business.ToProfileDTO()}, nil, h.errRsp)
+}
+
+// Logo redirects to a short-lived URL of the uploaded logo, so the profile
+// can link to a stable address.
+func (h *companyHandler) Logo(w http.ResponseWriter, r *http.Request) {
+	slug, err := utils.ReadStringPathVariable(r, "slug")
+	if err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	business, err := h.business.FindPublicBySlug(slug)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	if business.LogoFileID == nil {
+		h.errRsp.NotFoundResponse(w, r)
+		return
+	}
+
+	file, err := h.file.SignedURLByID(*business.LogoFileID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	http.Redirect(w, r, file.URL, http.StatusFound)
+}
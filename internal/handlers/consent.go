@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+type consentHandler struct {
+	consent services.ConsentServiceInterface
+	errRsp  e.ErrorResponseInterface
+}
+
+type ConsentHandlerInterface interface {
+	CurrentDocuments(w http.ResponseWriter, r *http.Request)
+	Publish(w http.ResponseWriter, r *http.Request)
+	History(w http.ResponseWriter, r *http.Request)
+	Accept(w http.ResponseWriter, r *http.Request)
+	SetPurpose(w http.ResponseWriter, r *http.Request)
+}
+
+func NewConsentHandler(
+	consent services.ConsentServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *consentHandler {
+	return &consentHandler{
+		consent: consent,
+		errRsp:  errRsp,
+	}
+}
+
+func (h *consentHandler) CurrentDocuments(w http.ResponseWriter, r *http.Request) {
+	current, err := h.consent.CurrentDocuments()
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	docs := make(map[models.DocumentKind]*models.LegalDocumentDTO, len(current))
+	for kind, doc := range current {
+		docs[kind] = doc.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"legal_documents": docs}, nil, h.errRsp)
+}
+
+func (h *consentHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	var dto models.LegalDocumentSaveDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	doc := dto.ToModel()
+
+	if err := h.consent.Publish(doc, user.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"legal_document": doc.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *consentHandler) History(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	consents, purposes, err := h.consent.History(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	dtos := make([]*models.ConsentDTO, len(consents))
+	for i, consent := range consents {
+		dtos[i] = consent.ToDTO()
+	}
+
+	respond(
+		w,
+		r,
+		http.StatusOK,
+		utils.Envelope{
+			"terms_version":   user.TermsVersion,
+			"privacy_version": user.PrivacyVersion,
+			"purposes":        purposes,
+			"history":         dtos,
+		},
+		nil,
+		h.errRsp,
+	)
+}
+
+func (h *consentHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	var acceptance models.ConsentAcceptance
+	if err := utils.ReadJSON(w, r, &acceptance); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	acceptance.IP = utils.ClientIP(r)
+	acceptance.UserAgent = r.UserAgent()
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	if err := h.consent.Accept(user, &acceptance, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, nil, nil, h.errRsp)
+}
+
+func (h *consentHandler) SetPurpose(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Purpose models.ConsentPurpose `json:"purpose"`
+		Granted bool                  `json:"granted"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	consent := &models.Consent{
+		UserID:    user.ID,
+		Subject:   string(input.Purpose),
+		Granted:   input.Granted,
+		IP:        utils.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	v := validator.New()
+	if err := h.consent.SetPurpose(consent, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"consent": consent.ToDTO()}, nil, h.errRsp)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+type curriculumHandler struct {
+	GenericHandlerInterface[models.Curriculum, models.CurriculumDTO]
+	curriculum services.CurriculumServiceInterface
+	errRsp     e.ErrorResponseInterface
+}
+
+type CurriculumHandlerInterface interface {
+	FindAll(w http.ResponseWriter, r *http.Request)
+	SetDefault(w http.ResponseWriter, r *http.Request)
+	Clone(w http.ResponseWriter, r *http.Request)
+	Revisions(w http.ResponseWriter, r *http.Request)
+	Revision(w http.ResponseWriter, r *http.Request)
+	Diff(w http.ResponseWriter, r *http.Request)
+	PDF(w http.ResponseWriter, r *http.Request)
+	ImportJSONResume(w http.ResponseWriter, r *http.Request)
+	ExportJSONResume(w http.ResponseWriter, r *http.Request)
+	GenericHandlerInterface[
+		models.Curriculum,
+		models.CurriculumDTO,
+	]
+}
+
+func NewCurriculumHandler(
+	curriculum services.CurriculumServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *curriculumHandler {
+	return &curriculumHandler{
+		GenericHandlerInterface: NewGenericHandler(curriculum, errRsp),
+		curriculum:              curriculum,
+		errRsp:                  errRsp,
+	}
+}
+
+func (h *curriculumHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	curricula, err := h.curriculum.FindAll(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	dtos := make([]*models.CurriculumDTO, len(curricula))
+	for i, c := range curricula {
+		dtos[i] = c.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"curricula": dtos}, nil, h.errRsp)
+}
+
+func (h *curriculumHandler) SetDefault(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	c, err := h.curriculum.SetDefault(id, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"curriculum": c.ToDTO()}, nil, h.errRsp)
+}
+
+// Clone takes the name of the copy, which defaults to the original's with
+// " (cópia)" appended.
+func (h *curriculumHandler) Clone(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	c, err := h.curriculum.Clone(id, user.ID, input.Name, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"curriculum": c.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *curriculumHandler) Revisions(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	revisions, err := h.curriculum.Revisions(id, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	dtos := make([]*models.CurriculumRevisionDTO, len(revisions))
+	for i, rev := range revisions {
+		dtos[i] = rev.ToDTO(false)
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"revisions": dtos}, nil, h.errRsp)
+}
+
+func (h *curriculumHandler) Revision(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	version, err := utils.ReadIntPathVariable(r, "version")
+	if err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	rev, err := h.curriculum.Revision(id, user.ID, int(version))
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"revision": rev.ToDTO(true)}, nil, h.errRsp)
+}
+
+// Diff compares the revisions in ?from= and ?to=, by default the last two.
+func (h *curriculumHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	qs := r.URL.Query()
+	from := utils.ReadInt(qs, "from", 0, v)
+	to := utils.ReadInt(qs, "to", 0, v)
+	if !v.Valid() {
+		h.errRsp.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	changes, err := h.curriculum.Diff(id, user.ID, from, to, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"changes": changes}, nil, h.errRsp)
+}
+
+// PDF renders the curriculum; ?template= picks the layout.
+func (h *curriculumHandler) PDF(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	c, content, err := h.curriculum.RenderPDF(id, user, r.URL.Query().Get("template"), v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	name := "curriculo-" + models.Slugify(c.FullName) + ".pdf"
+	writeFile(w, name, "application/pdf", int64(len(content)), bytes.NewReader(content))
+}
+
+// ImportJSONResume takes a JSON Resume document as the body. With
+// ?dry_run=true it only returns the mapped curriculum and the warnings.
+func (h *curriculumHandler) ImportJSONResume(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	dryRun := utils.ReadBool(r.URL.Query(), "dry_run", false, v)
+	if !v.Valid() {
+		h.errRsp.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// The document is decoded leniently by the importer: unknown keys are
+	// warnings there, not errors.
+	var raw json.RawMessage
+	if err := utils.ReadJSON(w, r, &raw); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	c, warnings, err := h.curriculum.ImportJSONResume(raw, user, dryRun, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+
+	respond(w, r, status, utils.Envelope{
+		"curriculum": c.ToDTO(),
+		"warnings":   warnings,
+		"dry_run":    dryRun,
+	}, nil, h.errRsp)
+}
+
+func (h *curriculumHandler) ExportJSONResume(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	c, resume, err := h.curriculum.ExportJSONResume(id, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	content, err := json.MarshalIndent(resume, "", "\t")
+	if err != nil {
+		h.errRsp.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	name := "curriculo-" + models.Slugify(c.FullName) + ".json"
+	writeFile(w, name, "application/json", int64(len(content)), bytes.NewReader(content))
+}
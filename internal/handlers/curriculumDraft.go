@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+type curriculumDraftHandler struct {
+	draft  services.CurriculumDraftServiceInterface
+	errRsp e.ErrorResponseInterface
+}
+
+type CurriculumDraftHandlerInterface interface {
+	Upload(w http.ResponseWriter, r *http.Request)
+	FindAll(w http.ResponseWriter, r *http.Request)
+	FindByID(w http.ResponseWriter, r *http.Request)
+	Confirm(w http.ResponseWriter, r *http.Request)
+	Discard(w http.ResponseWriter, r *http.Request)
+}
+
+func NewCurriculumDraftHandler(
+	draft services.CurriculumDraftServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *curriculumDraftHandler {
+	return &curriculumDraftHandler{
+		draft:  draft,
+		errRsp: errRsp,
+	}
+}
+
+// Upload expects a multipart form with the PDF or DOCX résumé in the
+// "file" field. The draft is read in the background: poll it until it is
+// no longer pending.
+func (h *curriculumDraftHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	file, content, err := readUpload(w, r, "file")
+	if err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	d, err := h.draft.Upload(file, content, user.ID, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusAccepted, utils.Envelope{"draft": d.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *curriculumDraftHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	drafts, err := h.draft.FindAll(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	dtos := make([]*models.CurriculumDraftDTO, len(drafts))
+	for i, d := range drafts {
+		dtos[i] = d.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"drafts": dtos}, nil, h.errRsp)
+}
+
+func (h *curriculumDraftHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	d, err := h.draft.FindByID(id, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"draft": d.ToDTO()}, nil, h.errRsp)
+}
+
+// Confirm takes the draft's version and, optionally, the curriculum as the
+// candidate corrected it.
+func (h *curriculumDraftHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Curriculum *models.CurriculumDTO `json:"curriculum"`
+		Version    int                   `json:"version"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	var c *models.Curriculum
+	if input.Curriculum != nil {
+		c = input.Curriculum.ToModel()
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	d, c, err := h.draft.Confirm(id, user.ID, input.Version, c, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{
+		"draft":      d.ToDTO(),
+		"curriculum": c.ToDTO(),
+	}, nil, h.errRsp)
+}
+
+func (h *curriculumDraftHandler) Discard(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	if err := h.draft.Discard(id, user.ID); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, nil, nil, h.errRsp)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+	"path/filepath"
+	"strconv"
+)
+
+type fileHandler struct {
+	file   services.FileServiceInterface
+	errRsp e.ErrorResponseInterface
+}
+
+type FileHandlerInterface interface {
+	Upload(w http.ResponseWriter, r *http.Request)
+	FindAll(w http.ResponseWriter, r *http.Request)
+	FindByID(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	Download(w http.ResponseWriter, r *http.Request)
+}
+
+func NewFileHandler(
+	file services.FileServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *fileHandler {
+	return &fileHandler{
+		file:   file,
+		errRsp: errRsp,
+	}
+}
+
+// readUpload reads the file sent in a multipart form field. Bodies are
+// capped at the largest size any kind accepts; the per-kind limit is
+// checked later by the service. It returns a File with only FileName set.
+func readUpload(w http.ResponseWriter, r *http.Request, field string) (*models.File, []byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, models.MaxFileSize+1_048_576)
+	if err := r.ParseMultipartForm(models.MaxFileSize); err != nil {
+		return nil, nil, fmt.Errorf("body must be a multipart form of at most %d bytes", models.MaxFileSize)
+	}
+
+	f, header, err := r.FormFile(field)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the %s field must contain a file", field)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &models.File{FileName: filepath.Base(header.Filename)}, content, nil
+}
+
+func (h *fileHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	file, content, err := readUpload(w, r, "file")
+	if err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	file.Kind = models.FileKind(r.FormValue("kind"))
+	file.OwnerID = user.ID
+
+	v := validator.New()
+	if err := h.file.Upload(file, content, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	dto, err := h.file.SignedURL(file)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"file": dto}, nil, h.errRsp)
+}
+
+func (h *fileHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	files, err := h.file.FindAll(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	dtos := make([]*models.FileDTO, len(files))
+	for i, file := range files {
+		dtos[i] = file.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"files": dtos}, nil, h.errRsp)
+}
+
+// FindByID returns the metadata with a fresh download URL.
+func (h *fileHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	file, err := h.file.FindByID(id, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	dto, err := h.file.SignedURL(file)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"file": dto}, nil, h.errRsp)
+}
+
+func (h *fileHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	if err := h.file.Delete(id, user.ID); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, nil, nil, h.errRsp)
+}
+
+// Download serves the signed URLs of the local storage. The signature is
+// the authorization, so this route is public.
+func (h *fileHandler) Download(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	expires, err := strconv.ParseInt(qs.Get("expires"), 10, 64)
+	if err != nil {
+		h.errRsp.BadRequestResponse(w, r, errors.New("invalid expires parameter"))
+		return
+	}
+
+	file, body, err := h.file.OpenSigned(qs.Get("key"), expires, qs.Get("signature"))
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+	defer body.Close()
+
+	writeFile(w, file.FileName, file.ContentType, file.Size, body)
+}
+
+func writeFile(w http.ResponseWriter, fileName, contentType string, size int64, body io.Reader) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
@@ -10,10 +10,28 @@ import (
 )
 
 type Handler struct {
-	User     UserHandlerInterface
-	Auth     AuthHandlerInterface
-	Business BusinessHandlerInterface
-	Service  *services.Service
+	User            UserHandlerInterface
+	Auth            AuthHandlerInterface
+	Business        BusinessHandlerInterface
+	APIKey          APIKeyHandlerInterface
+	Session         SessionHandlerInterface
+	Privacy         PrivacyHandlerInterface
+	Consent         ConsentHandlerInterface
+	Verification    VerificationHandlerInterface
+	Company         CompanyHandlerInterface
+	File            FileHandlerInterface
+	Curriculum      CurriculumHandlerInterface
+	CurriculumDraft CurriculumDraftHandlerInterface
+	Skill           SkillHandlerInterface
+	Job             JobHandlerInterface
+	JobMatch        JobMatchHandlerInterface
+	SavedSearch     SavedSearchHandlerInterface
+	Application     ApplicationHandlerInterface
+	Interview       InterviewHandlerInterface
+	Message         MessageHandlerInterface
+	Notification    NotificationHandlerInterface
+	Webhook         WebhookHandlerInterface
+	Service         *services.Service
 }
 
 func NewHandler(
@@ -24,10 +42,28 @@ func NewHandler(
 	s := services.New(db, config)
 
 	return &Handler{
-		Service:  s,
-		User:     NewUserHandler(s.User, errRsp),
-		Auth:     NewAuthHandler(s.Auth, errRsp),
-		Business: NewBusinessHandler(s.Business, errRsp),
+		Service:         s,
+		User:            NewUserHandler(s.User, s.Auth, errRsp),
+		Auth:            NewAuthHandler(s.Auth, errRsp),
+		Business:        NewBusinessHandler(s.Business, errRsp),
+		APIKey:          NewAPIKeyHandler(s.APIKey, errRsp),
+		Session:         NewSessionHandler(s.Session, errRsp),
+		Privacy:         NewPrivacyHandler(s.Privacy, errRsp),
+		Consent:         NewConsentHandler(s.Consent, errRsp),
+		Verification:    NewVerificationHandler(s.Verification, errRsp),
+		Company:         NewCompanyHandler(s.Business, s.File, errRsp),
+		File:            NewFileHandler(s.File, errRsp),
+		Curriculum:      NewCurriculumHandler(s.Curriculum, errRsp),
+		CurriculumDraft: NewCurriculumDraftHandler(s.CurriculumDraft, errRsp),
+		Skill:           NewSkillHandler(s.Skill, errRsp),
+		Job:             NewJobHandler(s.Job, s.JobMatch, errRsp),
+		JobMatch:        NewJobMatchHandler(s.JobMatch, errRsp),
+		SavedSearch:     NewSavedSearchHandler(s.SavedSearch, errRsp),
+		Application:     NewApplicationHandler(s.Application, errRsp),
+		Interview:       NewInterviewHandler(s.Interview, errRsp),
+		Message:         NewMessageHandler(s.Message, s.File, errRsp),
+		Notification:    NewNotificationHandler(s.Notification, errRsp),
+		Webhook:         NewWebhookHandler(s.Webhook, errRsp),
 	}
 }
 
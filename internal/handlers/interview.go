@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"bytes"
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+type interviewHandler struct {
+	interview services.InterviewServiceInterface
+	errRsp    e.ErrorResponseInterface
+}
+
+type InterviewHandlerInterface interface {
+	FindAllByApplication(w http.ResponseWriter, r *http.Request)
+	Accept(w http.ResponseWriter, r *http.Request)
+	CancelByCandidate(w http.ResponseWriter, r *http.Request)
+	InviteForCandidate(w http.ResponseWriter, r *http.Request)
+	FindAllForBusiness(w http.ResponseWriter, r *http.Request)
+	Propose(w http.ResponseWriter, r *http.Request)
+	FindUpcoming(w http.ResponseWriter, r *http.Request)
+	FindForBusiness(w http.ResponseWriter, r *http.Request)
+	Reschedule(w http.ResponseWriter, r *http.Request)
+	CancelByBusiness(w http.ResponseWriter, r *http.Request)
+	InviteForBusiness(w http.ResponseWriter, r *http.Request)
+	CalendarURL(w http.ResponseWriter, r *http.Request)
+	RotateCalendarURL(w http.ResponseWriter, r *http.Request)
+	CalendarFeed(w http.ResponseWriter, r *http.Request)
+}
+
+func NewInterviewHandler(
+	interview services.InterviewServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *interviewHandler {
+	return &interviewHandler{
+		interview: interview,
+		errRsp:    errRsp,
+	}
+}
+
+// parseInterviewID reads the application or business from {id} and the
+// interview from {interviewID}.
+func parseInterviewID(
+	w http.ResponseWriter,
+	r *http.Request,
+	errRsp e.ErrorResponseInterface,
+) (int64, int64, bool) {
+	id, ok := parseID(w, r, errRsp)
+	if !ok {
+		return 0, 0, false
+	}
+
+	interviewID, err := utils.ReadIntPathVariable(r, "interviewID")
+	if err != nil {
+		errRsp.BadRequestResponse(w, r, err)
+		return 0, 0, false
+	}
+	return id, interviewID, true
+}
+
+func interviewDTOs(interviews []*models.Interview) []*models.InterviewDTO {
+	dtos := make([]*models.InterviewDTO, len(interviews))
+	for i, interview := range interviews {
+		dtos[i] = interview.ToDTO()
+	}
+	return dtos
+}
+
+func writeInvite(w http.ResponseWriter, id int64, content []byte) {
+	name := "entrevista-" + strconv.FormatInt(id, 10) + ".ics"
+	writeFile(w, name, calendarContentType, int64(len(content)), bytes.NewReader(content))
+}
+
+func (h *interviewHandler) FindAllByApplication(w http.ResponseWriter, r *http.Request) {
+	applicationID, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	interviews, err := h.interview.FindAllByApplication(applicationID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"interviews": interviewDTOs(interviews)}, nil, h.errRsp)
+}
+
+// Accept takes the start of the slot picked along with the version last
+// read.
+func (h *interviewHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	applicationID, interviewID, ok := parseInterviewID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		StartsAt *time.Time `json:"starts_at"`
+		Version  int        `json:"version"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	i, err := h.interview.Accept(interviewID, applicationID, user.ID, input.StartsAt, input.Version, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"interview": i.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *interviewHandler) CancelByCandidate(w http.ResponseWriter, r *http.Request) {
+	applicationID, interviewID, ok := parseInterviewID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input models.InterviewActionDTO
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	i, err := h.interview.CancelByCandidate(interviewID, applicationID, user.ID, strings.TrimSpace(input.Reason), input.Version, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"interview": i.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *interviewHandler) InviteForCandidate(w http.ResponseWriter, r *http.Request) {
+	applicationID, interviewID, ok := parseInterviewID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	i, err := h.interview.FindForCandidate(interviewID, applicationID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	writeInvite(w, i.ID, h.interview.Invite(i))
+}
+
+func (h *interviewHandler) FindAllForBusiness(w http.ResponseWriter, r *http.Request) {
+	businessID, applicationID, ok := parseApplicationID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	interviews, err := h.interview.FindAllForBusiness(applicationID, businessID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"interviews": interviewDTOs(interviews)}, nil, h.errRsp)
+}
+
+// Propose makes the member calling it the interviewer.
+func (h *interviewHandler) Propose(w http.ResponseWriter, r *http.Request) {
+	businessID, applicationID, ok := parseApplicationID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var dto models.InterviewProposalDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	i := dto.ToModel()
+	if err := h.interview.Propose(i, applicationID, businessID, user.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"interview": i.ToDTO()}, nil, h.errRsp)
+}
+
+// FindUpcoming lists the interviews of every member of the business, the
+// soonest first.
+func (h *interviewHandler) FindUpcoming(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		filters.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+	input.Filters.Page = utils.ReadInt(qs, "page", 1, v)
+	input.Filters.PageSize = utils.ReadInt(qs, "page_size", 20, v)
+	input.Filters.Sort = utils.ReadString(qs, "sort", "starts_at")
+	input.Filters.SortSafelist = []string{"starts_at", "created_at", "-starts_at", "-created_at"}
+
+	if filters.ValidateFilters(v, input.Filters); !v.Valid() {
+		h.errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	interviews, metadata, err := h.interview.FindUpcoming(businessID, user.ID, input.Filters)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"interviews": interviewDTOs(interviews), "metadata": metadata}, nil, h.errRsp)
+}
+
+func (h *interviewHandler) FindForBusiness(w http.ResponseWriter, r *http.Request) {
+	businessID, interviewID, ok := parseInterviewID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	i, err := h.interview.FindForBusiness(interviewID, businessID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"interview": i.ToDTO()}, nil, h.errRsp)
+}
+
+// Reschedule takes a whole new proposal, with the reason for the change.
+func (h *interviewHandler) Reschedule(w http.ResponseWriter, r *http.Request) {
+	businessID, interviewID, ok := parseInterviewID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var dto models.InterviewProposalDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	proposal := dto.ToModel()
+	proposal.ID = interviewID
+	proposal.Version = dto.Version
+	i, err := h.interview.Reschedule(proposal, businessID, user.ID, strings.TrimSpace(dto.Reason), v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"interview": i.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *interviewHandler) CancelByBusiness(w http.ResponseWriter, r *http.Request) {
+	businessID, interviewID, ok := parseInterviewID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input models.InterviewActionDTO
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	i, err := h.interview.CancelByBusiness(interviewID, businessID, user.ID, strings.TrimSpace(input.Reason), input.Version, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"interview": i.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *interviewHandler) InviteForBusiness(w http.ResponseWriter, r *http.Request) {
+	businessID, interviewID, ok := parseInterviewID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	i, err := h.interview.FindForBusiness(interviewID, businessID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	writeInvite(w, i.ID, h.interview.Invite(i))
+}
+
+// CalendarURL is the address to subscribe to in a calendar app.
+func (h *interviewHandler) CalendarURL(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	respond(w, r, http.StatusOK, utils.Envelope{"calendar_url": h.interview.CalendarURL(user)}, nil, h.errRsp)
+}
+
+// RotateCalendarURL answers with the new address; the old one stops
+// working at once.
+func (h *interviewHandler) RotateCalendarURL(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	calendarURL, err := h.interview.RotateCalendarURL(user)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"calendar_url": calendarURL}, nil, h.errRsp)
+}
+
+// CalendarFeed is fetched by calendar apps, so it takes no login: the
+// token in the path is all it needs.
+func (h *interviewHandler) CalendarFeed(w http.ResponseWriter, r *http.Request) {
+	content, err := h.interview.CalendarFeed(chi.URLParam(r, "token"))
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", calendarContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+	"strings"
+)
+
+type jobHandler struct {
+	job    services.JobServiceInterface
+	match  services.JobMatchServiceInterface
+	errRsp e.ErrorResponseInterface
+}
+
+type JobHandlerInterface interface {
+	FindAll(w http.ResponseWriter, r *http.Request)
+	FindByID(w http.ResponseWriter, r *http.Request)
+	Save(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Publish(w http.ResponseWriter, r *http.Request)
+	Close(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	Candidates(w http.ResponseWriter, r *http.Request)
+	Search(w http.ResponseWriter, r *http.Request)
+	FindPublic(w http.ResponseWriter, r *http.Request)
+}
+
+func NewJobHandler(
+	job services.JobServiceInterface,
+	match services.JobMatchServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *jobHandler {
+	return &jobHandler{
+		job:    job,
+		match:  match,
+		errRsp: errRsp,
+	}
+}
+
+// parseJobID reads the business from {id} and the job from {jobID}.
+func parseJobID(
+	w http.ResponseWriter,
+	r *http.Request,
+	errRsp e.ErrorResponseInterface,
+) (int64, int64, bool) {
+	businessID, ok := parseID(w, r, errRsp)
+	if !ok {
+		return 0, 0, false
+	}
+
+	jobID, err := utils.ReadIntPathVariable(r, "jobID")
+	if err != nil {
+		errRsp.BadRequestResponse(w, r, err)
+		return 0, 0, false
+	}
+	return businessID, jobID, true
+}
+
+func (h *jobHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		status string
+		filters.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+	input.status = utils.ReadString(qs, "status", "")
+	input.Filters.Page = utils.ReadInt(qs, "page", 1, v)
+	input.Filters.PageSize = utils.ReadInt(qs, "page_size", 20, v)
+	input.Filters.Sort = utils.ReadString(qs, "sort", "-created_at")
+	input.Filters.SortSafelist = []string{"title", "created_at", "published_at", "-title", "-created_at", "-published_at"}
+
+	v.Check(input.status == "" || validator.In(input.status, models.JobStatuses...), "status", "invalid status")
+	if filters.ValidateFilters(v, input.Filters); !v.Valid() {
+		h.errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	jobs, metadata, err := h.job.FindAll(businessID, user.ID, input.status, input.Filters)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	dtos := make([]*models.JobDTO, len(jobs))
+	for i, j := range jobs {
+		dtos[i] = j.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"jobs": dtos, "metadata": metadata}, nil, h.errRsp)
+}
+
+func (h *jobHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	businessID, jobID, ok := parseJobID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	j, err := h.job.FindByID(jobID, businessID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"job": j.ToDTO()}, nil, h.errRsp)
+}
+
+// Save creates the job as a draft.
+func (h *jobHandler) Save(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var dto models.JobDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	j := dto.ToModel()
+	j.ID = 0
+
+	if err := h.job.Save(j, businessID, user.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"job": j.ToDTO()}, nil, h.errRsp)
+}
+
+// Update replaces the job as a whole against the version last read.
+func (h *jobHandler) Update(w http.ResponseWriter, r *http.Request) {
+	businessID, jobID, ok := parseJobID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var dto models.JobDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	j := dto.ToModel()
+	j.ID = jobID
+
+	if err := h.job.Update(j, businessID, user.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"job": j.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *jobHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, h.job.Publish)
+}
+
+func (h *jobHandler) Close(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, h.job.Close)
+}
+
+// setStatus takes the version last read, so a job edited meanwhile isn't
+// published or closed unseen.
+func (h *jobHandler) setStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	fn func(id, businessID, userID int64, version int, v *validator.Validator) (*models.Job, error),
+) {
+	businessID, jobID, ok := parseJobID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Version int `json:"version"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	j, err := fn(jobID, businessID, user.ID, input.Version, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"job": j.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *jobHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	businessID, jobID, ok := parseJobID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	if err := h.job.Delete(jobID, businessID, user.ID); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, nil, nil, h.errRsp)
+}
+
+// Candidates ranks the candidates for the job, best first unless sorted
+// otherwise; min_score leaves out the weaker ones, max_distance_km those
+// living farther away.
+func (h *jobHandler) Candidates(w http.ResponseWriter, r *http.Request) {
+	businessID, jobID, ok := parseJobID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	matches, metadata, ok := readMatches(w, r, h.errRsp, func(mf models.MatchFilter, f filters.Filters) ([]*models.JobMatch, filters.Metadata, error) {
+		user := contexts.ContextGetUser(r)
+		return h.match.Candidates(jobID, businessID, user.ID, mf, f)
+	})
+	if !ok {
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"candidates": matches, "metadata": metadata}, nil, h.errRsp)
+}
+
+// Search serves the public job search. Only PublicJobDTO may leave it, as
+// it requires no authentication. Relevance is the default order when there
+// is a text query, recency otherwise; near_cep, or lat and lng, add the
+// distance to each job, to filter by radius_km or sort by.
+func (h *jobHandler) Search(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		models.JobSearch
+		filters.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+	input.Query = utils.ReadString(qs, "q", "")
+	input.UF = strings.ToUpper(utils.ReadString(qs, "uf", ""))
+	input.City = utils.ReadString(qs, "city", "")
+	input.RemoteMode = utils.ReadString(qs, "remote_mode", "")
+	input.ContractType = utils.ReadString(qs, "contract_type", "")
+	input.Seniority = utils.ReadString(qs, "seniority", "")
+	input.SalaryBand = utils.ReadString(qs, "salary_band", "")
+	input.Company = utils.ReadString(qs, "company", "")
+	input.NearCEP = models.NormalizeCEP(utils.ReadString(qs, "near_cep", ""))
+	input.Latitude = utils.ReadFloat(qs, "lat", v)
+	input.Longitude = utils.ReadFloat(qs, "lng", v)
+	input.RadiusKM = utils.ReadInt(qs, "radius_km", 0, v)
+
+	sort := "-published_at"
+	if input.Query != "" {
+		sort = "-relevance"
+	}
+	input.Filters.Page = utils.ReadInt(qs, "page", 1, v)
+	input.Filters.PageSize = utils.ReadInt(qs, "page_size", 20, v)
+	input.Filters.Sort = utils.ReadString(qs, "sort", sort)
+	input.Filters.SortSafelist = []string{"-relevance", "published_at", "-published_at", "distance"}
+
+	if filters.ValidateFilters(v, input.Filters); !v.Valid() {
+		h.errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return
+	}
+
+	jobs, facets, metadata, err := h.job.Search(input.JobSearch, input.Filters, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	dtos := make([]*models.PublicJobDTO, len(jobs))
+	for i, j := range jobs {
+		dtos[i] = j.ToPublicDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"jobs": dtos, "facets": facets, "metadata": metadata}, nil, h.errRsp)
+}
+
+func (h *jobHandler) FindPublic(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	j, err := h.job.FindPublic(id)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"job": j.ToPublicDTO()}, nil, h.errRsp)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+type jobMatchHandler struct {
+	match  services.JobMatchServiceInterface
+	errRsp e.ErrorResponseInterface
+}
+
+type JobMatchHandlerInterface interface {
+	FindAll(w http.ResponseWriter, r *http.Request)
+	Explain(w http.ResponseWriter, r *http.Request)
+}
+
+func NewJobMatchHandler(
+	match services.JobMatchServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *jobMatchHandler {
+	return &jobMatchHandler{
+		match:  match,
+		errRsp: errRsp,
+	}
+}
+
+// readMatches reads min_score, max_distance_km and the paging shared by
+// both rankings and lists the matches with fn, writing the error response
+// when it fails. Sorting by distance puts the pairs not geocoded last.
+func readMatches(
+	w http.ResponseWriter,
+	r *http.Request,
+	errRsp e.ErrorResponseInterface,
+	fn func(mf models.MatchFilter, f filters.Filters) ([]*models.JobMatch, filters.Metadata, error),
+) ([]*models.JobMatchDTO, filters.Metadata, bool) {
+	var input struct {
+		models.MatchFilter
+		filters.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+	input.MinScore = utils.ReadInt(qs, "min_score", 0, v)
+	input.MaxDistanceKM = utils.ReadInt(qs, "max_distance_km", 0, v)
+	input.Filters.Page = utils.ReadInt(qs, "page", 1, v)
+	input.Filters.PageSize = utils.ReadInt(qs, "page_size", 20, v)
+	input.Filters.Sort = utils.ReadString(qs, "sort", "-score")
+	input.Filters.SortSafelist = []string{"score", "computed_at", "distance", "-score", "-computed_at"}
+
+	v.Check(input.MinScore >= 0 && input.MinScore <= 100, "min_score", "must be between 0 and 100")
+	v.Check(input.MaxDistanceKM >= 0, "max_distance_km", "must not be negative")
+	if filters.ValidateFilters(v, input.Filters); !v.Valid() {
+		errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return nil, filters.Metadata{}, false
+	}
+
+	matches, metadata, err := fn(input.MatchFilter, input.Filters)
+	if err != nil {
+		errRsp.HandlerErrorResponse(w, r, err, v)
+		return nil, filters.Metadata{}, false
+	}
+
+	dtos := make([]*models.JobMatchDTO, len(matches))
+	for i, m := range matches {
+		dtos[i] = m.ToDTO()
+	}
+	return dtos, metadata, true
+}
+
+// FindAll ranks the published jobs for the user's default curriculum.
+func (h *jobMatchHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	matches, metadata, ok := readMatches(w, r, h.errRsp, func(mf models.MatchFilter, f filters.Filters) ([]*models.JobMatch, filters.Metadata, error) {
+		return h.match.JobsForUser(user.ID, mf, f)
+	})
+	if !ok {
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"matches": matches, "metadata": metadata}, nil, h.errRsp)
+}
+
+// Explain scores the job in the path right away, against the default
+// curriculum or the one given as curriculum_id.
+func (h *jobMatchHandler) Explain(w http.ResponseWriter, r *http.Request) {
+	jobID, err := utils.ReadIntPathVariable(r, "jobID")
+	if err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	curriculumID := utils.ReadInt(r.URL.Query(), "curriculum_id", 0, v)
+	if !v.Valid() {
+		h.errRsp.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	m, err := h.match.Explain(jobID, user.ID, int64(curriculumID))
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"match": m.ToDTO()}, nil, h.errRsp)
+}
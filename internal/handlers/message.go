@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+type messageHandler struct {
+	message services.MessageServiceInterface
+	file    services.FileServiceInterface
+	errRsp  e.ErrorResponseInterface
+}
+
+type MessageHandlerInterface interface {
+	FindAllByApplication(w http.ResponseWriter, r *http.Request)
+	SendAsCandidate(w http.ResponseWriter, r *http.Request)
+	MarkReadByCandidate(w http.ResponseWriter, r *http.Request)
+	FindAllForBusiness(w http.ResponseWriter, r *http.Request)
+	SendAsBusiness(w http.ResponseWriter, r *http.Request)
+	MarkReadByBusiness(w http.ResponseWriter, r *http.Request)
+	Unread(w http.ResponseWriter, r *http.Request)
+}
+
+func NewMessageHandler(
+	message services.MessageServiceInterface,
+	file services.FileServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *messageHandler {
+	return &messageHandler{
+		message: message,
+		file:    file,
+		errRsp:  errRsp,
+	}
+}
+
+// readMessageFilters pages the thread, the latest messages first unless
+// ?sort=created_at.
+func readMessageFilters(r *http.Request, v *validator.Validator) filters.Filters {
+	qs := r.URL.Query()
+	f := filters.Filters{
+		Page:         utils.ReadInt(qs, "page", 1, v),
+		PageSize:     utils.ReadInt(qs, "page_size", 50, v),
+		Sort:         utils.ReadString(qs, "sort", "-created_at"),
+		SortSafelist: []string{"created_at", "-created_at"},
+	}
+	filters.ValidateFilters(v, f)
+	return f
+}
+
+// toDTOs signs the URLs of the attachments, which either side may
+// download.
+func (h *messageHandler) toDTOs(messages []*models.Message) ([]*models.MessageDTO, error) {
+	dtos := make([]*models.MessageDTO, len(messages))
+	for i, m := range messages {
+		dto, err := h.toDTO(m)
+		if err != nil {
+			return nil, err
+		}
+		dtos[i] = dto
+	}
+	return dtos, nil
+}
+
+func (h *messageHandler) toDTO(m *models.Message) (*models.MessageDTO, error) {
+	dto := m.ToDTO()
+	for _, file := range m.Attachments {
+		signed, err := h.file.SignedURL(file)
+		if err != nil {
+			return nil, err
+		}
+		dto.Attachments = append(dto.Attachments, signed)
+	}
+	return dto, nil
+}
+
+func (h *messageHandler) respondThread(
+	w http.ResponseWriter,
+	r *http.Request,
+	messages []*models.Message,
+	metadata filters.Metadata,
+) {
+	dtos, err := h.toDTOs(messages)
+	if err != nil {
+		h.errRsp.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"messages": dtos, "metadata": metadata}, nil, h.errRsp)
+}
+
+func (h *messageHandler) respondSent(w http.ResponseWriter, r *http.Request, m *models.Message) {
+	dto, err := h.toDTO(m)
+	if err != nil {
+		h.errRsp.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"message": dto}, nil, h.errRsp)
+}
+
+func (h *messageHandler) FindAllByApplication(w http.ResponseWriter, r *http.Request) {
+	applicationID, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	f := readMessageFilters(r, v)
+	if !v.Valid() {
+		h.errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	messages, metadata, err := h.message.FindAllByApplication(applicationID, user.ID, f)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	h.respondThread(w, r, messages, metadata)
+}
+
+func (h *messageHandler) SendAsCandidate(w http.ResponseWriter, r *http.Request) {
+	applicationID, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var dto models.SendMessageDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	m := dto.ToModel()
+	m.Sender.Name = user.Name
+	if err := h.message.SendAsCandidate(m, applicationID, user.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	h.respondSent(w, r, m)
+}
+
+// MarkReadByCandidate is the read receipt: the business sees when its
+// messages were read.
+func (h *messageHandler) MarkReadByCandidate(w http.ResponseWriter, r *http.Request) {
+	applicationID, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	read, err := h.message.MarkReadByCandidate(applicationID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"read": read}, nil, h.errRsp)
+}
+
+func (h *messageHandler) FindAllForBusiness(w http.ResponseWriter, r *http.Request) {
+	businessID, applicationID, ok := parseApplicationID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	f := readMessageFilters(r, v)
+	if !v.Valid() {
+		h.errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	messages, metadata, err := h.message.FindAllForBusiness(applicationID, businessID, user.ID, f)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	h.respondThread(w, r, messages, metadata)
+}
+
+func (h *messageHandler) SendAsBusiness(w http.ResponseWriter, r *http.Request) {
+	businessID, applicationID, ok := parseApplicationID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var dto models.SendMessageDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	m := dto.ToModel()
+	m.Sender.Name = user.Name
+	if err := h.message.SendAsBusiness(m, applicationID, businessID, user.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	h.respondSent(w, r, m)
+}
+
+func (h *messageHandler) MarkReadByBusiness(w http.ResponseWriter, r *http.Request) {
+	businessID, applicationID, ok := parseApplicationID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	read, err := h.message.MarkReadByBusiness(applicationID, businessID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"read": read}, nil, h.errRsp)
+}
+
+// Unread counts the unread messages by thread, as candidate and as member
+// of a business alike.
+func (h *messageHandler) Unread(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	threads, err := h.message.Unread(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	total := 0
+	dtos := make([]*models.UnreadThreadDTO, len(threads))
+	for i, t := range threads {
+		total += t.Unread
+		dtos[i] = t.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"unread": total, "threads": dtos}, nil, h.errRsp)
+}
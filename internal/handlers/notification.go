@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// streamRetry is how long browsers wait before reconnecting a stream
+	// that dropped, as on a deploy.
+	streamRetry = 3 * time.Second
+	// streamHeartbeat keeps proxies from closing an idle stream, finds out
+	// about clients that went away and checks the credentials again.
+	streamHeartbeat = 20 * time.Second
+	// streamWriteTimeout is how long each write to a stream may take. It
+	// stands in for the server's WriteTimeout, which counts from the start
+	// of the request and would cut every stream off.
+	streamWriteTimeout = 10 * time.Second
+	streamBatch        = 100
+)
+
+type notificationHandler struct {
+	notification services.NotificationServiceInterface
+	errRsp       e.ErrorResponseInterface
+}
+
+type NotificationHandlerInterface interface {
+	FindAll(w http.ResponseWriter, r *http.Request)
+	MarkRead(w http.ResponseWriter, r *http.Request)
+	MarkAllRead(w http.ResponseWriter, r *http.Request)
+	Preferences(w http.ResponseWriter, r *http.Request)
+	SetPreferences(w http.ResponseWriter, r *http.Request)
+	Stream(w http.ResponseWriter, r *http.Request)
+}
+
+func NewNotificationHandler(
+	notification services.NotificationServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *notificationHandler {
+	return &notificationHandler{
+		notification: notification,
+		errRsp:       errRsp,
+	}
+}
+
+func notificationDTOs(notifications []*models.Notification) []*models.NotificationDTO {
+	dtos := make([]*models.NotificationDTO, len(notifications))
+	for i, n := range notifications {
+		dtos[i] = n.ToDTO()
+	}
+	return dtos
+}
+
+// FindAll lists the latest notifications first, only the unread ones with
+// ?unread=true, along with how many are unread.
+func (h *notificationHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	qs := r.URL.Query()
+	unreadOnly := utils.ReadBool(qs, "unread", false, v)
+	f := filters.Filters{
+		Page:         utils.ReadInt(qs, "page", 1, v),
+		PageSize:     utils.ReadInt(qs, "page_size", 20, v),
+		Sort:         utils.ReadString(qs, "sort", "-created_at"),
+		SortSafelist: []string{"created_at", "-created_at"},
+	}
+
+	if filters.ValidateFilters(v, f); !v.Valid() {
+		h.errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	notifications, metadata, err := h.notification.FindAll(user.ID, unreadOnly, f)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	unread, err := h.notification.UnreadCount(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{
+		"notifications": notificationDTOs(notifications),
+		"unread":        unread,
+		"metadata":      metadata,
+	}, nil, h.errRsp)
+}
+
+func (h *notificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	n, err := h.notification.MarkRead(id, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"notification": n.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *notificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	read, err := h.notification.MarkAllRead(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"read": read}, nil, h.errRsp)
+}
+
+func (h *notificationHandler) Preferences(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	prefs, err := h.notification.Preferences(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"preferences": prefs}, nil, h.errRsp)
+}
+
+// SetPreferences takes the types to turn on or off, as in
+// {"preferences": {"message_received": false}}.
+func (h *notificationHandler) SetPreferences(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Preferences models.NotificationPreferences `json:"preferences"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	prefs, err := h.notification.SetPreferences(user.ID, input.Preferences, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"preferences": prefs}, nil, h.errRsp)
+}
+
+// Stream sends the user's notifications as Server-Sent Events while the
+// connection lasts. Each one is a "notification" event whose id is the
+// notification's, followed by an "unread" event with the count. Clients
+// reconnecting with Last-Event-ID get what they missed; new connections
+// start from now. The stream ends when the server shuts down, and the
+// client reconnects to another instance. It also ends when the token or key
+// it was opened with expires, or at the next heartbeat once it's no longer
+// good, as when the session is revoked, the password changed or the
+// account erased; reconnecting then takes new credentials.
+func (h *notificationHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	creds := contexts.ContextGetCredentials(r)
+
+	lastID, ok := h.lastEventID(w, r)
+	if !ok {
+		return
+	}
+
+	// Subscribing before finding where to start means nothing created in
+	// between is missed.
+	signals, unsubscribe := h.notification.Subscribe(user.ID)
+	defer unsubscribe()
+
+	if lastID == 0 {
+		var err error
+		if lastID, err = h.notification.LatestID(user.ID); err != nil {
+			h.errRsp.ServerErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	// The server's ReadTimeout would otherwise end the stream too, as the
+	// read watching for the client to hang up times out.
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		h.errRsp.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	s := &eventStream{w: w, rc: rc}
+	if err := s.write(fmt.Sprintf("retry: %d\n\n", streamRetry.Milliseconds())); err != nil {
+		return
+	}
+
+	lastID, err := h.sendSince(s, user.ID, lastID, true)
+	if err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	var expired <-chan time.Time
+	if creds != nil && creds.ExpiresAt != nil {
+		expiry := time.NewTimer(time.Until(*creds.ExpiresAt))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-expired:
+			return
+		case _, ok := <-signals:
+			if !ok {
+				return
+			}
+			if lastID, err = h.sendSince(s, user.ID, lastID, false); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if creds != nil && creds.Check() != nil {
+				return
+			}
+			if err := s.write(": ping\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// lastEventID reads where a reconnecting client left off, from the header
+// browsers send or from ?last_event_id for clients that can't set it.
+func (h *notificationHandler) lastEventID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	if raw == "" {
+		return 0, true
+	}
+
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 1 {
+		h.errRsp.BadRequestResponse(w, r, errors.New("invalid Last-Event-ID"))
+		return 0, false
+	}
+	return id, true
+}
+
+// sendSince sends the notifications created after lastID, then the unread
+// count if any were sent or always is set, and reports the last one sent.
+func (h *notificationHandler) sendSince(s *eventStream, userID, lastID int64, always bool) (int64, error) {
+	sent := false
+	for {
+		notifications, err := h.notification.FindAfter(userID, lastID, streamBatch)
+		if err != nil {
+			return lastID, err
+		}
+
+		for _, n := range notifications {
+			if err := s.event(strconv.FormatInt(n.ID, 10), "notification", n.ToDTO()); err != nil {
+				return lastID, err
+			}
+			lastID, sent = n.ID, true
+		}
+		if len(notifications) < streamBatch {
+			break
+		}
+	}
+
+	if !sent && !always {
+		return lastID, nil
+	}
+
+	unread, err := h.notification.UnreadCount(userID)
+	if err != nil {
+		return lastID, err
+	}
+	return lastID, s.event("", "unread", utils.Envelope{"unread": unread})
+}
+
+// eventStream writes Server-Sent Events, flushing each one.
+type eventStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (s *eventStream) event(id, name string, data any) error {
+	js, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("event: %s\ndata: %s\n\n", name, js)
+	if id != "" {
+		msg = "id: " + id + "\n" + msg
+	}
+	return s.write(msg)
+}
+
+func (s *eventStream) write(msg string) error {
+	if err := s.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+		return err
+	}
+	if _, err := s.w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
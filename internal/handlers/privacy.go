@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"fmt"
+	"meu_job/internal/contexts"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+type privacyHandler struct {
+	privacy services.PrivacyServiceInterface
+	errRsp  e.ErrorResponseInterface
+}
+
+type PrivacyHandlerInterface interface {
+	Export(w http.ResponseWriter, r *http.Request)
+	RequestErasure(w http.ResponseWriter, r *http.Request)
+	CancelErasure(w http.ResponseWriter, r *http.Request)
+}
+
+func NewPrivacyHandler(
+	privacy services.PrivacyServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *privacyHandler {
+	return &privacyHandler{
+		privacy: privacy,
+		errRsp:  errRsp,
+	}
+}
+
+func (h *privacyHandler) Export(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	export, err := h.privacy.Export(user)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set(
+		"Content-Disposition",
+		fmt.Sprintf(`attachment; filename="meu_job-data-export-%d.json"`, user.ID),
+	)
+
+	respond(w, r, http.StatusOK, utils.Envelope{"export": export}, headers, h.errRsp)
+}
+
+func (h *privacyHandler) RequestErasure(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Password string `json:"password"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	if err := h.privacy.RequestErasure(user, input.Password, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(
+		w,
+		r,
+		http.StatusAccepted,
+		utils.Envelope{"erasure_scheduled_for": user.ErasureScheduledFor},
+		nil,
+		h.errRsp,
+	)
+}
+
+func (h *privacyHandler) CancelErasure(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	if err := h.privacy.CancelErasure(user); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, nil, nil, h.errRsp)
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"html/template"
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+	"strings"
+)
+
+type savedSearchHandler struct {
+	savedSearch services.SavedSearchServiceInterface
+	errRsp      e.ErrorResponseInterface
+}
+
+type SavedSearchHandlerInterface interface {
+	FindAll(w http.ResponseWriter, r *http.Request)
+	FindByID(w http.ResponseWriter, r *http.Request)
+	Save(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	ConfirmUnsubscribe(w http.ResponseWriter, r *http.Request)
+	Unsubscribe(w http.ResponseWriter, r *http.Request)
+}
+
+func NewSavedSearchHandler(
+	savedSearch services.SavedSearchServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *savedSearchHandler {
+	return &savedSearchHandler{
+		savedSearch: savedSearch,
+		errRsp:      errRsp,
+	}
+}
+
+func (h *savedSearchHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	searches, err := h.savedSearch.FindAll(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	dtos := make([]*models.SavedSearchDTO, len(searches))
+	for i, s := range searches {
+		dtos[i] = s.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"saved_searches": dtos}, nil, h.errRsp)
+}
+
+func (h *savedSearchHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	s, err := h.savedSearch.FindByID(id, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"saved_search": s.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *savedSearchHandler) Save(w http.ResponseWriter, r *http.Request) {
+	var dto models.SavedSearchDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	s := dto.ToModel()
+	if err := h.savedSearch.Save(s, user.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"saved_search": s.ToDTO()}, nil, h.errRsp)
+}
+
+// Update replaces the search as a whole against the version last read.
+func (h *savedSearchHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var dto models.SavedSearchDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	s := dto.ToModel()
+	s.ID = id
+	if err := h.savedSearch.Update(s, user.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"saved_search": s.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *savedSearchHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	if err := h.savedSearch.Delete(id, user.ID); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, nil, nil, h.errRsp)
+}
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head><meta charset="utf-8"><title>Alertas de vagas</title></head>
+<body>
+{{if .Done}}<p>Você não receberá mais os alertas desta busca.</p>
+{{else}}<form method="post" action="?token={{.Token}}">
+<p>Deseja parar de receber os alertas desta busca?</p>
+<button type="submit">Cancelar alertas</button>
+</form>
+{{end}}</body>
+</html>
+`))
+
+func writeUnsubscribePage(w http.ResponseWriter, token string, done bool) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	unsubscribePage.Execute(w, struct {
+		Token string
+		Done  bool
+	}{token, done})
+}
+
+// ConfirmUnsubscribe is where the link in the digests lands, so it takes no
+// login: the token in ?token= is all it needs. It only asks to confirm, as
+// mail scanners and link previews follow links on their own.
+func (h *savedSearchHandler) ConfirmUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if err := h.savedSearch.CheckUnsubscribe(token); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	writeUnsubscribePage(w, token, false)
+}
+
+// Unsubscribe is posted to by the confirmation page and by mail clients
+// offering one-click unsubscribe (RFC 8058). Browsers get a page back.
+func (h *savedSearchHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	s, err := h.savedSearch.Unsubscribe(token)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		writeUnsubscribePage(w, token, true)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{
+		"message":      "you will no longer receive alerts for this search",
+		"saved_search": s.ToDTO(),
+	}, nil, h.errRsp)
+}
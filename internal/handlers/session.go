@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"net/http"
+)
+
+type sessionHandler struct {
+	session services.SessionServiceInterface
+	errRsp  e.ErrorResponseInterface
+}
+
+type SessionHandlerInterface interface {
+	FindAll(w http.ResponseWriter, r *http.Request)
+	Revoke(w http.ResponseWriter, r *http.Request)
+	RevokeOthers(w http.ResponseWriter, r *http.Request)
+}
+
+func NewSessionHandler(
+	session services.SessionServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *sessionHandler {
+	return &sessionHandler{
+		session: session,
+		errRsp:  errRsp,
+	}
+}
+
+func (h *sessionHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	current := contexts.ContextGetSession(r)
+
+	sessions, err := h.session.FindAll(user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	dtos := make([]*models.SessionDTO, len(sessions))
+	for i, session := range sessions {
+		dtos[i] = session.ToDTO()
+		dtos[i].Current = current != nil && session.ID == current.ID
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"sessions": dtos}, nil, h.errRsp)
+}
+
+func (h *sessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	if err := h.session.Revoke(id, user.ID); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, nil, nil, h.errRsp)
+}
+
+func (h *sessionHandler) RevokeOthers(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+
+	var currentID int64
+	if current := contexts.ContextGetSession(r); current != nil {
+		currentID = current.ID
+	}
+
+	if err := h.session.RevokeOthers(user.ID, currentID); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, nil, nil, h.errRsp)
+}
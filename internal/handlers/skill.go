@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+type skillHandler struct {
+	skill  services.SkillServiceInterface
+	errRsp e.ErrorResponseInterface
+}
+
+type SkillHandlerInterface interface {
+	Search(w http.ResponseWriter, r *http.Request)
+	FindByID(w http.ResponseWriter, r *http.Request)
+	Save(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Merge(w http.ResponseWriter, r *http.Request)
+}
+
+func NewSkillHandler(
+	skill services.SkillServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *skillHandler {
+	return &skillHandler{
+		skill:  skill,
+		errRsp: errRsp,
+	}
+}
+
+// Search takes the text typed so far in q, and optionally a category and
+// how many suggestions to return.
+func (h *skillHandler) Search(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+	q := utils.ReadString(qs, "q", "")
+	category := utils.ReadString(qs, "category", "")
+	limit := utils.ReadInt(qs, "limit", 0, v)
+	if !v.Valid() {
+		h.errRsp.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	skills, err := h.skill.Search(q, category, limit, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	dtos := make([]*models.SkillDTO, len(skills))
+	for i, s := range skills {
+		dtos[i] = s.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"skills": dtos}, nil, h.errRsp)
+}
+
+func (h *skillHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	s, err := h.skill.FindByID(id)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"skill": s.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *skillHandler) Save(w http.ResponseWriter, r *http.Request) {
+	var dto models.SkillDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	admin := contexts.ContextGetUser(r)
+	s := dto.ToModel()
+	s.ID = 0
+
+	if err := h.skill.Save(s, admin.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"skill": s.ToDTO()}, nil, h.errRsp)
+}
+
+// Update replaces the skill as a whole, synonyms included, against the
+// version last read.
+func (h *skillHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var dto models.SkillDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	admin := contexts.ContextGetUser(r)
+	s := dto.ToModel()
+	s.ID = id
+
+	if err := h.skill.Update(s, admin.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"skill": s.ToDTO()}, nil, h.errRsp)
+}
+
+// Merge folds the skill in the path into the one given as "into" and
+// returns the latter.
+func (h *skillHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Into int64 `json:"into"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	admin := contexts.ContextGetUser(r)
+	s, err := h.skill.Merge(id, input.Into, admin.ID, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"skill": s.ToDTO()}, nil, h.errRsp)
+}
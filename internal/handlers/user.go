@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"meu_job/internal/contexts"
 	"meu_job/internal/models"
 	"meu_job/internal/services"
 	"meu_job/utils"
@@ -11,20 +12,28 @@ import (
 
 type UserHandler struct {
 	user   services.UserServiceInterface
+	auth   services.AuthServiceInterface
 	errRsp e.ErrorResponseInterface
 }
 
 type UserHandlerInterface interface {
 	ActivateUserHandler(w http.ResponseWriter, r *http.Request)
 	CreateUserHandler(w http.ResponseWriter, r *http.Request)
+	GetMe(w http.ResponseWriter, r *http.Request)
+	UpdateMe(w http.ResponseWriter, r *http.Request)
+	ChangePassword(w http.ResponseWriter, r *http.Request)
+	RequestEmailChange(w http.ResponseWriter, r *http.Request)
+	ConfirmEmailChange(w http.ResponseWriter, r *http.Request)
 }
 
 func NewUserHandler(
 	user services.UserServiceInterface,
+	auth services.AuthServiceInterface,
 	errRsp e.ErrorResponseInterface,
 ) *UserHandler {
 	return &UserHandler{
 		user:   user,
+		auth:   auth,
 		errRsp: errRsp,
 	}
 }
@@ -75,8 +84,12 @@ func (h *UserHandler) CreateUserHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	acceptance := &userDTO.ConsentAcceptance
+	acceptance.IP = utils.ClientIP(r)
+	acceptance.UserAgent = r.UserAgent()
+
 	v := validator.New()
-	err = h.user.RegisterUserHandler(user, v)
+	err = h.user.RegisterUserHandler(user, acceptance, v)
 	if err != nil {
 		h.errRsp.HandlerErrorResponse(w, r, err, v)
 		return
@@ -91,3 +104,107 @@ func (h *UserHandler) CreateUserHandler(w http.ResponseWriter, r *http.Request)
 		h.errRsp,
 	)
 }
+
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	user := contexts.ContextGetUser(r)
+	respond(w, r, http.StatusOK, utils.Envelope{"user": user.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	var dto models.UserUpdateDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	if err := h.user.UpdateProfile(user, &dto, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"user": user.ToDTO()}, nil, h.errRsp)
+}
+
+// ChangePassword answers with a new token for the session that made the
+// request, as the one it used is no longer valid; API keys carry on as
+// they are.
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	var sessionID int64
+	if session := contexts.ContextGetSession(r); session != nil {
+		sessionID = session.ID
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	err := h.user.ChangePassword(user, input.CurrentPassword, input.NewPassword, sessionID, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	if sessionID == 0 {
+		respond(w, r, http.StatusNoContent, nil, nil, h.errRsp)
+		return
+	}
+
+	token, err := h.auth.IssueToken(user, sessionID)
+	if err != nil {
+		h.errRsp.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"authentication_token": token}, nil, h.errRsp)
+}
+
+func (h *UserHandler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	if err := h.user.RequestEmailChange(user, input.Email, input.Password, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusAccepted, utils.Envelope{"pending_email": input.Email}, nil, h.errRsp)
+}
+
+func (h *UserHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Cod int `json:"cod"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	if err := h.user.ConfirmEmailChange(user, input.Cod, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"user": user.ToDTO()}, nil, h.errRsp)
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+type verificationHandler struct {
+	verification services.VerificationServiceInterface
+	errRsp       e.ErrorResponseInterface
+}
+
+type VerificationHandlerInterface interface {
+	Status(w http.ResponseWriter, r *http.Request)
+	UploadDocument(w http.ResponseWriter, r *http.Request)
+	Submit(w http.ResponseWriter, r *http.Request)
+	Queue(w http.ResponseWriter, r *http.Request)
+	ReviewDetails(w http.ResponseWriter, r *http.Request)
+	Document(w http.ResponseWriter, r *http.Request)
+	Approve(w http.ResponseWriter, r *http.Request)
+	Reject(w http.ResponseWriter, r *http.Request)
+}
+
+func NewVerificationHandler(
+	verification services.VerificationServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *verificationHandler {
+	return &verificationHandler{
+		verification: verification,
+		errRsp:       errRsp,
+	}
+}
+
+func (h *verificationHandler) Status(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	business, docs, err := h.verification.Status(id, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"verification": business.ToVerificationDTO(docs)}, nil, h.errRsp)
+}
+
+// UploadDocument expects a multipart form with the file in the "document"
+// field.
+func (h *verificationHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	file, content, err := readUpload(w, r, "document")
+	if err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	doc, err := h.verification.UploadDocument(id, user.ID, file, content, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"document": doc.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *verificationHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	business, err := h.verification.Submit(id, user.ID, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusAccepted, utils.Envelope{"business": business.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *verificationHandler) Queue(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		status string
+		filters.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+	input.status = utils.ReadString(qs, "status", string(models.VerificationPending))
+	input.Filters.Page = utils.ReadInt(qs, "page", 1, v)
+	input.Filters.PageSize = utils.ReadInt(qs, "page_size", 20, v)
+	input.Filters.Sort = "id"
+	input.Filters.SortSafelist = []string{"id"}
+
+	status := models.VerificationStatus(input.status)
+	v.Check(status.IsValid(), "status", "must be unverified, pending, verified or rejected")
+	if filters.ValidateFilters(v, input.Filters); !v.Valid() {
+		h.errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return
+	}
+
+	businessList, metadata, err := h.verification.Queue(status, input.Filters)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	dtos := make([]*models.BusinessDTO, len(businessList))
+	for i, business := range businessList {
+		dtos[i] = business.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"business": dtos, "metadata": metadata}, nil, h.errRsp)
+}
+
+func (h *verificationHandler) ReviewDetails(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	business, docs, err := h.verification.ReviewDetails(id)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(
+		w,
+		r,
+		http.StatusOK,
+		utils.Envelope{
+			"business":     business.ToDTO(),
+			"verification": business.ToVerificationDTO(docs),
+		},
+		nil,
+		h.errRsp,
+	)
+}
+
+func (h *verificationHandler) Document(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	documentID, err := utils.ReadIntPathVariable(r, "documentID")
+	if err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	file, err := h.verification.Document(id, documentID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	http.Redirect(w, r, file.URL, http.StatusFound)
+}
+
+func (h *verificationHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, true)
+}
+
+func (h *verificationHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, false)
+}
+
+func (h *verificationHandler) review(w http.ResponseWriter, r *http.Request, approve bool) {
+	id, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+
+	if r.ContentLength != 0 {
+		if err := utils.ReadJSON(w, r, &input); err != nil {
+			h.errRsp.BadRequestResponse(w, r, err)
+			return
+		}
+	}
+
+	v := validator.New()
+	admin := contexts.ContextGetUser(r)
+	business, err := h.verification.Review(id, admin.ID, approve, input.Reason, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"business": business.ToDTO()}, nil, h.errRsp)
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"meu_job/internal/contexts"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/services"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/http"
+)
+
+type webhookHandler struct {
+	webhook services.WebhookServiceInterface
+	errRsp  e.ErrorResponseInterface
+}
+
+type WebhookHandlerInterface interface {
+	FindAll(w http.ResponseWriter, r *http.Request)
+	FindByID(w http.ResponseWriter, r *http.Request)
+	Save(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	RotateSecret(w http.ResponseWriter, r *http.Request)
+	Ping(w http.ResponseWriter, r *http.Request)
+	FindDeliveries(w http.ResponseWriter, r *http.Request)
+	FindDelivery(w http.ResponseWriter, r *http.Request)
+	Redeliver(w http.ResponseWriter, r *http.Request)
+}
+
+func NewWebhookHandler(
+	webhook services.WebhookServiceInterface,
+	errRsp e.ErrorResponseInterface,
+) *webhookHandler {
+	return &webhookHandler{
+		webhook: webhook,
+		errRsp:  errRsp,
+	}
+}
+
+// parseWebhookID reads the business from {id} and the webhook from
+// {webhookID}.
+func parseWebhookID(
+	w http.ResponseWriter,
+	r *http.Request,
+	errRsp e.ErrorResponseInterface,
+) (int64, int64, bool) {
+	businessID, ok := parseID(w, r, errRsp)
+	if !ok {
+		return 0, 0, false
+	}
+
+	webhookID, err := utils.ReadIntPathVariable(r, "webhookID")
+	if err != nil {
+		errRsp.BadRequestResponse(w, r, err)
+		return 0, 0, false
+	}
+	return businessID, webhookID, true
+}
+
+// parseDeliveryID also reads the delivery from {deliveryID}.
+func parseDeliveryID(
+	w http.ResponseWriter,
+	r *http.Request,
+	errRsp e.ErrorResponseInterface,
+) (int64, int64, int64, bool) {
+	businessID, webhookID, ok := parseWebhookID(w, r, errRsp)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	deliveryID, err := utils.ReadIntPathVariable(r, "deliveryID")
+	if err != nil {
+		errRsp.BadRequestResponse(w, r, err)
+		return 0, 0, 0, false
+	}
+	return businessID, webhookID, deliveryID, true
+}
+
+func (h *webhookHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	webhooks, err := h.webhook.FindAll(businessID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	dtos := make([]*models.WebhookDTO, len(webhooks))
+	for i, wh := range webhooks {
+		dtos[i] = wh.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"webhooks": dtos}, nil, h.errRsp)
+}
+
+func (h *webhookHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	businessID, webhookID, ok := parseWebhookID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	wh, err := h.webhook.FindByID(webhookID, businessID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"webhook": wh.ToDTO()}, nil, h.errRsp)
+}
+
+// Save registers the endpoint. The secret is in the response, and only
+// there until it's rotated.
+func (h *webhookHandler) Save(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := parseID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var dto models.WebhookDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	wh := dto.ToModel()
+	wh.ID = 0
+
+	if err := h.webhook.Save(wh, businessID, user.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, utils.Envelope{"webhook": wh.ToDTO()}, nil, h.errRsp)
+}
+
+// Update replaces the webhook as a whole against the version last read;
+// the secret is left as it is.
+func (h *webhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	businessID, webhookID, ok := parseWebhookID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var dto models.WebhookDTO
+	if err := utils.ReadJSON(w, r, &dto); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	wh := dto.ToModel()
+	wh.ID = webhookID
+
+	if err := h.webhook.Update(wh, businessID, user.ID, v); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"webhook": wh.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *webhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	businessID, webhookID, ok := parseWebhookID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	if err := h.webhook.Delete(webhookID, businessID, user.ID); err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, nil, nil, h.errRsp)
+}
+
+// RotateSecret takes the version last read and answers with the new secret.
+func (h *webhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	businessID, webhookID, ok := parseWebhookID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Version int `json:"version"`
+	}
+
+	if err := utils.ReadJSON(w, r, &input); err != nil {
+		h.errRsp.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	wh, err := h.webhook.RotateSecret(webhookID, businessID, user.ID, input.Version, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"webhook": wh.ToDTO()}, nil, h.errRsp)
+}
+
+// Ping answers with the queued delivery, to be polled for how it went.
+func (h *webhookHandler) Ping(w http.ResponseWriter, r *http.Request) {
+	businessID, webhookID, ok := parseWebhookID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	d, err := h.webhook.Ping(webhookID, businessID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusAccepted, utils.Envelope{"delivery": d.ToDTO()}, nil, h.errRsp)
+}
+
+// FindDeliveries lists the deliveries to the webhook, newest first; status
+// narrows them down, to the dead ones say.
+func (h *webhookHandler) FindDeliveries(w http.ResponseWriter, r *http.Request) {
+	businessID, webhookID, ok := parseWebhookID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		status string
+		filters.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+	input.status = utils.ReadString(qs, "status", "")
+	input.Filters.Page = utils.ReadInt(qs, "page", 1, v)
+	input.Filters.PageSize = utils.ReadInt(qs, "page_size", 20, v)
+	input.Filters.Sort = utils.ReadString(qs, "sort", "-created_at")
+	input.Filters.SortSafelist = []string{"created_at", "-created_at", "next_attempt_at", "-next_attempt_at"}
+
+	v.Check(input.status == "" || validator.In(input.status, models.DeliveryStatuses...), "status", "invalid status")
+	if filters.ValidateFilters(v, input.Filters); !v.Valid() {
+		h.errRsp.HandlerErrorResponse(w, r, e.ErrInvalidData, v)
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	deliveries, metadata, err := h.webhook.FindDeliveries(webhookID, businessID, user.ID, input.status, input.Filters)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	dtos := make([]*models.WebhookDeliveryDTO, len(deliveries))
+	for i, d := range deliveries {
+		dtos[i] = d.ToDTO()
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"deliveries": dtos, "metadata": metadata}, nil, h.errRsp)
+}
+
+// FindDelivery shows the delivery with every attempt at it and what the
+// endpoint answered.
+func (h *webhookHandler) FindDelivery(w http.ResponseWriter, r *http.Request) {
+	businessID, webhookID, deliveryID, ok := parseDeliveryID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	user := contexts.ContextGetUser(r)
+	d, err := h.webhook.FindDelivery(deliveryID, webhookID, businessID, user.ID)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, nil)
+		return
+	}
+
+	respond(w, r, http.StatusOK, utils.Envelope{"delivery": d.ToDTO()}, nil, h.errRsp)
+}
+
+func (h *webhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	businessID, webhookID, deliveryID, ok := parseDeliveryID(w, r, h.errRsp)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+	user := contexts.ContextGetUser(r)
+	d, err := h.webhook.Redeliver(deliveryID, webhookID, businessID, user.ID, v)
+	if err != nil {
+		h.errRsp.HandlerErrorResponse(w, r, err, v)
+		return
+	}
+
+	respond(w, r, http.StatusAccepted, utils.Envelope{"delivery": d.ToDTO()}, nil, h.errRsp)
+}
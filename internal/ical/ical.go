@@ -0,0 +1,147 @@
+// Package ical writes iCalendar (RFC 5545) documents: the invites attached
+// to interview emails and the calendar feeds subscribed to by URL.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const prodID = "-//Meu Job//Entrevistas//PT-BR"
+
+// Method is the iTIP method of a calendar sent by email. Feeds publish.
+type Method string
+
+const (
+	MethodPublish Method = "PUBLISH"
+	MethodRequest Method = "REQUEST"
+	MethodCancel  Method = "CANCEL"
+)
+
+type Person struct {
+	Name  string
+	Email string
+}
+
+// Event is a VEVENT. Events with the same UID are the same meeting; a
+// higher Sequence supersedes what was sent before.
+type Event struct {
+	UID         string
+	Sequence    int
+	Stamp       time.Time
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	// Conference is the video call link, if any.
+	Conference string
+	Organizer  Person
+	Attendees  []Person
+	Cancelled  bool
+}
+
+type Calendar struct {
+	Method Method
+	// Name is shown by clients subscribed to a feed.
+	Name   string
+	Events []Event
+}
+
+// Encode writes the calendar with CRLF line endings, long lines folded.
+func (c Calendar) Encode() []byte {
+	var b bytes.Buffer
+	line := func(name, value string) {
+		fold(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", prodID)
+	line("CALSCALE", "GREGORIAN")
+	if c.Method != "" {
+		line("METHOD", string(c.Method))
+	}
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("SEQUENCE", fmt.Sprint(e.Sequence))
+		line("DTSTAMP", formatTime(e.Stamp))
+		line("DTSTART", formatTime(e.Start))
+		line("DTEND", formatTime(e.End))
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		if e.Conference != "" {
+			line("CONFERENCE;VALUE=URI;FEATURE=VIDEO", e.Conference)
+		}
+		if e.Organizer.Email != "" {
+			line("ORGANIZER;CN="+param(e.Organizer.Name), "mailto:"+e.Organizer.Email)
+		}
+		for _, a := range e.Attendees {
+			line("ATTENDEE;CN="+param(a.Name)+";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION", "mailto:"+a.Email)
+		}
+		if e.Cancelled {
+			line("STATUS", "CANCELLED")
+		} else {
+			line("STATUS", "CONFIRMED")
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return b.Bytes()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape writes a TEXT value, where backslashes, semicolons, commas and
+// newlines are escaped.
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(s)
+}
+
+// param quotes a parameter value, which can't contain double quotes at
+// all.
+func param(s string) string {
+	return `"` + strings.NewReplacer(`"`, "", "\r", "", "\n", " ").Replace(s) + `"`
+}
+
+// fold breaks the line into lines of at most 75 octets, the ones after
+// the first starting with a space, without splitting a UTF-8 character.
+func fold(b *bytes.Buffer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// The space starting the continuation counts towards its length.
+		limit = 74
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,111 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Entrevista", "Entrevista"},
+		{"Rua A, 10; sala 2", `Rua A\, 10\; sala 2`},
+		{`C:\Users`, `C:\\Users`},
+		{"linha 1\nlinha 2", `linha 1\nlinha 2`},
+		{"linha 1\r\nlinha 2", `linha 1\nlinha 2`},
+		{"linha 1\rlinha 2", "linha 1linha 2"},
+		{`\n`, `\\n`},
+	}
+
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"short", "SUMMARY:Entrevista", "SUMMARY:Entrevista\r\n"},
+		{"exactly 75", strings.Repeat("a", 75), strings.Repeat("a", 75) + "\r\n"},
+		{"76", strings.Repeat("a", 76), strings.Repeat("a", 75) + "\r\n a\r\n"},
+		{
+			"continuations of 74",
+			strings.Repeat("a", 75+74+1),
+			strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a\r\n",
+		},
+		{
+			// "ç" takes octets 75 and 76, so the first line stops short.
+			"multibyte at the limit",
+			strings.Repeat("a", 74) + "çb",
+			strings.Repeat("a", 74) + "\r\n çb\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			fold(&b, tt.in)
+			if got := b.String(); got != tt.want {
+				t.Errorf("fold = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Every line of an encoded calendar, unfolded or not, must be at most 75
+// octets and valid UTF-8, and unfolding must give the content lines back.
+func TestEncodeLines(t *testing.T) {
+	long := strings.Repeat("Descrição com acentuação, vírgulas; e quebras\n", 10)
+	c := Calendar{
+		Method: MethodRequest,
+		Name:   "Entrevistas - Meu Job",
+		Events: []Event{{
+			UID:         "interview-1@meujob.com.br",
+			Stamp:       time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+			Start:       time.Date(2026, 10, 20, 14, 0, 0, 0, time.FixedZone("BRT", -3*3600)),
+			End:         time.Date(2026, 10, 20, 15, 0, 0, 0, time.FixedZone("BRT", -3*3600)),
+			Summary:     "Entrevista: Desenvolvedora Go - Empresa, Ltda.",
+			Description: long,
+			Organizer:   Person{Name: `Ana "Recrutadora" Souza`, Email: "ana@empresa.com.br"},
+			Attendees:   []Person{{Name: "Maria\nSilva", Email: "maria@example.com"}},
+		}},
+	}
+
+	out := string(c.Encode())
+	if !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Fatalf("calendar doesn't end in END:VCALENDAR: %q", out)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a character: %q", line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	for _, want := range []string{
+		"DTSTART:20261020T170000Z\r\n",
+		"DTEND:20261020T180000Z\r\n",
+		`SUMMARY:Entrevista: Desenvolvedora Go - Empresa\, Ltda.` + "\r\n",
+		"DESCRIPTION:" + escape(long) + "\r\n",
+		`ORGANIZER;CN="Ana Recrutadora Souza":mailto:ana@empresa.com.br` + "\r\n",
+		`ATTENDEE;CN="Maria Silva";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION:mailto:maria@example.com` + "\r\n",
+		"METHOD:REQUEST\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("calendar is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package jsonresume
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+var sections = []string{"$schema", "basics", "work", "education", "skills", "languages", "meta"}
+
+// Import maps a JSON Resume document onto a curriculum. Whatever has no
+// place in a curriculum or can't be understood is reported as a warning
+// rather than failing the import; the error is only for documents that
+// aren't JSON Resume at all.
+func Import(raw []byte) (*models.Curriculum, []Warning, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &top); err != nil || top == nil {
+		return nil, nil, errors.New("body must be a JSON Resume object")
+	}
+
+	var r Resume
+	if err := json.Unmarshal(raw, &r); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return nil, nil, fmt.Errorf("field %q has the wrong type", typeErr.Field)
+		}
+		return nil, nil, errors.New("body must be a JSON Resume object")
+	}
+
+	m := &mapper{}
+
+	keys := make([]string, 0, len(top))
+	for key := range top {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if !slices.Contains(sections, key) && !isEmpty(top[key]) {
+			m.warn(key, "section is not supported and was ignored")
+		}
+	}
+
+	c := &models.Curriculum{
+		FullName:   strings.TrimSpace(r.Basics.Name),
+		Profession: strings.TrimSpace(r.Basics.Label),
+		Email:      strings.TrimSpace(r.Basics.Email),
+		Phone:      models.NormalizePhone(r.Basics.Phone),
+		Summary:    strings.TrimSpace(r.Basics.Summary),
+		Experience: []models.ExperienceEntry{},
+		Education:  []models.EducationEntry{},
+		Skills:     []models.CurriculumSkill{},
+		Languages:  []models.LanguageEntry{},
+	}
+	m.ignored("basics.url", r.Basics.URL != "")
+	m.ignored("basics.image", r.Basics.Image != "")
+	m.location(c, r.Basics.Location)
+	m.ignored("basics.profiles", !isEmpty(r.Basics.Profiles))
+
+	for i, work := range r.Work {
+		key := fmt.Sprintf("work[%d]", i)
+		start, end, ok := m.period(key, work.StartDate, work.EndDate)
+		if !ok {
+			continue
+		}
+
+		description := strings.TrimSpace(work.Summary)
+		for _, highlight := range work.Highlights {
+			if highlight = strings.TrimSpace(highlight); highlight != "" {
+				description = strings.TrimSpace(description + "\n• " + highlight)
+			}
+		}
+
+		c.Experience = append(c.Experience, models.ExperienceEntry{
+			Company:     strings.TrimSpace(work.Name),
+			Role:        strings.TrimSpace(work.Position),
+			Description: description,
+			StartDate:   start,
+			EndDate:     end,
+		})
+		m.ignored(key+".url", work.URL != "")
+		m.ignored(key+".location", work.Location != "")
+	}
+
+	for i, education := range r.Education {
+		key := fmt.Sprintf("education[%d]", i)
+		degree, ok := models.ParseEducationDegree(education.StudyType)
+		if !ok {
+			m.warn(key+".studyType", fmt.Sprintf("%q is not a known degree; the entry was skipped", education.StudyType))
+			continue
+		}
+
+		start, end, ok := m.period(key, education.StartDate, education.EndDate)
+		if !ok {
+			continue
+		}
+
+		c.Education = append(c.Education, models.EducationEntry{
+			Institution: strings.TrimSpace(education.Institution),
+			Degree:      degree,
+			StartDate:   start,
+			EndDate:     end,
+		})
+		m.ignored(key+".area", education.Area != "")
+		m.ignored(key+".score", education.Score != "")
+		m.ignored(key+".courses", len(education.Courses) > 0)
+		m.ignored(key+".url", education.URL != "")
+	}
+
+	seen := map[string]bool{}
+	addSkill := func(name string, level models.SkillLevel) {
+		name = strings.TrimSpace(name)
+		if name != "" && !seen[models.SkillKey(name)] {
+			seen[models.SkillKey(name)] = true
+			c.Skills = append(c.Skills, models.CurriculumSkill{Name: name, Level: level})
+		}
+	}
+	for i, skill := range r.Skills {
+		key := fmt.Sprintf("skills[%d]", i)
+		level, ok := models.ParseSkillLevel(skill.Level)
+		if !ok && strings.TrimSpace(skill.Level) != "" {
+			m.warn(key+".level", fmt.Sprintf("%q is not a known level and was ignored", skill.Level))
+		}
+
+		if len(skill.Keywords) == 0 {
+			addSkill(skill.Name, level)
+		} else {
+			for _, keyword := range skill.Keywords {
+				addSkill(keyword, level)
+			}
+			if skill.Name != "" {
+				m.warn(key+".name", "the keywords were imported as skills; the group name was not kept")
+			}
+		}
+	}
+
+	for i, language := range r.Languages {
+		key := fmt.Sprintf("languages[%d]", i)
+		level, ok := models.ParseLanguageLevel(language.Fluency)
+		if !ok {
+			m.warn(key+".fluency", fmt.Sprintf("%q is not a known fluency; the entry was skipped", language.Fluency))
+			continue
+		}
+		c.Languages = append(c.Languages, models.LanguageEntry{
+			Name:  strings.TrimSpace(language.Language),
+			Level: level,
+		})
+	}
+
+	return c, m.warnings, nil
+}
+
+type mapper struct {
+	warnings []Warning
+}
+
+func (m *mapper) warn(field, message string) {
+	m.warnings = append(m.warnings, Warning{Field: field, Message: message})
+}
+
+func (m *mapper) ignored(field string, present bool) {
+	if present {
+		m.warn(field, "has no equivalent in a curriculum and was ignored")
+	}
+}
+
+// location takes the CEP, street, city and state; the state must be given
+// as its abbreviation, the way the schema suggests.
+func (m *mapper) location(c *models.Curriculum, raw json.RawMessage) {
+	if isEmpty(raw) {
+		return
+	}
+
+	var location Location
+	if err := json.Unmarshal(raw, &location); err != nil {
+		m.warn("basics.location", "is not a location object and was ignored")
+		return
+	}
+
+	if cep := models.NormalizeCEP(location.PostalCode); cep != "" {
+		if models.CEPRX.MatchString(cep) {
+			c.CEP = cep
+		} else {
+			m.warn("basics.location.postalCode", fmt.Sprintf("%q is not a CEP and was ignored", location.PostalCode))
+		}
+	}
+	c.Street = strings.TrimSpace(location.Address)
+
+	city := strings.TrimSpace(location.City)
+	uf := strings.ToUpper(strings.TrimSpace(location.Region))
+	switch {
+	case uf != "" && !slices.Contains(models.UFs, uf):
+		m.warn("basics.location.region", fmt.Sprintf("%q is not a Brazilian state abbreviation; the location was ignored", location.Region))
+	case city == "" && uf == "" && c.CEP != "":
+		// The city and state follow from the CEP.
+	case city == "" || uf == "":
+		m.warn("basics.location", "needs both city and region; the location was ignored")
+	default:
+		c.City, c.UF = city, uf
+	}
+}
+
+// period parses an entry's dates, warning and reporting false when the
+// entry can't be kept.
+func (m *mapper) period(key, start, end string) (time.Time, *time.Time, bool) {
+	if strings.TrimSpace(start) == "" {
+		m.warn(key+".startDate", "is missing; the entry was skipped")
+		return time.Time{}, nil, false
+	}
+
+	startDate, ok := m.date(key+".startDate", start)
+	if !ok {
+		return time.Time{}, nil, false
+	}
+
+	if strings.TrimSpace(end) == "" {
+		return startDate, nil, true
+	}
+
+	endDate, ok := m.date(key+".endDate", end)
+	if !ok {
+		return time.Time{}, nil, false
+	}
+	return startDate, &endDate, true
+}
+
+// date accepts the ISO 8601 forms the schema allows: a day, a month or
+// just a year.
+func (m *mapper) date(field, s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if d, err := models.ParseDate(s); err == nil {
+		return d.Time, true
+	}
+
+	if t, err := time.Parse("2006", s); err == nil {
+		m.warn(field, "only the year is known; January was assumed")
+		return t, true
+	}
+
+	m.warn(field, fmt.Sprintf("%q is not a date; the entry was skipped", s))
+	return time.Time{}, false
+}
+
+func isEmpty(raw json.RawMessage) bool {
+	switch strings.TrimSpace(string(raw)) {
+	case "", "null", "[]", "{}", `""`:
+		return true
+	default:
+		return false
+	}
+}
+
+// Export writes the curriculum as a JSON Resume document.
+func Export(c *models.Curriculum) *Resume {
+	r := &Resume{
+		Schema: SchemaURL,
+		Basics: Basics{
+			Name:    c.FullName,
+			Label:   c.Profession,
+			Email:   c.Email,
+			Phone:   c.Phone.Format(),
+			Summary: c.Summary,
+		},
+		Work:      make([]Work, len(c.Experience)),
+		Education: make([]Education, len(c.Education)),
+		Skills:    make([]Skill, len(c.Skills)),
+		Languages: make([]Language, len(c.Languages)),
+		Meta:      &Meta{Version: "v1.0.0"},
+	}
+
+	if c.City != "" {
+		r.Basics.Location, _ = json.Marshal(Location{
+			Address:     c.Street,
+			PostalCode:  models.FormatCEP(c.CEP),
+			City:        c.City,
+			Region:      c.UF,
+			CountryCode: "BR",
+		})
+	}
+
+	modified := c.CreatedAt
+	if c.UpdatedAt != nil {
+		modified = *c.UpdatedAt
+	}
+	if !modified.IsZero() {
+		r.Meta.LastModified = modified.UTC().Format(time.RFC3339)
+	}
+
+	for i, entry := range c.Experience {
+		r.Work[i] = Work{
+			Name:      entry.Company,
+			Position:  entry.Role,
+			Summary:   entry.Description,
+			StartDate: formatDate(&entry.StartDate),
+			EndDate:   formatDate(entry.EndDate),
+		}
+	}
+
+	for i, entry := range c.Education {
+		r.Education[i] = Education{
+			Institution: entry.Institution,
+			StudyType:   degreeNames[entry.Degree],
+			StartDate:   formatDate(&entry.StartDate),
+			EndDate:     formatDate(entry.EndDate),
+		}
+	}
+
+	for i, skill := range c.Skills {
+		r.Skills[i] = Skill{Name: skill.Name, Level: skillLevelNames[skill.Level]}
+	}
+
+	for i, language := range c.Languages {
+		r.Languages[i] = Language{
+			Language: language.Name,
+			Fluency:  levelNames[language.Level],
+		}
+	}
+
+	return r
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
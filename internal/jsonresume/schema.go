@@ -0,0 +1,87 @@
+// Package jsonresume converts curricula to and from the JSON Resume schema
+// (https://jsonresume.org/schema).
+package jsonresume
+
+import "encoding/json"
+
+const SchemaURL = "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json"
+
+// Resume holds the parts of the schema a curriculum can represent. The
+// other sections are only looked at to warn that they were left out.
+type Resume struct {
+	Schema    string      `json:"$schema,omitempty"`
+	Basics    Basics      `json:"basics"`
+	Work      []Work      `json:"work"`
+	Education []Education `json:"education"`
+	Skills    []Skill     `json:"skills"`
+	Languages []Language  `json:"languages"`
+	Meta      *Meta       `json:"meta,omitempty"`
+}
+
+type Basics struct {
+	Name     string          `json:"name"`
+	Label    string          `json:"label,omitempty"`
+	Email    string          `json:"email,omitempty"`
+	Phone    string          `json:"phone,omitempty"`
+	Summary  string          `json:"summary,omitempty"`
+	URL      string          `json:"url,omitempty"`
+	Image    string          `json:"image,omitempty"`
+	Location json.RawMessage `json:"location,omitempty"`
+	Profiles json.RawMessage `json:"profiles,omitempty"`
+}
+
+// Location is kept as raw JSON in Basics so a malformed one is warned
+// about instead of failing the import.
+type Location struct {
+	Address     string `json:"address,omitempty"`
+	PostalCode  string `json:"postalCode,omitempty"`
+	City        string `json:"city,omitempty"`
+	CountryCode string `json:"countryCode,omitempty"`
+	Region      string `json:"region,omitempty"`
+}
+
+type Work struct {
+	Name       string   `json:"name"`
+	Position   string   `json:"position"`
+	Summary    string   `json:"summary,omitempty"`
+	Highlights []string `json:"highlights,omitempty"`
+	StartDate  string   `json:"startDate"`
+	EndDate    string   `json:"endDate,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	Location   string   `json:"location,omitempty"`
+}
+
+type Education struct {
+	Institution string   `json:"institution"`
+	StudyType   string   `json:"studyType"`
+	Area        string   `json:"area,omitempty"`
+	StartDate   string   `json:"startDate"`
+	EndDate     string   `json:"endDate,omitempty"`
+	Score       string   `json:"score,omitempty"`
+	Courses     []string `json:"courses,omitempty"`
+	URL         string   `json:"url,omitempty"`
+}
+
+type Skill struct {
+	Name     string   `json:"name"`
+	Level    string   `json:"level,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+type Language struct {
+	Language string `json:"language"`
+	Fluency  string `json:"fluency"`
+}
+
+type Meta struct {
+	Canonical    string `json:"canonical,omitempty"`
+	Version      string `json:"version,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// Warning points at a field of the imported document that couldn't be
+// carried over, using the document's own path ("work[2].url").
+type Warning struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
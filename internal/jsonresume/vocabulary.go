@@ -0,0 +1,35 @@
+package jsonresume
+
+import "meu_job/internal/models"
+
+// degreeNames, levelNames and skillLevelNames are what Export writes.
+// Import reads them back with models.ParseEducationDegree,
+// models.ParseLanguageLevel and models.ParseSkillLevel.
+var degreeNames = map[models.EducationDegree]string{
+	models.DegreeElementary:   "Elementary School",
+	models.DegreeHighSchool:   "High School",
+	models.DegreeTechnical:    "Technical",
+	models.DegreeAssociate:    "Associate",
+	models.DegreeBachelor:     "Bachelor",
+	models.DegreeLicentiate:   "Licentiate",
+	models.DegreePostgraduate: "Postgraduate",
+	models.DegreeMaster:       "Master",
+	models.DegreeDoctorate:    "Doctorate",
+	models.DegreeMBA:          "MBA",
+	models.DegreeCertificate:  "Certificate",
+}
+
+var levelNames = map[models.LanguageLevel]string{
+	models.LanguageBasic:        "Basic",
+	models.LanguageIntermediate: "Intermediate",
+	models.LanguageAdvanced:     "Advanced",
+	models.LanguageFluent:       "Fluent",
+	models.LanguageNative:       "Native speaker",
+}
+
+var skillLevelNames = map[models.SkillLevel]string{
+	models.SkillBeginner:     "Beginner",
+	models.SkillIntermediate: "Intermediate",
+	models.SkillAdvanced:     "Advanced",
+	models.SkillExpert:       "Expert",
+}
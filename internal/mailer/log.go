@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// logMailer writes the messages out instead of sending them, for local
+// environments without an SMTP server.
+type logMailer struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func NewLogMailer(out io.Writer) *logMailer {
+	return &logMailer{out: out}
+}
+
+func (l *logMailer) Send(m Message) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fmt.Fprintf(l.out, "To: %s\nSubject: %s\n", m.To, m.Subject)
+	for _, key := range slices.Sorted(maps.Keys(m.Headers)) {
+		fmt.Fprintf(l.out, "%s: %s\n", key, m.Headers[key])
+	}
+	fmt.Fprintf(l.out, "\n%s\n", m.Body)
+	for _, a := range m.Attachments {
+		fmt.Fprintf(l.out, "[attachment %s, %s, %d bytes]\n", a.Filename, a.ContentType, len(a.Content))
+	}
+	_, err := fmt.Fprintln(l.out)
+	return err
+}
@@ -0,0 +1,30 @@
+// Package mailer delivers email. Messages are not sent from request
+// handlers: services queue them in the emails table and a background task
+// hands them to a Mailer, so a slow or failing server only delays them.
+package mailer
+
+import "errors"
+
+var ErrInvalidMessage = errors.New("invalid email message")
+
+// Message is a plain text email. Headers carries extras such as
+// List-Unsubscribe.
+type Message struct {
+	To          string
+	Subject     string
+	Body        string
+	Headers     map[string]string
+	Attachments []Attachment
+}
+
+// Attachment is a file sent along with the message. ContentType may carry
+// parameters, as calendar invites do with method=REQUEST.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+type Mailer interface {
+	Send(m Message) error
+}
@@ -0,0 +1,124 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type smtpMailer struct {
+	addr   string
+	host   string
+	auth   smtp.Auth
+	sender mail.Address
+}
+
+// NewSMTPMailer sends through a relay; username may be empty for relays
+// that don't authenticate. Authentication is only attempted over TLS,
+// which net/smtp negotiates with STARTTLS when the server offers it.
+func NewSMTPMailer(host string, port int, username, password, sender string) (*smtpMailer, error) {
+	from, err := mail.ParseAddress(sender)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", sender, err)
+	}
+
+	m := &smtpMailer{
+		addr:   net.JoinHostPort(host, strconv.Itoa(port)),
+		host:   host,
+		sender: *from,
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m, nil
+}
+
+func (s *smtpMailer) Send(m Message) error {
+	to, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+	}
+
+	return smtp.SendMail(s.addr, s.auth, s.sender.Address, []string{to.Address}, compose(s.sender, *to, m))
+}
+
+// compose writes the message as RFC 5322 text, UTF-8 and quoted-printable
+// so accents survive any relay. Attachments make it multipart/mixed, with
+// the text as the first part.
+func compose(from, to mail.Address, m Message) []byte {
+	var b bytes.Buffer
+	header := func(key, value string) {
+		// Header values come from our own code, but a newline would still
+		// let one smuggle in more headers.
+		value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	for _, key := range slices.Sorted(maps.Keys(m.Headers)) {
+		header(key, m.Headers[key])
+	}
+
+	if len(m.Attachments) == 0 {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		b.WriteString("\r\n")
+		writeText(&b, m.Body)
+		return b.Bytes()
+	}
+
+	mw := multipart.NewWriter(&b)
+	header("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+	b.WriteString("\r\n")
+
+	part, _ := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	writeText(part, m.Body)
+
+	for _, a := range m.Attachments {
+		part, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		writeBase64(part, a.Content)
+	}
+	mw.Close()
+	return b.Bytes()
+}
+
+func writeText(w io.Writer, body string) {
+	qp := quotedprintable.NewWriter(w)
+	qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	qp.Close()
+}
+
+// writeBase64 breaks the encoded content into lines of 76 characters, as
+// MIME requires.
+func writeBase64(w io.Writer, content []byte) {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > 76 {
+		io.WriteString(w, encoded[:76]+"\r\n")
+		encoded = encoded[76:]
+	}
+	io.WriteString(w, encoded+"\r\n")
+}
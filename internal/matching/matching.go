@@ -0,0 +1,319 @@
+// Package matching scores how well a curriculum fits a job posting. The
+// score is a weighted sum of criteria, each explained in the breakdown, so
+// candidates and recruiters can see why a match ranks where it does. The
+// same inputs always give the same score.
+package matching
+
+import (
+	"fmt"
+	"math"
+	"meu_job/internal/models"
+	"slices"
+	"time"
+)
+
+// weights add up to 100. They are shared out anew among the criteria the
+// job asks about.
+var weights = []struct {
+	criterion models.MatchCriterion
+	weight    int
+}{
+	{models.MatchRequiredSkills, 35},
+	{models.MatchOptionalSkills, 10},
+	{models.MatchSeniority, 20},
+	{models.MatchEducation, 10},
+	{models.MatchLanguages, 10},
+	{models.MatchLocation, 10},
+	{models.MatchSalary, 5},
+}
+
+// salaryTolerance is how far above the top of the range an expectation
+// can go before it stops counting at all.
+const salaryTolerance = 0.3
+
+// Score compares c to j as of now, which is when ongoing experience ends.
+func Score(j *models.Job, c *models.Curriculum, now time.Time) (int, []models.MatchItem) {
+	breakdown := make([]models.MatchItem, 0, len(weights))
+	total := 0
+	for _, w := range weights {
+		item := score(w.criterion, j, c, now)
+		item.Criterion = w.criterion
+		item.Weight = w.weight
+		if item.Applicable {
+			total += w.weight
+		}
+		breakdown = append(breakdown, item)
+	}
+
+	sum := 0.0
+	for i := range breakdown {
+		item := &breakdown[i]
+		item.Score = round(item.Score, 2)
+		if item.Applicable && total > 0 {
+			item.Points = round(item.Score*float64(item.Weight)*100/float64(total), 1)
+			sum += item.Score * float64(item.Weight) * 100 / float64(total)
+		}
+	}
+
+	return int(math.Round(sum)), breakdown
+}
+
+func score(criterion models.MatchCriterion, j *models.Job, c *models.Curriculum, now time.Time) models.MatchItem {
+	switch criterion {
+	case models.MatchRequiredSkills:
+		return skills(j, c, true)
+	case models.MatchOptionalSkills:
+		return skills(j, c, false)
+	case models.MatchSeniority:
+		return seniority(j, c, now)
+	case models.MatchEducation:
+		return education(j, c, now)
+	case models.MatchLanguages:
+		return languages(j, c)
+	case models.MatchLocation:
+		return location(j, c)
+	case models.MatchSalary:
+		return salary(j, c)
+	}
+	return models.MatchItem{}
+}
+
+// skills gives full credit for each skill listed at the level asked or
+// above, half when the level is below, and three quarters when the
+// candidate didn't say their level.
+func skills(j *models.Job, c *models.Curriculum, required bool) models.MatchItem {
+	item := models.MatchItem{}
+	asked := 0
+	credit := 0.0
+	for _, want := range j.Skills {
+		if want.Required != required {
+			continue
+		}
+		asked++
+
+		have, ok := findSkill(c.Skills, want)
+		switch {
+		case !ok:
+			item.Missing = append(item.Missing, want.Name)
+			continue
+		case want.Level == "" || have.Level.Rank() >= want.Level.Rank():
+			credit += 1
+		case have.Level == "":
+			credit += 0.75
+		default:
+			credit += 0.5
+		}
+		item.Matched = append(item.Matched, want.Name)
+	}
+
+	if asked == 0 {
+		item.Detail = "none asked"
+		return item
+	}
+
+	item.Applicable = true
+	item.Score = credit / float64(asked)
+	item.Detail = fmt.Sprintf("%d of %d skills listed", len(item.Matched), asked)
+	return item
+}
+
+func findSkill(skills []models.CurriculumSkill, want models.JobSkill) (models.CurriculumSkill, bool) {
+	for _, have := range skills {
+		if want.SkillID != nil && have.SkillID != nil {
+			if *want.SkillID == *have.SkillID {
+				return have, true
+			}
+			continue
+		}
+		if models.SkillKey(have.Name) == models.SkillKey(want.Name) {
+			return have, true
+		}
+	}
+	return models.CurriculumSkill{}, false
+}
+
+// seniority is derived from the time worked, counting overlapping jobs
+// once. One step short of what's asked gets half; more than one step
+// above is taken as overqualified.
+func seniority(j *models.Job, c *models.Curriculum, now time.Time) models.MatchItem {
+	years := ExperienceYears(c.Experience, now)
+	have := models.SeniorityForYears(years)
+	item := models.MatchItem{
+		Applicable: true,
+		Detail:     fmt.Sprintf("%.1f years of experience, %s; %s asked", years, have, j.Seniority),
+	}
+
+	switch diff := have.Rank() - j.Seniority.Rank(); {
+	case diff >= 2:
+		item.Score = 0.8
+	case diff >= 0:
+		item.Score = 1
+	case diff == -1:
+		item.Score = 0.5
+	}
+	return item
+}
+
+// ExperienceYears adds up the periods of experience, merging the ones that
+// overlap.
+func ExperienceYears(experience []models.ExperienceEntry, now time.Time) float64 {
+	type period struct{ start, end time.Time }
+	periods := make([]period, 0, len(experience))
+	for _, entry := range experience {
+		end := now
+		if entry.EndDate != nil && entry.EndDate.Before(now) {
+			end = *entry.EndDate
+		}
+		if entry.StartDate.Before(end) {
+			periods = append(periods, period{entry.StartDate, end})
+		}
+	}
+	slices.SortFunc(periods, func(a, b period) int {
+		return a.start.Compare(b.start)
+	})
+
+	var total time.Duration
+	var current *period
+	for i := range periods {
+		p := periods[i]
+		switch {
+		case current == nil:
+			current = &p
+		case !p.start.After(current.end):
+			if p.end.After(current.end) {
+				current.end = p.end
+			}
+		default:
+			total += current.end.Sub(current.start)
+			current = &p
+		}
+	}
+	if current != nil {
+		total += current.end.Sub(current.start)
+	}
+
+	return round(total.Hours()/24/365.25, 1)
+}
+
+// education counts completed degrees in full and ongoing ones by half.
+func education(j *models.Job, c *models.Curriculum, now time.Time) models.MatchItem {
+	if j.MinDegree == nil {
+		return models.MatchItem{Detail: "none asked"}
+	}
+
+	item := models.MatchItem{Applicable: true}
+	want := j.MinDegree.Rank()
+	var completed, ongoing *models.EducationEntry
+	for i, entry := range c.Education {
+		if entry.Degree.Rank() < want {
+			continue
+		}
+		if entry.EndDate != nil && !entry.EndDate.After(now) {
+			completed = &c.Education[i]
+			break
+		}
+		if ongoing == nil {
+			ongoing = &c.Education[i]
+		}
+	}
+
+	switch {
+	case completed != nil:
+		item.Score = 1
+		item.Detail = fmt.Sprintf("%s completed; %s asked", completed.Degree, *j.MinDegree)
+	case ongoing != nil:
+		item.Score = 0.5
+		item.Detail = fmt.Sprintf("%s in progress; %s asked", ongoing.Degree, *j.MinDegree)
+	default:
+		item.Detail = fmt.Sprintf("no %s or above", *j.MinDegree)
+	}
+	return item
+}
+
+// languages gives full credit for each language at the level asked or
+// above and half for one level short.
+func languages(j *models.Job, c *models.Curriculum) models.MatchItem {
+	if len(j.Languages) == 0 {
+		return models.MatchItem{Detail: "none asked"}
+	}
+
+	item := models.MatchItem{Applicable: true}
+	credit := 0.0
+	for _, want := range j.Languages {
+		i := slices.IndexFunc(c.Languages, func(have models.LanguageEntry) bool {
+			return models.Slugify(have.Name) == models.Slugify(want.Name)
+		})
+		if i < 0 {
+			item.Missing = append(item.Missing, want.Name)
+			continue
+		}
+
+		switch diff := c.Languages[i].Level.Rank() - want.Level.Rank(); {
+		case diff >= 0:
+			credit += 1
+		case diff == -1:
+			credit += 0.5
+		default:
+			item.Missing = append(item.Missing, want.Name)
+			continue
+		}
+		item.Matched = append(item.Matched, want.Name)
+	}
+
+	item.Score = credit / float64(len(j.Languages))
+	item.Detail = fmt.Sprintf("%d of %d languages at the level asked or close", len(item.Matched), len(j.Languages))
+	return item
+}
+
+// location only matters for jobs that aren't remote: the same city counts
+// in full, the same state by half.
+func location(j *models.Job, c *models.Curriculum) models.MatchItem {
+	if j.RemoteMode == models.RemoteFull {
+		return models.MatchItem{Detail: "remote job"}
+	}
+
+	item := models.MatchItem{Applicable: true}
+	switch {
+	case c.UF == "":
+		item.Detail = "candidate's location unknown"
+	case c.UF == j.UF && models.Slugify(c.City) == models.Slugify(j.City):
+		item.Score = 1
+		item.Detail = fmt.Sprintf("lives in %s/%s", c.City, c.UF)
+	case c.UF == j.UF:
+		item.Score = 0.5
+		item.Detail = fmt.Sprintf("lives in %s/%s, same state", c.City, c.UF)
+	default:
+		item.Detail = fmt.Sprintf("lives in %s/%s, another state", c.City, c.UF)
+	}
+	return item
+}
+
+// salary compares the expectation with the top of the range offered,
+// counting less and less up to salaryTolerance above it.
+func salary(j *models.Job, c *models.Curriculum) models.MatchItem {
+	top := j.SalaryMax
+	if top == nil {
+		top = j.SalaryMin
+	}
+	if top == nil || c.SalaryExpectation == nil {
+		return models.MatchItem{Detail: "no salary to compare"}
+	}
+
+	item := models.MatchItem{Applicable: true}
+	expected := float64(*c.SalaryExpectation)
+	offered := float64(*top)
+	switch {
+	case expected <= offered:
+		item.Score = 1
+		item.Detail = "expectation within the range offered"
+	default:
+		item.Score = max(0, 1-(expected/offered-1)/salaryTolerance)
+		item.Detail = fmt.Sprintf("expectation %.0f%% above the range offered", (expected/offered-1)*100)
+	}
+	return item
+}
+
+func round(x float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(x*p) / p
+}
@@ -0,0 +1,176 @@
+package matching
+
+import (
+	"meu_job/internal/models"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+var now = date(2024, 1, 1)
+
+func TestExperienceYears(t *testing.T) {
+	tests := []struct {
+		name       string
+		experience []models.ExperienceEntry
+		want       float64
+	}{
+		{"none", nil, 0},
+		{"ongoing", []models.ExperienceEntry{{StartDate: date(2022, 1, 1)}}, 2},
+		{
+			"overlapping counted once",
+			[]models.ExperienceEntry{
+				{StartDate: date(2020, 1, 1)},
+				{StartDate: date(2018, 1, 1), EndDate: ptr(date(2021, 1, 1))},
+			},
+			6,
+		},
+		{
+			"gaps left out",
+			[]models.ExperienceEntry{
+				{StartDate: date(2015, 1, 1), EndDate: ptr(date(2016, 1, 1))},
+				{StartDate: date(2020, 1, 1), EndDate: ptr(date(2021, 7, 2))},
+			},
+			2.5,
+		},
+		{
+			"nested",
+			[]models.ExperienceEntry{
+				{StartDate: date(2019, 1, 1), EndDate: ptr(date(2023, 1, 1))},
+				{StartDate: date(2020, 1, 1), EndDate: ptr(date(2021, 1, 1))},
+			},
+			4,
+		},
+		{
+			"future and past the present clipped",
+			[]models.ExperienceEntry{
+				{StartDate: date(2025, 1, 1)},
+				{StartDate: date(2023, 1, 1), EndDate: ptr(date(2030, 1, 1))},
+			},
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExperienceYears(tt.experience, now); got != tt.want {
+				t.Errorf("ExperienceYears = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		job    *models.Job
+		cv     *models.Curriculum
+		want   int
+		points map[models.MatchCriterion]float64
+	}{
+		{
+			// Required skills 35, optional 10, seniority 20 and salary 5 are
+			// shared out over 70. Points come from the score as shown,
+			// rounded to 0.88 from 0.875.
+			name: "remote job",
+			job: &models.Job{
+				Seniority:  models.SeniorityMid,
+				RemoteMode: models.RemoteFull,
+				SalaryMax:  ptr(10000),
+				Skills: []models.JobSkill{
+					{Name: "Go", Level: models.SkillAdvanced, Required: true},
+					{Name: "PostgreSQL", Level: models.SkillIntermediate, Required: true},
+					{Name: "Docker"},
+				},
+			},
+			cv: &models.Curriculum{
+				SalaryExpectation: ptr(11500),
+				Skills: []models.CurriculumSkill{
+					{Name: "go", Level: models.SkillExpert},
+					{Name: "PostgreSQL"},
+				},
+				Experience: []models.ExperienceEntry{
+					{StartDate: date(2018, 1, 1), EndDate: ptr(date(2021, 1, 1))},
+					{StartDate: date(2020, 1, 1)},
+				},
+			},
+			want: 76,
+			points: map[models.MatchCriterion]float64{
+				models.MatchRequiredSkills: 44,
+				models.MatchOptionalSkills: 0,
+				models.MatchSeniority:      28.6,
+				models.MatchSalary:         3.6,
+				models.MatchLocation:       0,
+			},
+		},
+		{
+			// Only seniority 20 and location 10 apply.
+			name: "onsite job in the same state",
+			job: &models.Job{
+				Seniority:  models.SenioritySenior,
+				RemoteMode: models.RemoteOnsite,
+				Place:      models.Place{City: "São Paulo", UF: "SP"},
+			},
+			cv: &models.Curriculum{
+				Place: models.Place{City: "Campinas", UF: "SP"},
+			},
+			want: 17,
+			points: map[models.MatchCriterion]float64{
+				models.MatchSeniority: 0,
+				models.MatchLocation:  16.7,
+			},
+		},
+		{
+			name: "everything asked and met",
+			job: &models.Job{
+				Seniority:  models.SeniorityJunior,
+				RemoteMode: models.RemoteHybrid,
+				Place:      models.Place{City: "Belo Horizonte", UF: "MG"},
+				SalaryMin:  ptr(4000),
+				MinDegree:  ptr(models.EducationDegree("bachelor")),
+				Skills:     []models.JobSkill{{Name: "Go", Required: true}, {Name: "Docker"}},
+				Languages:  []models.LanguageEntry{{Name: "Inglês", Level: "intermediate"}},
+			},
+			cv: &models.Curriculum{
+				Place:             models.Place{City: "belo horizonte", UF: "MG"},
+				SalaryExpectation: ptr(4000),
+				Skills:            []models.CurriculumSkill{{Name: "Go"}, {Name: "Docker"}},
+				Languages:         []models.LanguageEntry{{Name: "ingles", Level: "advanced"}},
+				Education: []models.EducationEntry{
+					{Degree: "bachelor", StartDate: date(2016, 1, 1), EndDate: ptr(date(2020, 1, 1))},
+				},
+				Experience: []models.ExperienceEntry{{StartDate: date(2021, 6, 1)}},
+			},
+			want: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, breakdown := Score(tt.job, tt.cv, now)
+			if got != tt.want {
+				t.Errorf("Score = %d, want %d: %+v", got, tt.want, breakdown)
+			}
+			if len(breakdown) != len(weights) {
+				t.Fatalf("breakdown has %d items, want %d", len(breakdown), len(weights))
+			}
+			for _, item := range breakdown {
+				if want, ok := tt.points[item.Criterion]; ok && item.Points != want {
+					t.Errorf("%s: %v points, want %v (%+v)", item.Criterion, item.Points, want, item)
+				}
+			}
+
+			again, _ := Score(tt.job, tt.cv, now)
+			if again != got {
+				t.Errorf("Score isn't deterministic: %d, then %d", got, again)
+			}
+		})
+	}
+}
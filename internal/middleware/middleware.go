@@ -1,14 +1,14 @@
 package middleware
 
 import (
+	"errors"
 	"expvar"
 	"fmt"
 	"meu_job/internal/config"
 	"meu_job/internal/contexts"
 	"meu_job/internal/models"
 	"meu_job/internal/services"
-	"meu_job/utils/errors"
-	"meu_job/utils/validator"
+	e "meu_job/utils/errors"
 	"net"
 	"net/http"
 	"slices"
@@ -28,9 +28,12 @@ var (
 )
 
 type Middleware struct {
-	errRsp      errors.ErrorResponseInterface
+	errRsp      e.ErrorResponseInterface
 	userService services.UserServiceInterface
 	authService services.AuthServiceInterface
+	apiKey      services.APIKeyServiceInterface
+	session     services.SessionServiceInterface
+	consent     services.ConsentServiceInterface
 	config      config.Config
 }
 
@@ -43,18 +46,26 @@ type MiddlewareInterface interface {
 	RateLimit(next http.Handler) http.Handler
 	RecoverPanic(next http.Handler) http.Handler
 	RequirePermission(roles []models.Role) func(http.Handler) http.Handler
+	RequireScope(scope models.Scope) func(http.Handler) http.Handler
+	RequireCurrentConsent(next http.Handler) http.Handler
 }
 
 func New(
-	errRsp errors.ErrorResponseInterface,
+	errRsp e.ErrorResponseInterface,
 	userService services.UserServiceInterface,
 	authService services.AuthServiceInterface,
+	apiKey services.APIKeyServiceInterface,
+	session services.SessionServiceInterface,
+	consent services.ConsentServiceInterface,
 	config config.Config,
 ) *Middleware {
 	return &Middleware{
 		errRsp:      errRsp,
 		userService: userService,
 		authService: authService,
+		apiKey:      apiKey,
+		session:     session,
+		consent:     consent,
 		config:      config,
 	}
 }
@@ -181,26 +192,145 @@ func (m *Middleware) Authenticate(next http.Handler) http.Handler {
 		}
 
 		headerParts := strings.Split(authorizationHeader, " ")
-		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
+		if len(headerParts) != 2 {
 			m.errRsp.InvalidCredentialsResponse(w, r)
 			return
 		}
 
-		token := headerParts[1]
-		username, err := m.authService.ExtractUsername(token)
-		if err != nil {
+		switch headerParts[0] {
+		case "Bearer":
+			m.authenticateToken(w, r, next, headerParts[1])
+		case "ApiKey":
+			m.authenticateAPIKey(w, r, next, headerParts[1])
+		default:
+			m.errRsp.InvalidCredentialsResponse(w, r)
+		}
+	})
+}
+
+func (m *Middleware) authenticateToken(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	user, session, claims, err := m.checkToken(token)
+	if err != nil {
+		switch {
+		case errors.Is(err, e.ErrInvalidCredentials):
 			m.errRsp.InvalidAuthenticationTokenResponse(w, r)
+		default:
+			m.errRsp.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	r = contexts.ContextSetUser(r, user)
+	r = contexts.ContextSetSession(r, session)
+	r = contexts.ContextSetCredentials(r, &contexts.Credentials{
+		ExpiresAt: &claims.ExpiresAt.Time,
+		Check: func() error {
+			_, _, _, err := m.checkToken(token)
+			return err
+		},
+	})
+	next.ServeHTTP(w, r)
+}
+
+// checkToken reports a token that's invalid, expired, issued before the
+// user's token version was raised or for a session since revoked as
+// ErrInvalidCredentials.
+func (m *Middleware) checkToken(token string) (*models.User, *models.Session, *services.TokenClaims, error) {
+	claims, err := m.authService.ParseToken(token)
+	if err != nil {
+		return nil, nil, nil, e.ErrInvalidCredentials
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, nil, nil, e.ErrInvalidCredentials
+	}
+
+	user, err := m.userService.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, e.ErrRecordNotFound) {
+			return nil, nil, nil, e.ErrInvalidCredentials
+		}
+		return nil, nil, nil, err
+	}
+
+	if user.TokenVersion != claims.TokenVersion {
+		return nil, nil, nil, e.ErrInvalidCredentials
+	}
+
+	session, err := m.session.Authenticate(claims.SessionID, user.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return user, session, claims, nil
+}
+
+func (m *Middleware) authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, plaintext string) {
+	user, key, err := m.apiKey.Authenticate(plaintext)
+	if err != nil {
+		switch {
+		case errors.Is(err, e.ErrInvalidCredentials):
+			m.errRsp.InvalidCredentialsResponse(w, r)
+		default:
+			m.errRsp.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	r = contexts.ContextSetUser(r, user)
+	r = contexts.ContextSetAPIKey(r, key)
+	r = contexts.ContextSetCredentials(r, &contexts.Credentials{
+		ExpiresAt: key.ExpiresAt,
+		Check: func() error {
+			_, _, err := m.apiKey.Authenticate(plaintext)
+			return err
+		},
+	})
+	next.ServeHTTP(w, r)
+}
+
+// RequireScope only restricts requests authenticated with an API key; the key
+// must carry the scope and the owner's role must still allow it.
+func (m *Middleware) RequireScope(scope models.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := contexts.ContextGetAPIKey(r)
+			if key != nil {
+				user := contexts.ContextGetUser(r)
+				if !key.HasScope(scope) || !slices.Contains(user.Role.AllowedScopes(), scope) {
+					m.errRsp.NotPermittedResponse(w, r)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireCurrentConsent blocks users who haven't accepted the legal documents
+// currently in force. API key requests are let through: integrations can't
+// accept terms, and the owner is prompted on their next interactive request.
+func (m *Middleware) RequireCurrentConsent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := contexts.ContextGetUser(r)
+		if user.IsAnonymous() || contexts.ContextGetAPIKey(r) != nil {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		v := validator.New()
-		user, err := m.userService.GetUserByEmail(username, v)
+		pending, err := m.consent.PendingDocuments(user)
 		if err != nil {
-			m.errRsp.HandlerErrorResponse(w, r, err, v)
+			m.errRsp.ServerErrorResponse(w, r, err)
+			return
+		}
+
+		if len(pending) > 0 {
+			m.errRsp.ConsentRequiredResponse(w, r, pending)
 			return
 		}
 
-		r = contexts.ContextSetUser(r, user)
 		next.ServeHTTP(w, r)
 	})
 }
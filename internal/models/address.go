@@ -0,0 +1,192 @@
+package models
+
+import (
+	"math"
+	"meu_job/utils/validator"
+	"regexp"
+	"strings"
+)
+
+// Place is the part of an address that can be found on the map. Jobs and
+// curricula are placed by it; businesses have a full Address.
+type Place struct {
+	CEP      string
+	Street   string
+	District string
+	City     string
+	UF       string
+	// Coordinates are geocoded from the CEP, or the city without one, and
+	// are nil when neither is known.
+	Coordinates *Coordinates
+}
+
+type Address struct {
+	Place
+	Number     string
+	Complement string
+}
+
+// Coordinates are a point in decimal degrees.
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+const earthRadiusKM = 6371.0
+
+// DistanceKM is the great-circle distance between the points, the same
+// haversine the haversine_km SQL function computes.
+func (c Coordinates) DistanceKM(to Coordinates) float64 {
+	lat1, lat2 := c.Latitude*math.Pi/180, to.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (to.Longitude - c.Longitude) * math.Pi / 180
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * earthRadiusKM * math.Asin(math.Sqrt(h))
+}
+
+// BoundingBox is a box around the point containing every point within km,
+// cheap to check against an index before computing the distance.
+func (c Coordinates) BoundingBox(km float64) (minLat, maxLat, minLng, maxLng float64) {
+	dLat := km / earthRadiusKM * 180 / math.Pi
+	dLng := 180.0
+	if cos := math.Cos(c.Latitude * math.Pi / 180); cos > 0.01 {
+		dLng = math.Min(dLat/cos, 180)
+	}
+	return c.Latitude - dLat, c.Latitude + dLat, c.Longitude - dLng, c.Longitude + dLng
+}
+
+func ValidateCoordinates(v *validator.Validator, latitude, longitude float64) {
+	v.Check(latitude >= -90 && latitude <= 90, "lat", "must be between -90 and 90")
+	v.Check(longitude >= -180 && longitude <= 180, "lng", "must be between -180 and 180")
+}
+
+var CEPRX = regexp.MustCompile(`^[0-9]{8}$`)
+
+// NormalizeCEP strips the punctuation from a CEP, so 01310-100 and
+// 01.310-100 are both stored as 01310100.
+func NormalizeCEP(cep string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(cep))
+}
+
+// FormatCEP writes a normalized CEP the usual way, 01310-100.
+func FormatCEP(cep string) string {
+	if !CEPRX.MatchString(cep) {
+		return cep
+	}
+	return cep[:5] + "-" + cep[5:]
+}
+
+func ValidateCEP(v *validator.Validator, key, cep string) {
+	v.Check(CEPRX.MatchString(cep), key, "must have 8 digits, such as 01310-100")
+}
+
+type AddressDTO struct {
+	CEP        string `json:"cep"`
+	Street     string `json:"logradouro"`
+	Number     string `json:"numero"`
+	Complement string `json:"complemento"`
+	District   string `json:"bairro"`
+	City       string `json:"cidade"`
+	UF         string `json:"uf"`
+	// Latitude and Longitude are geocoded, so ToModel ignores them.
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+func (a Address) ToDTO() *AddressDTO {
+	dto := &AddressDTO{
+		CEP:        a.CEP,
+		Street:     a.Street,
+		Number:     a.Number,
+		Complement: a.Complement,
+		District:   a.District,
+		City:       a.City,
+		UF:         a.UF,
+	}
+	dto.Latitude, dto.Longitude = a.coordinatesDTO()
+	return dto
+}
+
+// coordinatesDTO splits the coordinates into the latitude and longitude
+// fields of the DTOs, both nil when the place isn't geocoded.
+func (p Place) coordinatesDTO() (*float64, *float64) {
+	if p.Coordinates == nil {
+		return nil, nil
+	}
+	return &p.Coordinates.Latitude, &p.Coordinates.Longitude
+}
+
+func (a AddressDTO) ToModel() *Address {
+	return &Address{
+		Place: Place{
+			CEP:      a.CEP,
+			Street:   a.Street,
+			District: a.District,
+			City:     a.City,
+			UF:       a.UF,
+		},
+		Number:     a.Number,
+		Complement: a.Complement,
+	}
+}
+
+// String writes the address on one line, such as "Avenida Paulista, 1000 -
+// Bela Vista - São Paulo/SP - 01310-100", leaving out what's missing.
+func (a Address) String() string {
+	street := a.Street
+	if street != "" && a.Number != "" {
+		street += ", " + a.Number
+	}
+	if street != "" && a.Complement != "" {
+		street += " " + a.Complement
+	}
+
+	parts := []string{}
+	for _, part := range []string{street, a.District, strings.Trim(a.City+"/"+a.UF, "/"), FormatCEP(a.CEP)} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " - ")
+}
+
+// IsZero ignores the coordinates, which follow from the rest.
+func (a Address) IsZero() bool {
+	a.Coordinates = nil
+	return a == Address{}
+}
+
+func (p *Place) ValidatePlace(v *validator.Validator) {
+	if p.CEP != "" {
+		ValidateCEP(v, "cep", p.CEP)
+	}
+	v.Check(len(p.Street) <= 500, "street", "must not be more than 500 bytes long")
+	v.Check(len(p.District) <= 200, "district", "must not be more than 200 bytes long")
+	ValidateLocation(v, p.City, p.UF)
+}
+
+func (a *Address) ValidateAddress(v *validator.Validator) {
+	v.Check(len(a.UF) == 0 || len(a.UF) == 2, "uf", "must have 2 letters")
+	v.Check(len(a.City) <= 200, "cidade", "must not be more than 200 bytes long")
+	v.Check(len(a.Street) <= 500, "logradouro", "must not be more than 500 bytes long")
+}
+
+var UFs = []string{
+	"AC", "AL", "AM", "AP", "BA", "CE", "DF", "ES", "GO", "MA", "MG", "MS", "MT", "PA",
+	"PB", "PE", "PI", "PR", "RJ", "RN", "RO", "RR", "RS", "SC", "SE", "SP", "TO",
+}
+
+// ValidateLocation checks a city given with its state, as curricula and
+// job postings place themselves. Both may be left out, not just one.
+func ValidateLocation(v *validator.Validator, city, uf string) {
+	v.Check(len(city) <= 200, "city", "must not be more than 200 bytes long")
+	v.Check(uf == "" || validator.In(uf, UFs...), "uf", "must be a Brazilian state abbreviation, such as SP")
+	v.Check(city == "" || uf != "", "uf", "must be provided along with the city")
+	v.Check(uf == "" || city != "", "city", "must be provided along with the state")
+}
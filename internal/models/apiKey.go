@@ -0,0 +1,150 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"meu_job/utils/validator"
+	"slices"
+	"strings"
+	"time"
+)
+
+const apiKeyTag = "mj"
+
+type Scope string
+
+const (
+	ScopeBusinessRead  Scope = "business:read"
+	ScopeBusinessWrite Scope = "business:write"
+
+	// ScopeAccount is never granted to a key, so keys cannot be used to
+	// manage the account itself (API keys, sessions, profile).
+	ScopeAccount Scope = "account"
+)
+
+var roleScopes = map[Role][]Scope{
+	USER:     {ScopeBusinessRead},
+	BUSINESS: {ScopeBusinessRead},
+	ADMIN:    {ScopeBusinessRead, ScopeBusinessWrite},
+}
+
+func (r Role) AllowedScopes() []Scope {
+	return roleScopes[r]
+}
+
+type APIKey struct {
+	ID         int64
+	UserID     int64
+	Name       string
+	Prefix     string
+	Hash       []byte
+	Plaintext  *string
+	Scopes     []Scope
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	BaseModel
+}
+
+type APIKeyDTO struct {
+	ID         int64      `json:"api_key_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Key        *string    `json:"key,omitempty"`
+	Scopes     []Scope    `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type APIKeySaveDTO struct {
+	Name      string     `json:"name"`
+	Scopes    []Scope    `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (k *APIKey) ToDTO() *APIKeyDTO {
+	return &APIKeyDTO{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Key:        k.Plaintext,
+		Scopes:     k.Scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+func (d *APIKeySaveDTO) ToModel() *APIKey {
+	return &APIKey{
+		Name:      d.Name,
+		Scopes:    d.Scopes,
+		ExpiresAt: d.ExpiresAt,
+	}
+}
+
+func (k *APIKey) Generate() error {
+	prefix := make([]byte, 4)
+	if _, err := rand.Read(prefix); err != nil {
+		return err
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+
+	k.Prefix = hex.EncodeToString(prefix)
+	plaintext := apiKeyTag + "_" + k.Prefix + "_" + hex.EncodeToString(secret)
+	k.Plaintext = &plaintext
+	k.Hash = HashAPIKey(plaintext)
+	return nil
+}
+
+func (k *APIKey) Matches(plaintext string) bool {
+	return subtle.ConstantTimeCompare(k.Hash, HashAPIKey(plaintext)) == 1
+}
+
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+func (k *APIKey) HasScope(scope Scope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+func HashAPIKey(plaintext string) []byte {
+	hash := sha256.Sum256([]byte(plaintext))
+	return hash[:]
+}
+
+func ParseAPIKeyPrefix(plaintext string) (string, bool) {
+	parts := strings.Split(plaintext, "_")
+	if len(parts) != 3 || parts[0] != apiKeyTag || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func (k *APIKey) ValidateAPIKey(v *validator.Validator, role Role) {
+	v.Check(k.Name != "", "name", "must be provided")
+	v.Check(len(k.Name) <= 100, "name", "must not be more than 100 bytes long")
+	v.Check(len(k.Scopes) > 0, "scopes", "must contain at least one scope")
+
+	allowed := role.AllowedScopes()
+	for _, scope := range k.Scopes {
+		v.Check(slices.Contains(allowed, scope), "scopes", "contains a scope not allowed for your account: "+string(scope))
+	}
+
+	scopes := make([]string, len(k.Scopes))
+	for i, scope := range k.Scopes {
+		scopes[i] = string(scope)
+	}
+	v.Check(validator.Unique(scopes), "scopes", "must not contain duplicate values")
+
+	if k.ExpiresAt != nil {
+		v.Check(k.ExpiresAt.After(time.Now()), "expires_at", "must be in the future")
+	}
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"meu_job/utils/validator"
+	"slices"
+	"strings"
+	"time"
+)
+
+type ApplicationStage string
+
+const (
+	StageApplied   ApplicationStage = "applied"
+	StageScreening ApplicationStage = "screening"
+	StageInterview ApplicationStage = "interview"
+	StageOffer     ApplicationStage = "offer"
+	StageHired     ApplicationStage = "hired"
+	StageRejected  ApplicationStage = "rejected"
+	StageWithdrawn ApplicationStage = "withdrawn"
+)
+
+var ApplicationStages = []string{
+	string(StageApplied),
+	string(StageScreening),
+	string(StageInterview),
+	string(StageOffer),
+	string(StageHired),
+	string(StageRejected),
+	string(StageWithdrawn),
+}
+
+// stageTransitions are the moves a business may make. Rejecting is allowed
+// from any open stage, and so is withdrawing for the candidate.
+var stageTransitions = map[ApplicationStage][]ApplicationStage{
+	StageApplied:   {StageScreening, StageInterview, StageRejected},
+	StageScreening: {StageInterview, StageRejected},
+	StageInterview: {StageScreening, StageOffer, StageRejected},
+	StageOffer:     {StageInterview, StageHired, StageRejected},
+}
+
+// IsOpen is whether the application is still under way.
+func (s ApplicationStage) IsOpen() bool {
+	_, ok := stageTransitions[s]
+	return ok
+}
+
+func (s ApplicationStage) CanMoveTo(to ApplicationStage) bool {
+	return slices.Contains(stageTransitions[s], to)
+}
+
+// stageLabels are how the stages read to users.
+var stageLabels = map[ApplicationStage]string{
+	StageApplied:   "candidatura enviada",
+	StageScreening: "triagem",
+	StageInterview: "entrevista",
+	StageOffer:     "proposta",
+	StageHired:     "contratação",
+	StageRejected:  "não selecionada",
+	StageWithdrawn: "retirada",
+}
+
+func (s ApplicationStage) Label() string {
+	if label, ok := stageLabels[s]; ok {
+		return label
+	}
+	return string(s)
+}
+
+// Participant is one side of an application, as shown to the other.
+type Participant struct {
+	UserID int64
+	Name   string
+	Email  string
+}
+
+type ParticipantDTO struct {
+	UserID int64  `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email,omitempty"`
+}
+
+func (p Participant) ToDTO() *ParticipantDTO {
+	return &ParticipantDTO{
+		UserID: p.UserID,
+		Name:   p.Name,
+		Email:  p.Email,
+	}
+}
+
+// Application is a candidate applying to a published job with one of
+// their curricula, sent as it was at CurriculumVersion. CurriculumID is nil
+// once the candidate's account is erased.
+type Application struct {
+	ID                int64
+	JobID             int64
+	BusinessID        int64
+	UserID            int64
+	CurriculumID      *int64
+	CurriculumVersion int
+	CoverLetter       string
+	Stage             ApplicationStage
+	// StageReason is the one given for the last stage change, if any.
+	StageReason    string
+	StageChangedAt time.Time
+	// JobTitle, BusinessName and Candidate are loaded along with it;
+	// Curriculum only when a business opens the application.
+	JobTitle     string
+	BusinessName string
+	Candidate    Participant
+	Curriculum   *Curriculum
+	BaseModel
+}
+
+type ApplicationDTO struct {
+	ID                int64            `json:"application_id"`
+	JobID             int64            `json:"job_id"`
+	JobTitle          string           `json:"job_title"`
+	BusinessID        int64            `json:"business_id"`
+	BusinessName      string           `json:"business_name"`
+	CurriculumID      *int64           `json:"curriculum_id"`
+	CurriculumVersion int              `json:"curriculum_version"`
+	CoverLetter       string           `json:"cover_letter"`
+	Stage             ApplicationStage `json:"stage"`
+	StageReason       string           `json:"stage_reason"`
+	StageChangedAt    time.Time        `json:"stage_changed_at"`
+	Candidate         *ParticipantDTO  `json:"candidate,omitempty"`
+	Curriculum        *CurriculumDTO   `json:"curriculum,omitempty"`
+	Version           int              `json:"version"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         *time.Time       `json:"updated_at"`
+}
+
+// ToDTO is the candidate's view of their application.
+func (a Application) ToDTO() *ApplicationDTO {
+	return &ApplicationDTO{
+		ID:                a.ID,
+		JobID:             a.JobID,
+		JobTitle:          a.JobTitle,
+		BusinessID:        a.BusinessID,
+		BusinessName:      a.BusinessName,
+		CurriculumID:      a.CurriculumID,
+		CurriculumVersion: a.CurriculumVersion,
+		CoverLetter:       a.CoverLetter,
+		Stage:             a.Stage,
+		StageReason:       a.StageReason,
+		StageChangedAt:    a.StageChangedAt,
+		Version:           a.Version,
+		CreatedAt:         a.CreatedAt,
+		UpdatedAt:         a.UpdatedAt,
+	}
+}
+
+// ToBusinessDTO adds who the candidate is and, once loaded, the curriculum
+// they applied with.
+func (a Application) ToBusinessDTO() *ApplicationDTO {
+	dto := a.ToDTO()
+	dto.Candidate = a.Candidate.ToDTO()
+	if a.Curriculum != nil {
+		dto.Curriculum = a.Curriculum.ToRecruiterDTO()
+	}
+	return dto
+}
+
+// ApplyDTO is what a candidate sends to apply. The default curriculum is
+// used unless CurriculumID is given.
+type ApplyDTO struct {
+	JobID        int64  `json:"job_id"`
+	CurriculumID *int64 `json:"curriculum_id"`
+	CoverLetter  string `json:"cover_letter"`
+}
+
+func (d ApplyDTO) ToModel() *Application {
+	return &Application{
+		JobID:        d.JobID,
+		CurriculumID: d.CurriculumID,
+		CoverLetter:  strings.TrimSpace(d.CoverLetter),
+	}
+}
+
+func (a *Application) ValidateApplication(v *validator.Validator) {
+	v.Check(a.JobID > 0, "job_id", "must be provided")
+	v.Check(len(a.CoverLetter) <= 5000, "cover_letter", "must not be more than 5000 bytes long")
+}
+
+// StageChangeDTO moves an application, against the version last read.
+type StageChangeDTO struct {
+	Stage   ApplicationStage `json:"stage"`
+	Reason  string           `json:"reason"`
+	Version int              `json:"version"`
+}
+
+func ValidateStageReason(v *validator.Validator, reason string) {
+	v.Check(len(reason) <= 1000, "reason", "must not be more than 1000 bytes long")
+}
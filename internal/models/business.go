@@ -1,38 +1,95 @@
 package models
 
 import (
+	"encoding/json"
 	"meu_job/utils/validator"
-	"slices"
-	"unicode"
+	"time"
 )
 
 type Business struct {
 	ID    int64
 	Name  string
-	CNPJ  string
+	CNPJ  CNPJ
 	Email string
 	Phone string
+
+	// Registry data is filled from the CNPJ registry on creation and when
+	// the CNPJ changes, and is not editable by members. Verified means the
+	// registry confirmed an active company matching the given name.
+	LegalName          string
+	TradeName          string
+	Address            Address
+	RegistrationStatus string
+	Verified           bool
+	RegistrySnapshot   json.RawMessage
+	RegistryCheckedAt  *time.Time
+
+	// Verification is the admin review of proof of ownership, independent
+	// of the registry check above.
+	VerificationStatus     VerificationStatus
+	VerificationReason     string
+	VerificationReviewedBy *int64
+	VerificationReviewedAt *time.Time
+
+	Profile       CompanyProfile
+	ProfileGiven  ProfileFields
+	LogoFileID    *int64
+	OpenVacancies int
 	BaseModel
 }
 
 type BusinessDTO struct {
-	ID    *int64  `json:"business_id"`
-	Name  *string `json:"name"`
-	CNPJ  *string `json:"cnpj"`
-	Email *string `json:"email"`
-	Phone *string `json:"phone"`
+	ID                 *int64      `json:"business_id"`
+	Name               *string     `json:"name"`
+	CNPJ               *string     `json:"cnpj"`
+	Email              *string     `json:"email"`
+	Phone              *string     `json:"phone"`
+	Address            *AddressDTO `json:"address"`
+	LegalName          *string     `json:"legal_name,omitempty"`
+	TradeName          *string     `json:"trade_name,omitempty"`
+	RegistrationStatus *string     `json:"registration_status,omitempty"`
+	Verified           *bool       `json:"verified,omitempty"`
+	VerificationStatus *string     `json:"verification_status,omitempty"`
+
+	Slug        *string           `json:"slug"`
+	Description *string           `json:"description"`
+	Sector      *string           `json:"sector"`
+	Size        *string           `json:"size"`
+	LogoURL     *string           `json:"logo_url"`
+	Website     *string           `json:"website"`
+	SocialLinks map[string]string `json:"social_links"`
+	Public      *bool             `json:"public"`
+	LogoFileID  *int64            `json:"logo_file_id,omitempty"`
 }
 
 func (b Business) ToDTO() *BusinessDTO {
+	cnpj := b.CNPJ.Format()
 	return &BusinessDTO{
-		ID:    &b.ID,
-		Name:  &b.Name,
-		CNPJ:  &b.CNPJ,
-		Email: &b.Email,
-		Phone: &b.Phone,
+		ID:                 &b.ID,
+		Name:               &b.Name,
+		CNPJ:               &cnpj,
+		Email:              &b.Email,
+		Phone:              &b.Phone,
+		Address:            b.Address.ToDTO(),
+		LegalName:          &b.LegalName,
+		TradeName:          &b.TradeName,
+		RegistrationStatus: &b.RegistrationStatus,
+		Verified:           &b.Verified,
+		VerificationStatus: (*string)(&b.VerificationStatus),
+		Slug:               &b.Profile.Slug,
+		Description:        &b.Profile.Description,
+		Sector:             &b.Profile.Sector,
+		Size:               (*string)(&b.Profile.Size),
+		LogoURL:            &b.Profile.LogoURL,
+		Website:            &b.Profile.Website,
+		SocialLinks:        b.Profile.SocialLinks,
+		Public:             &b.Profile.Public,
+		LogoFileID:         b.LogoFileID,
 	}
 }
 
+// ToModel ignores the registry fields: they are only ever set by the server.
+// Public is set on creation only; afterwards it has its own toggle.
 func (b BusinessDTO) ToModel() *Business {
 	var model = &Business{}
 	if b.ID != nil {
@@ -43,7 +100,7 @@ func (b BusinessDTO) ToModel() *Business {
 	}
 
 	if b.CNPJ != nil {
-		model.CNPJ = *b.CNPJ
+		model.CNPJ = NormalizeCNPJ(*b.CNPJ)
 	}
 
 	if b.Phone != nil {
@@ -54,82 +111,55 @@ func (b BusinessDTO) ToModel() *Business {
 		model.Email = *b.Email
 	}
 
-	return model
-}
-
-func (m *Business) ValidateBusiness(v *validator.Validator) {
-	v.Check(m.Name != "", "name", "must be provided")
-	v.Check(len(m.Name) <= 500, "name", "must not be more than 500 bytes long")
-	v.Check(m.Phone != "", "phone", "must be provided")
-	ValidateEmail(v, m.Email)
-	ValidateCNPJ(v, m.CNPJ)
-}
-
-func ValidateCNPJ(v *validator.Validator, cnpj string) {
-	v.Check(cnpj != "", "cnpj", "must be provided")
-	v.Check(isValidCNPJ(cnpj), "cnpj", "must be a valid cnpj")
-}
-
-func normalizeCNPJ(cnpj string) string {
-	var out []rune
-	for _, r := range cnpj {
-		if unicode.IsDigit(r) {
-			out = append(out, r)
-		}
+	if b.Address != nil {
+		model.Address = *b.Address.ToModel()
 	}
 
-	return string(out)
-}
-
-func isValidCNPJ(cnpj string) bool {
-	cnpj = normalizeCNPJ(cnpj)
-
-	if len(cnpj) != 14 {
-		return false
+	if b.Slug != nil {
+		model.Profile.Slug = *b.Slug
 	}
 
-	invalids := []string{
-		"00000000000000", "11111111111111", "22222222222222",
-		"33333333333333", "44444444444444", "55555555555555",
-		"66666666666666", "77777777777777", "88888888888888",
-		"99999999999999",
+	if b.Description != nil {
+		model.Profile.Description = *b.Description
+		model.ProfileGiven.Description = true
 	}
 
-	if slices.Contains(invalids, cnpj) {
-		return false
+	if b.Sector != nil {
+		model.Profile.Sector = *b.Sector
+		model.ProfileGiven.Sector = true
 	}
 
-	var nums []int
-	for _, c := range cnpj {
-		nums = append(nums, int(c-'0'))
+	if b.Size != nil {
+		model.Profile.Size = CompanySize(*b.Size)
+		model.ProfileGiven.Size = true
 	}
 
-	w1 := []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
-	w2 := []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
-
-	sum := 0
-	for i := range 12 {
-		sum += nums[i] * w1[i]
-	}
-	r1 := sum % 11
-	d1 := 0
-	if r1 >= 2 {
-		d1 = 11 - r1
+	if b.LogoURL != nil {
+		model.Profile.LogoURL = *b.LogoURL
+		model.ProfileGiven.LogoURL = true
 	}
 
-	if nums[12] != d1 {
-		return false
+	if b.Website != nil {
+		model.Profile.Website = *b.Website
+		model.ProfileGiven.Website = true
 	}
 
-	sum = 0
-	for i := range 13 {
-		sum += nums[i] * w2[i]
-	}
-	r2 := sum % 11
-	d2 := 0
-	if r2 >= 2 {
-		d2 = 11 - r2
+	if b.Public != nil {
+		model.Profile.Public = *b.Public
 	}
 
-	return nums[13] == d2
+	model.Profile.SocialLinks = b.SocialLinks
+	model.ProfileGiven.SocialLinks = b.SocialLinks != nil
+
+	return model
+}
+
+func (m *Business) ValidateBusiness(v *validator.Validator) {
+	v.Check(m.Name != "", "name", "must be provided")
+	v.Check(len(m.Name) <= 500, "name", "must not be more than 500 bytes long")
+	v.Check(m.Phone != "", "phone", "must be provided")
+	ValidateEmail(v, m.Email)
+	ValidateCNPJ(v, m.CNPJ)
+	m.Address.ValidateAddress(v)
+	m.Profile.ValidateCompanyProfile(v)
 }
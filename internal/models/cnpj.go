@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"meu_job/utils/validator"
+	"strings"
+)
+
+// CNPJ holds the canonical form of a CNPJ: 14 characters, upper case, no
+// punctuation. Since July 2026 the first 12 characters may be letters; the two
+// check digits are always numeric.
+type CNPJ string
+
+var ErrInvalidCNPJ = errors.New("invalid CNPJ")
+
+var (
+	cnpjWeights1 = []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	cnpjWeights2 = []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+)
+
+// NormalizeCNPJ drops the usual mask characters and upper-cases the rest. It
+// does not validate; use ParseCNPJ for that.
+func NormalizeCNPJ(s string) CNPJ {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		switch r {
+		case '.', '/', '-', ' ':
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return CNPJ(b.String())
+}
+
+func ParseCNPJ(s string) (CNPJ, error) {
+	cnpj := NormalizeCNPJ(s)
+	if !cnpj.IsValid() {
+		return "", ErrInvalidCNPJ
+	}
+	return cnpj, nil
+}
+
+func (c CNPJ) IsValid() bool {
+	if len(c) != 14 {
+		return false
+	}
+
+	values := make([]int, 14)
+	for i := range 14 {
+		ch := c[i]
+		switch {
+		case ch >= '0' && ch <= '9':
+		case ch >= 'A' && ch <= 'Z' && i < 12:
+		default:
+			return false
+		}
+		// ASCII - 48 maps '0'-'9' to 0-9 and 'A'-'Z' to 17-42.
+		values[i] = int(ch) - '0'
+	}
+
+	if strings.Count(string(c), string(c[0])) == 14 {
+		return false
+	}
+
+	return values[12] == cnpjCheckDigit(values[:12], cnpjWeights1) &&
+		values[13] == cnpjCheckDigit(values[:13], cnpjWeights2)
+}
+
+func cnpjCheckDigit(values, weights []int) int {
+	sum := 0
+	for i, value := range values {
+		sum += value * weights[i]
+	}
+
+	r := sum % 11
+	if r < 2 {
+		return 0
+	}
+	return 11 - r
+}
+
+// Format renders the CNPJ as XX.XXX.XXX/XXXX-XX. Values that aren't 14
+// characters long are returned unchanged.
+func (c CNPJ) Format() string {
+	if len(c) != 14 {
+		return string(c)
+	}
+
+	s := string(c)
+	return s[0:2] + "." + s[2:5] + "." + s[5:8] + "/" + s[8:12] + "-" + s[12:14]
+}
+
+func (c CNPJ) String() string {
+	return string(c)
+}
+
+func ValidateCNPJ(v *validator.Validator, cnpj CNPJ) {
+	v.Check(cnpj != "", "cnpj", "must be provided")
+	v.Check(cnpj.IsValid(), "cnpj", "must be a valid cnpj")
+}
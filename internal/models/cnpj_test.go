@@ -0,0 +1,82 @@
+package models
+
+import (
+	"errors"
+	"meu_job/utils/validator"
+	"testing"
+)
+
+func TestParseCNPJ(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    CNPJ
+		wantErr bool
+	}{
+		{"11.222.333/0001-81", "11222333000181", false},
+		{"11222333000181", "11222333000181", false},
+		{" 11 444 777 0001-61 ", "11444777000161", false},
+		// The alphanumeric example published by the Receita Federal.
+		{"12.ABC.345/01DE-35", "12ABC34501DE35", false},
+		{"12.abc.345/01de-35", "12ABC34501DE35", false},
+		{"11.222.333/0001-82", "", true},
+		{"12.ABC.345/01DE-36", "", true},
+		{"12.ABC.345/01DE-3A", "", true},
+		{"00.000.000/0000-00", "", true},
+		{"11.111.111/1111-11", "", true},
+		{"11.222.333/0001-8", "", true},
+		{"11.222.333/0001-811", "", true},
+		{"11.222.333#0001-81", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseCNPJ(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCNPJ) {
+					t.Fatalf("ParseCNPJ(%q) = %q, %v, want %v", tt.in, got, err, ErrInvalidCNPJ)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("ParseCNPJ(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCNPJFormat(t *testing.T) {
+	tests := []struct {
+		cnpj CNPJ
+		want string
+	}{
+		{"11222333000181", "11.222.333/0001-81"},
+		{"12ABC34501DE35", "12.ABC.345/01DE-35"},
+		{"1122233300018", "1122233300018"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cnpj.Format(); got != tt.want {
+			t.Errorf("CNPJ(%q).Format() = %q, want %q", tt.cnpj, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCNPJ(t *testing.T) {
+	tests := []struct {
+		cnpj  CNPJ
+		valid bool
+	}{
+		{"11222333000181", true},
+		{"12ABC34501DE35", true},
+		{"11222333000182", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		v := validator.New()
+		if ValidateCNPJ(v, tt.cnpj); v.Valid() != tt.valid {
+			t.Errorf("ValidateCNPJ(%q) valid = %v, want %v: %v", tt.cnpj, v.Valid(), tt.valid, v.Errors)
+		}
+	}
+}
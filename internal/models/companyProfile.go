@@ -0,0 +1,120 @@
+package models
+
+import (
+	"maps"
+	"meu_job/utils/validator"
+	"net/url"
+	"slices"
+)
+
+type CompanySize string
+
+const (
+	CompanySizeMicro  CompanySize = "1-10"
+	CompanySizeSmall  CompanySize = "11-50"
+	CompanySizeMedium CompanySize = "51-200"
+	CompanySizeLarge  CompanySize = "201-1000"
+	CompanySizeHuge   CompanySize = "1000+"
+)
+
+var companySizes = []string{
+	string(CompanySizeMicro),
+	string(CompanySizeSmall),
+	string(CompanySizeMedium),
+	string(CompanySizeLarge),
+	string(CompanySizeHuge),
+}
+
+var socialNetworks = []string{"linkedin", "instagram", "facebook", "x", "github", "youtube"}
+
+// CompanyProfile holds the fields members edit to present the business to
+// candidates. Nothing here is shown publicly unless Public is set.
+type CompanyProfile struct {
+	Slug        string
+	Description string
+	Sector      string
+	Size        CompanySize
+	LogoURL     string
+	Website     string
+	SocialLinks map[string]string
+	Public      bool
+}
+
+// ProfileFields marks the profile fields an update carried; those it left
+// out are kept as they are.
+type ProfileFields struct {
+	Description bool
+	Sector      bool
+	Size        bool
+	LogoURL     bool
+	Website     bool
+	SocialLinks bool
+}
+
+// CompanyProfileDTO is the curated, unauthenticated view of a business.
+type CompanyProfileDTO struct {
+	Slug          string            `json:"slug"`
+	Name          string            `json:"name"`
+	Description   string            `json:"description"`
+	Sector        string            `json:"sector"`
+	Size          CompanySize       `json:"size,omitempty"`
+	City          string            `json:"city"`
+	UF            string            `json:"uf"`
+	LogoURL       string            `json:"logo_url,omitempty"`
+	Website       string            `json:"website,omitempty"`
+	SocialLinks   map[string]string `json:"social_links"`
+	OpenVacancies int               `json:"open_vacancies"`
+	Verified      bool              `json:"verified"`
+}
+
+func (b *Business) ToProfileDTO() *CompanyProfileDTO {
+	links := b.Profile.SocialLinks
+	if links == nil {
+		links = map[string]string{}
+	}
+
+	return &CompanyProfileDTO{
+		Slug:          b.Profile.Slug,
+		Name:          b.Name,
+		Description:   b.Profile.Description,
+		Sector:        b.Profile.Sector,
+		Size:          b.Profile.Size,
+		City:          b.Address.City,
+		UF:            b.Address.UF,
+		LogoURL:       b.profileLogoURL(),
+		Website:       b.Profile.Website,
+		SocialLinks:   links,
+		OpenVacancies: b.OpenVacancies,
+		Verified:      b.VerificationStatus == VerificationVerified,
+	}
+}
+
+// profileLogoURL prefers an uploaded logo over the URL typed by the
+// members; it is served through a redirect to a fresh signed URL.
+func (b *Business) profileLogoURL() string {
+	if b.LogoFileID != nil {
+		return "/v1/companies/" + b.Profile.Slug + "/logo"
+	}
+	return b.Profile.LogoURL
+}
+
+func (p *CompanyProfile) ValidateCompanyProfile(v *validator.Validator) {
+	if p.Slug != "" {
+		ValidateSlug(v, p.Slug)
+	}
+	v.Check(len(p.Description) <= 5000, "description", "must not be more than 5000 bytes long")
+	v.Check(len(p.Sector) <= 100, "sector", "must not be more than 100 bytes long")
+	v.Check(p.Size == "" || validator.In(string(p.Size), companySizes...), "size", "must be one of 1-10, 11-50, 51-200, 201-1000 or 1000+")
+	v.Check(p.LogoURL == "" || isWebURL(p.LogoURL), "logo_url", "must be a valid http(s) URL")
+	v.Check(p.Website == "" || isWebURL(p.Website), "website", "must be a valid http(s) URL")
+
+	for _, network := range slices.Sorted(maps.Keys(p.SocialLinks)) {
+		v.Check(validator.In(network, socialNetworks...), "social_links", "unsupported network "+network)
+		v.Check(isWebURL(p.SocialLinks[network]), "social_links", network+" must be a valid http(s) URL")
+	}
+}
+
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && len(s) <= 500
+}
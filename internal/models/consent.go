@@ -0,0 +1,141 @@
+package models
+
+import (
+	"meu_job/utils/validator"
+	"slices"
+	"time"
+)
+
+type DocumentKind string
+
+const (
+	DocumentTerms   DocumentKind = "terms"
+	DocumentPrivacy DocumentKind = "privacy"
+)
+
+type ConsentPurpose string
+
+const (
+	PurposeMarketingEmail        ConsentPurpose = "marketing_email"
+	PurposeShareCVWithRecruiters ConsentPurpose = "share_cv_with_recruiters"
+)
+
+var ConsentPurposes = []ConsentPurpose{
+	PurposeMarketingEmail,
+	PurposeShareCVWithRecruiters,
+}
+
+type LegalDocument struct {
+	ID          int64
+	Kind        DocumentKind
+	Version     int
+	Title       string
+	Content     string
+	PublishedAt time.Time
+	CreatedBy   *int64
+	CreatedAt   time.Time
+}
+
+type LegalDocumentDTO struct {
+	ID          int64        `json:"document_id"`
+	Kind        DocumentKind `json:"kind"`
+	Version     int          `json:"version"`
+	Title       string       `json:"title"`
+	Content     string       `json:"content"`
+	PublishedAt time.Time    `json:"published_at"`
+}
+
+type LegalDocumentSaveDTO struct {
+	Kind    DocumentKind `json:"kind"`
+	Title   string       `json:"title"`
+	Content string       `json:"content"`
+}
+
+// Consent is an append-only record: the current state of a subject is the
+// most recent row for it.
+type Consent struct {
+	ID         int64
+	UserID     int64
+	Subject    string
+	DocumentID *int64
+	Granted    bool
+	IP         string
+	UserAgent  string
+	CreatedAt  time.Time
+}
+
+type ConsentDTO struct {
+	ID         int64     `json:"consent_id"`
+	Subject    string    `json:"subject"`
+	DocumentID *int64    `json:"document_id,omitempty"`
+	Granted    bool      `json:"granted"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ConsentAcceptance struct {
+	TermsVersion   int              `json:"terms_version"`
+	PrivacyVersion int              `json:"privacy_version"`
+	OptIns         []ConsentPurpose `json:"opt_ins"`
+	IP             string           `json:"-"`
+	UserAgent      string           `json:"-"`
+}
+
+func (d *LegalDocument) ToDTO() *LegalDocumentDTO {
+	return &LegalDocumentDTO{
+		ID:          d.ID,
+		Kind:        d.Kind,
+		Version:     d.Version,
+		Title:       d.Title,
+		Content:     d.Content,
+		PublishedAt: d.PublishedAt,
+	}
+}
+
+func (d *LegalDocumentSaveDTO) ToModel() *LegalDocument {
+	return &LegalDocument{
+		Kind:    d.Kind,
+		Title:   d.Title,
+		Content: d.Content,
+	}
+}
+
+func (c *Consent) ToDTO() *ConsentDTO {
+	return &ConsentDTO{
+		ID:         c.ID,
+		Subject:    c.Subject,
+		DocumentID: c.DocumentID,
+		Granted:    c.Granted,
+		IP:         c.IP,
+		UserAgent:  c.UserAgent,
+		CreatedAt:  c.CreatedAt,
+	}
+}
+
+func (d *LegalDocument) ValidateLegalDocument(v *validator.Validator) {
+	v.Check(d.Kind == DocumentTerms || d.Kind == DocumentPrivacy, "kind", "must be terms or privacy")
+	v.Check(d.Title != "", "title", "must be provided")
+	v.Check(len(d.Title) <= 500, "title", "must not be more than 500 bytes long")
+	v.Check(d.Content != "", "content", "must be provided")
+}
+
+func ValidateConsentPurpose(v *validator.Validator, purpose ConsentPurpose) {
+	v.Check(slices.Contains(ConsentPurposes, purpose), "purpose", "must be a known consent purpose")
+}
+
+// ValidateAcceptance checks the accepted versions against the documents
+// currently in force. Kinds without a published document are not required.
+func (a *ConsentAcceptance) ValidateAcceptance(v *validator.Validator, current map[DocumentKind]*LegalDocument) {
+	if doc, ok := current[DocumentTerms]; ok {
+		v.Check(a.TermsVersion == doc.Version, "terms_version", "must accept the current terms of use")
+	}
+
+	if doc, ok := current[DocumentPrivacy]; ok {
+		v.Check(a.PrivacyVersion == doc.Version, "privacy_version", "must accept the current privacy policy")
+	}
+
+	for _, purpose := range a.OptIns {
+		ValidateConsentPurpose(v, purpose)
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"meu_job/utils/validator"
+	"strings"
+)
+
+// CPF holds the 11 digits of a CPF without punctuation.
+type CPF string
+
+func NormalizeCPF(s string) CPF {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '.', '-', ' ':
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return CPF(b.String())
+}
+
+func (c CPF) IsValid() bool {
+	if len(c) != 11 {
+		return false
+	}
+
+	values := make([]int, 11)
+	for i := range 11 {
+		if c[i] < '0' || c[i] > '9' {
+			return false
+		}
+		values[i] = int(c[i] - '0')
+	}
+
+	if strings.Count(string(c), string(c[0])) == 11 {
+		return false
+	}
+
+	return values[9] == cpfCheckDigit(values[:9]) && values[10] == cpfCheckDigit(values[:10])
+}
+
+func cpfCheckDigit(values []int) int {
+	sum := 0
+	weight := len(values) + 1
+	for _, value := range values {
+		sum += value * weight
+		weight--
+	}
+
+	r := (sum * 10) % 11
+	if r == 10 {
+		return 0
+	}
+	return r
+}
+
+func (c CPF) Format() string {
+	if len(c) != 11 {
+		return string(c)
+	}
+
+	s := string(c)
+	return s[0:3] + "." + s[3:6] + "." + s[6:9] + "-" + s[9:11]
+}
+
+// Masked hides everything but the middle six digits, e.g. ***.456.789-**,
+// which is how CPFs are shown back to clients.
+func (c CPF) Masked() string {
+	if len(c) != 11 {
+		return "***"
+	}
+
+	s := string(c)
+	return "***." + s[3:6] + "." + s[6:9] + "-**"
+}
+
+func (c CPF) String() string {
+	return string(c)
+}
+
+func ValidateCPF(v *validator.Validator, cpf CPF) {
+	v.Check(cpf.IsValid(), "cpf", "must be a valid cpf")
+}
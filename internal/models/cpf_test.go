@@ -0,0 +1,77 @@
+package models
+
+import (
+	"meu_job/utils/validator"
+	"testing"
+)
+
+func TestCPF(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   CPF
+		valid  bool
+		format string
+		masked string
+	}{
+		{"529.982.247-25", "52998224725", true, "529.982.247-25", "***.982.247-**"},
+		{"52998224725", "52998224725", true, "529.982.247-25", "***.982.247-**"},
+		{" 123 456 789 09 ", "12345678909", true, "123.456.789-09", "***.456.789-**"},
+		{"529.982.247-24", "52998224724", false, "529.982.247-24", "***.982.247-**"},
+		{"111.111.111-11", "11111111111", false, "111.111.111-11", "***.111.111-**"},
+		{"000.000.000-00", "00000000000", false, "000.000.000-00", "***.000.000-**"},
+		{"529.982.247-2", "5299822472", false, "5299822472", "***"},
+		{"529/982/247-25", "529/982/24725", false, "529/982/24725", "***"},
+		{"5299822472a", "5299822472a", false, "529.982.247-2a", "***.982.247-**"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			cpf := NormalizeCPF(tt.in)
+			if cpf != tt.want {
+				t.Fatalf("NormalizeCPF(%q) = %q, want %q", tt.in, cpf, tt.want)
+			}
+			if got := cpf.IsValid(); got != tt.valid {
+				t.Errorf("IsValid() = %v, want %v", got, tt.valid)
+			}
+			if got := cpf.Format(); got != tt.format {
+				t.Errorf("Format() = %q, want %q", got, tt.format)
+			}
+			if got := cpf.Masked(); got != tt.masked {
+				t.Errorf("Masked() = %q, want %q", got, tt.masked)
+			}
+
+			v := validator.New()
+			if ValidateCPF(v, cpf); v.Valid() != tt.valid {
+				t.Errorf("ValidateCPF valid = %v, want %v", v.Valid(), tt.valid)
+			}
+		})
+	}
+}
+
+// A curriculum sent back as it was shown keeps the CPF stored, since the
+// client only ever sees it masked.
+func TestCurriculumCPFRoundTrip(t *testing.T) {
+	cpf := CPF("52998224725")
+	shown := Curriculum{CPF: &cpf}.ToDTO()
+	if shown.CPF == nil || *shown.CPF != "***.982.247-**" {
+		t.Fatalf("ToDTO CPF = %v, want it masked", shown.CPF)
+	}
+
+	back := shown.ToModel()
+	if !back.KeepCPF || back.CPF != nil {
+		t.Errorf("masked CPF: KeepCPF = %v, CPF = %v, want the stored one kept", back.KeepCPF, back.CPF)
+	}
+
+	changed := "123.456.789-09"
+	shown.CPF = &changed
+	back = shown.ToModel()
+	if back.KeepCPF || back.CPF == nil || *back.CPF != "12345678909" {
+		t.Errorf("new CPF: KeepCPF = %v, CPF = %v, want 12345678909", back.KeepCPF, back.CPF)
+	}
+
+	shown.CPF = nil
+	back = shown.ToModel()
+	if back.KeepCPF || back.CPF != nil {
+		t.Errorf("no CPF: KeepCPF = %v, CPF = %v, want it cleared", back.KeepCPF, back.CPF)
+	}
+}
@@ -1,20 +1,41 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"meu_job/utils/validator"
+	"strings"
+	"time"
+)
 
+// Curriculum is one of the résumés a candidate keeps, each named for the
+// kind of role it targets. One of them is the default, shown when a
+// recruiter doesn't ask for a specific one.
 type Curriculum struct {
 	ID        int64
+	Name      string
+	IsDefault bool
 	FullName  string
 	Email     string
-	Phone     string
+	Phone     Phone
+	CPF       *CPF
+	// KeepCPF is set when the CPF came back masked, as ToDTO shows it, so
+	// the one already stored stays.
+	KeepCPF   bool
 	BirthDate *time.Time
 
 	Summary    string
 	Profession string
-	Experience []ExperienceEntry
-	Education  []EducationEntry
-	Skills     []string
-	Languages  []LanguageEntry
+	// Place is where the candidate lives. Only the city and state are
+	// shown to businesses.
+	Place
+	// SalaryExpectation is the monthly pay sought, in whole reais.
+	SalaryExpectation *int
+	Experience        []ExperienceEntry
+	Education         []EducationEntry
+	Skills            []CurriculumSkill
+	Languages         []LanguageEntry
 
 	User User
 	BaseModel
@@ -35,11 +56,47 @@ type EducationEntry struct {
 	EndDate     *time.Time
 }
 
+// CurriculumSkill is a skill as the candidate listed it. SkillID links it
+// to the catalog when the name was recognized; other names are kept as
+// typed.
+type CurriculumSkill struct {
+	SkillID *int64
+	Name    string
+	Level   SkillLevel
+}
+
 type LanguageEntry struct {
 	Name  string
 	Level LanguageLevel
 }
 
+type SkillLevel string
+
+const (
+	SkillBeginner     SkillLevel = "beginner"
+	SkillIntermediate SkillLevel = "intermediate"
+	SkillAdvanced     SkillLevel = "advanced"
+	SkillExpert       SkillLevel = "expert"
+)
+
+var skillLevels = []string{
+	string(SkillBeginner),
+	string(SkillIntermediate),
+	string(SkillAdvanced),
+	string(SkillExpert),
+}
+
+// Rank orders the levels from 1, beginner, to 4, expert; an unknown level
+// ranks 0.
+func (l SkillLevel) Rank() int {
+	for i, level := range skillLevels {
+		if string(l) == level {
+			return i + 1
+		}
+	}
+	return 0
+}
+
 type EducationDegree string
 
 const (
@@ -65,3 +122,408 @@ const (
 	LanguageFluent       LanguageLevel = "fluent"
 	LanguageNative       LanguageLevel = "native"
 )
+
+var educationDegrees = []string{
+	string(DegreeElementary), string(DegreeHighSchool), string(DegreeTechnical),
+	string(DegreeAssociate), string(DegreeBachelor), string(DegreeLicentiate),
+	string(DegreePostgraduate), string(DegreeMaster), string(DegreeDoctorate),
+	string(DegreeMBA), string(DegreeCertificate),
+}
+
+func (d EducationDegree) IsValid() bool {
+	return validator.In(string(d), educationDegrees...)
+}
+
+// degreeRanks orders the degrees by how far along they are; those on the
+// same step, such as a licentiate and a bachelor's, count the same.
+// Certificates stand outside the ladder and rank 0.
+var degreeRanks = map[EducationDegree]int{
+	DegreeElementary:   1,
+	DegreeHighSchool:   2,
+	DegreeTechnical:    3,
+	DegreeAssociate:    4,
+	DegreeBachelor:     5,
+	DegreeLicentiate:   5,
+	DegreePostgraduate: 6,
+	DegreeMBA:          6,
+	DegreeMaster:       7,
+	DegreeDoctorate:    8,
+}
+
+func (d EducationDegree) Rank() int {
+	return degreeRanks[d]
+}
+
+var languageLevels = []string{
+	string(LanguageBasic), string(LanguageIntermediate), string(LanguageAdvanced),
+	string(LanguageFluent), string(LanguageNative),
+}
+
+func (l LanguageLevel) IsValid() bool {
+	return validator.In(string(l), languageLevels...)
+}
+
+// Rank orders the levels from 1, basic, to 5, native; an unknown level
+// ranks 0.
+func (l LanguageLevel) Rank() int {
+	for i, level := range languageLevels {
+		if string(l) == level {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Date is a calendar day. It reads "2006-01-02" or just "2006-01" from JSON,
+// since résumés rarely say on which day a job started.
+type Date struct {
+	time.Time
+}
+
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+func ParseDate(s string) (Date, error) {
+	for _, layout := range []string{time.DateOnly, "2006-01"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Date{t}, nil
+		}
+	}
+	return Date{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD or YYYY-MM", s)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(time.DateOnly))
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return errors.New("date must be a string")
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func dateDTO(t *time.Time) *Date {
+	if t == nil {
+		return nil
+	}
+	d := NewDate(*t)
+	return &d
+}
+
+func dateModel(d *Date) *time.Time {
+	if d == nil {
+		return nil
+	}
+	return &d.Time
+}
+
+// ExperienceDTO, EducationDTO and LanguageDTO are also the shape the
+// entries are stored in.
+type ExperienceDTO struct {
+	Company     string `json:"company"`
+	Role        string `json:"role"`
+	Description string `json:"description"`
+	StartDate   Date   `json:"start_date"`
+	EndDate     *Date  `json:"end_date"`
+}
+
+type EducationDTO struct {
+	Institution string          `json:"institution"`
+	Degree      EducationDegree `json:"degree"`
+	StartDate   Date            `json:"start_date"`
+	EndDate     *Date           `json:"end_date"`
+}
+
+type CurriculumSkillDTO struct {
+	SkillID *int64     `json:"skill_id"`
+	Name    string     `json:"name"`
+	Level   SkillLevel `json:"level,omitempty"`
+}
+
+// UnmarshalJSON also takes a bare name, as skills were sent and stored
+// before they had levels.
+func (d *CurriculumSkillDTO) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*d = CurriculumSkillDTO{Name: name}
+		return nil
+	}
+
+	type plain CurriculumSkillDTO
+	return json.Unmarshal(b, (*plain)(d))
+}
+
+type LanguageDTO struct {
+	Name  string        `json:"name"`
+	Level LanguageLevel `json:"level"`
+}
+
+type CurriculumDTO struct {
+	ID         int64   `json:"curriculum_id"`
+	UserID     int64   `json:"user_id"`
+	Name       string  `json:"name"`
+	IsDefault  bool    `json:"is_default"`
+	FullName   string  `json:"full_name"`
+	Email      string  `json:"email"`
+	Phone      string  `json:"phone"`
+	CPF        *string `json:"cpf,omitempty"`
+	BirthDate  *Date   `json:"birth_date"`
+	Summary    string  `json:"summary"`
+	Profession string  `json:"profession"`
+	CEP        string  `json:"cep"`
+	Street     string  `json:"street"`
+	District   string  `json:"district"`
+	City       string  `json:"city"`
+	UF         string  `json:"uf"`
+	// Latitude and Longitude are geocoded, so ToModel ignores them.
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	// SalaryExpectation is the monthly pay sought, in whole reais.
+	SalaryExpectation *int                 `json:"salary_expectation"`
+	Experience        []ExperienceDTO      `json:"experience"`
+	Education         []EducationDTO       `json:"education"`
+	Skills            []CurriculumSkillDTO `json:"skills"`
+	Languages         []LanguageDTO        `json:"languages"`
+	Version           int                  `json:"version"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         *time.Time           `json:"updated_at"`
+}
+
+func (e ExperienceEntry) ToDTO() ExperienceDTO {
+	return ExperienceDTO{
+		Company:     e.Company,
+		Role:        e.Role,
+		Description: e.Description,
+		StartDate:   NewDate(e.StartDate),
+		EndDate:     dateDTO(e.EndDate),
+	}
+}
+
+func (d ExperienceDTO) ToModel() ExperienceEntry {
+	return ExperienceEntry{
+		Company:     strings.TrimSpace(d.Company),
+		Role:        strings.TrimSpace(d.Role),
+		Description: strings.TrimSpace(d.Description),
+		StartDate:   d.StartDate.Time,
+		EndDate:     dateModel(d.EndDate),
+	}
+}
+
+func (e EducationEntry) ToDTO() EducationDTO {
+	return EducationDTO{
+		Institution: e.Institution,
+		Degree:      e.Degree,
+		StartDate:   NewDate(e.StartDate),
+		EndDate:     dateDTO(e.EndDate),
+	}
+}
+
+func (d EducationDTO) ToModel() EducationEntry {
+	return EducationEntry{
+		Institution: strings.TrimSpace(d.Institution),
+		Degree:      d.Degree,
+		StartDate:   d.StartDate.Time,
+		EndDate:     dateModel(d.EndDate),
+	}
+}
+
+func (c Curriculum) ToDTO() *CurriculumDTO {
+	dto := &CurriculumDTO{
+		ID:                c.ID,
+		UserID:            c.User.ID,
+		Name:              c.Name,
+		IsDefault:         c.IsDefault,
+		FullName:          c.FullName,
+		Email:             c.Email,
+		Phone:             c.Phone.Format(),
+		BirthDate:         dateDTO(c.BirthDate),
+		Summary:           c.Summary,
+		Profession:        c.Profession,
+		CEP:               FormatCEP(c.CEP),
+		Street:            c.Street,
+		District:          c.District,
+		City:              c.City,
+		UF:                c.UF,
+		SalaryExpectation: c.SalaryExpectation,
+		Experience:        make([]ExperienceDTO, len(c.Experience)),
+		Education:         make([]EducationDTO, len(c.Education)),
+		Skills:            make([]CurriculumSkillDTO, len(c.Skills)),
+		Languages:         make([]LanguageDTO, len(c.Languages)),
+		Version:           c.Version,
+		CreatedAt:         c.CreatedAt,
+		UpdatedAt:         c.UpdatedAt,
+	}
+	dto.Latitude, dto.Longitude = c.coordinatesDTO()
+
+	if c.CPF != nil {
+		cpf := c.CPF.Masked()
+		dto.CPF = &cpf
+	}
+	for i, skill := range c.Skills {
+		dto.Skills[i] = CurriculumSkillDTO(skill)
+	}
+	for i, entry := range c.Experience {
+		dto.Experience[i] = entry.ToDTO()
+	}
+	for i, entry := range c.Education {
+		dto.Education[i] = entry.ToDTO()
+	}
+	for i, entry := range c.Languages {
+		dto.Languages[i] = LanguageDTO(entry)
+	}
+
+	return dto
+}
+
+// ToRecruiterDTO is the curriculum as a business applied to sees it: the
+// CPF, the birth date and anything finer than the city are left out.
+func (c Curriculum) ToRecruiterDTO() *CurriculumDTO {
+	dto := c.ToDTO()
+	dto.CPF, dto.BirthDate = nil, nil
+	dto.CEP, dto.Street, dto.District = "", "", ""
+	dto.Latitude, dto.Longitude = nil, nil
+	return dto
+}
+
+// ToModel leaves the owner and the default flag out: the owner always
+// comes from the authenticated user and the default is changed on its own.
+func (d CurriculumDTO) ToModel() *Curriculum {
+	c := &Curriculum{
+		ID:         d.ID,
+		Name:       strings.TrimSpace(d.Name),
+		FullName:   strings.TrimSpace(d.FullName),
+		Email:      strings.TrimSpace(d.Email),
+		Phone:      NormalizePhone(d.Phone),
+		BirthDate:  dateModel(d.BirthDate),
+		Summary:    strings.TrimSpace(d.Summary),
+		Profession: strings.TrimSpace(d.Profession),
+		Place: Place{
+			CEP:      NormalizeCEP(d.CEP),
+			Street:   strings.TrimSpace(d.Street),
+			District: strings.TrimSpace(d.District),
+			City:     strings.TrimSpace(d.City),
+			UF:       strings.ToUpper(strings.TrimSpace(d.UF)),
+		},
+		SalaryExpectation: d.SalaryExpectation,
+		Experience:        make([]ExperienceEntry, len(d.Experience)),
+		Education:         make([]EducationEntry, len(d.Education)),
+		Skills:            make([]CurriculumSkill, 0, len(d.Skills)),
+		Languages:         make([]LanguageEntry, len(d.Languages)),
+	}
+	c.Version = d.Version
+
+	switch {
+	case d.CPF == nil || *d.CPF == "":
+	case strings.Contains(*d.CPF, "*"):
+		c.KeepCPF = true
+	default:
+		cpf := NormalizeCPF(*d.CPF)
+		c.CPF = &cpf
+	}
+	for i, entry := range d.Experience {
+		c.Experience[i] = entry.ToModel()
+	}
+	for i, entry := range d.Education {
+		c.Education[i] = entry.ToModel()
+	}
+	for _, skill := range d.Skills {
+		if skill.Name = strings.TrimSpace(skill.Name); skill.Name != "" {
+			c.Skills = append(c.Skills, CurriculumSkill(skill))
+		}
+	}
+	for i, entry := range d.Languages {
+		c.Languages[i] = LanguageEntry{Name: strings.TrimSpace(entry.Name), Level: entry.Level}
+	}
+
+	return c
+}
+
+func validatePeriod(v *validator.Validator, key string, start time.Time, end *time.Time) {
+	v.Check(!start.IsZero(), key+".start_date", "must be provided")
+	v.Check(start.Before(time.Now()), key+".start_date", "must not be in the future")
+	if end != nil {
+		v.Check(!end.Before(start), key+".end_date", "must not be before the start date")
+	}
+}
+
+// DefaultName names curricula created without one, such as imported ones,
+// after the profession.
+func (c *Curriculum) DefaultName() {
+	switch {
+	case c.Name != "":
+	case c.Profession != "":
+		c.Name = c.Profession
+		for runes := []rune(c.Name); len(c.Name) > 100; runes = runes[:len(runes)-1] {
+			c.Name = string(runes[:len(runes)-1])
+		}
+	default:
+		c.Name = "Currículo"
+	}
+}
+
+func (c *Curriculum) ValidateCurriculum(v *validator.Validator) {
+	v.Check(c.Name != "", "name", "must be provided")
+	v.Check(len(c.Name) <= 100, "name", "must not be more than 100 bytes long")
+	v.Check(c.FullName != "", "full_name", "must be provided")
+	v.Check(len(c.FullName) <= 200, "full_name", "must not be more than 200 bytes long")
+	ValidateEmail(v, c.Email)
+	ValidatePhone(v, c.Phone)
+	if c.CPF != nil {
+		ValidateCPF(v, *c.CPF)
+	}
+	if c.BirthDate != nil {
+		v.Check(c.BirthDate.Before(time.Now().AddDate(-14, 0, 0)), "birth_date", "must be at least 14 years ago")
+	}
+	v.Check(len(c.Summary) <= 5000, "summary", "must not be more than 5000 bytes long")
+	v.Check(len(c.Profession) <= 200, "profession", "must not be more than 200 bytes long")
+	c.ValidatePlace(v)
+	if c.SalaryExpectation != nil {
+		v.Check(*c.SalaryExpectation > 0, "salary_expectation", "must be greater than zero")
+	}
+
+	v.Check(len(c.Experience) <= 50, "experience", "must not have more than 50 entries")
+	for i, entry := range c.Experience {
+		key := fmt.Sprintf("experience[%d]", i)
+		v.Check(entry.Company != "", key+".company", "must be provided")
+		v.Check(len(entry.Company) <= 200, key+".company", "must not be more than 200 bytes long")
+		v.Check(entry.Role != "", key+".role", "must be provided")
+		v.Check(len(entry.Role) <= 200, key+".role", "must not be more than 200 bytes long")
+		v.Check(len(entry.Description) <= 5000, key+".description", "must not be more than 5000 bytes long")
+		validatePeriod(v, key, entry.StartDate, entry.EndDate)
+	}
+
+	v.Check(len(c.Education) <= 30, "education", "must not have more than 30 entries")
+	for i, entry := range c.Education {
+		key := fmt.Sprintf("education[%d]", i)
+		v.Check(entry.Institution != "", key+".institution", "must be provided")
+		v.Check(len(entry.Institution) <= 200, key+".institution", "must not be more than 200 bytes long")
+		v.Check(entry.Degree.IsValid(), key+".degree", "must be one of "+strings.Join(educationDegrees, ", "))
+		validatePeriod(v, key, entry.StartDate, entry.EndDate)
+	}
+
+	v.Check(len(c.Skills) <= 100, "skills", "must not have more than 100 entries")
+	keys := make([]string, len(c.Skills))
+	for i, skill := range c.Skills {
+		key := fmt.Sprintf("skills[%d]", i)
+		v.Check(len(skill.Name) <= 100, key+".name", "must not be more than 100 bytes long")
+		v.Check(skill.Level == "" || skill.Level.Rank() > 0, key+".level", "must be one of "+strings.Join(skillLevels, ", "))
+		keys[i] = SkillKey(skill.Name)
+	}
+	v.Check(validator.Unique(keys), "skills", "must not contain duplicate values")
+
+	v.Check(len(c.Languages) <= 20, "languages", "must not have more than 20 entries")
+	for i, entry := range c.Languages {
+		key := fmt.Sprintf("languages[%d]", i)
+		v.Check(entry.Name != "", key+".name", "must be provided")
+		v.Check(len(entry.Name) <= 100, key+".name", "must not be more than 100 bytes long")
+		v.Check(entry.Level.IsValid(), key+".level", "must be one of "+strings.Join(languageLevels, ", "))
+	}
+}
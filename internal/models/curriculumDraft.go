@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+type DraftStatus string
+
+const (
+	DraftPending   DraftStatus = "pending"
+	DraftReady     DraftStatus = "ready"
+	DraftFailed    DraftStatus = "failed"
+	DraftConfirmed DraftStatus = "confirmed"
+)
+
+const RefCurriculumDraft = "curriculum_draft"
+
+// CurriculumDraft is an uploaded résumé on its way to becoming a
+// curriculum. It is parsed in the background; the candidate then reviews
+// the result and confirms it, which saves it as a Curriculum. Confidence
+// and Unparsed come from the parser, see resume.Draft.
+type CurriculumDraft struct {
+	ID           int64
+	UserID       int64
+	FileID       int64
+	Status       DraftStatus
+	Curriculum   *Curriculum
+	Confidence   map[string]float64
+	Unparsed     map[string]string
+	Error        string
+	Attempts     int
+	ProcessedAt  *time.Time
+	CurriculumID *int64
+	BaseModel
+}
+
+type CurriculumDraftDTO struct {
+	ID           int64              `json:"draft_id"`
+	FileID       int64              `json:"file_id"`
+	Status       DraftStatus        `json:"status"`
+	Curriculum   *CurriculumDTO     `json:"curriculum"`
+	Confidence   map[string]float64 `json:"confidence"`
+	Overall      float64            `json:"overall_confidence"`
+	Unparsed     map[string]string  `json:"unparsed"`
+	Error        string             `json:"error,omitempty"`
+	ProcessedAt  *time.Time         `json:"processed_at"`
+	CurriculumID *int64             `json:"curriculum_id"`
+	Version      int                `json:"version"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+func (d *CurriculumDraft) ToDTO() *CurriculumDraftDTO {
+	dto := &CurriculumDraftDTO{
+		ID:           d.ID,
+		FileID:       d.FileID,
+		Status:       d.Status,
+		Confidence:   d.Confidence,
+		Unparsed:     d.Unparsed,
+		Error:        d.Error,
+		ProcessedAt:  d.ProcessedAt,
+		CurriculumID: d.CurriculumID,
+		Version:      d.Version,
+		CreatedAt:    d.CreatedAt,
+	}
+	if d.Curriculum != nil {
+		dto.Curriculum = d.Curriculum.ToDTO()
+	}
+	if dto.Confidence == nil {
+		dto.Confidence = map[string]float64{}
+	}
+	if dto.Unparsed == nil {
+		dto.Unparsed = map[string]string{}
+	}
+
+	for _, c := range dto.Confidence {
+		dto.Overall += c
+	}
+	if len(dto.Confidence) > 0 {
+		dto.Overall /= float64(len(dto.Confidence))
+	}
+	return dto
+}
@@ -0,0 +1,219 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// CurriculumRevision is the curriculum as it was saved at Version.
+// Revisions are written along with every save and never change.
+type CurriculumRevision struct {
+	ID           int64
+	CurriculumID int64
+	Version      int
+	Curriculum   *Curriculum
+	CreatedBy    *int64
+	CreatedAt    time.Time
+}
+
+type CurriculumRevisionDTO struct {
+	CurriculumID int64          `json:"curriculum_id"`
+	Version      int            `json:"version"`
+	CreatedAt    time.Time      `json:"created_at"`
+	Curriculum   *CurriculumDTO `json:"curriculum,omitempty"`
+}
+
+// ToDTO leaves the snapshot out unless withCurriculum, to keep listings
+// short.
+func (r *CurriculumRevision) ToDTO(withCurriculum bool) *CurriculumRevisionDTO {
+	dto := &CurriculumRevisionDTO{
+		CurriculumID: r.CurriculumID,
+		Version:      r.Version,
+		CreatedAt:    r.CreatedAt,
+	}
+	if withCurriculum && r.Curriculum != nil {
+		dto.Curriculum = r.Curriculum.ToDTO()
+	}
+	return dto
+}
+
+type ChangeOp string
+
+const (
+	ChangeAdded   ChangeOp = "added"
+	ChangeRemoved ChangeOp = "removed"
+	ChangeChanged ChangeOp = "changed"
+)
+
+// CurriculumChange is one difference between two revisions. Path uses the
+// same notation as validation errors, such as "experience[1].role".
+type CurriculumChange struct {
+	Path string   `json:"path"`
+	Op   ChangeOp `json:"op"`
+	From any      `json:"from,omitempty"`
+	To   any      `json:"to,omitempty"`
+}
+
+// bookkeeping fields change on every save and say nothing about the
+// content.
+var bookkeeping = []string{"curriculum_id", "user_id", "is_default", "version", "created_at", "updated_at"}
+
+// DiffCurricula compares two versions of a curriculum field by field, as
+// they read in the API. Skills and languages are matched by name, as in
+// "skills[Go].level"; the other entries by position.
+func DiffCurricula(from, to *Curriculum) ([]CurriculumChange, error) {
+	a, err := asJSONObject(from.ToDTO())
+	if err != nil {
+		return nil, err
+	}
+	b, err := asJSONObject(to.ToDTO())
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range bookkeeping {
+		delete(a, key)
+		delete(b, key)
+	}
+
+	changes := []CurriculumChange{}
+	diffValue("", a, b, &changes)
+	return changes, nil
+}
+
+func asJSONObject(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	err = json.Unmarshal(b, &m)
+	return m, err
+}
+
+func diffValue(path string, a, b any, changes *[]CurriculumChange) {
+	switch {
+	case a == nil && b == nil:
+		return
+	case a == nil:
+		*changes = append(*changes, CurriculumChange{Path: path, Op: ChangeAdded, To: b})
+		return
+	case b == nil:
+		*changes = append(*changes, CurriculumChange{Path: path, Op: ChangeRemoved, From: a})
+		return
+	}
+
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for key := range av {
+			keys = append(keys, key)
+		}
+		for key := range bv {
+			if _, ok := av[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			sub := key
+			if path != "" {
+				sub = path + "." + key
+			}
+			diffValue(sub, av[key], bv[key], changes)
+		}
+		return
+
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+		if isStringList(av) && isStringList(bv) {
+			diffSet(path, av, bv, changes)
+			return
+		}
+		if x, ok := byName(av); ok {
+			if y, ok := byName(bv); ok {
+				diffNamed(path, av, bv, x, y, changes)
+				return
+			}
+		}
+		for i := range max(len(av), len(bv)) {
+			var x, y any
+			if i < len(av) {
+				x = av[i]
+			}
+			if i < len(bv) {
+				y = bv[i]
+			}
+			diffValue(fmt.Sprintf("%s[%d]", path, i), x, y, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, CurriculumChange{Path: path, Op: ChangeChanged, From: a, To: b})
+	}
+}
+
+func isStringList(list []any) bool {
+	for _, v := range list {
+		if _, ok := v.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// byName indexes the entries of a list by their "name", if they all have
+// a distinct one.
+func byName(list []any) (map[string]any, bool) {
+	named := make(map[string]any, len(list))
+	for _, v := range list {
+		entry, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		name, ok := entry["name"].(string)
+		if _, dup := named[name]; !ok || dup {
+			return nil, false
+		}
+		named[name] = entry
+	}
+	return named, true
+}
+
+// diffNamed goes through the entries of a, then those only in b, so the
+// changes come in the order the entries are listed.
+func diffNamed(path string, a, b []any, x, y map[string]any, changes *[]CurriculumChange) {
+	for _, v := range a {
+		name := v.(map[string]any)["name"].(string)
+		diffValue(fmt.Sprintf("%s[%s]", path, name), v, y[name], changes)
+	}
+	for _, v := range b {
+		name := v.(map[string]any)["name"].(string)
+		if _, found := x[name]; !found {
+			diffValue(fmt.Sprintf("%s[%s]", path, name), nil, v, changes)
+		}
+	}
+}
+
+func diffSet(path string, a, b []any, changes *[]CurriculumChange) {
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			*changes = append(*changes, CurriculumChange{Path: path, Op: ChangeRemoved, From: v})
+		}
+	}
+	for _, v := range b {
+		if !slices.Contains(a, v) {
+			*changes = append(*changes, CurriculumChange{Path: path, Op: ChangeAdded, To: v})
+		}
+	}
+}
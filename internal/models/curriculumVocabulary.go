@@ -0,0 +1,72 @@
+package models
+
+import "strings"
+
+type synonyms[T any] struct {
+	value T
+	terms []string
+}
+
+// Rules are tried in order, so the more specific ones come first: "pós-
+// graduação" must not be read as an undergraduate "graduação".
+var degreeSynonyms = []synonyms[EducationDegree]{
+	{DegreeDoctorate, []string{"doctorate", "doctor", "phd", "dsc", "doutorado", "doutor"}},
+	{DegreeMBA, []string{"mba"}},
+	{DegreeMaster, []string{"master", "masters", "msc", "mestrado", "mestre"}},
+	{DegreePostgraduate, []string{"postgraduate", "post-graduate", "specialization", "pos-graduacao", "posgraduacao", "pos", "especializacao", "lato-sensu"}},
+	{DegreeLicentiate, []string{"licentiate", "licenciatura"}},
+	{DegreeBachelor, []string{"bachelor", "bachelors", "bsc", "bs", "ba", "undergraduate", "bacharelado", "bacharel", "graduacao"}},
+	{DegreeAssociate, []string{"associate", "tecnologo", "tecnologa"}},
+	{DegreeTechnical, []string{"technical", "tecnico", "tecnica"}},
+	{DegreeHighSchool, []string{"high-school", "secondary", "ensino-medio", "colegial"}},
+	{DegreeElementary, []string{"elementary", "primary", "ensino-fundamental", "fundamental"}},
+	{DegreeCertificate, []string{"certificate", "certification", "course", "bootcamp", "certificado", "certificacao", "curso"}},
+}
+
+var levelSynonyms = []synonyms[LanguageLevel]{
+	{LanguageNative, []string{"native", "bilingual", "mother-tongue", "nativo", "nativa", "materna", "bilingue"}},
+	{LanguageFluent, []string{"fluent", "full-professional", "c2", "fluente"}},
+	{LanguageAdvanced, []string{"advanced", "professional-working", "proficient", "c1", "avancado"}},
+	{LanguageIntermediate, []string{"intermediate", "limited-working", "conversational", "b1", "b2", "intermediario"}},
+	{LanguageBasic, []string{"basic", "beginner", "elementary", "a1", "a2", "basico", "iniciante"}},
+}
+
+var skillLevelSynonyms = []synonyms[SkillLevel]{
+	{SkillExpert, []string{"expert", "master", "especialista", "senior", "5"}},
+	{SkillAdvanced, []string{"advanced", "proficient", "avancado", "pleno", "4"}},
+	{SkillIntermediate, []string{"intermediate", "intermediario", "medio", "3"}},
+	{SkillBeginner, []string{"beginner", "basic", "novice", "iniciante", "basico", "junior", "1", "2"}},
+}
+
+// match looks for the terms as whole words of the slugified text, so
+// "Bacharelado em Ciência da Computação" matches "bacharelado" while "ba"
+// doesn't match inside "basic".
+func match[T any](rules []synonyms[T], text string) (T, bool) {
+	slug := "-" + Slugify(text) + "-"
+	for _, rule := range rules {
+		for _, term := range rule.terms {
+			if strings.Contains(slug, "-"+term+"-") {
+				return rule.value, true
+			}
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// ParseEducationDegree recognizes a degree written out in Portuguese or
+// English, such as "Bacharelado em Direito" or "MSc in Physics".
+func ParseEducationDegree(text string) (EducationDegree, bool) {
+	return match(degreeSynonyms, text)
+}
+
+// ParseLanguageLevel recognizes a fluency written out in Portuguese or
+// English, including the LinkedIn proficiency names and CEFR levels.
+func ParseLanguageLevel(text string) (LanguageLevel, bool) {
+	return match(levelSynonyms, text)
+}
+
+func ParseSkillLevel(text string) (SkillLevel, bool) {
+	return match(skillLevelSynonyms, text)
+}
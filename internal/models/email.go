@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type EmailStatus string
+
+const (
+	EmailPending EmailStatus = "pending"
+	EmailSent    EmailStatus = "sent"
+	EmailFailed  EmailStatus = "failed"
+)
+
+// Email is a message in the outbox. UserID is set when it's addressed to
+// an account, so it goes with the account when erased.
+type Email struct {
+	ID          int64
+	UserID      *int64
+	To          string
+	Subject     string
+	Body        string
+	Headers     map[string]string
+	Attachments []EmailAttachment
+	Status      EmailStatus
+	Attempts    int
+	LastError   *string
+	SendAfter   time.Time
+	SentAt      *time.Time
+	CreatedAt   time.Time
+}
+
+// EmailAttachment is stored as JSON along with the email, the content
+// base64 encoded; only small files such as calendar invites are attached.
+type EmailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"meu_job/utils/validator"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type FileKind string
+
+const (
+	FileResume               FileKind = "resume"
+	FileLogo                 FileKind = "logo"
+	FileVerificationDocument FileKind = "verification_document"
+	FileMessageAttachment    FileKind = "message_attachment"
+)
+
+// Reference types recorded on files.
+const (
+	RefBusinessLogo         = "business_logo"
+	RefBusinessVerification = "business_verification"
+	RefMessage              = "message"
+)
+
+type fileKindRule struct {
+	maxSize int64
+	types   []string
+}
+
+const (
+	mimePDF  = "application/pdf"
+	mimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	mimeJPEG = "image/jpeg"
+	mimePNG  = "image/png"
+	mimeWEBP = "image/webp"
+)
+
+var fileKindRules = map[FileKind]fileKindRule{
+	FileResume:               {maxSize: 5 << 20, types: []string{mimePDF, mimeDOCX}},
+	FileLogo:                 {maxSize: 1 << 20, types: []string{mimePNG, mimeJPEG, mimeWEBP}},
+	FileVerificationDocument: {maxSize: 5 << 20, types: []string{mimePDF, mimePNG, mimeJPEG}},
+	FileMessageAttachment:    {maxSize: 5 << 20, types: []string{mimePDF, mimeDOCX, mimePNG, mimeJPEG, mimeWEBP}},
+}
+
+// MaxFileSize is the largest upload any kind accepts, used to cap request
+// bodies before the kind is known.
+const MaxFileSize = 5 << 20
+
+func (k FileKind) IsValid() bool {
+	_, ok := fileKindRules[k]
+	return ok
+}
+
+func (k FileKind) MaxSize() int64 {
+	return fileKindRules[k].maxSize
+}
+
+func (k FileKind) AllowedTypes() []string {
+	return fileKindRules[k].types
+}
+
+// File is the metadata of an uploaded object. The content itself lives in
+// the storage under StorageKey. RefType/RefID record what the file is
+// attached to (a business logo, a verification request, ...).
+type File struct {
+	ID          int64
+	OwnerID     int64
+	Kind        FileKind
+	StorageKey  string
+	FileName    string
+	ContentType string
+	Size        int64
+	Checksum    string
+	RefType     *string
+	RefID       *int64
+	BaseModel
+}
+
+type FileDTO struct {
+	ID          int64      `json:"file_id"`
+	Kind        FileKind   `json:"kind"`
+	FileName    string     `json:"file_name"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	Checksum    string     `json:"sha256"`
+	CreatedAt   time.Time  `json:"created_at"`
+	URL         string     `json:"url,omitempty"`
+	URLExpires  *time.Time `json:"url_expires_at,omitempty"`
+}
+
+func (f *File) ToDTO() *FileDTO {
+	return &FileDTO{
+		ID:          f.ID,
+		Kind:        f.Kind,
+		FileName:    f.FileName,
+		ContentType: f.ContentType,
+		Size:        f.Size,
+		Checksum:    f.Checksum,
+		CreatedAt:   f.CreatedAt,
+	}
+}
+
+func (f *File) ValidateFile(v *validator.Validator) {
+	v.Check(f.Kind.IsValid(), "kind", "must be resume, logo, verification_document or message_attachment")
+	v.Check(f.FileName != "", "file", "must be provided")
+	v.Check(len(f.FileName) <= 255, "file", "file name must not be more than 255 bytes long")
+	v.Check(f.Size > 0, "file", "must not be empty")
+
+	if f.Kind.IsValid() {
+		v.Check(f.Size <= f.Kind.MaxSize(), "file", "is too large for this kind of file")
+		v.Check(validator.In(f.ContentType, f.Kind.AllowedTypes()...), "file", "content type "+f.ContentType+" is not allowed for this kind of file")
+	}
+}
+
+// DetectContentType sniffs the content instead of trusting the client.
+// DOCX files sniff as plain zip archives, so those are opened to look for
+// the Word document part.
+func DetectContentType(content []byte) string {
+	contentType, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	if contentType != "application/zip" {
+		return contentType
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return contentType
+	}
+
+	for _, f := range archive.File {
+		if f.Name == "word/document.xml" {
+			return mimeDOCX
+		}
+	}
+	return contentType
+}
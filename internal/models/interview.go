@@ -0,0 +1,214 @@
+package models
+
+import (
+	"fmt"
+	"meu_job/utils/validator"
+	"slices"
+	"strings"
+	"time"
+)
+
+type InterviewStatus string
+
+const (
+	InterviewProposed  InterviewStatus = "proposed"
+	InterviewScheduled InterviewStatus = "scheduled"
+	InterviewCancelled InterviewStatus = "cancelled"
+)
+
+type InterviewMode string
+
+const (
+	InterviewVideo  InterviewMode = "video"
+	InterviewOnsite InterviewMode = "onsite"
+	InterviewPhone  InterviewMode = "phone"
+)
+
+var InterviewModes = []string{
+	string(InterviewVideo),
+	string(InterviewOnsite),
+	string(InterviewPhone),
+}
+
+const (
+	maxInterviewSlots = 10
+	// interviewHorizon is how far ahead slots may be proposed.
+	interviewHorizon = 90 * 24 * time.Hour
+)
+
+// InterviewSlot is a time proposed for an interview.
+type InterviewSlot struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+func (s InterviewSlot) Overlaps(o InterviewSlot) bool {
+	return s.StartsAt.Before(o.EndsAt) && o.StartsAt.Before(s.EndsAt)
+}
+
+// Interview is held for an application at the interview stage. The
+// interviewer proposes Slots and the candidate picks one, which schedules
+// it from StartsAt to EndsAt. An interviewer can't have two scheduled
+// interviews at the same time.
+type Interview struct {
+	ID              int64
+	ApplicationID   int64
+	BusinessID      int64
+	Status          InterviewStatus
+	Mode            InterviewMode
+	VideoURL        string
+	Location        string
+	Notes           string
+	DurationMinutes int
+	Slots           []InterviewSlot
+	StartsAt        *time.Time
+	EndsAt          *time.Time
+	// Reason is the one given for the last reschedule or the cancellation.
+	Reason      string
+	CancelledBy *int64
+	// Sequence is the iCalendar SEQUENCE of the invites.
+	Sequence int
+	// JobTitle, BusinessName and both sides are loaded along with it.
+	JobTitle     string
+	BusinessName string
+	Candidate    Participant
+	Interviewer  Participant
+	BaseModel
+}
+
+// Duration is how long each slot is.
+func (i Interview) Duration() time.Duration {
+	return time.Duration(i.DurationMinutes) * time.Minute
+}
+
+type InterviewDTO struct {
+	ID              int64           `json:"interview_id"`
+	ApplicationID   int64           `json:"application_id"`
+	BusinessID      int64           `json:"business_id"`
+	BusinessName    string          `json:"business_name"`
+	JobTitle        string          `json:"job_title"`
+	Status          InterviewStatus `json:"status"`
+	Mode            InterviewMode   `json:"mode"`
+	VideoURL        string          `json:"video_url"`
+	Location        string          `json:"location"`
+	Notes           string          `json:"notes"`
+	DurationMinutes int             `json:"duration_minutes"`
+	Slots           []InterviewSlot `json:"slots"`
+	StartsAt        *time.Time      `json:"starts_at"`
+	EndsAt          *time.Time      `json:"ends_at"`
+	Reason          string          `json:"reason"`
+	Candidate       *ParticipantDTO `json:"candidate"`
+	Interviewer     *ParticipantDTO `json:"interviewer"`
+	Version         int             `json:"version"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       *time.Time      `json:"updated_at"`
+}
+
+func (i Interview) ToDTO() *InterviewDTO {
+	dto := &InterviewDTO{
+		ID:              i.ID,
+		ApplicationID:   i.ApplicationID,
+		BusinessID:      i.BusinessID,
+		BusinessName:    i.BusinessName,
+		JobTitle:        i.JobTitle,
+		Status:          i.Status,
+		Mode:            i.Mode,
+		VideoURL:        i.VideoURL,
+		Location:        i.Location,
+		Notes:           i.Notes,
+		DurationMinutes: i.DurationMinutes,
+		Slots:           i.Slots,
+		StartsAt:        i.StartsAt,
+		EndsAt:          i.EndsAt,
+		Reason:          i.Reason,
+		Candidate:       i.Candidate.ToDTO(),
+		Interviewer:     i.Interviewer.ToDTO(),
+		Version:         i.Version,
+		CreatedAt:       i.CreatedAt,
+		UpdatedAt:       i.UpdatedAt,
+	}
+	if dto.Slots == nil {
+		dto.Slots = []InterviewSlot{}
+	}
+	return dto
+}
+
+// InterviewProposalDTO proposes an interview, or new times for one. Only
+// the start of each slot is given; all last DurationMinutes.
+type InterviewProposalDTO struct {
+	Mode            InterviewMode `json:"mode"`
+	VideoURL        string        `json:"video_url"`
+	Location        string        `json:"location"`
+	Notes           string        `json:"notes"`
+	DurationMinutes int           `json:"duration_minutes"`
+	Slots           []time.Time   `json:"slots"`
+	Reason          string        `json:"reason"`
+	Version         int           `json:"version"`
+}
+
+// ToModel sorts the slots; the reason and version are read on their own,
+// as only rescheduling takes them.
+func (d InterviewProposalDTO) ToModel() *Interview {
+	i := &Interview{
+		Mode:            d.Mode,
+		VideoURL:        strings.TrimSpace(d.VideoURL),
+		Location:        strings.TrimSpace(d.Location),
+		Notes:           strings.TrimSpace(d.Notes),
+		DurationMinutes: d.DurationMinutes,
+		Slots:           make([]InterviewSlot, len(d.Slots)),
+	}
+	for n, start := range d.Slots {
+		i.Slots[n] = InterviewSlot{StartsAt: start.UTC(), EndsAt: start.UTC().Add(i.Duration())}
+	}
+	slices.SortFunc(i.Slots, func(a, b InterviewSlot) int { return a.StartsAt.Compare(b.StartsAt) })
+	return i
+}
+
+func (i *Interview) ValidateInterview(v *validator.Validator, now time.Time) {
+	v.Check(validator.In(string(i.Mode), InterviewModes...), "mode", "must be one of "+strings.Join(InterviewModes, ", "))
+	v.Check(i.DurationMinutes >= 15 && i.DurationMinutes <= 480, "duration_minutes", "must be between 15 and 480")
+	v.Check(len(i.Notes) <= 2000, "notes", "must not be more than 2000 bytes long")
+	v.Check(len(i.Location) <= 500, "location", "must not be more than 500 bytes long")
+
+	switch i.Mode {
+	case InterviewVideo:
+		v.Check(i.VideoURL != "", "video_url", "must be provided for video interviews")
+		v.Check(i.VideoURL == "" || isWebURL(i.VideoURL), "video_url", "must be a valid http(s) URL")
+	case InterviewOnsite:
+		v.Check(i.Location != "", "location", "must be provided for onsite interviews")
+	}
+
+	v.Check(len(i.Slots) > 0, "slots", "must contain at least one time")
+	v.Check(len(i.Slots) <= maxInterviewSlots, "slots", fmt.Sprintf("must not have more than %d times", maxInterviewSlots))
+	for n, slot := range i.Slots {
+		key := fmt.Sprintf("slots[%d]", n)
+		v.Check(slot.StartsAt.After(now), key, "must be in the future")
+		v.Check(slot.StartsAt.Before(now.Add(interviewHorizon)), key, "must be within the next 90 days")
+		if n > 0 {
+			v.Check(!slot.Overlaps(i.Slots[n-1]), key, "must not overlap another of the times")
+		}
+	}
+}
+
+// Slot finds the proposed slot starting at start.
+func (i Interview) Slot(start time.Time) (InterviewSlot, bool) {
+	for _, slot := range i.Slots {
+		if slot.StartsAt.Equal(start) {
+			return slot, true
+		}
+	}
+	return InterviewSlot{}, false
+}
+
+// InterviewActionDTO is what accepting and cancelling take: the slot
+// picked, or the reason for cancelling.
+type InterviewActionDTO struct {
+	StartsAt *time.Time `json:"starts_at"`
+	Reason   string     `json:"reason"`
+	Version  int        `json:"version"`
+}
+
+func ValidateInterviewReason(v *validator.Validator, reason string) {
+	v.Check(reason != "", "reason", "must be provided")
+	v.Check(len(reason) <= 1000, "reason", "must not be more than 1000 bytes long")
+}
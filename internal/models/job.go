@@ -0,0 +1,293 @@
+package models
+
+import (
+	"fmt"
+	"meu_job/utils/validator"
+	"strings"
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobDraft     JobStatus = "draft"
+	JobPublished JobStatus = "published"
+	JobClosed    JobStatus = "closed"
+)
+
+var JobStatuses = []string{
+	string(JobDraft),
+	string(JobPublished),
+	string(JobClosed),
+}
+
+type Seniority string
+
+const (
+	SeniorityIntern Seniority = "intern"
+	SeniorityJunior Seniority = "junior"
+	SeniorityMid    Seniority = "mid"
+	SenioritySenior Seniority = "senior"
+	SeniorityLead   Seniority = "lead"
+)
+
+var Seniorities = []string{
+	string(SeniorityIntern),
+	string(SeniorityJunior),
+	string(SeniorityMid),
+	string(SenioritySenior),
+	string(SeniorityLead),
+}
+
+// Rank orders the seniorities from 1, intern, to 5, lead; an unknown one
+// ranks 0.
+func (s Seniority) Rank() int {
+	for i, seniority := range Seniorities {
+		if string(s) == seniority {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// SeniorityForYears is the seniority expected after that many years of
+// work experience.
+func SeniorityForYears(years float64) Seniority {
+	switch {
+	case years < 1:
+		return SeniorityIntern
+	case years < 3:
+		return SeniorityJunior
+	case years < 6:
+		return SeniorityMid
+	case years < 10:
+		return SenioritySenior
+	default:
+		return SeniorityLead
+	}
+}
+
+type ContractType string
+
+const (
+	ContractCLT        ContractType = "clt"
+	ContractPJ         ContractType = "pj"
+	ContractInternship ContractType = "internship"
+	ContractTemporary  ContractType = "temporary"
+	ContractFreelance  ContractType = "freelance"
+)
+
+var ContractTypes = []string{
+	string(ContractCLT),
+	string(ContractPJ),
+	string(ContractInternship),
+	string(ContractTemporary),
+	string(ContractFreelance),
+}
+
+type RemoteMode string
+
+const (
+	RemoteOnsite RemoteMode = "onsite"
+	RemoteHybrid RemoteMode = "hybrid"
+	RemoteFull   RemoteMode = "remote"
+)
+
+var RemoteModes = []string{
+	string(RemoteOnsite),
+	string(RemoteHybrid),
+	string(RemoteFull),
+}
+
+// JobSkill is a skill the job asks for. Level is the least expected, if
+// any; skills not Required are nice to have.
+type JobSkill struct {
+	SkillID  *int64
+	Name     string
+	Level    SkillLevel
+	Required bool
+}
+
+// Job is a job posting of a business. It's written as a draft, and only
+// verified businesses may publish it.
+type Job struct {
+	ID           int64
+	BusinessID   int64
+	Title        string
+	Description  string
+	Status       JobStatus
+	Seniority    Seniority
+	ContractType ContractType
+	RemoteMode   RemoteMode
+	// Place is where the work is done, optional for remote jobs.
+	Place
+	// SalaryMin and SalaryMax are the monthly pay offered, in whole reais.
+	SalaryMin *int
+	SalaryMax *int
+	MinDegree *EducationDegree
+	Skills    []JobSkill
+	// Languages are the least level expected of each.
+	Languages   []LanguageEntry
+	PublishedAt *time.Time
+	ClosedAt    *time.Time
+	// Company is only loaded by the public search, and DistanceKM by
+	// searches near a point.
+	Company    *Business
+	DistanceKM *float64
+	BaseModel
+}
+
+type JobSkillDTO struct {
+	SkillID  *int64     `json:"skill_id"`
+	Name     string     `json:"name"`
+	Level    SkillLevel `json:"level,omitempty"`
+	Required bool       `json:"required"`
+}
+
+// JobDTO is also the shape Skills and Languages are stored in.
+type JobDTO struct {
+	ID           int64        `json:"job_id"`
+	BusinessID   int64        `json:"business_id"`
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	Status       JobStatus    `json:"status"`
+	Seniority    Seniority    `json:"seniority"`
+	ContractType ContractType `json:"contract_type"`
+	RemoteMode   RemoteMode   `json:"remote_mode"`
+	CEP          string       `json:"cep"`
+	Street       string       `json:"street"`
+	District     string       `json:"district"`
+	City         string       `json:"city"`
+	UF           string       `json:"uf"`
+	// Latitude and Longitude are geocoded, so ToModel ignores them.
+	Latitude    *float64         `json:"latitude"`
+	Longitude   *float64         `json:"longitude"`
+	SalaryMin   *int             `json:"salary_min"`
+	SalaryMax   *int             `json:"salary_max"`
+	MinDegree   *EducationDegree `json:"min_degree"`
+	Skills      []JobSkillDTO    `json:"skills"`
+	Languages   []LanguageDTO    `json:"languages"`
+	PublishedAt *time.Time       `json:"published_at"`
+	ClosedAt    *time.Time       `json:"closed_at"`
+	Version     int              `json:"version"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   *time.Time       `json:"updated_at"`
+}
+
+func (j Job) ToDTO() *JobDTO {
+	dto := &JobDTO{
+		ID:           j.ID,
+		BusinessID:   j.BusinessID,
+		Title:        j.Title,
+		Description:  j.Description,
+		Status:       j.Status,
+		Seniority:    j.Seniority,
+		ContractType: j.ContractType,
+		RemoteMode:   j.RemoteMode,
+		CEP:          FormatCEP(j.CEP),
+		Street:       j.Street,
+		District:     j.District,
+		City:         j.City,
+		UF:           j.UF,
+		SalaryMin:    j.SalaryMin,
+		SalaryMax:    j.SalaryMax,
+		MinDegree:    j.MinDegree,
+		Skills:       make([]JobSkillDTO, len(j.Skills)),
+		Languages:    make([]LanguageDTO, len(j.Languages)),
+		PublishedAt:  j.PublishedAt,
+		ClosedAt:     j.ClosedAt,
+		Version:      j.Version,
+		CreatedAt:    j.CreatedAt,
+		UpdatedAt:    j.UpdatedAt,
+	}
+	dto.Latitude, dto.Longitude = j.coordinatesDTO()
+
+	for i, skill := range j.Skills {
+		dto.Skills[i] = JobSkillDTO(skill)
+	}
+	for i, entry := range j.Languages {
+		dto.Languages[i] = LanguageDTO(entry)
+	}
+
+	return dto
+}
+
+// ToModel leaves out the business and the status, which come from the
+// path and from publishing and closing the job.
+func (d JobDTO) ToModel() *Job {
+	j := &Job{
+		ID:           d.ID,
+		Title:        strings.TrimSpace(d.Title),
+		Description:  strings.TrimSpace(d.Description),
+		Seniority:    d.Seniority,
+		ContractType: d.ContractType,
+		RemoteMode:   d.RemoteMode,
+		Place: Place{
+			CEP:      NormalizeCEP(d.CEP),
+			Street:   strings.TrimSpace(d.Street),
+			District: strings.TrimSpace(d.District),
+			City:     strings.TrimSpace(d.City),
+			UF:       strings.ToUpper(strings.TrimSpace(d.UF)),
+		},
+		SalaryMin: d.SalaryMin,
+		SalaryMax: d.SalaryMax,
+		MinDegree: d.MinDegree,
+		Skills:    make([]JobSkill, 0, len(d.Skills)),
+		Languages: make([]LanguageEntry, len(d.Languages)),
+	}
+	j.Version = d.Version
+
+	for _, skill := range d.Skills {
+		if skill.Name = strings.TrimSpace(skill.Name); skill.Name != "" {
+			j.Skills = append(j.Skills, JobSkill(skill))
+		}
+	}
+	for i, entry := range d.Languages {
+		j.Languages[i] = LanguageEntry{Name: strings.TrimSpace(entry.Name), Level: entry.Level}
+	}
+
+	return j
+}
+
+func (j *Job) ValidateJob(v *validator.Validator) {
+	v.Check(j.Title != "", "title", "must be provided")
+	v.Check(len(j.Title) <= 200, "title", "must not be more than 200 bytes long")
+	v.Check(len(j.Description) <= 20000, "description", "must not be more than 20000 bytes long")
+	v.Check(validator.In(string(j.Seniority), Seniorities...), "seniority", "must be one of "+strings.Join(Seniorities, ", "))
+	v.Check(validator.In(string(j.ContractType), ContractTypes...), "contract_type", "must be one of "+strings.Join(ContractTypes, ", "))
+	v.Check(validator.In(string(j.RemoteMode), RemoteModes...), "remote_mode", "must be one of "+strings.Join(RemoteModes, ", "))
+
+	j.ValidatePlace(v)
+	v.Check(j.RemoteMode == RemoteFull || j.UF != "", "uf", "must be provided unless the job is remote")
+
+	if j.SalaryMin != nil {
+		v.Check(*j.SalaryMin > 0, "salary_min", "must be greater than zero")
+	}
+	if j.SalaryMax != nil {
+		v.Check(*j.SalaryMax > 0, "salary_max", "must be greater than zero")
+	}
+	if j.SalaryMin != nil && j.SalaryMax != nil {
+		v.Check(*j.SalaryMin <= *j.SalaryMax, "salary_max", "must not be less than salary_min")
+	}
+	if j.MinDegree != nil {
+		v.Check(j.MinDegree.Rank() > 0, "min_degree", "must be a degree other than a certificate")
+	}
+
+	v.Check(len(j.Skills) <= 50, "skills", "must not have more than 50 entries")
+	keys := make([]string, len(j.Skills))
+	for i, skill := range j.Skills {
+		key := fmt.Sprintf("skills[%d]", i)
+		v.Check(len(skill.Name) <= 100, key+".name", "must not be more than 100 bytes long")
+		v.Check(skill.Level == "" || skill.Level.Rank() > 0, key+".level", "must be one of "+strings.Join(skillLevels, ", "))
+		keys[i] = SkillKey(skill.Name)
+	}
+	v.Check(validator.Unique(keys), "skills", "must not contain duplicate values")
+
+	v.Check(len(j.Languages) <= 10, "languages", "must not have more than 10 entries")
+	for i, entry := range j.Languages {
+		key := fmt.Sprintf("languages[%d]", i)
+		v.Check(entry.Name != "", key+".name", "must be provided")
+		v.Check(len(entry.Name) <= 100, key+".name", "must not be more than 100 bytes long")
+		v.Check(entry.Level.IsValid(), key+".level", "must be one of "+strings.Join(languageLevels, ", "))
+	}
+}
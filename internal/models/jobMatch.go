@@ -0,0 +1,101 @@
+package models
+
+import "time"
+
+type MatchCriterion string
+
+const (
+	MatchRequiredSkills MatchCriterion = "required_skills"
+	MatchOptionalSkills MatchCriterion = "nice_to_have_skills"
+	MatchSeniority      MatchCriterion = "seniority"
+	MatchEducation      MatchCriterion = "education"
+	MatchLanguages      MatchCriterion = "languages"
+	MatchLocation       MatchCriterion = "location"
+	MatchSalary         MatchCriterion = "salary"
+)
+
+// MatchItem is how one criterion went. Score goes from 0 to 1; Points is
+// what it added to the match score, out of 100. Criteria the job doesn't
+// ask about aren't Applicable and their weight goes to the others.
+type MatchItem struct {
+	Criterion  MatchCriterion `json:"criterion"`
+	Weight     int            `json:"weight"`
+	Applicable bool           `json:"applicable"`
+	Score      float64        `json:"score"`
+	Points     float64        `json:"points"`
+	Detail     string         `json:"detail"`
+	Matched    []string       `json:"matched,omitempty"`
+	Missing    []string       `json:"missing,omitempty"`
+}
+
+// JobMatch is how well a candidate's default curriculum fits a published
+// job, as last computed. Job or Curriculum are loaded depending on which
+// side is listing.
+type JobMatch struct {
+	JobID        int64
+	CurriculumID int64
+	UserID       int64
+	Score        int
+	Breakdown    []MatchItem
+	ComputedAt   time.Time
+	Job          *Job
+	Curriculum   *Curriculum
+	// DistanceKM is how far apart the job and the candidate are, in whole
+	// km, when both are geocoded. Only the rankings load it.
+	DistanceKM *float64
+}
+
+// MatchFilter narrows a ranking: MinScore leaves out the weaker matches
+// and MaxDistanceKM, when set, the ones farther apart or not geocoded.
+type MatchFilter struct {
+	MinScore      int
+	MaxDistanceKM int
+}
+
+// MatchCandidateDTO is the part of the curriculum recruiters see in the
+// ranking.
+type MatchCandidateDTO struct {
+	CurriculumID int64  `json:"curriculum_id"`
+	FullName     string `json:"full_name"`
+	Profession   string `json:"profession"`
+	City         string `json:"city"`
+	UF           string `json:"uf"`
+}
+
+type JobMatchDTO struct {
+	JobID        int64              `json:"job_id"`
+	CurriculumID int64              `json:"curriculum_id"`
+	Score        int                `json:"score"`
+	Breakdown    []MatchItem        `json:"breakdown"`
+	ComputedAt   time.Time          `json:"computed_at"`
+	DistanceKM   *float64           `json:"distance_km,omitempty"`
+	Job          *JobDTO            `json:"job,omitempty"`
+	Candidate    *MatchCandidateDTO `json:"candidate,omitempty"`
+}
+
+func (m JobMatch) ToDTO() *JobMatchDTO {
+	dto := &JobMatchDTO{
+		JobID:        m.JobID,
+		CurriculumID: m.CurriculumID,
+		Score:        m.Score,
+		Breakdown:    m.Breakdown,
+		ComputedAt:   m.ComputedAt,
+		DistanceKM:   m.DistanceKM,
+	}
+	if dto.Breakdown == nil {
+		dto.Breakdown = []MatchItem{}
+	}
+	if m.Job != nil {
+		dto.Job = m.Job.ToDTO()
+	}
+	if m.Curriculum != nil {
+		dto.Candidate = &MatchCandidateDTO{
+			CurriculumID: m.Curriculum.ID,
+			FullName:     m.Curriculum.FullName,
+			Profession:   m.Curriculum.Profession,
+			City:         m.Curriculum.City,
+			UF:           m.Curriculum.UF,
+		}
+	}
+	return dto
+}
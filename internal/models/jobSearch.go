@@ -0,0 +1,220 @@
+package models
+
+import (
+	"fmt"
+	"meu_job/utils/validator"
+	"strings"
+	"time"
+)
+
+// SalaryBand groups jobs by the top of the salary range they offer, the
+// minimum when no maximum is given. Max is exclusive; zero leaves the band
+// open.
+type SalaryBand struct {
+	Key string
+	Min int
+	Max int
+}
+
+var SalaryBands = []SalaryBand{
+	{Key: "0-3000", Min: 0, Max: 3000},
+	{Key: "3000-6000", Min: 3000, Max: 6000},
+	{Key: "6000-10000", Min: 6000, Max: 10000},
+	{Key: "10000-15000", Min: 10000, Max: 15000},
+	{Key: "15000+", Min: 15000},
+}
+
+func FindSalaryBand(key string) (SalaryBand, bool) {
+	for _, band := range SalaryBands {
+		if band.Key == key {
+			return band, true
+		}
+	}
+	return SalaryBand{}, false
+}
+
+// JobSearch is what candidates filter the published jobs by. Empty fields
+// don't filter; Company is the company's slug. The JSON form, named after
+// the query parameters, is how saved searches keep it.
+type JobSearch struct {
+	Query        string `json:"q,omitempty"`
+	UF           string `json:"uf,omitempty"`
+	City         string `json:"city,omitempty"`
+	RemoteMode   string `json:"remote_mode,omitempty"`
+	ContractType string `json:"contract_type,omitempty"`
+	Seniority    string `json:"seniority,omitempty"`
+	SalaryBand   string `json:"salary_band,omitempty"`
+	Company      string `json:"company,omitempty"`
+	// NearCEP is resolved to Latitude and Longitude, the point RadiusKM
+	// and sorting by distance are measured from.
+	NearCEP   string   `json:"near_cep,omitempty"`
+	Latitude  *float64 `json:"lat,omitempty"`
+	Longitude *float64 `json:"lng,omitempty"`
+	RadiusKM  int      `json:"radius_km,omitempty"`
+	// PublishedAfter and PublishedUntil narrow to the jobs published since
+	// a job alert last ran, up to when it runs.
+	PublishedAfter *time.Time `json:"-"`
+	PublishedUntil *time.Time `json:"-"`
+}
+
+func (s *JobSearch) ValidateJobSearch(v *validator.Validator) {
+	bands := make([]string, len(SalaryBands))
+	for i, band := range SalaryBands {
+		bands[i] = band.Key
+	}
+
+	v.Check(len(s.Query) <= 200, "q", "must not be more than 200 bytes long")
+	v.Check(s.UF == "" || validator.In(s.UF, UFs...), "uf", "must be a Brazilian state abbreviation, such as SP")
+	v.Check(len(s.City) <= 200, "city", "must not be more than 200 bytes long")
+	v.Check(s.RemoteMode == "" || validator.In(s.RemoteMode, RemoteModes...), "remote_mode", "must be one of "+strings.Join(RemoteModes, ", "))
+	v.Check(s.ContractType == "" || validator.In(s.ContractType, ContractTypes...), "contract_type", "must be one of "+strings.Join(ContractTypes, ", "))
+	v.Check(s.Seniority == "" || validator.In(s.Seniority, Seniorities...), "seniority", "must be one of "+strings.Join(Seniorities, ", "))
+	v.Check(s.SalaryBand == "" || validator.In(s.SalaryBand, bands...), "salary_band", "must be one of "+strings.Join(bands, ", "))
+	v.Check(len(s.Company) <= 100, "company", "must not be more than 100 bytes long")
+
+	if s.NearCEP != "" {
+		ValidateCEP(v, "near_cep", s.NearCEP)
+	}
+	v.Check((s.Latitude == nil) == (s.Longitude == nil), "lat", "must be given along with lng")
+	if origin := s.Origin(); origin != nil {
+		ValidateCoordinates(v, origin.Latitude, origin.Longitude)
+	}
+	v.Check(s.RadiusKM >= 0 && s.RadiusKM <= maxRadiusKM, "radius_km", fmt.Sprintf("must be between 1 and %d", maxRadiusKM))
+	v.Check(s.RadiusKM == 0 || s.Origin() != nil || s.NearCEP != "", "radius_km", "needs near_cep, or lat and lng")
+}
+
+// maxRadiusKM bounds distance searches to a region, not the country.
+const maxRadiusKM = 500
+
+// Origin is the point the search is made around, if any.
+func (s JobSearch) Origin() *Coordinates {
+	if s.Latitude == nil || s.Longitude == nil {
+		return nil
+	}
+	return &Coordinates{Latitude: *s.Latitude, Longitude: *s.Longitude}
+}
+
+// FacetCount is how many jobs a filter value would leave. Label is set
+// where the value alone doesn't read well: the company's name for its
+// slug, the state next to a city.
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+// JobFacets counts each facet under every filter but its own, so choosing
+// a value doesn't hide the alternatives.
+type JobFacets struct {
+	UF           []FacetCount `json:"uf"`
+	City         []FacetCount `json:"city"`
+	RemoteMode   []FacetCount `json:"remote_mode"`
+	ContractType []FacetCount `json:"contract_type"`
+	Seniority    []FacetCount `json:"seniority"`
+	SalaryBand   []FacetCount `json:"salary_band"`
+	Company      []FacetCount `json:"company"`
+}
+
+func NewJobFacets() *JobFacets {
+	return &JobFacets{
+		UF:           []FacetCount{},
+		City:         []FacetCount{},
+		RemoteMode:   []FacetCount{},
+		ContractType: []FacetCount{},
+		Seniority:    []FacetCount{},
+		SalaryBand:   []FacetCount{},
+		Company:      []FacetCount{},
+	}
+}
+
+// Add files a count under its facet, as named by the search parameter.
+func (f *JobFacets) Add(facet string, count FacetCount) error {
+	switch facet {
+	case "uf":
+		f.UF = append(f.UF, count)
+	case "city":
+		f.City = append(f.City, count)
+	case "remote_mode":
+		f.RemoteMode = append(f.RemoteMode, count)
+	case "contract_type":
+		f.ContractType = append(f.ContractType, count)
+	case "seniority":
+		f.Seniority = append(f.Seniority, count)
+	case "salary_band":
+		f.SalaryBand = append(f.SalaryBand, count)
+	case "company":
+		f.Company = append(f.Company, count)
+	default:
+		return fmt.Errorf("unknown facet %q", facet)
+	}
+	return nil
+}
+
+// JobCompanyDTO is the part of the company profile shown with each job.
+type JobCompanyDTO struct {
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	LogoURL  string `json:"logo_url,omitempty"`
+	Verified bool   `json:"verified"`
+}
+
+// PublicJobDTO is the unauthenticated view of a published job; drafts,
+// versions and who edited it stay private.
+type PublicJobDTO struct {
+	ID           int64            `json:"job_id"`
+	Title        string           `json:"title"`
+	Description  string           `json:"description"`
+	Seniority    Seniority        `json:"seniority"`
+	ContractType ContractType     `json:"contract_type"`
+	RemoteMode   RemoteMode       `json:"remote_mode"`
+	CEP          string           `json:"cep"`
+	Street       string           `json:"street"`
+	District     string           `json:"district"`
+	City         string           `json:"city"`
+	UF           string           `json:"uf"`
+	Latitude     *float64         `json:"latitude"`
+	Longitude    *float64         `json:"longitude"`
+	DistanceKM   *float64         `json:"distance_km,omitempty"`
+	SalaryMin    *int             `json:"salary_min"`
+	SalaryMax    *int             `json:"salary_max"`
+	MinDegree    *EducationDegree `json:"min_degree"`
+	Skills       []JobSkillDTO    `json:"skills"`
+	Languages    []LanguageDTO    `json:"languages"`
+	PublishedAt  *time.Time       `json:"published_at"`
+	Company      *JobCompanyDTO   `json:"company,omitempty"`
+}
+
+func (j Job) ToPublicDTO() *PublicJobDTO {
+	dto := j.ToDTO()
+	public := &PublicJobDTO{
+		ID:           dto.ID,
+		Title:        dto.Title,
+		Description:  dto.Description,
+		Seniority:    dto.Seniority,
+		ContractType: dto.ContractType,
+		RemoteMode:   dto.RemoteMode,
+		CEP:          dto.CEP,
+		Street:       dto.Street,
+		District:     dto.District,
+		City:         dto.City,
+		UF:           dto.UF,
+		Latitude:     dto.Latitude,
+		Longitude:    dto.Longitude,
+		DistanceKM:   j.DistanceKM,
+		SalaryMin:    dto.SalaryMin,
+		SalaryMax:    dto.SalaryMax,
+		MinDegree:    dto.MinDegree,
+		Skills:       dto.Skills,
+		Languages:    dto.Languages,
+		PublishedAt:  dto.PublishedAt,
+	}
+	if j.Company != nil {
+		public.Company = &JobCompanyDTO{
+			Slug:     j.Company.Profile.Slug,
+			Name:     j.Company.Name,
+			LogoURL:  j.Company.profileLogoURL(),
+			Verified: j.Company.VerificationStatus == VerificationVerified,
+		}
+	}
+	return public
+}
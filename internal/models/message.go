@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+	"meu_job/utils/validator"
+	"strings"
+	"time"
+)
+
+const maxMessageAttachments = 5
+
+// Message is posted in the thread of an application, which only the
+// candidate and the members of the business can read. Messages aren't
+// edited once sent.
+type Message struct {
+	ID            int64
+	ApplicationID int64
+	Sender        Participant
+	// FromCandidate tells the sides apart; any member of the business
+	// speaks for it.
+	FromCandidate bool
+	Body          string
+	// ReadAt is when the other side first read it, and ReadBy who did.
+	ReadAt *time.Time
+	ReadBy *int64
+	// FileIDs are the uploads attached when sending; Attachments are
+	// loaded with the thread.
+	FileIDs     []int64
+	Attachments []*File
+	CreatedAt   time.Time
+}
+
+type MessageDTO struct {
+	ID            int64           `json:"message_id"`
+	ApplicationID int64           `json:"application_id"`
+	Sender        *ParticipantDTO `json:"sender"`
+	FromCandidate bool            `json:"from_candidate"`
+	Body          string          `json:"body"`
+	Attachments   []*FileDTO      `json:"attachments"`
+	ReadAt        *time.Time      `json:"read_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// ToDTO leaves out the sender's email, and the attachments, which need
+// signed URLs the handler adds.
+func (m Message) ToDTO() *MessageDTO {
+	sender := m.Sender.ToDTO()
+	sender.Email = ""
+	return &MessageDTO{
+		ID:            m.ID,
+		ApplicationID: m.ApplicationID,
+		Sender:        sender,
+		FromCandidate: m.FromCandidate,
+		Body:          m.Body,
+		Attachments:   []*FileDTO{},
+		ReadAt:        m.ReadAt,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+// SendMessageDTO sends a message; FileIDs are files uploaded beforehand as
+// message_attachment.
+type SendMessageDTO struct {
+	Body    string  `json:"body"`
+	FileIDs []int64 `json:"file_ids"`
+}
+
+func (d SendMessageDTO) ToModel() *Message {
+	return &Message{
+		Body:    strings.TrimSpace(d.Body),
+		FileIDs: d.FileIDs,
+	}
+}
+
+func (m *Message) ValidateMessage(v *validator.Validator) {
+	v.Check(m.Body != "" || len(m.FileIDs) > 0, "body", "must be provided unless files are attached")
+	v.Check(len(m.Body) <= 5000, "body", "must not be more than 5000 bytes long")
+	v.Check(len(m.FileIDs) <= maxMessageAttachments, "file_ids", fmt.Sprintf("must not have more than %d files", maxMessageAttachments))
+	v.Check(validator.Unique(m.FileIDs), "file_ids", "must not contain duplicate files")
+}
+
+// UnreadThread counts the messages of a thread the user hasn't read yet.
+type UnreadThread struct {
+	ApplicationID int64
+	BusinessID    int64
+	JobTitle      string
+	// AsCandidate is whether the user reads the thread as the candidate
+	// rather than as a member of the business.
+	AsCandidate bool
+	Unread      int
+	LastAt      time.Time
+}
+
+type UnreadThreadDTO struct {
+	ApplicationID int64     `json:"application_id"`
+	BusinessID    int64     `json:"business_id"`
+	JobTitle      string    `json:"job_title"`
+	AsCandidate   bool      `json:"as_candidate"`
+	Unread        int       `json:"unread"`
+	LastAt        time.Time `json:"last_message_at"`
+}
+
+func (t UnreadThread) ToDTO() *UnreadThreadDTO {
+	return &UnreadThreadDTO{
+		ApplicationID: t.ApplicationID,
+		BusinessID:    t.BusinessID,
+		JobTitle:      t.JobTitle,
+		AsCandidate:   t.AsCandidate,
+		Unread:        t.Unread,
+		LastAt:        t.LastAt,
+	}
+}
+
+// MessageNotice is a thread with messages that went unread through the
+// quiet period, to email the other side about.
+type MessageNotice struct {
+	ApplicationID int64
+	FromCandidate bool
+	Count         int
+	LastID        int64
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"meu_job/utils/validator"
+	"strings"
+	"time"
+)
+
+type NotificationType string
+
+const (
+	NotifyApplicationReceived NotificationType = "application_received"
+	NotifyApplicationStage    NotificationType = "application_stage_changed"
+	NotifyInterviewProposed   NotificationType = "interview_proposed"
+	NotifyInterviewScheduled  NotificationType = "interview_scheduled"
+	NotifyInterviewCancelled  NotificationType = "interview_cancelled"
+	NotifyMessageReceived     NotificationType = "message_received"
+)
+
+var NotificationTypes = []string{
+	string(NotifyApplicationReceived),
+	string(NotifyApplicationStage),
+	string(NotifyInterviewProposed),
+	string(NotifyInterviewScheduled),
+	string(NotifyInterviewCancelled),
+	string(NotifyMessageReceived),
+}
+
+// Notification tells a user about something that happened in the app. Data
+// has the ids of what it's about, such as application_id, for clients to
+// link to it. Notifications of a type the user turned off aren't created.
+type Notification struct {
+	ID        int64
+	UserID    int64
+	Type      NotificationType
+	Title     string
+	Body      string
+	Data      map[string]int64
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+type NotificationDTO struct {
+	ID        int64            `json:"notification_id"`
+	Type      NotificationType `json:"type"`
+	Title     string           `json:"title"`
+	Body      string           `json:"body"`
+	Data      map[string]int64 `json:"data"`
+	ReadAt    *time.Time       `json:"read_at"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+func (n Notification) ToDTO() *NotificationDTO {
+	data := n.Data
+	if data == nil {
+		data = map[string]int64{}
+	}
+	return &NotificationDTO{
+		ID:        n.ID,
+		Type:      n.Type,
+		Title:     n.Title,
+		Body:      n.Body,
+		Data:      data,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+// NotificationPreferences has whether each type is enabled, every type
+// included.
+type NotificationPreferences map[NotificationType]bool
+
+// ValidatePreferences checks the types being changed; the ones left out
+// keep their setting.
+func ValidatePreferences(v *validator.Validator, prefs NotificationPreferences) {
+	v.Check(len(prefs) > 0, "preferences", "must be provided")
+	for t := range prefs {
+		v.Check(
+			validator.In(string(t), NotificationTypes...),
+			"preferences",
+			"must only have the types "+strings.Join(NotificationTypes, ", "),
+		)
+	}
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"meu_job/utils/validator"
+	"strings"
+)
+
+// Phone holds a Brazilian number in E.164 form, e.g. +5511912345678.
+type Phone string
+
+var validDDDs = map[string]bool{
+	"11": true, "12": true, "13": true, "14": true, "15": true, "16": true, "17": true, "18": true, "19": true,
+	"21": true, "22": true, "24": true, "27": true, "28": true,
+	"31": true, "32": true, "33": true, "34": true, "35": true, "37": true, "38": true,
+	"41": true, "42": true, "43": true, "44": true, "45": true, "46": true, "47": true, "48": true, "49": true,
+	"51": true, "53": true, "54": true, "55": true,
+	"61": true, "62": true, "63": true, "64": true, "65": true, "66": true, "67": true, "68": true, "69": true,
+	"71": true, "73": true, "74": true, "75": true, "77": true, "79": true,
+	"81": true, "82": true, "83": true, "84": true, "85": true, "86": true, "87": true, "88": true, "89": true,
+	"91": true, "92": true, "93": true, "94": true, "95": true, "96": true, "97": true, "98": true, "99": true,
+}
+
+// NormalizePhone turns the common ways of writing a Brazilian number
+// ("(11) 91234-5678", "011 91234 5678", "0 21 11 91234-5678", "+55 11 ...")
+// into E.164. Input that can't be read as a Brazilian number is returned
+// trimmed but otherwise unchanged, so validation can report it.
+func NormalizePhone(s string) Phone {
+	s = strings.TrimSpace(s)
+
+	var digits strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	d := digits.String()
+
+	switch {
+	case strings.HasPrefix(s, "+"):
+		if !strings.HasPrefix(d, "55") {
+			return Phone(s)
+		}
+		d = d[2:]
+	case strings.HasPrefix(d, "55") && (len(d) == 12 || len(d) == 13):
+		d = d[2:]
+	case strings.HasPrefix(d, "0") && (len(d) == 13 || len(d) == 14):
+		// 0 + carrier selection code + DDD + number
+		d = d[3:]
+	case strings.HasPrefix(d, "0") && (len(d) == 11 || len(d) == 12):
+		d = d[1:]
+	}
+
+	if len(d) != 10 && len(d) != 11 {
+		return Phone(s)
+	}
+
+	return Phone("+55" + d)
+}
+
+func (p Phone) national() string {
+	return strings.TrimPrefix(string(p), "+55")
+}
+
+// IsValid checks the DDD and the subscriber number rules: mobiles have nine
+// digits starting with 9, landlines eight digits starting with 2 to 5.
+func (p Phone) IsValid() bool {
+	if !strings.HasPrefix(string(p), "+55") {
+		return false
+	}
+
+	n := p.national()
+	for _, r := range n {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	if len(n) != 10 && len(n) != 11 {
+		return false
+	}
+
+	if !validDDDs[n[:2]] {
+		return false
+	}
+
+	subscriber := n[2:]
+	switch len(subscriber) {
+	case 9:
+		return subscriber[0] == '9'
+	case 8:
+		return subscriber[0] >= '2' && subscriber[0] <= '5'
+	}
+	return false
+}
+
+func (p Phone) IsMobile() bool {
+	return p.IsValid() && len(p.national()) == 11
+}
+
+// Format renders the number the way it's written in Brazil, e.g.
+// (11) 91234-5678. Invalid values are returned unchanged.
+func (p Phone) Format() string {
+	if !p.IsValid() {
+		return string(p)
+	}
+
+	n := p.national()
+	subscriber := n[2:]
+	split := len(subscriber) - 4
+	return "(" + n[:2] + ") " + subscriber[:split] + "-" + subscriber[split:]
+}
+
+func (p Phone) String() string {
+	return string(p)
+}
+
+func ValidatePhone(v *validator.Validator, phone Phone) {
+	v.Check(phone != "", "phone", "must be provided")
+	v.Check(phone.IsValid(), "phone", "must be a valid brazilian phone number")
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"meu_job/utils/validator"
+	"testing"
+)
+
+func TestPhone(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   Phone
+		valid  bool
+		mobile bool
+		format string
+	}{
+		{"(11) 91234-5678", "+5511912345678", true, true, "(11) 91234-5678"},
+		{"11912345678", "+5511912345678", true, true, "(11) 91234-5678"},
+		{"+55 11 91234-5678", "+5511912345678", true, true, "(11) 91234-5678"},
+		{"55 11 91234 5678", "+5511912345678", true, true, "(11) 91234-5678"},
+		{"011 91234 5678", "+5511912345678", true, true, "(11) 91234-5678"},
+		{"0 21 11 91234-5678", "+5511912345678", true, true, "(11) 91234-5678"},
+		{"(21) 3456-7890", "+552134567890", true, false, "(21) 3456-7890"},
+		{"021 3456 7890", "+552134567890", true, false, "(21) 3456-7890"},
+		{"0 15 21 3456-7890", "+552134567890", true, false, "(21) 3456-7890"},
+		// 55 is also the DDD of Santa Maria, RS.
+		{"(55) 3222-1234", "+555532221234", true, false, "(55) 3222-1234"},
+		{"(11) 81234-5678", "+5511812345678", false, false, "+5511812345678"},
+		{"(11) 1234-5678", "+551112345678", false, false, "+551112345678"},
+		{"(11) 6234-5678", "+551162345678", false, false, "+551162345678"},
+		{"(20) 91234-5678", "+5520912345678", false, false, "+5520912345678"},
+		{"(10) 3456-7890", "+551034567890", false, false, "+551034567890"},
+		{"+1 415 555 0100", "+1 415 555 0100", false, false, "+1 415 555 0100"},
+		{"91234-5678", "91234-5678", false, false, "91234-5678"},
+		{"  ", "", false, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			phone := NormalizePhone(tt.in)
+			if phone != tt.want {
+				t.Fatalf("NormalizePhone(%q) = %q, want %q", tt.in, phone, tt.want)
+			}
+			if got := phone.IsValid(); got != tt.valid {
+				t.Errorf("IsValid() = %v, want %v", got, tt.valid)
+			}
+			if got := phone.IsMobile(); got != tt.mobile {
+				t.Errorf("IsMobile() = %v, want %v", got, tt.mobile)
+			}
+			if got := phone.Format(); got != tt.format {
+				t.Errorf("Format() = %q, want %q", got, tt.format)
+			}
+
+			v := validator.New()
+			if ValidatePhone(v, phone); v.Valid() != tt.valid {
+				t.Errorf("ValidatePhone valid = %v, want %v", v.Valid(), tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+type DataExport struct {
+	GeneratedAt         time.Time                `json:"generated_at"`
+	Profile             ProfileExport            `json:"profile"`
+	Businesses          []*BusinessDTO           `json:"businesses"`
+	Sessions            []*SessionDTO            `json:"sessions"`
+	APIKeys             []*APIKeyDTO             `json:"api_keys"`
+	Consents            []*ConsentDTO            `json:"consents"`
+	Files               []*FileDTO               `json:"files"`
+	Curricula           []*CurriculumDTO         `json:"curricula"`
+	CurriculumRevisions []*CurriculumRevisionDTO `json:"curriculum_revisions"`
+	CurriculumDrafts    []*CurriculumDraftDTO    `json:"curriculum_drafts"`
+	JobMatches          []*JobMatchDTO           `json:"job_matches"`
+	SavedSearches       []*SavedSearchDTO        `json:"saved_searches"`
+	Applications        []*ApplicationDTO        `json:"applications"`
+	Interviews          []*InterviewDTO          `json:"interviews"`
+	Messages            []*MessageDTO            `json:"messages"`
+	Notifications       []*NotificationDTO       `json:"notifications"`
+	AuditEntries        []*AuditEntryDTO         `json:"audit_entries"`
+}
+
+// AuditEntry is something the audit columns of a table record the user
+// doing to one of its rows.
+type AuditEntry struct {
+	Table    string
+	RecordID int64
+	Action   string
+	At       time.Time
+}
+
+type AuditEntryDTO struct {
+	Table    string    `json:"table"`
+	RecordID int64     `json:"record_id"`
+	Action   string    `json:"action"`
+	At       time.Time `json:"at"`
+}
+
+func (a *AuditEntry) ToDTO() *AuditEntryDTO {
+	return &AuditEntryDTO{
+		Table:    a.Table,
+		RecordID: a.RecordID,
+		Action:   a.Action,
+		At:       a.At,
+	}
+}
+
+type ProfileExport struct {
+	ID                  int64      `json:"user_id"`
+	Name                string     `json:"name"`
+	Email               string     `json:"email"`
+	PendingEmail        *string    `json:"pending_email"`
+	Phone               Phone      `json:"phone"`
+	CPF                 *CPF       `json:"cpf"`
+	Role                string     `json:"role"`
+	Activated           bool       `json:"activated"`
+	CreatedAt           time.Time  `json:"created_at"`
+	ErasureScheduledFor *time.Time `json:"erasure_scheduled_for"`
+}
+
+func (u *User) ToProfileExport() ProfileExport {
+	return ProfileExport{
+		ID:                  u.ID,
+		Name:                u.Name,
+		Email:               u.Email,
+		PendingEmail:        u.PendingEmail,
+		Phone:               u.Phone,
+		CPF:                 u.CPF,
+		Role:                u.Role.String(),
+		Activated:           u.Activated,
+		CreatedAt:           u.CreatedAt,
+		ErasureScheduledFor: u.ErasureScheduledFor,
+	}
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"meu_job/utils/validator"
+	"strings"
+	"time"
+)
+
+type AlertFrequency string
+
+const (
+	AlertInstant AlertFrequency = "instant"
+	AlertDaily   AlertFrequency = "daily"
+	AlertWeekly  AlertFrequency = "weekly"
+)
+
+var AlertFrequencies = []string{
+	string(AlertInstant),
+	string(AlertDaily),
+	string(AlertWeekly),
+}
+
+// Interval is how long after a run the next one is due. Instant alerts run
+// on every pass of the scheduler.
+func (f AlertFrequency) Interval() time.Duration {
+	switch f {
+	case AlertDaily:
+		return 24 * time.Hour
+	case AlertWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// SavedSearch is a job search kept by a candidate, who is emailed the
+// jobs published since it last ran while AlertsEnabled is set.
+type SavedSearch struct {
+	ID            int64
+	UserID        int64
+	Name          string
+	Criteria      JobSearch
+	Frequency     AlertFrequency
+	AlertsEnabled bool
+	CheckedUntil  time.Time
+	NextRunAt     time.Time
+	BaseModel
+}
+
+type SavedSearchDTO struct {
+	ID            int64          `json:"saved_search_id"`
+	Name          string         `json:"name"`
+	Criteria      JobSearch      `json:"criteria"`
+	Frequency     AlertFrequency `json:"frequency"`
+	AlertsEnabled *bool          `json:"alerts_enabled"`
+	CheckedUntil  time.Time      `json:"checked_until"`
+	Version       int            `json:"version"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     *time.Time     `json:"updated_at"`
+}
+
+func (s SavedSearch) ToDTO() *SavedSearchDTO {
+	return &SavedSearchDTO{
+		ID:            s.ID,
+		Name:          s.Name,
+		Criteria:      s.Criteria,
+		Frequency:     s.Frequency,
+		AlertsEnabled: &s.AlertsEnabled,
+		CheckedUntil:  s.CheckedUntil,
+		Version:       s.Version,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+	}
+}
+
+// ToModel turns alerts on unless alerts_enabled says otherwise.
+func (d SavedSearchDTO) ToModel() *SavedSearch {
+	s := &SavedSearch{
+		ID:            d.ID,
+		Name:          strings.TrimSpace(d.Name),
+		Criteria:      d.Criteria,
+		Frequency:     d.Frequency,
+		AlertsEnabled: d.AlertsEnabled == nil || *d.AlertsEnabled,
+	}
+	s.Criteria.Query = strings.TrimSpace(s.Criteria.Query)
+	s.Criteria.UF = strings.ToUpper(strings.TrimSpace(s.Criteria.UF))
+	s.Criteria.City = strings.TrimSpace(s.Criteria.City)
+	s.Criteria.NearCEP = NormalizeCEP(s.Criteria.NearCEP)
+	s.Version = d.Version
+	return s
+}
+
+func (s *SavedSearch) ValidateSavedSearch(v *validator.Validator) {
+	v.Check(s.Name != "", "name", "must be provided")
+	v.Check(len(s.Name) <= 100, "name", "must not be more than 100 bytes long")
+	v.Check(validator.In(string(s.Frequency), AlertFrequencies...), "frequency", "must be one of "+strings.Join(AlertFrequencies, ", "))
+	s.Criteria.ValidateJobSearch(v)
+}
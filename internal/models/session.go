@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type Session struct {
+	ID         int64
+	UserID     int64
+	UserAgent  string
+	IP         string
+	LastSeenAt time.Time
+	BaseModel
+}
+
+type SessionDTO struct {
+	ID         int64     `json:"session_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+func (s *Session) ToDTO() *SessionDTO {
+	return &SessionDTO{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+	}
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"meu_job/utils/validator"
+	"strings"
+	"unicode"
+)
+
+type SkillCategory string
+
+const (
+	SkillProgrammingLanguage SkillCategory = "programming_language"
+	SkillFramework           SkillCategory = "framework"
+	SkillDatabase            SkillCategory = "database"
+	SkillCloud               SkillCategory = "cloud"
+	SkillTool                SkillCategory = "tool"
+	SkillMethodology         SkillCategory = "methodology"
+	SkillSoft                SkillCategory = "soft_skill"
+	SkillDomain              SkillCategory = "domain"
+	SkillOther               SkillCategory = "other"
+)
+
+var SkillCategories = []string{
+	string(SkillProgrammingLanguage),
+	string(SkillFramework),
+	string(SkillDatabase),
+	string(SkillCloud),
+	string(SkillTool),
+	string(SkillMethodology),
+	string(SkillSoft),
+	string(SkillDomain),
+	string(SkillOther),
+}
+
+func (c SkillCategory) IsValid() bool {
+	return validator.In(string(c), SkillCategories...)
+}
+
+// Skill is an entry of the skills catalog. Curricula and job postings point
+// at it once the names they use are recognized, by the canonical name or
+// any of the synonyms.
+type Skill struct {
+	ID       int64
+	Name     string
+	Key      string
+	Category SkillCategory
+	Synonyms []string
+	// MergedInto is set on skills an admin merged into another, which are
+	// deleted.
+	MergedInto *int64
+	BaseModel
+}
+
+type SkillDTO struct {
+	ID       int64         `json:"skill_id"`
+	Name     string        `json:"name"`
+	Category SkillCategory `json:"category"`
+	Synonyms []string      `json:"synonyms"`
+	Version  int           `json:"version"`
+}
+
+func (s Skill) ToDTO() *SkillDTO {
+	dto := &SkillDTO{
+		ID:       s.ID,
+		Name:     s.Name,
+		Category: s.Category,
+		Synonyms: s.Synonyms,
+		Version:  s.Version,
+	}
+	if dto.Synonyms == nil {
+		dto.Synonyms = []string{}
+	}
+	return dto
+}
+
+func (d SkillDTO) ToModel() *Skill {
+	s := &Skill{
+		ID:       d.ID,
+		Name:     strings.TrimSpace(d.Name),
+		Category: d.Category,
+		Synonyms: make([]string, 0, len(d.Synonyms)),
+	}
+	s.Key = SkillKey(s.Name)
+	s.Version = d.Version
+
+	for _, synonym := range d.Synonyms {
+		if synonym = strings.TrimSpace(synonym); synonym != "" {
+			s.Synonyms = append(s.Synonyms, synonym)
+		}
+	}
+	return s
+}
+
+// SkillKey is what skill names are compared by: lower case, without
+// accents, spaces or punctuation, so "Golang", "go-lang" and "Go Lang" are
+// the same. The + and # are kept, or C, C++ and C# would collide.
+func SkillKey(name string) string {
+	name = accentReplacer.Replace(strings.ToLower(name))
+
+	var b strings.Builder
+	for _, r := range name {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) || r == '+' || r == '#' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (s *Skill) ValidateSkill(v *validator.Validator) {
+	v.Check(s.Name != "", "name", "must be provided")
+	v.Check(len(s.Name) <= 100, "name", "must not be more than 100 bytes long")
+	v.Check(s.Name == "" || s.Key != "", "name", "must contain letters or digits")
+	v.Check(s.Category.IsValid(), "category", "must be one of "+strings.Join(SkillCategories, ", "))
+
+	v.Check(len(s.Synonyms) <= 50, "synonyms", "must not have more than 50 entries")
+	keys := make([]string, 0, len(s.Synonyms))
+	for _, synonym := range s.Synonyms {
+		v.Check(len(synonym) <= 100, "synonyms", "each synonym must not be more than 100 bytes long")
+		key := SkillKey(synonym)
+		v.Check(key != "", "synonyms", "each synonym must contain letters or digits")
+		v.Check(key != s.Key, "synonyms", "must not repeat the name")
+		keys = append(keys, key)
+	}
+	v.Check(validator.Unique(keys), "synonyms", "must not contain duplicate values")
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"meu_job/utils/validator"
+	"regexp"
+	"strings"
+)
+
+var SlugRX = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// Slugify turns a company name into a URL friendly identifier, folding the
+// Portuguese accents: "Padaria São João Ltda." becomes "padaria-sao-joao-ltda".
+func Slugify(s string) string {
+	s = accentReplacer.Replace(strings.ToLower(s))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > 80 {
+		slug = strings.TrimRight(slug[:80], "-")
+	}
+	return slug
+}
+
+func ValidateSlug(v *validator.Validator, slug string) {
+	v.Check(slug != "", "slug", "must be provided")
+	v.Check(len(slug) <= 80, "slug", "must not be more than 80 bytes long")
+	v.Check(validator.Matches(slug, SlugRX), "slug", "must contain only lowercase letters, digits and single hyphens")
+}
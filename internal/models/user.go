@@ -4,6 +4,7 @@ import (
 	"errors"
 	"meu_job/utils/validator"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -19,29 +20,46 @@ const (
 )
 
 type User struct {
-	ID        int64
-	Name      string
-	Email     string
-	Password  password
-	Phone     string
-	Activated bool
-	Cod       int
+	ID                  int64
+	Name                string
+	Email               string
+	PendingEmail        *string
+	PendingEmailCod     *int
+	Password            password
+	Phone               Phone
+	CPF                 *CPF
+	Activated           bool
+	Cod                 int
+	TokenVersion        int
+	CalendarFeedVersion int
+	ErasureScheduledFor *time.Time
+	TermsVersion        int
+	PrivacyVersion      int
 	Role
 	BaseModel
 }
 
 type UserDTO struct {
-	ID    int64  `json:"user_id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Phone string `json:"phone"`
+	ID    int64   `json:"user_id"`
+	Name  string  `json:"name"`
+	Email string  `json:"email"`
+	Phone string  `json:"phone"`
+	CPF   *string `json:"cpf,omitempty"`
 }
 
 type UserSaveDTO struct {
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	Password string `json:"password"`
+	Name     string  `json:"name"`
+	Email    string  `json:"email"`
+	Phone    string  `json:"phone"`
+	CPF      *string `json:"cpf"`
+	Password string  `json:"password"`
+	ConsentAcceptance
+}
+
+type UserUpdateDTO struct {
+	Name  *string `json:"name"`
+	Phone *string `json:"phone"`
+	CPF   *string `json:"cpf"`
 }
 
 type password struct {
@@ -78,12 +96,19 @@ func (u *User) IsAnonymous() bool {
 }
 
 func (u *User) ToDTO() *UserDTO {
-	return &UserDTO{
+	dto := &UserDTO{
 		ID:    u.ID,
 		Name:  u.Name,
 		Email: u.Email,
-		Phone: u.Phone,
+		Phone: u.Phone.String(),
+	}
+
+	if u.CPF != nil {
+		masked := u.CPF.Masked()
+		dto.CPF = &masked
 	}
+
+	return dto
 }
 
 func (u *UserDTO) ToModel() *User {
@@ -91,7 +116,7 @@ func (u *UserDTO) ToModel() *User {
 		ID:    u.ID,
 		Name:  u.Name,
 		Email: u.Email,
-		Phone: u.Phone,
+		Phone: NormalizePhone(u.Phone),
 	}
 }
 
@@ -99,7 +124,12 @@ func (u *UserSaveDTO) ToModel() (*User, error) {
 	user := &User{
 		Name:  u.Name,
 		Email: u.Email,
-		Phone: u.Phone,
+		Phone: NormalizePhone(u.Phone),
+	}
+
+	if u.CPF != nil && *u.CPF != "" {
+		cpf := NormalizeCPF(*u.CPF)
+		user.CPF = &cpf
 	}
 
 	err := user.Password.Set(u.Password)
@@ -110,6 +140,19 @@ func (u *UserSaveDTO) ToModel() (*User, error) {
 	return user, nil
 }
 
+// PendingDocuments lists the kinds of legal document whose current version
+// the user has not accepted yet.
+func (u *User) PendingDocuments(current map[DocumentKind]*LegalDocument) []DocumentKind {
+	pending := []DocumentKind{}
+	if doc, ok := current[DocumentTerms]; ok && u.TermsVersion < doc.Version {
+		pending = append(pending, DocumentTerms)
+	}
+	if doc, ok := current[DocumentPrivacy]; ok && u.PrivacyVersion < doc.Version {
+		pending = append(pending, DocumentPrivacy)
+	}
+	return pending
+}
+
 func (p *password) Set(plaintextPassword string) error {
 	hash, err := bcrypt.GenerateFromPassword([]byte(plaintextPassword), 12)
 	if err != nil {
@@ -148,7 +191,11 @@ func ValidatePasswordPlaintext(v *validator.Validator, password string) {
 func (m *User) ValidateUser(v *validator.Validator) {
 	v.Check(m.Name != "", "name", "must be provided")
 	v.Check(len(m.Name) <= 500, "name", "must not be more than 500 bytes long")
-	v.Check(m.Phone != "", "phone", "must be provided")
+	ValidatePhone(v, m.Phone)
+
+	if m.CPF != nil {
+		ValidateCPF(v, *m.CPF)
+	}
 
 	ValidateEmail(v, m.Email)
 
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+type VerificationStatus string
+
+const (
+	VerificationUnverified VerificationStatus = "unverified"
+	VerificationPending    VerificationStatus = "pending"
+	VerificationVerified   VerificationStatus = "verified"
+	VerificationRejected   VerificationStatus = "rejected"
+)
+
+// verificationTransitions lists the moves members and admins may make. A
+// verified business only goes back to unverified when its CNPJ changes,
+// which the repository handles directly.
+var verificationTransitions = map[VerificationStatus][]VerificationStatus{
+	VerificationUnverified: {VerificationPending},
+	VerificationPending:    {VerificationVerified, VerificationRejected},
+	VerificationRejected:   {VerificationPending},
+}
+
+func (s VerificationStatus) CanTransitionTo(to VerificationStatus) bool {
+	return slices.Contains(verificationTransitions[s], to)
+}
+
+func (s VerificationStatus) IsValid() bool {
+	switch s {
+	case VerificationUnverified, VerificationPending, VerificationVerified, VerificationRejected:
+		return true
+	default:
+		return false
+	}
+}
+
+// VerificationDocument is a proof of ownership (social contract, CNPJ card,
+// ...) uploaded by a member for the admins to review. The content is in the
+// file storage.
+type VerificationDocument struct {
+	ID          int64
+	BusinessID  int64
+	FileID      int64
+	FileName    string
+	ContentType string
+	Size        int64
+	BaseModel
+}
+
+type VerificationDocumentDTO struct {
+	ID          int64     `json:"document_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type VerificationDTO struct {
+	Status     VerificationStatus         `json:"status"`
+	Reason     string                     `json:"reason,omitempty"`
+	ReviewedAt *time.Time                 `json:"reviewed_at,omitempty"`
+	Documents  []*VerificationDocumentDTO `json:"documents"`
+}
+
+func (d *VerificationDocument) ToDTO() *VerificationDocumentDTO {
+	return &VerificationDocumentDTO{
+		ID:          d.ID,
+		FileName:    d.FileName,
+		ContentType: d.ContentType,
+		Size:        d.Size,
+		CreatedAt:   d.CreatedAt,
+	}
+}
+
+func (b *Business) ToVerificationDTO(documents []*VerificationDocument) *VerificationDTO {
+	dto := &VerificationDTO{
+		Status:     b.VerificationStatus,
+		Reason:     b.VerificationReason,
+		ReviewedAt: b.VerificationReviewedAt,
+		Documents:  make([]*VerificationDocumentDTO, len(documents)),
+	}
+	for i, doc := range documents {
+		dto.Documents[i] = doc.ToDTO()
+	}
+	return dto
+}
+
+// CanPublishJobs keeps scams out of the job board: only businesses an admin
+// has verified may post openings.
+func (b *Business) CanPublishJobs() bool {
+	return b.VerificationStatus == VerificationVerified
+}
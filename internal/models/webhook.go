@@ -0,0 +1,254 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"meu_job/utils/validator"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const webhookSecretTag = "whsec"
+
+type WebhookEvent string
+
+const (
+	EventApplicationCreated      WebhookEvent = "application.created"
+	EventApplicationStageChanged WebhookEvent = "application.stage_changed"
+	// EventPing is only sent when asked for, to try out an endpoint.
+	EventPing WebhookEvent = "ping"
+)
+
+// WebhookEvents are the events an endpoint may subscribe to.
+var WebhookEvents = []string{
+	string(EventApplicationCreated),
+	string(EventApplicationStageChanged),
+}
+
+// Webhook is an endpoint of a business that is posted the events it's
+// subscribed to. The payloads are signed with Secret, shown when the
+// webhook is created or the secret rotated.
+type Webhook struct {
+	ID          int64
+	BusinessID  int64
+	URL         string
+	Description string
+	Events      []WebhookEvent
+	Secret      string
+	Active      bool
+	// ShowSecret puts the secret in the DTO.
+	ShowSecret bool
+	BaseModel
+}
+
+type WebhookDTO struct {
+	ID          int64          `json:"webhook_id"`
+	BusinessID  int64          `json:"business_id"`
+	URL         string         `json:"url"`
+	Description string         `json:"description"`
+	Events      []WebhookEvent `json:"events"`
+	Active      *bool          `json:"active"`
+	Secret      string         `json:"secret,omitempty"`
+	Version     int            `json:"version"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   *time.Time     `json:"updated_at"`
+}
+
+func (w Webhook) ToDTO() *WebhookDTO {
+	dto := &WebhookDTO{
+		ID:          w.ID,
+		BusinessID:  w.BusinessID,
+		URL:         w.URL,
+		Description: w.Description,
+		Events:      w.Events,
+		Active:      &w.Active,
+		Version:     w.Version,
+		CreatedAt:   w.CreatedAt,
+		UpdatedAt:   w.UpdatedAt,
+	}
+	if w.ShowSecret {
+		dto.Secret = w.Secret
+	}
+	return dto
+}
+
+// ToModel turns the webhook on unless active says otherwise.
+func (d WebhookDTO) ToModel() *Webhook {
+	w := &Webhook{
+		ID:          d.ID,
+		URL:         strings.TrimSpace(d.URL),
+		Description: strings.TrimSpace(d.Description),
+		Events:      d.Events,
+		Active:      d.Active == nil || *d.Active,
+	}
+	w.Version = d.Version
+	return w
+}
+
+// GenerateSecret replaces the secret with a new random one.
+func (w *Webhook) GenerateSecret() error {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+
+	w.Secret = webhookSecretTag + "_" + hex.EncodeToString(secret)
+	w.ShowSecret = true
+	return nil
+}
+
+// ValidateWebhook takes plain http and private hosts only with allowPrivate,
+// for trying webhooks out locally.
+func (w *Webhook) ValidateWebhook(v *validator.Validator, allowPrivate bool) {
+	u, err := url.Parse(w.URL)
+	valid := err == nil && u.Host != "" && (u.Scheme == "https" || (allowPrivate && u.Scheme == "http"))
+	v.Check(w.URL != "", "url", "must be provided")
+	v.Check(w.URL == "" || valid, "url", "must be a valid https URL")
+	v.Check(len(w.URL) <= 500, "url", "must not be more than 500 bytes long")
+	v.Check(err != nil || u.User == nil, "url", "must not have credentials")
+	v.Check(len(w.Description) <= 200, "description", "must not be more than 200 bytes long")
+
+	v.Check(len(w.Events) > 0, "events", "must have at least one event")
+	v.Check(validator.Unique(w.Events), "events", "must not contain duplicate values")
+	for _, event := range w.Events {
+		v.Check(
+			validator.In(string(event), WebhookEvents...),
+			"events",
+			"must only have the events "+strings.Join(WebhookEvents, ", "),
+		)
+	}
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryDead was given up on after every retry failed. It's only sent
+	// again when redelivered by hand.
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+var DeliveryStatuses = []string{
+	string(DeliveryPending),
+	string(DeliverySucceeded),
+	string(DeliveryDead),
+}
+
+// WebhookDelivery is an event queued for a webhook. Payload is the data of
+// the event, which is posted wrapped with the delivery's id, event and
+// time; redeliveries send it as it was.
+type WebhookDelivery struct {
+	ID             int64
+	WebhookID      int64
+	Event          WebhookEvent
+	Payload        json.RawMessage
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode *int
+	LastError      *string
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	// URL and Secret of the webhook are loaded when it's claimed for
+	// sending; AttemptLog only with the delivery alone.
+	URL        string
+	Secret     string
+	AttemptLog []*WebhookAttempt
+}
+
+type WebhookDeliveryDTO struct {
+	ID             int64                `json:"delivery_id"`
+	WebhookID      int64                `json:"webhook_id"`
+	Event          WebhookEvent         `json:"event"`
+	Payload        json.RawMessage      `json:"payload"`
+	Status         DeliveryStatus       `json:"status"`
+	Attempts       int                  `json:"attempts"`
+	NextAttemptAt  *time.Time           `json:"next_attempt_at"`
+	LastStatusCode *int                 `json:"last_status_code"`
+	LastError      *string              `json:"last_error"`
+	DeliveredAt    *time.Time           `json:"delivered_at"`
+	CreatedAt      time.Time            `json:"created_at"`
+	AttemptLog     []*WebhookAttemptDTO `json:"attempt_log,omitempty"`
+}
+
+func (d WebhookDelivery) ToDTO() *WebhookDeliveryDTO {
+	dto := &WebhookDeliveryDTO{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == DeliveryPending {
+		dto.NextAttemptAt = &d.NextAttemptAt
+	}
+	if d.AttemptLog != nil {
+		dto.AttemptLog = make([]*WebhookAttemptDTO, len(d.AttemptLog))
+		for i, a := range d.AttemptLog {
+			dto.AttemptLog[i] = a.ToDTO()
+		}
+	}
+	return dto
+}
+
+// Body is what is posted to the endpoint.
+func (d WebhookDelivery) Body() ([]byte, error) {
+	return json.Marshal(struct {
+		ID        int64           `json:"id"`
+		Event     WebhookEvent    `json:"event"`
+		CreatedAt time.Time       `json:"created_at"`
+		Data      json.RawMessage `json:"data"`
+	}{d.ID, d.Event, d.CreatedAt, d.Payload})
+}
+
+// WebhookAttempt is one try at a delivery. StatusCode is nil when no
+// response came back, and Error says why.
+type WebhookAttempt struct {
+	ID           int64
+	DeliveryID   int64
+	StatusCode   *int
+	Error        *string
+	ResponseBody string
+	Duration     time.Duration
+	AttemptedAt  time.Time
+}
+
+type WebhookAttemptDTO struct {
+	StatusCode   *int      `json:"status_code"`
+	Error        *string   `json:"error"`
+	ResponseBody string    `json:"response_body"`
+	DurationMS   int64     `json:"duration_ms"`
+	AttemptedAt  time.Time `json:"attempted_at"`
+}
+
+func (a WebhookAttempt) ToDTO() *WebhookAttemptDTO {
+	return &WebhookAttemptDTO{
+		StatusCode:   a.StatusCode,
+		Error:        a.Error,
+		ResponseBody: a.ResponseBody,
+		DurationMS:   a.Duration.Milliseconds(),
+		AttemptedAt:  a.AttemptedAt,
+	}
+}
+
+// ApplicationEvent is the data of the application events: the application
+// as the business sees it, without the curriculum, and the stage it left
+// when it moved.
+type ApplicationEvent struct {
+	Application   *ApplicationDTO  `json:"application"`
+	PreviousStage ApplicationStage `json:"previous_stage,omitempty"`
+}
+
+func NewApplicationEvent(a *Application, previous ApplicationStage) ApplicationEvent {
+	dto := a.ToDTO()
+	dto.Candidate = a.Candidate.ToDTO()
+	return ApplicationEvent{Application: dto, PreviousStage: previous}
+}
@@ -0,0 +1,146 @@
+package pdf
+
+import "strings"
+
+// Glyph widths of the standard Helvetica faces for the printable ASCII
+// range, in thousandths of the font size. The oblique face shares the
+// regular widths.
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// winAnsi maps the characters outside Latin-1 that WinAnsiEncoding can
+// still show.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// specialWidths covers the WinAnsi glyphs past ASCII that don't share the
+// width of a base letter. Both faces agree on them.
+var specialWidths = map[byte]int{
+	0x80: 556, 0x82: 222, 0x84: 333, 0x85: 1000, 0x91: 222, 0x92: 222,
+	0x93: 333, 0x94: 333, 0x95: 350, 0x96: 556, 0x97: 1000, 0x99: 1000,
+	0xA0: 278, 0xA7: 556, 0xAA: 370, 0xB0: 400, 0xBA: 365,
+}
+
+// latinBase is the unaccented letter whose width an accented Latin-1
+// letter shares, indexed from 0xC0.
+const latinBase = "AAAAAAACEEEEIIIIDNOOOOO*OUUUUYPsaaaaaaaceeeeiiiidnooooo/ouuuuypy"
+
+// encode converts UTF-8 to WinAnsiEncoding, replacing what it can't show.
+func encode(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			b = append(b, byte(r))
+		case winAnsi[r] != 0:
+			b = append(b, winAnsi[r])
+		default:
+			b = append(b, '?')
+		}
+	}
+	return b
+}
+
+func glyphWidth(font Font, c byte) int {
+	widths := &helveticaWidths
+	if font == HelveticaBold {
+		widths = &helveticaBoldWidths
+	}
+
+	switch {
+	case c >= 32 && c < 127:
+		return widths[c-32]
+	case specialWidths[c] != 0:
+		return specialWidths[c]
+	case c >= 0xC0:
+		base := latinBase[c-0xC0]
+		if base >= 32 && base < 127 && base != '*' && base != '/' {
+			return widths[base-32]
+		}
+		return 584
+	default:
+		return 556
+	}
+}
+
+// TextWidth measures s in points.
+func TextWidth(font Font, size float64, s string) float64 {
+	total := 0
+	for _, c := range encode(s) {
+		total += glyphWidth(font, c)
+	}
+	return float64(total) * size / 1000
+}
+
+// Wrap breaks s into lines no wider than width, splitting on spaces. A word
+// longer than a whole line is cut wherever it overflows.
+func Wrap(font Font, size, width float64, s string) []string {
+	var lines []string
+
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+
+			if TextWidth(font, size, candidate) <= width {
+				line = candidate
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, line)
+			}
+
+			for TextWidth(font, size, word) > width {
+				cut := fit(font, size, width, word)
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// fit returns how many bytes of word fit in width, always at least one rune.
+func fit(font Font, size, width float64, word string) int {
+	cut := 0
+	for i := range word {
+		if i > 0 && TextWidth(font, size, word[:i]) > width {
+			break
+		}
+		cut = i
+	}
+	if cut == 0 {
+		for i := range word {
+			if i > 0 {
+				return i
+			}
+		}
+		return len(word)
+	}
+	return cut
+}
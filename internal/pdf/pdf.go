@@ -0,0 +1,181 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica
+// faces, lines and filled rectangles on A4 pages. It only uses the fonts
+// every viewer ships with, so nothing has to be embedded.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+	HelveticaOblique
+)
+
+var fontNames = map[Font]string{
+	Helvetica:        "Helvetica",
+	HelveticaBold:    "Helvetica-Bold",
+	HelveticaOblique: "Helvetica-Oblique",
+}
+
+type Color struct {
+	R, G, B float64
+}
+
+var (
+	Black = Color{0, 0, 0}
+	White = Color{1, 1, 1}
+)
+
+// RGB builds a color from 0-255 components.
+func RGB(r, g, b uint8) Color {
+	return Color{float64(r) / 255, float64(g) / 255, float64(b) / 255}
+}
+
+type Document struct {
+	title string
+	pages []*Page
+}
+
+// Page coordinates start at the bottom left corner, as in PDF itself.
+type Page struct {
+	content bytes.Buffer
+}
+
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+func (p *Page) Text(x, y float64, font Font, size float64, color Color, s string) {
+	fmt.Fprintf(&p.content, "BT %s rg /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		color.operands(), font, num(size), num(x), num(y), escape(encode(s)))
+}
+
+func (p *Page) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s m %s %s l S\n",
+		color.operands(), num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+func (p *Page) Rect(x, y, w, h float64, fill Color) {
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n",
+		fill.operands(), num(x), num(y), num(w), num(h))
+}
+
+func (c Color) operands() string {
+	return num(c.R) + " " + num(c.G) + " " + num(c.B)
+}
+
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-" {
+		return "0"
+	}
+	return s
+}
+
+// Bytes serializes the document. Objects are numbered as: catalog, page
+// tree, info, one per font, then a page and its content stream per page.
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	const firstFont = 4
+	firstPage := firstFont + len(fontNames)
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	var fonts strings.Builder
+	for f := Helvetica; int(f) < len(fontNames); f++ {
+		fmt.Fprintf(&fonts, "/F%d %d 0 R ", f, firstFont+int(f))
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object(fmt.Sprintf("<< /Title (%s) /Producer (meu_job) >>", escape(encode(d.title))))
+
+	for f := Helvetica; int(f) < len(fontNames); f++ {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", fontNames[f]))
+	}
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			num(PageWidth), num(PageHeight), fonts.String(), firstPage+2*i+1,
+		))
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		if _, err := zw.Write(page.content.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", len(offsets), stream.Len())
+		buf.Write(stream.Bytes())
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes(), nil
+}
+
+func escape(b []byte) string {
+	var s strings.Builder
+	for _, c := range b {
+		switch c {
+		case '(', ')', '\\':
+			s.WriteByte('\\')
+			s.WriteByte(c)
+		case '\n', '\r', '\t':
+			s.WriteByte(' ')
+		default:
+			s.WriteByte(c)
+		}
+	}
+	return s.String()
+}
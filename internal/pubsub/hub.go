@@ -0,0 +1,153 @@
+// Package pubsub wakes up the requests waiting on a user when something
+// for them is written to the database. It listens on a Postgres channel
+// whose payload is the user ID, so a notification committed through any
+// instance of the API reaches the streams open on every one of them.
+package pubsub
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Hub fans the notifications out to the subscribers of each user. Signals
+// carry nothing: subscribers fetch what is new themselves, so a signal
+// dropped while one is pending loses nothing.
+type Hub struct {
+	listener *pq.Listener
+	mu       sync.Mutex
+	subs     map[int64]map[chan struct{}]struct{}
+	closed   bool
+	done     chan struct{}
+}
+
+// NewHub listens on channel through a connection of its own, reconnecting
+// as needed; onError is told about connection problems.
+func NewHub(dsn, channel string, onError func(error)) (*Hub, error) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	})
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	h := &Hub{
+		listener: listener,
+		subs:     make(map[int64]map[chan struct{}]struct{}),
+		done:     make(chan struct{}),
+	}
+	go h.run()
+	return h, nil
+}
+
+func (h *Hub) run() {
+	for {
+		select {
+		case n, ok := <-h.listener.Notify:
+			if !ok {
+				return
+			}
+			// A nil notification follows a reconnect, when some may have
+			// been missed, so everyone checks.
+			if n == nil {
+				h.broadcast()
+				continue
+			}
+			if userID, err := strconv.ParseInt(n.Extra, 10, 64); err == nil {
+				h.signal(userID)
+			}
+		case <-time.After(90 * time.Second):
+			// Pinging finds out about a dead connection that no
+			// notification would.
+			h.listener.Ping()
+		case <-h.done:
+			return
+		}
+	}
+}
+
+// Subscribe signals the channel returned whenever there is something new
+// for the user, until unsubscribe is called or the hub is closed, which
+// closes it.
+func (h *Hub) Subscribe(userID int64) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[userID][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if _, ok := h.subs[userID][ch]; !ok {
+			return
+		}
+		delete(h.subs[userID], ch)
+		if len(h.subs[userID]) == 0 {
+			delete(h.subs, userID)
+		}
+		close(ch)
+	}
+}
+
+func (h *Hub) signal(userID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[userID] {
+		notify(ch)
+	}
+}
+
+func (h *Hub) broadcast() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, chans := range h.subs {
+		for ch := range chans {
+			notify(ch)
+		}
+	}
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops listening and closes the channels of every subscriber, which
+// ends their streams. It's safe to call more than once.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	close(h.done)
+
+	for userID, chans := range h.subs {
+		for ch := range chans {
+			close(ch)
+		}
+		delete(h.subs, userID)
+	}
+	h.listener.Close()
+}
@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"meu_job/internal/models"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type brasilAPIRegistry struct {
+	baseURL string
+	client  *http.Client
+}
+
+type brasilAPICompany struct {
+	CNPJ              string `json:"cnpj"`
+	RazaoSocial       string `json:"razao_social"`
+	NomeFantasia      string `json:"nome_fantasia"`
+	SituacaoCadastral string `json:"descricao_situacao_cadastral"`
+	TipoLogradouro    string `json:"descricao_tipo_de_logradouro"`
+	Logradouro        string `json:"logradouro"`
+	Numero            string `json:"numero"`
+	Complemento       string `json:"complemento"`
+	Bairro            string `json:"bairro"`
+	Municipio         string `json:"municipio"`
+	UF                string `json:"uf"`
+	CEP               string `json:"cep"`
+}
+
+// NewBrasilAPIRegistry talks to a BrasilAPI compatible endpoint, where
+// GET {baseURL}/{cnpj} returns the company or 404.
+func NewBrasilAPIRegistry(baseURL string, timeout time.Duration) *brasilAPIRegistry {
+	return &brasilAPIRegistry{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (r *brasilAPIRegistry) Lookup(cnpj models.CNPJ) (*Company, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+"/"+cnpj.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1_048_576))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	return parseBrasilAPI(raw)
+}
+
+func parseBrasilAPI(raw []byte) (*Company, error) {
+	var payload brasilAPICompany
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	street := strings.TrimSpace(payload.TipoLogradouro + " " + payload.Logradouro)
+
+	return &Company{
+		CNPJ:      models.NormalizeCNPJ(payload.CNPJ),
+		LegalName: strings.TrimSpace(payload.RazaoSocial),
+		TradeName: strings.TrimSpace(payload.NomeFantasia),
+		Status:    strings.ToUpper(strings.TrimSpace(payload.SituacaoCadastral)),
+		Address: models.Address{
+			Place: models.Place{
+				CEP:      models.NormalizeCEP(payload.CEP),
+				Street:   street,
+				District: payload.Bairro,
+				City:     payload.Municipio,
+				UF:       payload.UF,
+			},
+			Number:     payload.Numero,
+			Complement: payload.Complemento,
+		},
+		Raw: raw,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package registry
+
+import (
+	"embed"
+	"meu_job/internal/models"
+	"path"
+	"strings"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// fakeRegistry answers from BrasilAPI shaped JSON fixtures, one file per
+// CNPJ named after its canonical form. It lets local and test environments
+// run without reaching the public API.
+type fakeRegistry struct {
+	companies map[models.CNPJ]*Company
+}
+
+// NewFakeRegistry panics on a malformed fixture: they are embedded at build
+// time, so that is a programming error rather than a runtime condition.
+func NewFakeRegistry() *fakeRegistry {
+	entries, err := fixtures.ReadDir("fixtures")
+	if err != nil {
+		panic(err)
+	}
+
+	companies := make(map[models.CNPJ]*Company, len(entries))
+	for _, entry := range entries {
+		raw, err := fixtures.ReadFile(path.Join("fixtures", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		company, err := parseBrasilAPI(raw)
+		if err != nil {
+			panic(err)
+		}
+
+		cnpj := models.NormalizeCNPJ(strings.TrimSuffix(entry.Name(), ".json"))
+		companies[cnpj] = company
+	}
+
+	return &fakeRegistry{companies: companies}
+}
+
+func (r *fakeRegistry) Lookup(cnpj models.CNPJ) (*Company, error) {
+	company, ok := r.companies[cnpj]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return company, nil
+}
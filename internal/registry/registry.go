@@ -0,0 +1,36 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"meu_job/internal/models"
+)
+
+var (
+	ErrNotFound    = errors.New("cnpj not found in registry")
+	ErrUnavailable = errors.New("cnpj registry unavailable")
+)
+
+const (
+	StatusActive = "ATIVA"
+	StatusClosed = "BAIXADA"
+)
+
+// Company is what the registry knows about a CNPJ. Raw keeps the original
+// payload so it can be stored as a snapshot next to the business.
+type Company struct {
+	CNPJ      models.CNPJ
+	LegalName string
+	TradeName string
+	Status    string
+	Address   models.Address
+	Raw       json.RawMessage
+}
+
+func (c *Company) IsActive() bool {
+	return c.Status == StatusActive
+}
+
+type CNPJRegistry interface {
+	Lookup(cnpj models.CNPJ) (*Company, error)
+}
@@ -0,0 +1,255 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	e "meu_job/utils/errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+type APIKeyRepositoryInterface interface {
+	GetAllByUser(userID int64) ([]*models.APIKey, error)
+	GetByPrefix(prefix string) (*models.APIKey, error)
+	Insert(key *models.APIKey, tx *sql.Tx) error
+	Revoke(id, userID int64, tx *sql.Tx) error
+	RevokeAllByUser(userID int64, tx *sql.Tx) error
+	TouchLastUsed(id int64) error
+}
+
+func NewAPIKeyRepository(db *sql.DB) *apiKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataAPIKey = `
+		k.id,
+		k.user_id,
+		k.name,
+		k.prefix,
+		k.key_hash,
+		k.scopes,
+		k.expires_at,
+		k.last_used_at,
+		k.version,
+		k.deleted,
+		k.created_by,
+		k.created_at,
+		k.updated_by,
+		k.updated_at
+	`
+
+func scanAPIKey(r scanner, key *models.APIKey) error {
+	var scopes []string
+	err := r.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		pq.Array(&scopes),
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.Version,
+		&key.Deleted,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&key.UpdatedBy,
+		&key.UpdatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	key.Scopes = make([]models.Scope, len(scopes))
+	for i, scope := range scopes {
+		key.Scopes[i] = models.Scope(scope)
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) GetAllByUser(userID int64) ([]*models.APIKey, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from api_keys k
+	where
+		k.user_id = $1
+		and k.deleted = false
+	order by k.created_at desc, k.id
+	`, SQLSelectDataAPIKey)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		key := models.APIKey{}
+		if err := scanAPIKey(rows, &key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) GetByPrefix(prefix string) (*models.APIKey, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from api_keys k
+	where
+		k.prefix = $1
+		and k.deleted = false
+	`, SQLSelectDataAPIKey)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	key := models.APIKey{}
+	if err := scanAPIKey(r.db.QueryRowContext(ctx, query, prefix), &key); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *apiKeyRepository) Insert(key *models.APIKey, tx *sql.Tx) error {
+	query := `
+	insert into api_keys (
+		user_id,
+		name,
+		prefix,
+		key_hash,
+		scopes,
+		expires_at,
+		created_by
+	)
+	values ($1,$2,$3,$4,$5,$6,$1)
+	returning
+		id,
+		created_at,
+		version
+	`
+
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	args := []any{
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+		pq.Array(scopes),
+		key.ExpiresAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, args...).Scan(
+		&key.ID,
+		&key.CreatedAt,
+		&key.Version,
+	)
+}
+
+func (r *apiKeyRepository) Revoke(id, userID int64, tx *sql.Tx) error {
+	query := `
+	update api_keys
+	set
+		deleted = true,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and user_id = $2
+		and deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return e.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) RevokeAllByUser(userID int64, tx *sql.Tx) error {
+	query := `
+	update api_keys
+	set
+		deleted = true,
+		updated_by = $1,
+		updated_at = now(),
+		version = version + 1
+	where
+		user_id = $1
+		and deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
+
+// TouchLastUsed records key usage at most once per minute so that busy
+// integrations don't turn every request into a write.
+func (r *apiKeyRepository) TouchLastUsed(id int64) error {
+	query := `
+	update api_keys
+	set last_used_at = now()
+	where
+		id = $1
+		and (last_used_at is null or last_used_at < now() - interval '1 minute')
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
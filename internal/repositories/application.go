@@ -0,0 +1,347 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	e "meu_job/utils/errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type applicationRepository struct {
+	db *sql.DB
+}
+
+type ApplicationRepositoryInterface interface {
+	GetByID(id int64) (*models.Application, error)
+	GetByUser(id, userID int64) (*models.Application, error)
+	GetByBusiness(id, businessID int64) (*models.Application, error)
+	GetAllByUser(userID int64) ([]*models.Application, error)
+	GetAllByJob(jobID int64, stage string, f filters.Filters) ([]*models.Application, filters.Metadata, error)
+	Insert(a *models.Application, tx *sql.Tx) error
+	SetStage(a *models.Application, userID int64, tx *sql.Tx) error
+	EraseAllByUser(userID int64, tx *sql.Tx) error
+}
+
+func NewApplicationRepository(db *sql.DB) *applicationRepository {
+	return &applicationRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataApplication = `
+		a.id,
+		a.job_id,
+		a.business_id,
+		a.user_id,
+		a.curriculum_id,
+		a.curriculum_version,
+		a.cover_letter,
+		a.stage,
+		a.stage_reason,
+		a.stage_changed_at,
+		a.version,
+		a.created_by,
+		a.created_at,
+		a.updated_by,
+		a.updated_at,
+		j.title,
+		b.name,
+		u.name,
+		u.email
+	`
+
+const sqlFromApplications = `
+	from applications a
+	join jobs j on j.id = a.job_id
+	join business b on b.id = a.business_id
+	join users u on u.id = a.user_id
+	`
+
+func applicationDest(a *models.Application) []any {
+	return []any{
+		&a.ID,
+		&a.JobID,
+		&a.BusinessID,
+		&a.UserID,
+		&a.CurriculumID,
+		&a.CurriculumVersion,
+		&a.CoverLetter,
+		&a.Stage,
+		&a.StageReason,
+		&a.StageChangedAt,
+		&a.Version,
+		&a.CreatedBy,
+		&a.CreatedAt,
+		&a.UpdatedBy,
+		&a.UpdatedAt,
+		&a.JobTitle,
+		&a.BusinessName,
+		&a.Candidate.Name,
+		&a.Candidate.Email,
+	}
+}
+
+func (r *applicationRepository) get(where string, args ...any) (*models.Application, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	%s
+	where %s
+	`, SQLSelectDataApplication, sqlFromApplications, where)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	a := models.Application{}
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(applicationDest(&a)...)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, e.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	a.Candidate.UserID = a.UserID
+	return &a, nil
+}
+
+func (r *applicationRepository) GetByID(id int64) (*models.Application, error) {
+	return r.get("a.id = $1", id)
+}
+
+func (r *applicationRepository) GetByUser(id, userID int64) (*models.Application, error) {
+	return r.get("a.id = $1 and a.user_id = $2", id, userID)
+}
+
+func (r *applicationRepository) GetByBusiness(id, businessID int64) (*models.Application, error) {
+	return r.get("a.id = $1 and a.business_id = $2", id, businessID)
+}
+
+func (r *applicationRepository) list(
+	where string,
+	f filters.Filters,
+	args ...any,
+) ([]*models.Application, filters.Metadata, error) {
+	args = append(args, f.Limit(), f.Offset())
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s
+		%s
+		where %s
+		order by a.%s %s, a.id
+		limit $%d offset $%d
+	`,
+		SQLSelectDataApplication,
+		sqlFromApplications,
+		where,
+		f.SortColumn(),
+		f.SortDirection(),
+		len(args)-1,
+		len(args),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	applications := []*models.Application{}
+
+	for rows.Next() {
+		a := models.Application{}
+		dest := append([]any{&totalRecords}, applicationDest(&a)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		a.Candidate.UserID = a.UserID
+		applications = append(applications, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	metaData := filters.CalculateMetadata(totalRecords, f.Page, f.PageSize)
+	return applications, metaData, nil
+}
+
+// GetAllByUser lists the candidate's applications, the latest first.
+func (r *applicationRepository) GetAllByUser(userID int64) ([]*models.Application, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	%s
+	where a.user_id = $1
+	order by a.created_at desc, a.id desc
+	`, SQLSelectDataApplication, sqlFromApplications)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applications := []*models.Application{}
+	for rows.Next() {
+		a := models.Application{}
+		if err := rows.Scan(applicationDest(&a)...); err != nil {
+			return nil, err
+		}
+		a.Candidate.UserID = a.UserID
+		applications = append(applications, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+// GetAllByJob leaves out the withdrawn applications unless asked for by
+// stage.
+func (r *applicationRepository) GetAllByJob(
+	jobID int64,
+	stage string,
+	f filters.Filters,
+) ([]*models.Application, filters.Metadata, error) {
+	where := `
+		a.job_id = $1
+		and (a.stage = $2 or ($2 = '' and a.stage <> 'withdrawn'))
+	`
+	return r.list(where, f, jobID, stage)
+}
+
+// Insert loads the job title, business name and candidate as the reads do.
+func (r *applicationRepository) Insert(a *models.Application, tx *sql.Tx) error {
+	query := `
+	insert into applications (
+		job_id,
+		business_id,
+		user_id,
+		curriculum_id,
+		curriculum_version,
+		cover_letter,
+		created_by
+	)
+	values ($1,$2,$3,$4,$5,$6,$3)
+	returning
+		id,
+		stage,
+		stage_changed_at,
+		version,
+		created_at,
+		(select j.title from jobs j where j.id = applications.job_id),
+		(select b.name from business b where b.id = applications.business_id),
+		(select u.name from users u where u.id = applications.user_id),
+		(select u.email from users u where u.id = applications.user_id)
+	`
+
+	args := []any{
+		a.JobID,
+		a.BusinessID,
+		a.UserID,
+		a.CurriculumID,
+		a.CurriculumVersion,
+		a.CoverLetter,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := tx.QueryRowContext(ctx, query, args...).Scan(
+		&a.ID,
+		&a.Stage,
+		&a.StageChangedAt,
+		&a.Version,
+		&a.CreatedAt,
+		&a.JobTitle,
+		&a.BusinessName,
+		&a.Candidate.Name,
+		&a.Candidate.Email,
+	)
+	a.Candidate.UserID = a.UserID
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "idx_applications_job_user" {
+		return e.ErrDuplicateApplication
+	}
+	return err
+}
+
+// SetStage only succeeds against the version the client last read.
+func (r *applicationRepository) SetStage(a *models.Application, userID int64, tx *sql.Tx) error {
+	query := `
+	update applications
+	set
+		stage = $2,
+		stage_reason = $3,
+		stage_changed_at = now(),
+		updated_by = $4,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and version = $5
+	returning
+		stage_changed_at,
+		version,
+		updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	a.UpdatedBy = &userID
+	err := tx.QueryRowContext(ctx, query, a.ID, a.Stage, a.StageReason, userID, a.Version).Scan(
+		&a.StageChangedAt,
+		&a.Version,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EraseAllByUser blanks what the candidate wrote, and the reasons given to
+// them, but keeps the applications: the businesses' hiring history, with
+// its interviews and messages, isn't the candidate's to erase.
+func (r *applicationRepository) EraseAllByUser(userID int64, tx *sql.Tx) error {
+	query := `
+	update applications
+	set
+		cover_letter = '',
+		stage_reason = '',
+		updated_at = now(),
+		version = version + 1
+	where user_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"meu_job/internal/models"
+	"strings"
+	"time"
+)
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+type AuditRepositoryInterface interface {
+	GetAllByUser(userID int64) ([]*models.AuditEntry, error)
+}
+
+func NewAuditRepository(db *sql.DB) *auditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+// auditedTables record who created and last updated each row; the
+// created-only ones are never changed after being written.
+var (
+	auditedTables = []string{
+		"users",
+		"business",
+		"business_verification_documents",
+		"api_keys",
+		"sessions",
+		"files",
+		"curricula",
+		"curriculum_drafts",
+		"skills",
+		"jobs",
+		"saved_searches",
+		"applications",
+		"interviews",
+		"webhooks",
+	}
+	auditedCreateOnlyTables = []string{
+		"legal_documents",
+		"curriculum_revisions",
+		"skill_synonyms",
+	}
+)
+
+// GetAllByUser lists what the audit columns say the user did, oldest
+// first. Only the last update of a row is recorded, so an update done by
+// someone else since hides the user's.
+func (r *auditRepository) GetAllByUser(userID int64) ([]*models.AuditEntry, error) {
+	var parts []string
+	for _, t := range append(auditedTables, auditedCreateOnlyTables...) {
+		parts = append(parts, fmt.Sprintf(
+			`select '%s', id, 'created', created_at from %[1]s where created_by = $1`, t,
+		))
+	}
+	for _, t := range auditedTables {
+		parts = append(parts, fmt.Sprintf(
+			`select '%s', id, 'updated', updated_at from %[1]s where updated_by = $1 and updated_at is not null`, t,
+		))
+	}
+	parts = append(parts, `
+		select 'business', id, 'verification_reviewed', verification_reviewed_at
+		from business
+		where verification_reviewed_by = $1 and verification_reviewed_at is not null`,
+	)
+
+	query := strings.Join(parts, "\n\tunion all\n\t") + "\n\torder by 4, 1, 2"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.AuditEntry{}
+	for rows.Next() {
+		entry := models.AuditEntry{}
+		if err := rows.Scan(&entry.Table, &entry.RecordID, &entry.Action, &entry.At); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"meu_job/internal/models"
@@ -36,6 +37,13 @@ type BusinessRepositoryInterface interface {
 	Update(business *models.Business, userID int64, tx *sql.Tx) error
 	Delete(id, userID int64, tx *sql.Tx) error
 	AddUserInBusiness(businessID, userID, userLogadoID int64, tx *sql.Tx) error
+	GetAllByUser(userID int64) ([]*models.Business, error)
+	SlugExists(slug string) (bool, error)
+	SetPublic(id, userID int64, public bool, tx *sql.Tx) error
+	SetLogo(id, userID, fileID int64, tx *sql.Tx) error
+	GetPublicBySlug(slug string) (*models.Business, error)
+	GetAllPublic(name, sector, city string, f filters.Filters) ([]*models.Business, filters.Metadata, error)
+	GetMembers(id int64) ([]models.Participant, error)
 }
 
 const SQLSelectDataBusiness = `
@@ -44,6 +52,34 @@ const SQLSelectDataBusiness = `
 		b.cnpj,
 		b.email,
 		b.phone,
+		b.legal_name,
+		b.trade_name,
+		b.address_cep,
+		b.address_street,
+		b.address_number,
+		b.address_complement,
+		b.address_district,
+		b.address_city,
+		b.address_uf,
+		b.address_latitude,
+		b.address_longitude,
+		b.registration_status,
+		b.verified,
+		b.registry_snapshot,
+		b.registry_checked_at,
+		b.verification_status,
+		b.verification_reason,
+		b.verification_reviewed_by,
+		b.verification_reviewed_at,
+		b.slug,
+		b.description,
+		b.sector,
+		b.size,
+		b.logo_url,
+		b.website,
+		b.social_links,
+		b.public,
+		b.logo_file_id,
 		b.version,
 		b.deleted,
 		b.created_by,
@@ -52,14 +88,44 @@ const SQLSelectDataBusiness = `
 		b.updated_at
 	`
 
-func scanBusinessPage(r *sql.Rows, totalRecords *int, business *models.Business) error {
-	return r.Scan(
-		totalRecords,
+// businessDest lists the scan targets matching SQLSelectDataBusiness. The
+// social links come back as raw JSON and are decoded by decodeSocialLinks.
+func businessDest(business *models.Business, socialLinks *[]byte) []any {
+	dest := []any{
 		&business.ID,
 		&business.Name,
 		&business.CNPJ,
 		&business.Email,
 		&business.Phone,
+		&business.LegalName,
+		&business.TradeName,
+		&business.Address.CEP,
+		&business.Address.Street,
+		&business.Address.Number,
+		&business.Address.Complement,
+		&business.Address.District,
+		&business.Address.City,
+		&business.Address.UF,
+	}
+	dest = append(dest, coordinatesDest(&business.Address.Coordinates)...)
+	return append(dest,
+		&business.RegistrationStatus,
+		&business.Verified,
+		(*[]byte)(&business.RegistrySnapshot),
+		&business.RegistryCheckedAt,
+		&business.VerificationStatus,
+		&business.VerificationReason,
+		&business.VerificationReviewedBy,
+		&business.VerificationReviewedAt,
+		&business.Profile.Slug,
+		&business.Profile.Description,
+		&business.Profile.Sector,
+		&business.Profile.Size,
+		&business.Profile.LogoURL,
+		&business.Profile.Website,
+		socialLinks,
+		&business.Profile.Public,
+		&business.LogoFileID,
 		&business.Version,
 		&business.Deleted,
 		&business.CreatedBy,
@@ -69,20 +135,43 @@ func scanBusinessPage(r *sql.Rows, totalRecords *int, business *models.Business)
 	)
 }
 
+func decodeSocialLinks(raw []byte, business *models.Business) error {
+	business.Profile.SocialLinks = map[string]string{}
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, &business.Profile.SocialLinks)
+}
+
+func encodeSocialLinks(links map[string]string) []byte {
+	if links == nil {
+		return []byte("{}")
+	}
+	raw, _ := json.Marshal(links)
+	return raw
+}
+
+// profileArg is the value to update a profile column with, or nil to keep
+// what it has when the update left the field out.
+func profileArg(given bool, value any) any {
+	if !given {
+		return nil
+	}
+	return value
+}
+
+func scanBusinessPage(r *sql.Rows, totalRecords *int, business *models.Business) error {
+	var socialLinks []byte
+	dest := append([]any{totalRecords}, businessDest(business, &socialLinks)...)
+	if err := r.Scan(dest...); err != nil {
+		return err
+	}
+	return decodeSocialLinks(socialLinks, business)
+}
+
 func scanBusiness(r *sql.Row, business *models.Business) error {
-	err := r.Scan(
-		&business.ID,
-		&business.Name,
-		&business.CNPJ,
-		&business.Email,
-		&business.Phone,
-		&business.Version,
-		&business.Deleted,
-		&business.CreatedBy,
-		&business.CreatedAt,
-		&business.UpdatedBy,
-		&business.UpdatedAt,
-	)
+	var socialLinks []byte
+	err := r.Scan(businessDest(business, &socialLinks)...)
 
 	if err != nil {
 		switch {
@@ -92,7 +181,29 @@ func scanBusiness(r *sql.Row, business *models.Business) error {
 			return err
 		}
 	}
-	return nil
+	return decodeSocialLinks(socialLinks, business)
+}
+
+// scanPublicBusiness reads the rows of the public queries, which append the
+// open vacancies count to SQLSelectDataBusiness.
+func scanPublicBusiness(r scanner, totalRecords *int, business *models.Business) error {
+	var socialLinks []byte
+	dest := businessDest(business, &socialLinks)
+	if totalRecords != nil {
+		dest = append([]any{totalRecords}, dest...)
+	}
+	dest = append(dest, &business.OpenVacancies)
+
+	err := r.Scan(dest...)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return decodeSocialLinks(socialLinks, business)
 }
 
 func (r *businessRepository) AddUserInBusiness(businessID, userID, userLogadoID int64, tx *sql.Tx) error {
@@ -216,6 +327,46 @@ func (r *businessRepository) GetAll(
 	return businessList, metaData, nil
 }
 
+func (r *businessRepository) GetAllByUser(userID int64) ([]*models.Business, error) {
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s
+		from business b
+		join business_users bu on bu.business_id = b.id
+		where
+			bu.user_id = $1
+			and b.deleted = false
+		order by b.id
+	`, SQLSelectDataBusiness)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	businessList := []*models.Business{}
+
+	for rows.Next() {
+		business := models.Business{}
+		if err := scanBusinessPage(rows, &totalRecords, &business); err != nil {
+			return nil, err
+		}
+		businessList = append(businessList, &business)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return businessList, nil
+}
+
 func (r *businessRepository) Insert(business *models.Business, userID int64, tx *sql.Tx) error {
 	query := `
 	insert into business (
@@ -223,21 +374,68 @@ func (r *businessRepository) Insert(business *models.Business, userID int64, tx
 		cnpj,
 		email,
 		phone,
-		created_by
+		legal_name,
+		trade_name,
+		address_cep,
+		address_street,
+		address_number,
+		address_complement,
+		address_district,
+		address_city,
+		address_uf,
+		registration_status,
+		verified,
+		registry_snapshot,
+		registry_checked_at,
+		slug,
+		description,
+		sector,
+		size,
+		logo_url,
+		website,
+		social_links,
+		public,
+		created_by,
+		address_latitude,
+		address_longitude
 	)
-	values ($1,$2,$3,$4,$5)
+	values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28)
 	returning 
 		id, 
 		created_at,
 		version
 	`
 
+	latitude, longitude := coordinatesArgs(business.Address.Coordinates)
 	args := []any{
 		business.Name,
 		business.CNPJ,
 		business.Email,
 		business.Phone,
+		business.LegalName,
+		business.TradeName,
+		business.Address.CEP,
+		business.Address.Street,
+		business.Address.Number,
+		business.Address.Complement,
+		business.Address.District,
+		business.Address.City,
+		business.Address.UF,
+		business.RegistrationStatus,
+		business.Verified,
+		[]byte(business.RegistrySnapshot),
+		business.RegistryCheckedAt,
+		business.Profile.Slug,
+		business.Profile.Description,
+		business.Profile.Sector,
+		business.Profile.Size,
+		business.Profile.LogoURL,
+		business.Profile.Website,
+		encodeSocialLinks(business.Profile.SocialLinks),
+		business.Profile.Public,
 		userID,
+		latitude,
+		longitude,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -272,6 +470,29 @@ func (r *businessRepository) Update(business *models.Business, userID int64, tx
 		cnpj = $2,
 		email = $3,
 		phone = $4,
+		address_cep = $8,
+		address_street = $9,
+		address_number = $10,
+		address_complement = $11,
+		address_district = $12,
+		address_city = $13,
+		address_uf = $14,
+		slug = coalesce(nullif($15, ''), slug),
+		description = coalesce($16, description),
+		sector = coalesce($17, sector),
+		size = coalesce($18, size),
+		logo_url = coalesce($19, logo_url),
+		website = coalesce($20, website),
+		social_links = coalesce($21, social_links),
+		address_latitude = $22,
+		address_longitude = $23,
+		legal_name = $24,
+		trade_name = $25,
+		registration_status = $26,
+		registry_snapshot = $27,
+		registry_checked_at = $28,
+		verified = $29,
+		verification_status = case when cnpj = $2 and name = $1 then verification_status else 'unverified' end,
 		updated_by = $5,	
 		updated_at=now(),
 		version = version + 1	
@@ -286,9 +507,22 @@ func (r *businessRepository) Update(business *models.Business, userID int64, tx
 		)
 		and deleted = false
 		and version = $7
-	returning version
+	returning
+		version,
+		verified,
+		verification_status,
+		slug,
+		description,
+		sector,
+		size,
+		logo_url,
+		website,
+		social_links,
+		public
 	`
 
+	latitude, longitude := coordinatesArgs(business.Address.Coordinates)
+	given := business.ProfileGiven
 	args := []any{
 		business.Name,
 		business.CNPJ,
@@ -297,16 +531,55 @@ func (r *businessRepository) Update(business *models.Business, userID int64, tx
 		userID,
 		business.ID,
 		business.Version,
+		business.Address.CEP,
+		business.Address.Street,
+		business.Address.Number,
+		business.Address.Complement,
+		business.Address.District,
+		business.Address.City,
+		business.Address.UF,
+		business.Profile.Slug,
+		profileArg(given.Description, business.Profile.Description),
+		profileArg(given.Sector, business.Profile.Sector),
+		profileArg(given.Size, string(business.Profile.Size)),
+		profileArg(given.LogoURL, business.Profile.LogoURL),
+		profileArg(given.Website, business.Profile.Website),
+		profileArg(given.SocialLinks, encodeSocialLinks(business.Profile.SocialLinks)),
+		latitude,
+		longitude,
+		business.LegalName,
+		business.TradeName,
+		business.RegistrationStatus,
+		[]byte(business.RegistrySnapshot),
+		business.RegistryCheckedAt,
+		business.Verified,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
+	// The registry data comes checked from the service. Changing the CNPJ
+	// or the name also drops the review: the documents on file prove who
+	// the business was, not who it now claims to be.
+	var socialLinks []byte
 	err := tx.QueryRowContext(ctx, query, args...).Scan(
 		&business.Version,
+		&business.Verified,
+		&business.VerificationStatus,
+		&business.Profile.Slug,
+		&business.Profile.Description,
+		&business.Profile.Sector,
+		&business.Profile.Size,
+		&business.Profile.LogoURL,
+		&business.Profile.Website,
+		&socialLinks,
+		&business.Profile.Public,
 	)
+	if err != nil {
+		return r.uniqueErrors(err)
+	}
 
-	return r.uniqueErrors(err)
+	return decodeSocialLinks(socialLinks, business)
 }
 
 func (r *businessRepository) Delete(id, userID int64, tx *sql.Tx) error {
@@ -342,6 +615,187 @@ func (r *businessRepository) Delete(id, userID int64, tx *sql.Tx) error {
 	return nil
 }
 
+func (r *businessRepository) SlugExists(slug string) (bool, error) {
+	query := `
+	select exists (
+		select 1
+		from business b
+		where
+			b.slug = $1
+			and b.deleted = false
+	)
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, slug).Scan(&exists)
+	return exists, err
+}
+
+func (r *businessRepository) SetPublic(id, userID int64, public bool, tx *sql.Tx) error {
+	query := `
+	update business
+	set
+		public = $3,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and exists (
+			select 1
+			from business_users bu
+			where
+				bu.business_id = $1
+				and bu.user_id = $2
+		)
+		and deleted = false
+	returning id
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var returnedID int64
+	err := tx.QueryRowContext(ctx, query, id, userID, public).Scan(&returnedID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *businessRepository) SetLogo(id, userID, fileID int64, tx *sql.Tx) error {
+	query := `
+	update business
+	set
+		logo_file_id = $3,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and exists (
+			select 1
+			from business_users bu
+			where
+				bu.business_id = $1
+				and bu.user_id = $2
+		)
+		and deleted = false
+	returning id
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var returnedID int64
+	err := tx.QueryRowContext(ctx, query, id, userID, fileID).Scan(&returnedID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SQLSelectOpenVacancies is appended to the public selects: the count of
+// published jobs.
+const SQLSelectOpenVacancies = `
+		(
+			select count(*)
+			from jobs j
+			where
+				j.business_id = b.id
+				and j.status = 'published'
+				and j.deleted = false
+		)
+	`
+
+func (r *businessRepository) GetPublicBySlug(slug string) (*models.Business, error) {
+	query := fmt.Sprintf(`
+	select
+		%s,
+		%s
+	from business b
+	where
+		b.slug = $1
+		and b.public = true
+		and b.deleted = false
+	`, SQLSelectDataBusiness, SQLSelectOpenVacancies)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	business := models.Business{}
+	if err := scanPublicBusiness(r.db.QueryRowContext(ctx, query, slug), nil, &business); err != nil {
+		return nil, err
+	}
+
+	return &business, nil
+}
+
+func (r *businessRepository) GetAllPublic(
+	name,
+	sector,
+	city string,
+	f filters.Filters,
+) ([]*models.Business, filters.Metadata, error) {
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s,
+			%s
+		from business b
+		where
+			b.public = true
+			and b.deleted = false
+			and (to_tsvector('simple', b.name) @@ plainto_tsquery('simple', $1) OR $1 = '')
+			and (lower(b.sector) = lower($2) OR $2 = '')
+			and (lower(b.address_city) = lower($3) OR $3 = '')
+		order by %s %s, b.id
+		limit $4 offset $5
+	`, SQLSelectDataBusiness, SQLSelectOpenVacancies, f.SortColumn(), f.SortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, name, sector, city, f.Limit(), f.Offset())
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	businessList := []*models.Business{}
+
+	for rows.Next() {
+		business := models.Business{}
+		if err := scanPublicBusiness(rows, &totalRecords, &business); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		businessList = append(businessList, &business)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	metaData := filters.CalculateMetadata(totalRecords, f.Page, f.PageSize)
+	return businessList, metaData, nil
+}
+
 func (r *businessRepository) uniqueErrors(err error) error {
 	if pqErr, ok := err.(*pq.Error); ok {
 		switch pqErr.Constraint {
@@ -351,7 +805,51 @@ func (r *businessRepository) uniqueErrors(err error) error {
 			return e.ErrDuplicateCNPJ
 		case "unique_business_email_deleted":
 			return e.ErrDuplicateEmail
+		case "unique_business_slug":
+			return e.ErrDuplicateSlug
 		}
 	}
 	return err
 }
+
+// GetMembers lists the active users of the business, to reach them by
+// email.
+func (r *businessRepository) GetMembers(id int64) ([]models.Participant, error) {
+	query := `
+	select
+		u.id,
+		u.name,
+		u.email
+	from business_users bu
+	join users u on u.id = bu.user_id
+	where
+		bu.business_id = $1
+		and u.activated = true
+		and u.deleted = false
+	order by u.id
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.Participant{}
+	for rows.Next() {
+		var p models.Participant
+		if err := rows.Scan(&p.UserID, &p.Name, &p.Email); err != nil {
+			return nil, err
+		}
+		members = append(members, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
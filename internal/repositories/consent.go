@@ -0,0 +1,253 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"meu_job/internal/models"
+	e "meu_job/utils/errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type consentRepository struct {
+	db *sql.DB
+}
+
+type ConsentRepositoryInterface interface {
+	GetCurrentDocuments() ([]*models.LegalDocument, error)
+	InsertDocument(doc *models.LegalDocument, tx *sql.Tx) error
+	GetAllByUser(userID int64) ([]*models.Consent, error)
+	GetPurposes(userID int64) (map[models.ConsentPurpose]bool, error)
+	Insert(consent *models.Consent, tx *sql.Tx) error
+}
+
+func NewConsentRepository(db *sql.DB) *consentRepository {
+	return &consentRepository{
+		db: db,
+	}
+}
+
+func (r *consentRepository) GetCurrentDocuments() ([]*models.LegalDocument, error) {
+	query := `
+	select distinct on (d.kind)
+		d.id,
+		d.kind,
+		d.version,
+		d.title,
+		d.content,
+		d.published_at,
+		d.created_by,
+		d.created_at
+	from legal_documents d
+	where d.published_at <= now()
+	order by d.kind, d.version desc
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []*models.LegalDocument{}
+	for rows.Next() {
+		doc := models.LegalDocument{}
+		err := rows.Scan(
+			&doc.ID,
+			&doc.Kind,
+			&doc.Version,
+			&doc.Title,
+			&doc.Content,
+			&doc.PublishedAt,
+			&doc.CreatedBy,
+			&doc.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+func (r *consentRepository) InsertDocument(doc *models.LegalDocument, tx *sql.Tx) error {
+	query := `
+	insert into legal_documents (
+		kind,
+		version,
+		title,
+		content,
+		created_by
+	)
+	select
+		$1,
+		coalesce(max(version), 0) + 1,
+		$2,
+		$3,
+		$4
+	from legal_documents
+	where kind = $1
+	returning
+		id,
+		version,
+		published_at,
+		created_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := tx.QueryRowContext(ctx, query, doc.Kind, doc.Title, doc.Content, doc.CreatedBy).Scan(
+		&doc.ID,
+		&doc.Version,
+		&doc.PublishedAt,
+		&doc.CreatedAt,
+	)
+
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "unique_legal_documents_kind_version" {
+		return e.ErrEditConflict
+	}
+
+	return err
+}
+
+func (r *consentRepository) GetAllByUser(userID int64) ([]*models.Consent, error) {
+	query := `
+	select
+		c.id,
+		c.user_id,
+		c.subject,
+		c.document_id,
+		c.granted,
+		c.ip,
+		c.user_agent,
+		c.created_at
+	from consents c
+	where c.user_id = $1
+	order by c.created_at desc, c.id desc
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consents := []*models.Consent{}
+	for rows.Next() {
+		consent := models.Consent{}
+		err := rows.Scan(
+			&consent.ID,
+			&consent.UserID,
+			&consent.Subject,
+			&consent.DocumentID,
+			&consent.Granted,
+			&consent.IP,
+			&consent.UserAgent,
+			&consent.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		consents = append(consents, &consent)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return consents, nil
+}
+
+func (r *consentRepository) GetPurposes(userID int64) (map[models.ConsentPurpose]bool, error) {
+	query := `
+	select distinct on (c.subject)
+		c.subject,
+		c.granted
+	from consents c
+	where
+		c.user_id = $1
+		and c.subject = any($2)
+	order by c.subject, c.created_at desc, c.id desc
+	`
+
+	purposes := make([]string, len(models.ConsentPurposes))
+	for i, purpose := range models.ConsentPurposes {
+		purposes[i] = string(purpose)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(purposes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	state := make(map[models.ConsentPurpose]bool, len(models.ConsentPurposes))
+	for _, purpose := range models.ConsentPurposes {
+		state[purpose] = false
+	}
+
+	for rows.Next() {
+		var subject string
+		var granted bool
+		if err := rows.Scan(&subject, &granted); err != nil {
+			return nil, err
+		}
+		state[models.ConsentPurpose(subject)] = granted
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (r *consentRepository) Insert(consent *models.Consent, tx *sql.Tx) error {
+	query := `
+	insert into consents (
+		user_id,
+		subject,
+		document_id,
+		granted,
+		ip,
+		user_agent
+	)
+	values ($1,$2,$3,$4,$5,$6)
+	returning
+		id,
+		created_at
+	`
+
+	args := []any{
+		consent.UserID,
+		consent.Subject,
+		consent.DocumentID,
+		consent.Granted,
+		consent.IP,
+		consent.UserAgent,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, args...).Scan(
+		&consent.ID,
+		&consent.CreatedAt,
+	)
+}
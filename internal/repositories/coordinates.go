@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"database/sql"
+	"meu_job/internal/models"
+)
+
+// coordinateDest scans the latitude or the longitude column into a
+// *models.Coordinates, which is only allocated once a column isn't null.
+type coordinateDest struct {
+	c         **models.Coordinates
+	longitude bool
+}
+
+func (d coordinateDest) Scan(src any) error {
+	var value sql.NullFloat64
+	if err := value.Scan(src); err != nil || !value.Valid {
+		return err
+	}
+
+	if *d.c == nil {
+		*d.c = &models.Coordinates{}
+	}
+	if d.longitude {
+		(*d.c).Longitude = value.Float64
+	} else {
+		(*d.c).Latitude = value.Float64
+	}
+	return nil
+}
+
+// coordinatesDest is the pair of scan targets for the latitude and the
+// longitude columns, in that order.
+func coordinatesDest(c **models.Coordinates) []any {
+	return []any{coordinateDest{c: c}, coordinateDest{c: c, longitude: true}}
+}
+
+// coordinatesArgs are the latitude and longitude to write, both null for
+// places not geocoded.
+func coordinatesArgs(c *models.Coordinates) (any, any) {
+	if c == nil {
+		return nil, nil
+	}
+	return c.Latitude, c.Longitude
+}
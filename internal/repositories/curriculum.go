@@ -0,0 +1,733 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	e "meu_job/utils/errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type curriculumRepository struct {
+	db *sql.DB
+}
+
+type CurriculumRepositoryInterface interface {
+	GetByID(id int64) (*models.Curriculum, error)
+	GetByOwner(id, userID int64) (*models.Curriculum, error)
+	GetAllByUser(userID int64) ([]*models.Curriculum, error)
+	Insert(c *models.Curriculum, userID int64, tx *sql.Tx) error
+	Update(c *models.Curriculum, userID int64, tx *sql.Tx) error
+	Delete(id, userID int64, tx *sql.Tx) error
+	SetDefault(id, userID int64, tx *sql.Tx) error
+	GetDefaultsAfter(afterID int64, limit int) ([]*models.Curriculum, error)
+	ClaimUnmatched(limit int, tx *sql.Tx) ([]*models.Curriculum, error)
+	MarkMatched(id int64, version int, tx *sql.Tx) error
+	GetRevisions(curriculumID int64) ([]*models.CurriculumRevision, error)
+	GetRevision(curriculumID int64, version int) (*models.CurriculumRevision, error)
+	EraseAllByUser(userID int64, tx *sql.Tx) error
+}
+
+func NewCurriculumRepository(db *sql.DB) *curriculumRepository {
+	return &curriculumRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataCurriculum = `
+		c.id,
+		c.user_id,
+		c.name,
+		c.is_default,
+		c.full_name,
+		c.email,
+		c.phone,
+		c.cpf,
+		c.birth_date,
+		c.summary,
+		c.profession,
+		c.cep,
+		c.street,
+		c.district,
+		c.city,
+		c.uf,
+		c.latitude,
+		c.longitude,
+		c.salary_expectation,
+		c.experience,
+		c.education,
+		c.skills,
+		c.languages,
+		c.version,
+		c.deleted,
+		c.created_by,
+		c.created_at,
+		c.updated_by,
+		c.updated_at
+	`
+
+// curriculumEntries holds the jsonb columns, stored in the same shape the
+// API uses for them.
+type curriculumEntries struct {
+	experience []byte
+	education  []byte
+	skills     []byte
+	languages  []byte
+}
+
+func encodeCurriculumEntries(c *models.Curriculum) (curriculumEntries, error) {
+	dto := c.ToDTO()
+
+	var entries curriculumEntries
+	var err error
+	if entries.experience, err = json.Marshal(dto.Experience); err != nil {
+		return entries, err
+	}
+	if entries.education, err = json.Marshal(dto.Education); err != nil {
+		return entries, err
+	}
+	if entries.skills, err = json.Marshal(dto.Skills); err != nil {
+		return entries, err
+	}
+	if entries.languages, err = json.Marshal(dto.Languages); err != nil {
+		return entries, err
+	}
+	return entries, nil
+}
+
+func (entries curriculumEntries) decode(c *models.Curriculum) error {
+	dto := models.CurriculumDTO{}
+	if err := json.Unmarshal(entries.experience, &dto.Experience); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(entries.education, &dto.Education); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(entries.skills, &dto.Skills); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(entries.languages, &dto.Languages); err != nil {
+		return err
+	}
+
+	decoded := dto.ToModel()
+	c.Experience = decoded.Experience
+	c.Education = decoded.Education
+	c.Skills = decoded.Skills
+	c.Languages = decoded.Languages
+	return nil
+}
+
+func curriculumDest(c *models.Curriculum, entries *curriculumEntries) []any {
+	dest := []any{
+		&c.ID,
+		&c.User.ID,
+		&c.Name,
+		&c.IsDefault,
+		&c.FullName,
+		&c.Email,
+		&c.Phone,
+		&c.CPF,
+		&c.BirthDate,
+		&c.Summary,
+		&c.Profession,
+		&c.CEP,
+		&c.Street,
+		&c.District,
+		&c.City,
+		&c.UF,
+	}
+	dest = append(dest, coordinatesDest(&c.Coordinates)...)
+	return append(dest,
+		&c.SalaryExpectation,
+		&entries.experience,
+		&entries.education,
+		&entries.skills,
+		&entries.languages,
+		&c.Version,
+		&c.Deleted,
+		&c.CreatedBy,
+		&c.CreatedAt,
+		&c.UpdatedBy,
+		&c.UpdatedAt,
+	)
+}
+
+func scanCurriculum(r scanner, c *models.Curriculum) error {
+	var entries curriculumEntries
+	err := r.Scan(curriculumDest(c, &entries)...)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return entries.decode(c)
+}
+
+func (r *curriculumRepository) getOne(where string, args ...any) (*models.Curriculum, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from curricula c
+	where
+		%s
+		and c.deleted = false
+	`, SQLSelectDataCurriculum, where)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	c := models.Curriculum{}
+	if err := scanCurriculum(r.db.QueryRowContext(ctx, query, args...), &c); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// GetByID ignores ownership; callers must have authorized the access some
+// other way (a recruiter the candidate shares curricula with).
+func (r *curriculumRepository) GetByID(id int64) (*models.Curriculum, error) {
+	return r.getOne("c.id = $1", id)
+}
+
+func (r *curriculumRepository) GetByOwner(id, userID int64) (*models.Curriculum, error) {
+	return r.getOne("c.id = $1 and c.user_id = $2", id, userID)
+}
+
+func (r *curriculumRepository) GetAllByUser(userID int64) ([]*models.Curriculum, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from curricula c
+	where
+		c.user_id = $1
+		and c.deleted = false
+	order by c.is_default desc, c.created_at, c.id
+	`, SQLSelectDataCurriculum)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectCurricula(rows)
+}
+
+func collectCurricula(rows *sql.Rows) ([]*models.Curriculum, error) {
+	curricula := []*models.Curriculum{}
+	for rows.Next() {
+		c := models.Curriculum{}
+		if err := scanCurriculum(rows, &c); err != nil {
+			return nil, err
+		}
+		curricula = append(curricula, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return curricula, nil
+}
+
+// GetDefaultsAfter pages through the default curricula by id, for matching
+// a job against every candidate.
+func (r *curriculumRepository) GetDefaultsAfter(afterID int64, limit int) ([]*models.Curriculum, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from curricula c
+	where
+		c.id > $1
+		and c.is_default
+		and c.deleted = false
+	order by c.id
+	limit $2
+	`, SQLSelectDataCurriculum)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectCurricula(rows)
+}
+
+// ClaimUnmatched locks default curricula changed since they were last
+// matched, skipping those another replica is already matching.
+func (r *curriculumRepository) ClaimUnmatched(limit int, tx *sql.Tx) ([]*models.Curriculum, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from curricula c
+	where
+		c.is_default
+		and c.deleted = false
+		and c.matched_version is distinct from c.version
+	order by c.id
+	limit $1
+	for update skip locked
+	`, SQLSelectDataCurriculum)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectCurricula(rows)
+}
+
+func (r *curriculumRepository) MarkMatched(id int64, version int, tx *sql.Tx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `update curricula set matched_version = $2 where id = $1`, id, version)
+	return err
+}
+
+// Insert makes the curriculum the default when the user has no other.
+func (r *curriculumRepository) Insert(c *models.Curriculum, userID int64, tx *sql.Tx) error {
+	entries, err := encodeCurriculumEntries(c)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	insert into curricula (
+		user_id,
+		name,
+		is_default,
+		full_name,
+		email,
+		phone,
+		cpf,
+		birth_date,
+		summary,
+		profession,
+		city,
+		uf,
+		salary_expectation,
+		experience,
+		education,
+		skills,
+		languages,
+		created_by,
+		cep,
+		street,
+		district,
+		latitude,
+		longitude
+	)
+	values (
+		$1,$2,
+		not exists (select 1 from curricula where user_id = $1 and is_default and not deleted),
+		$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$1,$17,$18,$19,$20,$21
+	)
+	returning
+		id,
+		is_default,
+		created_at,
+		version
+	`
+
+	latitude, longitude := coordinatesArgs(c.Coordinates)
+	args := []any{
+		userID,
+		c.Name,
+		c.FullName,
+		c.Email,
+		c.Phone,
+		c.CPF,
+		c.BirthDate,
+		c.Summary,
+		c.Profession,
+		c.City,
+		c.UF,
+		c.SalaryExpectation,
+		entries.experience,
+		entries.education,
+		entries.skills,
+		entries.languages,
+		c.CEP,
+		c.Street,
+		c.District,
+		latitude,
+		longitude,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	c.User.ID = userID
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
+		&c.ID,
+		&c.IsDefault,
+		&c.CreatedAt,
+		&c.Version,
+	)
+	if err != nil {
+		// Only when two first curricula are saved at the same time.
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "idx_curricula_default" {
+			return e.ErrEditConflict
+		}
+		return err
+	}
+
+	return r.insertRevision(c, userID, tx)
+}
+
+// Update only succeeds against the version the client last read. The
+// default flag is left as is, see SetDefault.
+func (r *curriculumRepository) Update(c *models.Curriculum, userID int64, tx *sql.Tx) error {
+	entries, err := encodeCurriculumEntries(c)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	update curricula
+	set
+		name = $3,
+		full_name = $4,
+		email = $5,
+		phone = $6,
+		cpf = $7,
+		birth_date = $8,
+		summary = $9,
+		profession = $10,
+		city = $11,
+		uf = $12,
+		salary_expectation = $13,
+		experience = $14,
+		education = $15,
+		skills = $16,
+		languages = $17,
+		cep = $19,
+		street = $20,
+		district = $21,
+		latitude = $22,
+		longitude = $23,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and user_id = $2
+		and version = $18
+		and deleted = false
+	returning
+		is_default,
+		version,
+		created_at,
+		updated_at
+	`
+
+	latitude, longitude := coordinatesArgs(c.Coordinates)
+	args := []any{
+		c.ID,
+		userID,
+		c.Name,
+		c.FullName,
+		c.Email,
+		c.Phone,
+		c.CPF,
+		c.BirthDate,
+		c.Summary,
+		c.Profession,
+		c.City,
+		c.UF,
+		c.SalaryExpectation,
+		entries.experience,
+		entries.education,
+		entries.skills,
+		entries.languages,
+		c.Version,
+		c.CEP,
+		c.Street,
+		c.District,
+		latitude,
+		longitude,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	c.User.ID = userID
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
+		&c.IsDefault,
+		&c.Version,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return r.insertRevision(c, userID, tx)
+}
+
+// insertRevision snapshots c as just saved, in the shape the API returns
+// it.
+func (r *curriculumRepository) insertRevision(c *models.Curriculum, userID int64, tx *sql.Tx) error {
+	snapshot, err := json.Marshal(c.ToDTO())
+	if err != nil {
+		return err
+	}
+
+	query := `
+	insert into curriculum_revisions (
+		curriculum_id,
+		version,
+		snapshot,
+		created_by
+	)
+	values ($1,$2,$3,$4)
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err = tx.ExecContext(ctx, query, c.ID, c.Version, snapshot, userID)
+	return err
+}
+
+func (r *curriculumRepository) Delete(id, userID int64, tx *sql.Tx) error {
+	query := `
+	update curricula
+	set
+		deleted = true,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and user_id = $2
+		and deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return e.ErrRecordNotFound
+	}
+
+	// When the default goes, the most recently edited of the others
+	// takes its place.
+	query = `
+	update curricula
+	set
+		is_default = true,
+		matched_version = null
+	where id = (
+		select c.id
+		from curricula c
+		where
+			c.user_id = $1
+			and c.deleted = false
+		order by coalesce(c.updated_at, c.created_at) desc, c.id desc
+		limit 1
+	)
+	and not exists (
+		select 1 from curricula where user_id = $1 and is_default and not deleted
+	)
+	`
+
+	_, err = tx.ExecContext(ctx, query, userID)
+	return err
+}
+
+// SetDefault makes the curriculum the default and unsets the previous one.
+// It isn't an edit of either, so their versions stay.
+func (r *curriculumRepository) SetDefault(id, userID int64, tx *sql.Tx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+	update curricula
+	set is_default = false
+	where
+		user_id = $2
+		and id <> $1
+		and is_default
+		and deleted = false
+	`
+
+	if _, err := tx.ExecContext(ctx, query, id, userID); err != nil {
+		return err
+	}
+
+	// The new default hasn't been matched to the jobs yet.
+	query = `
+	update curricula
+	set
+		is_default = true,
+		matched_version = null
+	where
+		id = $1
+		and user_id = $2
+		and deleted = false
+	`
+
+	result, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return e.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func scanCurriculumRevision(r scanner, rev *models.CurriculumRevision) error {
+	var snapshot []byte
+	err := r.Scan(
+		&rev.ID,
+		&rev.CurriculumID,
+		&rev.Version,
+		&snapshot,
+		&rev.CreatedBy,
+		&rev.CreatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	dto := models.CurriculumDTO{}
+	if err := json.Unmarshal(snapshot, &dto); err != nil {
+		return err
+	}
+	rev.Curriculum = dto.ToModel()
+	rev.Curriculum.User.ID = dto.UserID
+	rev.Curriculum.IsDefault = dto.IsDefault
+	rev.Curriculum.CreatedAt = dto.CreatedAt
+	rev.Curriculum.UpdatedAt = dto.UpdatedAt
+	return nil
+}
+
+// GetRevisions lists the revisions newest first. Callers must have checked
+// the curriculum belongs to the user.
+func (r *curriculumRepository) GetRevisions(curriculumID int64) ([]*models.CurriculumRevision, error) {
+	query := `
+	select
+		id,
+		curriculum_id,
+		version,
+		snapshot,
+		created_by,
+		created_at
+	from curriculum_revisions
+	where curriculum_id = $1
+	order by version desc
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, curriculumID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*models.CurriculumRevision{}
+	for rows.Next() {
+		rev := models.CurriculumRevision{}
+		if err := scanCurriculumRevision(rows, &rev); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, &rev)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (r *curriculumRepository) GetRevision(curriculumID int64, version int) (*models.CurriculumRevision, error) {
+	query := `
+	select
+		id,
+		curriculum_id,
+		version,
+		snapshot,
+		created_by,
+		created_at
+	from curriculum_revisions
+	where
+		curriculum_id = $1
+		and version = $2
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rev := models.CurriculumRevision{}
+	if err := scanCurriculumRevision(r.db.QueryRowContext(ctx, query, curriculumID, version), &rev); err != nil {
+		return nil, err
+	}
+
+	return &rev, nil
+}
+
+// EraseAllByUser removes the rows for good: unlike a soft delete, erasure
+// must not leave the personal data behind.
+func (r *curriculumRepository) EraseAllByUser(userID int64, tx *sql.Tx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `delete from curricula where user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,396 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	e "meu_job/utils/errors"
+	"time"
+)
+
+type curriculumDraftRepository struct {
+	db *sql.DB
+}
+
+type CurriculumDraftRepositoryInterface interface {
+	GetByOwner(id, userID int64) (*models.CurriculumDraft, error)
+	GetAllByUser(userID int64) ([]*models.CurriculumDraft, error)
+	ClaimPending(limit int, lease time.Duration, tx *sql.Tx) ([]*models.CurriculumDraft, error)
+	Insert(d *models.CurriculumDraft, tx *sql.Tx) error
+	SaveResult(d *models.CurriculumDraft, tx *sql.Tx) error
+	Confirm(d *models.CurriculumDraft, userID int64, tx *sql.Tx) error
+	Delete(id, userID int64, tx *sql.Tx) error
+	EraseAllByUser(userID int64, tx *sql.Tx) error
+}
+
+func NewCurriculumDraftRepository(db *sql.DB) *curriculumDraftRepository {
+	return &curriculumDraftRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataCurriculumDraft = `
+		d.id,
+		d.user_id,
+		d.file_id,
+		d.status,
+		d.curriculum,
+		d.confidence,
+		d.unparsed,
+		d.error,
+		d.attempts,
+		d.processed_at,
+		d.curriculum_id,
+		d.version,
+		d.deleted,
+		d.created_by,
+		d.created_at,
+		d.updated_by,
+		d.updated_at
+	`
+
+// draftResult holds the jsonb columns. The curriculum is stored as its DTO,
+// like the entries of the curricula table.
+type draftResult struct {
+	curriculum []byte
+	confidence []byte
+	unparsed   []byte
+}
+
+func encodeDraftResult(d *models.CurriculumDraft) (draftResult, error) {
+	var result draftResult
+	var err error
+	if d.Curriculum != nil {
+		if result.curriculum, err = json.Marshal(d.Curriculum.ToDTO()); err != nil {
+			return result, err
+		}
+	}
+	if result.confidence, err = json.Marshal(d.Confidence); err != nil {
+		return result, err
+	}
+	if result.unparsed, err = json.Marshal(d.Unparsed); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (result draftResult) decode(d *models.CurriculumDraft) error {
+	if result.curriculum != nil {
+		dto := models.CurriculumDTO{}
+		if err := json.Unmarshal(result.curriculum, &dto); err != nil {
+			return err
+		}
+		d.Curriculum = dto.ToModel()
+	}
+	if err := json.Unmarshal(result.confidence, &d.Confidence); err != nil {
+		return err
+	}
+	return json.Unmarshal(result.unparsed, &d.Unparsed)
+}
+
+func scanCurriculumDraft(r scanner, d *models.CurriculumDraft) error {
+	var result draftResult
+	err := r.Scan(
+		&d.ID,
+		&d.UserID,
+		&d.FileID,
+		&d.Status,
+		&result.curriculum,
+		&result.confidence,
+		&result.unparsed,
+		&d.Error,
+		&d.Attempts,
+		&d.ProcessedAt,
+		&d.CurriculumID,
+		&d.Version,
+		&d.Deleted,
+		&d.CreatedBy,
+		&d.CreatedAt,
+		&d.UpdatedBy,
+		&d.UpdatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return result.decode(d)
+}
+
+func (r *curriculumDraftRepository) GetByOwner(id, userID int64) (*models.CurriculumDraft, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from curriculum_drafts d
+	where
+		d.id = $1
+		and d.user_id = $2
+		and d.deleted = false
+	`, SQLSelectDataCurriculumDraft)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	d := models.CurriculumDraft{}
+	if err := scanCurriculumDraft(r.db.QueryRowContext(ctx, query, id, userID), &d); err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+func (r *curriculumDraftRepository) GetAllByUser(userID int64) ([]*models.CurriculumDraft, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from curriculum_drafts d
+	where
+		d.user_id = $1
+		and d.deleted = false
+	order by d.created_at desc, d.id desc
+	`, SQLSelectDataCurriculumDraft)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectCurriculumDrafts(rows)
+}
+
+func collectCurriculumDrafts(rows *sql.Rows) ([]*models.CurriculumDraft, error) {
+	drafts := []*models.CurriculumDraft{}
+	for rows.Next() {
+		d := models.CurriculumDraft{}
+		if err := scanCurriculumDraft(rows, &d); err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return drafts, nil
+}
+
+// ClaimPending takes the oldest pending drafts for the length of lease and
+// counts the attempt. Drafts another instance is taking are skipped, so
+// each is parsed once; they're parsed after tx commits, so no lock is held
+// meanwhile.
+func (r *curriculumDraftRepository) ClaimPending(limit int, lease time.Duration, tx *sql.Tx) ([]*models.CurriculumDraft, error) {
+	query := fmt.Sprintf(`
+	update curriculum_drafts d
+	set
+		claimed_until = now() + $2::int * interval '1 second',
+		attempts = attempts + 1
+	where d.id in (
+		select q.id
+		from curriculum_drafts q
+		where
+			q.status = 'pending'
+			and q.deleted = false
+			and (q.claimed_until is null or q.claimed_until <= now())
+		order by q.created_at, q.id
+		limit $1
+		for update skip locked
+	)
+	returning
+		%s
+	`, SQLSelectDataCurriculumDraft)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, query, limit, int(lease.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectCurriculumDrafts(rows)
+}
+
+func (r *curriculumDraftRepository) Insert(d *models.CurriculumDraft, tx *sql.Tx) error {
+	query := `
+	insert into curriculum_drafts (
+		user_id,
+		file_id,
+		created_by
+	)
+	values ($1,$2,$1)
+	returning
+		id,
+		status,
+		created_at,
+		version
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, d.UserID, d.FileID).Scan(
+		&d.ID,
+		&d.Status,
+		&d.CreatedAt,
+		&d.Version,
+	)
+}
+
+// SaveResult records the outcome of parsing a claimed draft and lets go of
+// it.
+func (r *curriculumDraftRepository) SaveResult(d *models.CurriculumDraft, tx *sql.Tx) error {
+	result, err := encodeDraftResult(d)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	update curriculum_drafts
+	set
+		status = $2,
+		curriculum = $3,
+		confidence = $4,
+		unparsed = $5,
+		error = $6,
+		attempts = $7,
+		processed_at = $8,
+		claimed_until = null,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+	returning
+		version,
+		updated_at
+	`
+
+	args := []any{
+		d.ID,
+		d.Status,
+		result.curriculum,
+		result.confidence,
+		result.unparsed,
+		d.Error,
+		d.Attempts,
+		d.ProcessedAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, args...).Scan(&d.Version, &d.UpdatedAt)
+}
+
+// Confirm stores the curriculum as the candidate reviewed it and links the
+// one saved from it. It only succeeds against the version the client last
+// read, and once.
+func (r *curriculumDraftRepository) Confirm(d *models.CurriculumDraft, userID int64, tx *sql.Tx) error {
+	result, err := encodeDraftResult(d)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	update curriculum_drafts
+	set
+		status = 'confirmed',
+		curriculum = $3,
+		curriculum_id = $4,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and user_id = $2
+		and version = $5
+		and status in ('ready', 'failed')
+		and deleted = false
+	returning
+		status,
+		version,
+		updated_at
+	`
+
+	args := []any{
+		d.ID,
+		userID,
+		result.curriculum,
+		d.CurriculumID,
+		d.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
+		&d.Status,
+		&d.Version,
+		&d.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *curriculumDraftRepository) Delete(id, userID int64, tx *sql.Tx) error {
+	query := `
+	update curriculum_drafts
+	set
+		deleted = true,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and user_id = $2
+		and deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return e.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// EraseAllByUser removes the rows for good, like the curricula they hold.
+func (r *curriculumDraftRepository) EraseAllByUser(userID int64, tx *sql.Tx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `delete from curriculum_drafts where user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"meu_job/internal/models"
+	"time"
+)
+
+type emailRepository struct {
+	db *sql.DB
+}
+
+type EmailRepositoryInterface interface {
+	Insert(m *models.Email, tx *sql.Tx) error
+	ClaimPending(limit int, tx *sql.Tx) ([]*models.Email, error)
+	MarkSent(id int64, tx *sql.Tx) error
+	MarkFailed(id int64, reason string, retryAt *time.Time, tx *sql.Tx) error
+	EraseAllByUser(userID int64, tx *sql.Tx) error
+}
+
+func NewEmailRepository(db *sql.DB) *emailRepository {
+	return &emailRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataEmail = `
+		m.id,
+		m.user_id,
+		m.to_address,
+		m.subject,
+		m.body,
+		m.headers,
+		m.attachments,
+		m.status,
+		m.attempts,
+		m.last_error,
+		m.send_after,
+		m.sent_at,
+		m.created_at
+	`
+
+func scanEmail(r scanner, m *models.Email) error {
+	var headers, attachments []byte
+	err := r.Scan(
+		&m.ID,
+		&m.UserID,
+		&m.To,
+		&m.Subject,
+		&m.Body,
+		&headers,
+		&attachments,
+		&m.Status,
+		&m.Attempts,
+		&m.LastError,
+		&m.SendAfter,
+		&m.SentAt,
+		&m.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(headers, &m.Headers); err != nil {
+		return err
+	}
+	return json.Unmarshal(attachments, &m.Attachments)
+}
+
+func (r *emailRepository) Insert(m *models.Email, tx *sql.Tx) error {
+	headers, err := json.Marshal(m.Headers)
+	if err != nil {
+		return err
+	}
+	if m.Headers == nil {
+		headers = []byte("{}")
+	}
+	attachments, err := json.Marshal(m.Attachments)
+	if err != nil {
+		return err
+	}
+	if m.Attachments == nil {
+		attachments = []byte("[]")
+	}
+
+	query := `
+	insert into emails (
+		user_id,
+		to_address,
+		subject,
+		body,
+		headers,
+		attachments
+	)
+	values ($1,$2,$3,$4,$5,$6)
+	returning
+		id,
+		status,
+		send_after,
+		created_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, m.UserID, m.To, m.Subject, m.Body, headers, attachments).Scan(
+		&m.ID,
+		&m.Status,
+		&m.SendAfter,
+		&m.CreatedAt,
+	)
+}
+
+// ClaimPending locks the emails due for sending, skipping those another
+// replica is already sending.
+func (r *emailRepository) ClaimPending(limit int, tx *sql.Tx) ([]*models.Email, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from emails m
+	where
+		m.status = 'pending'
+		and m.send_after <= now()
+	order by m.send_after, m.id
+	limit $1
+	for update skip locked
+	`, SQLSelectDataEmail)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := []*models.Email{}
+	for rows.Next() {
+		m := models.Email{}
+		if err := scanEmail(rows, &m); err != nil {
+			return nil, err
+		}
+		emails = append(emails, &m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return emails, nil
+}
+
+func (r *emailRepository) MarkSent(id int64, tx *sql.Tx) error {
+	query := `
+	update emails
+	set
+		status = 'sent',
+		attempts = attempts + 1,
+		last_error = null,
+		sent_at = now()
+	where id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, id)
+	return err
+}
+
+// MarkFailed records a failed attempt. The email is tried again at
+// retryAt, or given up on when it's nil.
+func (r *emailRepository) MarkFailed(id int64, reason string, retryAt *time.Time, tx *sql.Tx) error {
+	query := `
+	update emails
+	set
+		status = case when $3::timestamptz is null then 'failed' else 'pending' end,
+		attempts = attempts + 1,
+		last_error = $2,
+		send_after = coalesce($3, send_after)
+	where id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, id, reason, retryAt)
+	return err
+}
+
+func (r *emailRepository) EraseAllByUser(userID int64, tx *sql.Tx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `delete from emails where user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,303 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	e "meu_job/utils/errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type fileRepository struct {
+	db *sql.DB
+}
+
+type FileRepositoryInterface interface {
+	GetByID(id int64) (*models.File, error)
+	GetByOwner(id, ownerID int64) (*models.File, error)
+	GetByKey(key string) (*models.File, error)
+	GetAllByOwner(ownerID int64) ([]*models.File, error)
+	GetAllByRef(refType string, refIDs []int64) ([]*models.File, error)
+	Insert(file *models.File, tx *sql.Tx) error
+	Delete(id, ownerID int64, tx *sql.Tx) error
+	Attach(ids []int64, ownerID int64, refType string, refID int64, tx *sql.Tx) error
+	DetachAll(ownerID int64, refType string, tx *sql.Tx) error
+}
+
+func NewFileRepository(db *sql.DB) *fileRepository {
+	return &fileRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataFile = `
+		f.id,
+		f.owner_id,
+		f.kind,
+		f.storage_key,
+		f.file_name,
+		f.content_type,
+		f.size,
+		f.checksum,
+		f.ref_type,
+		f.ref_id,
+		f.version,
+		f.deleted,
+		f.created_by,
+		f.created_at,
+		f.updated_by,
+		f.updated_at
+	`
+
+func scanFile(r scanner, file *models.File) error {
+	err := r.Scan(
+		&file.ID,
+		&file.OwnerID,
+		&file.Kind,
+		&file.StorageKey,
+		&file.FileName,
+		&file.ContentType,
+		&file.Size,
+		&file.Checksum,
+		&file.RefType,
+		&file.RefID,
+		&file.Version,
+		&file.Deleted,
+		&file.CreatedBy,
+		&file.CreatedAt,
+		&file.UpdatedBy,
+		&file.UpdatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fileRepository) getOne(where string, args ...any) (*models.File, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from files f
+	where
+		%s
+		and f.deleted = false
+	`, SQLSelectDataFile, where)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	file := models.File{}
+	if err := scanFile(r.db.QueryRowContext(ctx, query, args...), &file); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+// GetByID ignores ownership; callers must have authorized the access some
+// other way (a public logo, an admin review).
+func (r *fileRepository) GetByID(id int64) (*models.File, error) {
+	return r.getOne("f.id = $1", id)
+}
+
+func (r *fileRepository) GetByOwner(id, ownerID int64) (*models.File, error) {
+	return r.getOne("f.id = $1 and f.owner_id = $2", id, ownerID)
+}
+
+func (r *fileRepository) GetByKey(key string) (*models.File, error) {
+	return r.getOne("f.storage_key = $1", key)
+}
+
+func (r *fileRepository) GetAllByOwner(ownerID int64) ([]*models.File, error) {
+	return r.list("f.owner_id = $1", ownerID)
+}
+
+// GetAllByRef lists the files attached to any of refIDs.
+func (r *fileRepository) GetAllByRef(refType string, refIDs []int64) ([]*models.File, error) {
+	return r.list("f.ref_type = $1 and f.ref_id = any($2)", refType, pq.Array(refIDs))
+}
+
+func (r *fileRepository) list(where string, args ...any) ([]*models.File, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from files f
+	where
+		%s
+		and f.deleted = false
+	order by f.created_at, f.id
+	`, SQLSelectDataFile, where)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := []*models.File{}
+	for rows.Next() {
+		file := models.File{}
+		if err := scanFile(rows, &file); err != nil {
+			return nil, err
+		}
+		files = append(files, &file)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (r *fileRepository) Insert(file *models.File, tx *sql.Tx) error {
+	query := `
+	insert into files (
+		owner_id,
+		kind,
+		storage_key,
+		file_name,
+		content_type,
+		size,
+		checksum,
+		ref_type,
+		ref_id,
+		created_by
+	)
+	values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$1)
+	returning
+		id,
+		created_at,
+		version
+	`
+
+	args := []any{
+		file.OwnerID,
+		file.Kind,
+		file.StorageKey,
+		file.FileName,
+		file.ContentType,
+		file.Size,
+		file.Checksum,
+		file.RefType,
+		file.RefID,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, args...).Scan(
+		&file.ID,
+		&file.CreatedAt,
+		&file.Version,
+	)
+}
+
+func (r *fileRepository) Delete(id, ownerID int64, tx *sql.Tx) error {
+	query := `
+	update files
+	set
+		deleted = true,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and owner_id = $2
+		and deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return e.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Attach references the owner's files that aren't attached to anything
+// yet. If any of them is no longer free it fails with ErrEditConflict.
+func (r *fileRepository) Attach(ids []int64, ownerID int64, refType string, refID int64, tx *sql.Tx) error {
+	query := `
+	update files
+	set
+		ref_type = $3,
+		ref_id = $4,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = any($1)
+		and owner_id = $2
+		and ref_type is null
+		and deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, pq.Array(ids), ownerID, refType, refID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != int64(len(ids)) {
+		return e.ErrEditConflict
+	}
+
+	return nil
+}
+
+// DetachAll unreferences the owner's files attached to anything of
+// refType, which stops them being listed with it.
+func (r *fileRepository) DetachAll(ownerID int64, refType string, tx *sql.Tx) error {
+	query := `
+	update files
+	set
+		ref_type = null,
+		ref_id = null,
+		updated_at = now(),
+		version = version + 1
+	where
+		owner_id = $1
+		and ref_type = $2
+		and deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, ownerID, refType)
+	return err
+}
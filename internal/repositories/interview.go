@@ -0,0 +1,422 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	e "meu_job/utils/errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type interviewRepository struct {
+	db *sql.DB
+}
+
+type InterviewRepositoryInterface interface {
+	GetByID(id int64) (*models.Interview, error)
+	GetAllByApplication(applicationID int64) ([]*models.Interview, error)
+	GetAllByBusiness(businessID int64, since time.Time, f filters.Filters) ([]*models.Interview, filters.Metadata, error)
+	GetCalendar(userID int64, since time.Time) ([]*models.Interview, error)
+	GetBusy(interviewerID int64, from, to time.Time, excludeID int64) ([]models.InterviewSlot, error)
+	Insert(i *models.Interview, userID int64, tx *sql.Tx) error
+	Update(i *models.Interview, userID int64, tx *sql.Tx) error
+}
+
+func NewInterviewRepository(db *sql.DB) *interviewRepository {
+	return &interviewRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataInterview = `
+		i.id,
+		i.application_id,
+		i.business_id,
+		i.status,
+		i.mode,
+		i.video_url,
+		i.location,
+		i.notes,
+		i.duration_minutes,
+		i.slots,
+		i.starts_at,
+		i.ends_at,
+		i.reason,
+		i.cancelled_by,
+		i.sequence,
+		i.version,
+		i.created_by,
+		i.created_at,
+		i.updated_by,
+		i.updated_at,
+		j.title,
+		b.name,
+		a.user_id,
+		cu.name,
+		cu.email,
+		i.interviewer_id,
+		iu.name,
+		iu.email
+	`
+
+const sqlFromInterviews = `
+	from interviews i
+	join applications a on a.id = i.application_id
+	join jobs j on j.id = a.job_id
+	join business b on b.id = i.business_id
+	join users cu on cu.id = a.user_id
+	join users iu on iu.id = i.interviewer_id
+	`
+
+func scanInterview(r scanner, i *models.Interview, extra ...any) error {
+	var slots []byte
+	dest := append(extra,
+		&i.ID,
+		&i.ApplicationID,
+		&i.BusinessID,
+		&i.Status,
+		&i.Mode,
+		&i.VideoURL,
+		&i.Location,
+		&i.Notes,
+		&i.DurationMinutes,
+		&slots,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Reason,
+		&i.CancelledBy,
+		&i.Sequence,
+		&i.Version,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.JobTitle,
+		&i.BusinessName,
+		&i.Candidate.UserID,
+		&i.Candidate.Name,
+		&i.Candidate.Email,
+		&i.Interviewer.UserID,
+		&i.Interviewer.Name,
+		&i.Interviewer.Email,
+	)
+
+	if err := r.Scan(dest...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return json.Unmarshal(slots, &i.Slots)
+}
+
+func collectInterviews(rows *sql.Rows) ([]*models.Interview, error) {
+	interviews := []*models.Interview{}
+	for rows.Next() {
+		i := models.Interview{}
+		if err := scanInterview(rows, &i); err != nil {
+			return nil, err
+		}
+		interviews = append(interviews, &i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return interviews, nil
+}
+
+func (r *interviewRepository) GetByID(id int64) (*models.Interview, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	%s
+	where i.id = $1
+	`, SQLSelectDataInterview, sqlFromInterviews)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	i := models.Interview{}
+	if err := scanInterview(r.db.QueryRowContext(ctx, query, id), &i); err != nil {
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+func (r *interviewRepository) GetAllByApplication(applicationID int64) ([]*models.Interview, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	%s
+	where i.application_id = $1
+	order by i.created_at, i.id
+	`, SQLSelectDataInterview, sqlFromInterviews)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectInterviews(rows)
+}
+
+// GetAllByBusiness lists the interviews not yet over: the scheduled ones
+// ending after since and the ones still waiting for the candidate.
+func (r *interviewRepository) GetAllByBusiness(
+	businessID int64,
+	since time.Time,
+	f filters.Filters,
+) ([]*models.Interview, filters.Metadata, error) {
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s
+		%s
+		where
+			i.business_id = $1
+			and (
+				(i.status = 'scheduled' and i.ends_at > $2)
+				or i.status = 'proposed'
+			)
+		order by i.%s %s nulls last, i.id
+		limit $3 offset $4
+	`, SQLSelectDataInterview, sqlFromInterviews, f.SortColumn(), f.SortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, since, f.Limit(), f.Offset())
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	interviews := []*models.Interview{}
+
+	for rows.Next() {
+		i := models.Interview{}
+		if err := scanInterview(rows, &i, &totalRecords); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		interviews = append(interviews, &i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	metaData := filters.CalculateMetadata(totalRecords, f.Page, f.PageSize)
+	return interviews, metaData, nil
+}
+
+// GetCalendar lists the interviews with a time that the user takes part
+// in, as the candidate or the interviewer, starting after since. Cancelled
+// ones are kept so subscribed calendars drop them.
+func (r *interviewRepository) GetCalendar(userID int64, since time.Time) ([]*models.Interview, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	%s
+	where
+		(a.user_id = $1 or i.interviewer_id = $1)
+		and i.starts_at >= $2
+	order by i.starts_at, i.id
+	limit 500
+	`, SQLSelectDataInterview, sqlFromInterviews)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectInterviews(rows)
+}
+
+// GetBusy is when the interviewer has scheduled interviews between from
+// and to, other than excludeID.
+func (r *interviewRepository) GetBusy(interviewerID int64, from, to time.Time, excludeID int64) ([]models.InterviewSlot, error) {
+	query := `
+	select
+		starts_at,
+		ends_at
+	from interviews
+	where
+		interviewer_id = $1
+		and status = 'scheduled'
+		and starts_at < $3
+		and ends_at > $2
+		and id <> $4
+	order by starts_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, interviewerID, from, to, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	busy := []models.InterviewSlot{}
+	for rows.Next() {
+		var slot models.InterviewSlot
+		if err := rows.Scan(&slot.StartsAt, &slot.EndsAt); err != nil {
+			return nil, err
+		}
+		busy = append(busy, slot)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return busy, nil
+}
+
+// Insert makes userID the interviewer.
+func (r *interviewRepository) Insert(i *models.Interview, userID int64, tx *sql.Tx) error {
+	slots, err := json.Marshal(i.Slots)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	insert into interviews (
+		application_id,
+		business_id,
+		interviewer_id,
+		mode,
+		video_url,
+		location,
+		notes,
+		duration_minutes,
+		slots,
+		created_by
+	)
+	values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$3)
+	returning
+		id,
+		status,
+		sequence,
+		version,
+		created_at
+	`
+
+	args := []any{
+		i.ApplicationID,
+		i.BusinessID,
+		userID,
+		i.Mode,
+		i.VideoURL,
+		i.Location,
+		i.Notes,
+		i.DurationMinutes,
+		slots,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	i.Interviewer.UserID = userID
+	return tx.QueryRowContext(ctx, query, args...).Scan(
+		&i.ID,
+		&i.Status,
+		&i.Sequence,
+		&i.Version,
+		&i.CreatedAt,
+	)
+}
+
+// Update writes the interview as changed by the service, against the
+// version last read. Scheduling over another of the interviewer's
+// interviews fails with ErrScheduleConflict.
+func (r *interviewRepository) Update(i *models.Interview, userID int64, tx *sql.Tx) error {
+	slots, err := json.Marshal(i.Slots)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	update interviews
+	set
+		status = $2,
+		mode = $3,
+		video_url = $4,
+		location = $5,
+		notes = $6,
+		duration_minutes = $7,
+		slots = $8,
+		starts_at = $9,
+		ends_at = $10,
+		reason = $11,
+		cancelled_by = $12,
+		sequence = $13,
+		updated_by = $14,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and version = $15
+	returning
+		version,
+		updated_at
+	`
+
+	args := []any{
+		i.ID,
+		i.Status,
+		i.Mode,
+		i.VideoURL,
+		i.Location,
+		i.Notes,
+		i.DurationMinutes,
+		slots,
+		i.StartsAt,
+		i.EndsAt,
+		i.Reason,
+		i.CancelledBy,
+		i.Sequence,
+		userID,
+		i.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	i.UpdatedBy = &userID
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&i.Version, &i.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "interviews_no_overlap" {
+			return e.ErrScheduleConflict
+		}
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
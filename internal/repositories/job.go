@@ -0,0 +1,560 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	e "meu_job/utils/errors"
+	"time"
+)
+
+type jobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) *jobRepository {
+	return &jobRepository{
+		db: db,
+	}
+}
+
+type JobRepositoryInterface interface {
+	GetByBusiness(id, businessID int64) (*models.Job, error)
+	GetAllByBusiness(businessID int64, status string, f filters.Filters) ([]*models.Job, filters.Metadata, error)
+	GetPublished(id int64) (*models.Job, error)
+	GetPublishedAfter(afterID int64, limit int) ([]*models.Job, error)
+	GetPublic(id int64) (*models.Job, error)
+	Search(s models.JobSearch, f filters.Filters) ([]*models.Job, filters.Metadata, error)
+	Facets(s models.JobSearch) (*models.JobFacets, error)
+	Insert(j *models.Job, userID int64, tx *sql.Tx) error
+	Update(j *models.Job, userID int64, tx *sql.Tx) error
+	SetStatus(j *models.Job, status models.JobStatus, userID int64, tx *sql.Tx) error
+	Delete(id, businessID, userID int64, tx *sql.Tx) error
+	ClaimUnmatched(limit int, tx *sql.Tx) ([]*models.Job, error)
+	MarkMatched(id int64, version int, tx *sql.Tx) error
+}
+
+const SQLSelectDataJob = `
+		j.id,
+		j.business_id,
+		j.title,
+		j.description,
+		j.status,
+		j.seniority,
+		j.contract_type,
+		j.remote_mode,
+		j.cep,
+		j.street,
+		j.district,
+		j.city,
+		j.uf,
+		j.latitude,
+		j.longitude,
+		j.salary_min,
+		j.salary_max,
+		j.min_degree,
+		j.skills,
+		j.languages,
+		j.published_at,
+		j.closed_at,
+		j.version,
+		j.deleted,
+		j.created_by,
+		j.created_at,
+		j.updated_by,
+		j.updated_at
+	`
+
+// jobEntries holds the jsonb columns, stored in the same shape the API
+// uses for them.
+type jobEntries struct {
+	skills    []byte
+	languages []byte
+}
+
+func encodeJobEntries(j *models.Job) (jobEntries, error) {
+	dto := j.ToDTO()
+
+	var entries jobEntries
+	var err error
+	if entries.skills, err = json.Marshal(dto.Skills); err != nil {
+		return entries, err
+	}
+	if entries.languages, err = json.Marshal(dto.Languages); err != nil {
+		return entries, err
+	}
+	return entries, nil
+}
+
+func (entries jobEntries) decode(j *models.Job) error {
+	dto := models.JobDTO{}
+	if err := json.Unmarshal(entries.skills, &dto.Skills); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(entries.languages, &dto.Languages); err != nil {
+		return err
+	}
+
+	decoded := dto.ToModel()
+	j.Skills = decoded.Skills
+	j.Languages = decoded.Languages
+	return nil
+}
+
+func jobDest(j *models.Job, entries *jobEntries) []any {
+	dest := []any{
+		&j.ID,
+		&j.BusinessID,
+		&j.Title,
+		&j.Description,
+		&j.Status,
+		&j.Seniority,
+		&j.ContractType,
+		&j.RemoteMode,
+		&j.CEP,
+		&j.Street,
+		&j.District,
+		&j.City,
+		&j.UF,
+	}
+	dest = append(dest, coordinatesDest(&j.Coordinates)...)
+	return append(dest,
+		&j.SalaryMin,
+		&j.SalaryMax,
+		&j.MinDegree,
+		&entries.skills,
+		&entries.languages,
+		&j.PublishedAt,
+		&j.ClosedAt,
+		&j.Version,
+		&j.Deleted,
+		&j.CreatedBy,
+		&j.CreatedAt,
+		&j.UpdatedBy,
+		&j.UpdatedAt,
+	)
+}
+
+func scanJob(r scanner, j *models.Job) error {
+	var entries jobEntries
+	err := r.Scan(jobDest(j, &entries)...)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return entries.decode(j)
+}
+
+func (r *jobRepository) getOne(where string, args ...any) (*models.Job, error) {
+	query := fmt.Sprintf(`
+		select
+			%s
+		from jobs j
+		where
+			%s
+			and j.deleted = false
+	`, SQLSelectDataJob, where)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	j := models.Job{}
+	if err := scanJob(r.db.QueryRowContext(ctx, query, args...), &j); err != nil {
+		return nil, err
+	}
+
+	return &j, nil
+}
+
+func (r *jobRepository) GetByBusiness(id, businessID int64) (*models.Job, error) {
+	return r.getOne("j.id = $1 and j.business_id = $2", id, businessID)
+}
+
+func (r *jobRepository) GetPublished(id int64) (*models.Job, error) {
+	return r.getOne("j.id = $1 and j.status = 'published'", id)
+}
+
+func (r *jobRepository) GetAllByBusiness(
+	businessID int64,
+	status string,
+	f filters.Filters,
+) ([]*models.Job, filters.Metadata, error) {
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s
+		from jobs j
+		where
+			j.business_id = $1
+			and (j.status = $2 or $2 = '')
+			and j.deleted = false
+		order by %s %s nulls last, j.id
+		limit $3 offset $4
+	`, SQLSelectDataJob, f.SortColumn(), f.SortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, status, f.Limit(), f.Offset())
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	jobs := []*models.Job{}
+
+	for rows.Next() {
+		j := models.Job{}
+		var entries jobEntries
+		dest := append([]any{&totalRecords}, jobDest(&j, &entries)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		if err := entries.decode(&j); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		jobs = append(jobs, &j)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	metaData := filters.CalculateMetadata(totalRecords, f.Page, f.PageSize)
+	return jobs, metaData, nil
+}
+
+// GetPublishedAfter pages through the published jobs by id, for matching
+// a curriculum against all of them.
+func (r *jobRepository) GetPublishedAfter(afterID int64, limit int) ([]*models.Job, error) {
+	query := fmt.Sprintf(`
+		select
+			%s
+		from jobs j
+		where
+			j.id > $1
+			and j.status = 'published'
+			and j.deleted = false
+		order by j.id
+		limit $2
+	`, SQLSelectDataJob)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectJobs(rows)
+}
+
+func collectJobs(rows *sql.Rows) ([]*models.Job, error) {
+	jobs := []*models.Job{}
+	for rows.Next() {
+		j := models.Job{}
+		if err := scanJob(rows, &j); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &j)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (r *jobRepository) Insert(j *models.Job, userID int64, tx *sql.Tx) error {
+	entries, err := encodeJobEntries(j)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	insert into jobs (
+		business_id,
+		title,
+		description,
+		seniority,
+		contract_type,
+		remote_mode,
+		city,
+		uf,
+		salary_min,
+		salary_max,
+		min_degree,
+		skills,
+		languages,
+		created_by,
+		cep,
+		street,
+		district,
+		latitude,
+		longitude
+	)
+	values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19)
+	returning
+		id,
+		status,
+		created_at,
+		version
+	`
+
+	latitude, longitude := coordinatesArgs(j.Coordinates)
+	args := []any{
+		j.BusinessID,
+		j.Title,
+		j.Description,
+		j.Seniority,
+		j.ContractType,
+		j.RemoteMode,
+		j.City,
+		j.UF,
+		j.SalaryMin,
+		j.SalaryMax,
+		j.MinDegree,
+		entries.skills,
+		entries.languages,
+		userID,
+		j.CEP,
+		j.Street,
+		j.District,
+		latitude,
+		longitude,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, args...).Scan(
+		&j.ID,
+		&j.Status,
+		&j.CreatedAt,
+		&j.Version,
+	)
+}
+
+// Update only succeeds against the version the client last read, and
+// leaves closed jobs alone.
+func (r *jobRepository) Update(j *models.Job, userID int64, tx *sql.Tx) error {
+	entries, err := encodeJobEntries(j)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	update jobs
+	set
+		title = $4,
+		description = $5,
+		seniority = $6,
+		contract_type = $7,
+		remote_mode = $8,
+		city = $9,
+		uf = $10,
+		salary_min = $11,
+		salary_max = $12,
+		min_degree = $13,
+		skills = $14,
+		languages = $15,
+		cep = $17,
+		street = $18,
+		district = $19,
+		latitude = $20,
+		longitude = $21,
+		updated_by = $3,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and business_id = $2
+		and version = $16
+		and status <> 'closed'
+		and deleted = false
+	returning
+		status,
+		published_at,
+		closed_at,
+		version,
+		created_at,
+		updated_at
+	`
+
+	latitude, longitude := coordinatesArgs(j.Coordinates)
+	args := []any{
+		j.ID,
+		j.BusinessID,
+		userID,
+		j.Title,
+		j.Description,
+		j.Seniority,
+		j.ContractType,
+		j.RemoteMode,
+		j.City,
+		j.UF,
+		j.SalaryMin,
+		j.SalaryMax,
+		j.MinDegree,
+		entries.skills,
+		entries.languages,
+		j.Version,
+		j.CEP,
+		j.Street,
+		j.District,
+		latitude,
+		longitude,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
+		&j.Status,
+		&j.PublishedAt,
+		&j.ClosedAt,
+		&j.Version,
+		&j.CreatedAt,
+		&j.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetStatus publishes or closes the job. The first publication is the one
+// kept as published_at.
+func (r *jobRepository) SetStatus(j *models.Job, status models.JobStatus, userID int64, tx *sql.Tx) error {
+	query := `
+	update jobs
+	set
+		status = $4,
+		published_at = case when $4 = 'published' then coalesce(published_at, now()) else published_at end,
+		closed_at = case when $4 = 'closed' then now() else null end,
+		updated_by = $3,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and business_id = $2
+		and version = $5
+		and deleted = false
+	returning
+		status,
+		published_at,
+		closed_at,
+		version,
+		updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := tx.QueryRowContext(ctx, query, j.ID, j.BusinessID, userID, status, j.Version).Scan(
+		&j.Status,
+		&j.PublishedAt,
+		&j.ClosedAt,
+		&j.Version,
+		&j.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *jobRepository) Delete(id, businessID, userID int64, tx *sql.Tx) error {
+	query := `
+	update jobs
+	set
+		deleted = true,
+		updated_by = $3,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and business_id = $2
+		and deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, id, businessID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return e.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ClaimUnmatched locks published jobs changed since they were last
+// matched, skipping those another replica is already matching.
+func (r *jobRepository) ClaimUnmatched(limit int, tx *sql.Tx) ([]*models.Job, error) {
+	query := fmt.Sprintf(`
+		select
+			%s
+		from jobs j
+		where
+			j.status = 'published'
+			and j.deleted = false
+			and j.matched_version is distinct from j.version
+		order by j.id
+		limit $1
+		for update skip locked
+	`, SQLSelectDataJob)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectJobs(rows)
+}
+
+func (r *jobRepository) MarkMatched(id int64, version int, tx *sql.Tx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `update jobs set matched_version = $2 where id = $1`, id, version)
+	return err
+}
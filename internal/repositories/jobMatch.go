@@ -0,0 +1,306 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type jobMatchRepository struct {
+	db *sql.DB
+}
+
+func NewJobMatchRepository(db *sql.DB) *jobMatchRepository {
+	return &jobMatchRepository{
+		db: db,
+	}
+}
+
+type JobMatchRepositoryInterface interface {
+	GetCandidates(jobID int64, mf models.MatchFilter, f filters.Filters) ([]*models.JobMatch, filters.Metadata, error)
+	GetJobsForUser(userID int64, mf models.MatchFilter, f filters.Filters) ([]*models.JobMatch, filters.Metadata, error)
+	GetAllByUser(userID int64) ([]*models.JobMatch, error)
+	Upsert(matches []*models.JobMatch, tx *sql.Tx) error
+	Prune(tx *sql.Tx) error
+}
+
+const SQLSelectDataJobMatch = `
+		m.job_id,
+		m.curriculum_id,
+		m.user_id,
+		m.score,
+		m.breakdown,
+		m.computed_at
+	`
+
+// sqlMatchDistance is how far apart the job j and the curriculum c are,
+// rounded to whole km so a candidate's address can't be worked out from
+// it. It's null unless both are geocoded.
+const sqlMatchDistance = `round(haversine_km(j.latitude, j.longitude, c.latitude, c.longitude))`
+
+func jobMatchDest(m *models.JobMatch, breakdown *[]byte) []any {
+	return []any{
+		&m.JobID,
+		&m.CurriculumID,
+		&m.UserID,
+		&m.Score,
+		breakdown,
+		&m.ComputedAt,
+	}
+}
+
+// GetCandidates ranks the candidates for a job, best first. Only those who
+// agreed to share their curriculum with recruiters are listed.
+func (r *jobMatchRepository) GetCandidates(
+	jobID int64,
+	mf models.MatchFilter,
+	f filters.Filters,
+) ([]*models.JobMatch, filters.Metadata, error) {
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s,
+			%s,
+			%s as distance
+		from job_matches m
+		join curricula c on c.id = m.curriculum_id
+		join jobs j on j.id = m.job_id
+		where
+			m.job_id = $1
+			and m.score >= $2
+			and ($6::int = 0 or %s <= $6)
+			and c.deleted = false
+			and (
+				select cs.granted
+				from consents cs
+				where
+					cs.user_id = m.user_id
+					and cs.subject = $3
+				order by cs.created_at desc, cs.id desc
+				limit 1
+			) is true
+		order by %s %s, m.curriculum_id
+		limit $4 offset $5
+	`, SQLSelectDataJobMatch, SQLSelectDataCurriculum, sqlMatchDistance, sqlMatchDistance, f.SortColumn(), f.SortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := []any{jobID, mf.MinScore, models.PurposeShareCVWithRecruiters, f.Limit(), f.Offset(), mf.MaxDistanceKM}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	matches := []*models.JobMatch{}
+	for rows.Next() {
+		m := models.JobMatch{Curriculum: &models.Curriculum{}}
+		var breakdown []byte
+		var entries curriculumEntries
+		dest := append([]any{&totalRecords}, jobMatchDest(&m, &breakdown)...)
+		dest = append(dest, curriculumDest(m.Curriculum, &entries)...)
+		dest = append(dest, &m.DistanceKM)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		if err := entries.decode(m.Curriculum); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		if err := json.Unmarshal(breakdown, &m.Breakdown); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		matches = append(matches, &m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	metaData := filters.CalculateMetadata(totalRecords, f.Page, f.PageSize)
+	return matches, metaData, nil
+}
+
+// GetJobsForUser ranks the published jobs for the user's default
+// curriculum, best first.
+func (r *jobMatchRepository) GetJobsForUser(
+	userID int64,
+	mf models.MatchFilter,
+	f filters.Filters,
+) ([]*models.JobMatch, filters.Metadata, error) {
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s,
+			%s,
+			%s as distance
+		from job_matches m
+		join jobs j on j.id = m.job_id
+		join curricula c on c.id = m.curriculum_id
+		where
+			m.user_id = $1
+			and m.score >= $2
+			and ($5::int = 0 or %s <= $5)
+			and j.status = 'published'
+			and j.deleted = false
+		order by %s %s, m.job_id desc
+		limit $3 offset $4
+	`, SQLSelectDataJobMatch, SQLSelectDataJob, sqlMatchDistance, sqlMatchDistance, f.SortColumn(), f.SortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID, mf.MinScore, f.Limit(), f.Offset(), mf.MaxDistanceKM)
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	matches := []*models.JobMatch{}
+	for rows.Next() {
+		m := models.JobMatch{Job: &models.Job{}}
+		var breakdown []byte
+		var entries jobEntries
+		dest := append([]any{&totalRecords}, jobMatchDest(&m, &breakdown)...)
+		dest = append(dest, jobDest(m.Job, &entries)...)
+		dest = append(dest, &m.DistanceKM)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		if err := entries.decode(m.Job); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		if err := json.Unmarshal(breakdown, &m.Breakdown); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		matches = append(matches, &m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	metaData := filters.CalculateMetadata(totalRecords, f.Page, f.PageSize)
+	return matches, metaData, nil
+}
+
+func (r *jobMatchRepository) GetAllByUser(userID int64) ([]*models.JobMatch, error) {
+	query := fmt.Sprintf(`
+		select
+			%s
+		from job_matches m
+		where m.user_id = $1
+		order by m.score desc, m.job_id
+	`, SQLSelectDataJobMatch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []*models.JobMatch{}
+	for rows.Next() {
+		m := models.JobMatch{}
+		var breakdown []byte
+		if err := rows.Scan(jobMatchDest(&m, &breakdown)...); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(breakdown, &m.Breakdown); err != nil {
+			return nil, err
+		}
+		matches = append(matches, &m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// Upsert writes the matches in one statement, replacing earlier scores of
+// the same pairs.
+func (r *jobMatchRepository) Upsert(matches []*models.JobMatch, tx *sql.Tx) error {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	jobIDs := make([]int64, len(matches))
+	curriculumIDs := make([]int64, len(matches))
+	userIDs := make([]int64, len(matches))
+	scores := make([]int64, len(matches))
+	breakdowns := make([]string, len(matches))
+	for i, m := range matches {
+		breakdown, err := json.Marshal(m.Breakdown)
+		if err != nil {
+			return err
+		}
+		jobIDs[i] = m.JobID
+		curriculumIDs[i] = m.CurriculumID
+		userIDs[i] = m.UserID
+		scores[i] = int64(m.Score)
+		breakdowns[i] = string(breakdown)
+	}
+
+	query := `
+	insert into job_matches (job_id, curriculum_id, user_id, score, breakdown)
+	select t.job_id, t.curriculum_id, t.user_id, t.score, t.breakdown::jsonb
+	from unnest($1::bigint[], $2::bigint[], $3::bigint[], $4::int[], $5::text[])
+		as t(job_id, curriculum_id, user_id, score, breakdown)
+	on conflict (job_id, curriculum_id) do update
+	set
+		user_id = excluded.user_id,
+		score = excluded.score,
+		breakdown = excluded.breakdown,
+		computed_at = now()
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(
+		ctx,
+		query,
+		pq.Array(jobIDs),
+		pq.Array(curriculumIDs),
+		pq.Array(userIDs),
+		pq.Array(scores),
+		pq.Array(breakdowns),
+	)
+	return err
+}
+
+// Prune drops the matches of jobs no longer published and of curricula no
+// longer the default.
+func (r *jobMatchRepository) Prune(tx *sql.Tx) error {
+	query := `
+	delete from job_matches m
+	where
+		not exists (
+			select 1 from jobs j
+			where j.id = m.job_id and j.status = 'published' and j.deleted = false
+		)
+		or not exists (
+			select 1 from curricula c
+			where c.id = m.curriculum_id and c.is_default and c.deleted = false
+		)
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,318 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	e "meu_job/utils/errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// facetLimit caps the facets that grow with the data, cities and
+// companies, to their most frequent values.
+const facetLimit = 20
+
+const SQLSelectDataJobCompany = `
+		b.slug,
+		b.name,
+		b.logo_url,
+		b.logo_file_id,
+		b.verification_status
+	`
+
+func jobCompanyDest(b *models.Business) []any {
+	return []any{
+		&b.Profile.Slug,
+		&b.Name,
+		&b.Profile.LogoURL,
+		&b.LogoFileID,
+		&b.VerificationStatus,
+	}
+}
+
+// sqlPublicJobs is what any public listing starts from: published jobs of
+// businesses that made their profile public.
+const sqlPublicJobs = `
+		from jobs j
+		join business b on b.id = j.business_id
+		where
+			j.status = 'published'
+			and j.deleted = false
+			and b.public = true
+			and b.deleted = false
+	`
+
+// jobSearchConditions turns the search into SQL conditions, keyed by the
+// facet each one narrows so facets can be counted without their own. The
+// text query, the radius and the publication dates, which aren't facets,
+// are keyed "q", "distance", "published_after" and "published_until".
+func jobSearchConditions(s models.JobSearch, args *[]any) map[string]string {
+	arg := func(value any) string {
+		*args = append(*args, value)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+
+	conditions := map[string]string{}
+	if s.Query != "" {
+		conditions["q"] = fmt.Sprintf("j.search @@ websearch_to_tsquery('portuguese', %s)", arg(s.Query))
+	}
+	if s.UF != "" {
+		conditions["uf"] = "j.uf = " + arg(s.UF)
+	}
+	if s.City != "" {
+		conditions["city"] = fmt.Sprintf("lower(j.city) = lower(%s)", arg(s.City))
+	}
+	if s.RemoteMode != "" {
+		conditions["remote_mode"] = "j.remote_mode = " + arg(s.RemoteMode)
+	}
+	if s.ContractType != "" {
+		conditions["contract_type"] = "j.contract_type = " + arg(s.ContractType)
+	}
+	if s.Seniority != "" {
+		conditions["seniority"] = "j.seniority = " + arg(s.Seniority)
+	}
+	if band, ok := models.FindSalaryBand(s.SalaryBand); ok {
+		condition := "j.salary_top >= " + arg(band.Min)
+		if band.Max > 0 {
+			condition += " and j.salary_top < " + arg(band.Max)
+		}
+		conditions["salary_band"] = condition
+	}
+	if s.Company != "" {
+		conditions["company"] = "b.slug = " + arg(s.Company)
+	}
+	if origin := s.Origin(); origin != nil && s.RadiusKM > 0 {
+		// The box is checked first, against the index.
+		minLat, maxLat, minLng, maxLng := origin.BoundingBox(float64(s.RadiusKM))
+		conditions["distance"] = fmt.Sprintf(
+			"j.latitude between %s and %s and j.longitude between %s and %s and haversine_km(j.latitude, j.longitude, %s, %s) <= %s",
+			arg(minLat), arg(maxLat), arg(minLng), arg(maxLng),
+			arg(origin.Latitude), arg(origin.Longitude), arg(s.RadiusKM),
+		)
+	}
+	if s.PublishedAfter != nil {
+		conditions["published_after"] = "j.published_at > " + arg(*s.PublishedAfter)
+	}
+	if s.PublishedUntil != nil {
+		conditions["published_until"] = "j.published_at <= " + arg(*s.PublishedUntil)
+	}
+	return conditions
+}
+
+// andConditions joins the conditions but the one keyed except, in a fixed
+// order so the same search always makes the same SQL.
+func andConditions(conditions map[string]string, except string) string {
+	keys := make([]string, 0, len(conditions))
+	for key := range conditions {
+		if key != except {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString("\n\t\t\tand (" + conditions[key] + ")")
+	}
+	return b.String()
+}
+
+// salaryBandCase names the band of each job; the bands are constants, so
+// they're written into the SQL.
+func salaryBandCase() string {
+	var b strings.Builder
+	b.WriteString("case")
+	for _, band := range models.SalaryBands {
+		if band.Max > 0 {
+			fmt.Fprintf(&b, " when j.salary_top < %d then '%s'", band.Max, band.Key)
+		} else {
+			fmt.Fprintf(&b, " else '%s'", band.Key)
+		}
+	}
+	b.WriteString(" end")
+	return b.String()
+}
+
+// Search lists the published jobs matching the search. Relevance ranks
+// the title and skills above the description and is zero without a text
+// query, leaving the most recent first. Distance is null without a point
+// to measure from, and for jobs that aren't geocoded.
+func (r *jobRepository) Search(s models.JobSearch, f filters.Filters) ([]*models.Job, filters.Metadata, error) {
+	args := []any{}
+	conditions := jobSearchConditions(s, &args)
+
+	// The text query, when given, is always the first argument.
+	relevance := "0"
+	if s.Query != "" {
+		relevance = "ts_rank(j.search, websearch_to_tsquery('portuguese', $1))"
+	}
+	distance := "null::double precision"
+	if origin := s.Origin(); origin != nil {
+		args = append(args, origin.Latitude, origin.Longitude)
+		distance = fmt.Sprintf("haversine_km(j.latitude, j.longitude, $%d, $%d)", len(args)-1, len(args))
+	}
+
+	args = append(args, f.Limit(), f.Offset())
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s,
+			%s,
+			%s as relevance,
+			%s as distance
+		%s%s
+		order by %s %s, published_at desc, j.id desc
+		limit $%d offset $%d
+	`,
+		SQLSelectDataJob,
+		SQLSelectDataJobCompany,
+		relevance,
+		distance,
+		sqlPublicJobs,
+		andConditions(conditions, ""),
+		f.SortColumn(),
+		f.SortDirection(),
+		len(args)-1,
+		len(args),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	jobs := []*models.Job{}
+
+	for rows.Next() {
+		j := models.Job{Company: &models.Business{}}
+		var entries jobEntries
+		var relevance float64
+		dest := append([]any{&totalRecords}, jobDest(&j, &entries)...)
+		dest = append(dest, jobCompanyDest(j.Company)...)
+		dest = append(dest, &relevance, &j.DistanceKM)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		if err := entries.decode(&j); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		jobs = append(jobs, &j)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	metaData := filters.CalculateMetadata(totalRecords, f.Page, f.PageSize)
+	return jobs, metaData, nil
+}
+
+// Facets counts the jobs by each facet value, in one round trip: a branch
+// per facet, each filtered by everything but that facet.
+func (r *jobRepository) Facets(s models.JobSearch) (*models.JobFacets, error) {
+	args := []any{}
+	conditions := jobSearchConditions(s, &args)
+
+	branches := []struct {
+		facet, value, label, extra string
+	}{
+		{"uf", "j.uf", "''", "j.uf <> ''"},
+		{"city", "j.city", "j.city || ' - ' || j.uf", "j.city <> ''"},
+		{"remote_mode", "j.remote_mode", "''", ""},
+		{"contract_type", "j.contract_type", "''", ""},
+		{"seniority", "j.seniority", "''", ""},
+		{"salary_band", salaryBandCase(), "''", "j.salary_top is not null"},
+		{"company", "b.slug", "b.name", ""},
+	}
+
+	parts := make([]string, len(branches))
+	for i, branch := range branches {
+		where := andConditions(conditions, branch.facet)
+		if branch.extra != "" {
+			where += "\n\t\t\tand " + branch.extra
+		}
+		parts[i] = fmt.Sprintf(`
+		select '%s', %s, %s, count(*)
+		%s%s
+		group by 2, 3`, branch.facet, branch.value, branch.label, sqlPublicJobs, where)
+	}
+
+	query := fmt.Sprintf(`
+		select facet, value, label, count
+		from (%s
+		) f(facet, value, label, count)
+		order by facet, count desc, value
+	`, strings.Join(parts, "\n\t\tunion all"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facets := models.NewJobFacets()
+	seen := map[string]int{}
+	for rows.Next() {
+		var facet string
+		var count models.FacetCount
+		if err := rows.Scan(&facet, &count.Value, &count.Label, &count.Count); err != nil {
+			return nil, err
+		}
+		if seen[facet]++; seen[facet] > facetLimit {
+			continue
+		}
+		if err := facets.Add(facet, count); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+// GetPublic reads a job as the public search lists it.
+func (r *jobRepository) GetPublic(id int64) (*models.Job, error) {
+	query := fmt.Sprintf(`
+		select
+			%s,
+			%s
+		%s
+			and j.id = $1
+	`, SQLSelectDataJob, SQLSelectDataJobCompany, sqlPublicJobs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	j := models.Job{Company: &models.Business{}}
+	var entries jobEntries
+	dest := append(jobDest(&j, &entries), jobCompanyDest(j.Company)...)
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(dest...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, e.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if err := entries.decode(&j); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
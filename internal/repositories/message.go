@@ -0,0 +1,334 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"time"
+)
+
+type messageRepository struct {
+	db *sql.DB
+}
+
+type MessageRepositoryInterface interface {
+	GetAllByApplication(applicationID int64, f filters.Filters) ([]*models.Message, filters.Metadata, error)
+	GetAllByUser(userID int64) ([]*models.Message, error)
+	GetUnread(userID int64) ([]*models.UnreadThread, error)
+	Insert(m *models.Message, tx *sql.Tx) error
+	MarkRead(applicationID int64, fromCandidate bool, userID int64, tx *sql.Tx) (int64, error)
+	ClaimUnnotified(quietSince time.Time, limit int, tx *sql.Tx) ([]*models.MessageNotice, error)
+	EraseAllBySender(userID int64, tx *sql.Tx) error
+}
+
+func NewMessageRepository(db *sql.DB) *messageRepository {
+	return &messageRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataMessage = `
+		m.id,
+		m.application_id,
+		m.sender_id,
+		u.name,
+		m.from_candidate,
+		m.body,
+		m.read_at,
+		m.read_by,
+		m.created_at
+	`
+
+func messageDest(m *models.Message) []any {
+	return []any{
+		&m.ID,
+		&m.ApplicationID,
+		&m.Sender.UserID,
+		&m.Sender.Name,
+		&m.FromCandidate,
+		&m.Body,
+		&m.ReadAt,
+		&m.ReadBy,
+		&m.CreatedAt,
+	}
+}
+
+func (r *messageRepository) GetAllByApplication(
+	applicationID int64,
+	f filters.Filters,
+) ([]*models.Message, filters.Metadata, error) {
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s
+		from messages m
+		join users u on u.id = m.sender_id
+		where m.application_id = $1
+		order by m.%s %s, m.id %s
+		limit $2 offset $3
+	`, SQLSelectDataMessage, f.SortColumn(), f.SortDirection(), f.SortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, applicationID, f.Limit(), f.Offset())
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	messages := []*models.Message{}
+
+	for rows.Next() {
+		m := models.Message{}
+		dest := append([]any{&totalRecords}, messageDest(&m)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		messages = append(messages, &m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	metaData := filters.CalculateMetadata(totalRecords, f.Page, f.PageSize)
+	return messages, metaData, nil
+}
+
+// GetAllByUser lists the threads of the user's applications along with
+// the messages they sent as a member of a business, oldest first.
+func (r *messageRepository) GetAllByUser(userID int64) ([]*models.Message, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from messages m
+	join users u on u.id = m.sender_id
+	join applications a on a.id = m.application_id
+	where
+		a.user_id = $1
+		or m.sender_id = $1
+	order by m.id
+	`, SQLSelectDataMessage)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*models.Message{}
+	for rows.Next() {
+		m := models.Message{}
+		if err := rows.Scan(messageDest(&m)...); err != nil {
+			return nil, err
+		}
+		messages = append(messages, &m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// GetUnread counts what the user hasn't read, by thread: the business's
+// messages in their own applications, and the candidates' messages in the
+// applications to businesses they're a member of.
+func (r *messageRepository) GetUnread(userID int64) ([]*models.UnreadThread, error) {
+	query := `
+	select
+		a.id,
+		a.business_id,
+		j.title,
+		a.user_id = $1,
+		count(*),
+		max(m.created_at)
+	from messages m
+	join applications a on a.id = m.application_id
+	join jobs j on j.id = a.job_id
+	where
+		m.read_at is null
+		and (
+			(a.user_id = $1 and not m.from_candidate)
+			or (
+				m.from_candidate
+				and exists (
+					select 1
+					from business_users bu
+					where
+						bu.business_id = a.business_id
+						and bu.user_id = $1
+				)
+			)
+		)
+	group by a.id, a.business_id, j.title, a.user_id
+	order by max(m.created_at) desc
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	threads := []*models.UnreadThread{}
+	for rows.Next() {
+		t := models.UnreadThread{}
+		err := rows.Scan(&t.ApplicationID, &t.BusinessID, &t.JobTitle, &t.AsCandidate, &t.Unread, &t.LastAt)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return threads, nil
+}
+
+func (r *messageRepository) Insert(m *models.Message, tx *sql.Tx) error {
+	query := `
+	insert into messages (
+		application_id,
+		sender_id,
+		from_candidate,
+		body
+	)
+	values ($1,$2,$3,$4)
+	returning
+		id,
+		created_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, m.ApplicationID, m.Sender.UserID, m.FromCandidate, m.Body).Scan(
+		&m.ID,
+		&m.CreatedAt,
+	)
+}
+
+// MarkRead marks the unread messages one side sent as read by userID, of
+// the other side, and reports how many there were.
+func (r *messageRepository) MarkRead(applicationID int64, fromCandidate bool, userID int64, tx *sql.Tx) (int64, error) {
+	query := `
+	update messages
+	set
+		read_at = now(),
+		read_by = $3
+	where
+		application_id = $1
+		and from_candidate = $2
+		and read_at is null
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, applicationID, fromCandidate, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// ClaimUnnotified finds the threads where one side's messages have gone
+// unread, and nobody was emailed about them, with none sent since
+// quietSince. The messages are marked notified as they're claimed, so
+// instances running at once don't both email about them.
+func (r *messageRepository) ClaimUnnotified(quietSince time.Time, limit int, tx *sql.Tx) ([]*models.MessageNotice, error) {
+	query := `
+	select
+		application_id,
+		from_candidate,
+		max(id)
+	from messages
+	where
+		read_at is null
+		and notified_at is null
+	group by application_id, from_candidate
+	having max(created_at) < $1
+	order by max(created_at)
+	limit $2
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, query, quietSince, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	found := []*models.MessageNotice{}
+	for rows.Next() {
+		n := models.MessageNotice{}
+		if err := rows.Scan(&n.ApplicationID, &n.FromCandidate, &n.LastID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		found = append(found, &n)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	claim := `
+	update messages
+	set notified_at = now()
+	where
+		application_id = $1
+		and from_candidate = $2
+		and id <= $3
+		and read_at is null
+		and notified_at is null
+	`
+
+	notices := []*models.MessageNotice{}
+	for _, n := range found {
+		result, err := tx.ExecContext(ctx, claim, n.ApplicationID, n.FromCandidate, n.LastID)
+		if err != nil {
+			return nil, err
+		}
+
+		claimed, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if claimed == 0 {
+			continue
+		}
+
+		n.Count = int(claimed)
+		notices = append(notices, n)
+	}
+
+	return notices, nil
+}
+
+// EraseAllBySender blanks what the user wrote; the messages stay in the
+// thread so the other side's replies still make sense.
+func (r *messageRepository) EraseAllBySender(userID int64, tx *sql.Tx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `update messages set body = '' where sender_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,387 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	e "meu_job/utils/errors"
+	"time"
+)
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+type NotificationRepositoryInterface interface {
+	GetForUser(userID int64, unreadOnly bool, f filters.Filters) ([]*models.Notification, filters.Metadata, error)
+	GetAllByUser(userID int64) ([]*models.Notification, error)
+	GetAfter(userID, afterID int64, limit int) ([]*models.Notification, error)
+	GetLatestID(userID int64) (int64, error)
+	CountUnread(userID int64) (int, error)
+	Insert(n *models.Notification, tx *sql.Tx) error
+	InsertForMembers(n *models.Notification, businessID, exceptUserID int64, tx *sql.Tx) error
+	MarkRead(id, userID int64, tx *sql.Tx) (*models.Notification, error)
+	MarkAllRead(userID int64, tx *sql.Tx) (int64, error)
+	GetPreferences(userID int64) (models.NotificationPreferences, error)
+	SetPreferences(userID int64, prefs models.NotificationPreferences, tx *sql.Tx) error
+	EraseAllByUser(userID int64, tx *sql.Tx) error
+}
+
+func NewNotificationRepository(db *sql.DB) *notificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataNotification = `
+		n.id,
+		n.user_id,
+		n.type,
+		n.title,
+		n.body,
+		n.data,
+		n.read_at,
+		n.created_at
+	`
+
+// notificationEnabled leaves out the users who turned the type off; $1 is
+// the type.
+const notificationEnabled = `
+	not exists (
+		select 1
+		from notification_preferences p
+		where
+			p.user_id = u.id
+			and p.type = $1
+			and p.enabled = false
+	)
+	`
+
+func scanNotification(r scanner, n *models.Notification, extra ...any) error {
+	var data []byte
+	dest := append(extra, &n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &data, &n.ReadAt, &n.CreatedAt)
+	if err := r.Scan(dest...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return json.Unmarshal(data, &n.Data)
+}
+
+func collectNotifications(rows *sql.Rows) ([]*models.Notification, error) {
+	notifications := []*models.Notification{}
+	for rows.Next() {
+		n := models.Notification{}
+		if err := scanNotification(rows, &n); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, &n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *notificationRepository) GetForUser(
+	userID int64,
+	unreadOnly bool,
+	f filters.Filters,
+) ([]*models.Notification, filters.Metadata, error) {
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s
+		from notifications n
+		where
+			n.user_id = $1
+			and (n.read_at is null or not $2)
+		order by n.%s %s, n.id %s
+		limit $3 offset $4
+	`, SQLSelectDataNotification, f.SortColumn(), f.SortDirection(), f.SortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID, unreadOnly, f.Limit(), f.Offset())
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	notifications := []*models.Notification{}
+
+	for rows.Next() {
+		n := models.Notification{}
+		if err := scanNotification(rows, &n, &totalRecords); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		notifications = append(notifications, &n)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	metaData := filters.CalculateMetadata(totalRecords, f.Page, f.PageSize)
+	return notifications, metaData, nil
+}
+
+func (r *notificationRepository) GetAllByUser(userID int64) ([]*models.Notification, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from notifications n
+	where n.user_id = $1
+	order by n.id
+	`, SQLSelectDataNotification)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectNotifications(rows)
+}
+
+// GetAfter lists the user's notifications created after afterID, oldest
+// first.
+func (r *notificationRepository) GetAfter(userID, afterID int64, limit int) ([]*models.Notification, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from notifications n
+	where
+		n.user_id = $1
+		and n.id > $2
+	order by n.id
+	limit $3
+	`, SQLSelectDataNotification)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectNotifications(rows)
+}
+
+// GetLatestID is the id of the user's last notification, or 0.
+func (r *notificationRepository) GetLatestID(userID int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var id int64
+	err := r.db.QueryRowContext(
+		ctx,
+		`select coalesce(max(id), 0) from notifications where user_id = $1`,
+		userID,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *notificationRepository) CountUnread(userID int64) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(
+		ctx,
+		`select count(*) from notifications where user_id = $1 and read_at is null`,
+		userID,
+	).Scan(&count)
+	return count, err
+}
+
+// Insert creates the notification unless the user turned its type off, in
+// which case n.ID is left 0.
+func (r *notificationRepository) Insert(n *models.Notification, tx *sql.Tx) error {
+	data, err := json.Marshal(n.Data)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+	insert into notifications (
+		user_id,
+		type,
+		title,
+		body,
+		data
+	)
+	select u.id, $1::text, $3::text, $4::text, $5::jsonb
+	from users u
+	where
+		u.id = $2
+		and %s
+	returning
+		id,
+		created_at
+	`, notificationEnabled)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = tx.QueryRowContext(ctx, query, n.Type, n.UserID, n.Title, n.Body, data).Scan(&n.ID, &n.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+// InsertForMembers gives each member of the business who hasn't turned the
+// type off a copy of the notification, except exceptUserID, who caused it.
+func (r *notificationRepository) InsertForMembers(n *models.Notification, businessID, exceptUserID int64, tx *sql.Tx) error {
+	data, err := json.Marshal(n.Data)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+	insert into notifications (
+		user_id,
+		type,
+		title,
+		body,
+		data
+	)
+	select u.id, $1::text, $4::text, $5::text, $6::jsonb
+	from business_users bu
+	join users u on u.id = bu.user_id
+	where
+		bu.business_id = $2
+		and u.id <> $3
+		and u.activated = true
+		and u.deleted = false
+		and %s
+	`, notificationEnabled)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err = tx.ExecContext(ctx, query, n.Type, businessID, exceptUserID, n.Title, n.Body, data)
+	return err
+}
+
+// MarkRead keeps when a notification was first read.
+func (r *notificationRepository) MarkRead(id, userID int64, tx *sql.Tx) (*models.Notification, error) {
+	query := fmt.Sprintf(`
+	update notifications n
+	set read_at = coalesce(n.read_at, now())
+	where
+		n.id = $1
+		and n.user_id = $2
+	returning
+		%s
+	`, SQLSelectDataNotification)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	n := models.Notification{}
+	if err := scanNotification(tx.QueryRowContext(ctx, query, id, userID), &n); err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+func (r *notificationRepository) MarkAllRead(userID int64, tx *sql.Tx) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		`update notifications set read_at = now() where user_id = $1 and read_at is null`,
+		userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetPreferences has only the types the user changed.
+func (r *notificationRepository) GetPreferences(userID int64) (models.NotificationPreferences, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`select type, enabled from notification_preferences where user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefs := models.NotificationPreferences{}
+	for rows.Next() {
+		var t models.NotificationType
+		var enabled bool
+		if err := rows.Scan(&t, &enabled); err != nil {
+			return nil, err
+		}
+		prefs[t] = enabled
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
+func (r *notificationRepository) SetPreferences(userID int64, prefs models.NotificationPreferences, tx *sql.Tx) error {
+	query := `
+	insert into notification_preferences (user_id, type, enabled)
+	values ($1, $2, $3)
+	on conflict (user_id, type) do update
+	set
+		enabled = excluded.enabled,
+		updated_at = now()
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	for t, enabled := range prefs {
+		if _, err := tx.ExecContext(ctx, query, userID, t, enabled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EraseAllByUser deletes the user's notifications, which name the
+// businesses and jobs they dealt with.
+func (r *notificationRepository) EraseAllByUser(userID int64, tx *sql.Tx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, `delete from notifications where user_id = $1`, userID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `delete from notification_preferences where user_id = $1`, userID)
+	return err
+}
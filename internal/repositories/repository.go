@@ -3,13 +3,55 @@ package repositories
 import "database/sql"
 
 type Repository struct {
-	User     UserRepositoryInterface
-	Business BusinessRepositoryInterface
+	User            UserRepositoryInterface
+	Business        BusinessRepositoryInterface
+	APIKey          APIKeyRepositoryInterface
+	Session         SessionRepositoryInterface
+	Consent         ConsentRepositoryInterface
+	Verification    VerificationRepositoryInterface
+	File            FileRepositoryInterface
+	Curriculum      CurriculumRepositoryInterface
+	CurriculumDraft CurriculumDraftRepositoryInterface
+	Skill           SkillRepositoryInterface
+	Job             JobRepositoryInterface
+	JobMatch        JobMatchRepositoryInterface
+	Email           EmailRepositoryInterface
+	SavedSearch     SavedSearchRepositoryInterface
+	Application     ApplicationRepositoryInterface
+	Interview       InterviewRepositoryInterface
+	Message         MessageRepositoryInterface
+	Notification    NotificationRepositoryInterface
+	Webhook         WebhookRepositoryInterface
+	WebhookDelivery WebhookDeliveryRepositoryInterface
+	Audit           AuditRepositoryInterface
+}
+
+type scanner interface {
+	Scan(dest ...any) error
 }
 
 func New(db *sql.DB) *Repository {
 	return &Repository{
-		User:     NewUserRepository(db),
-		Business: NewBusinessRepository(db),
+		User:            NewCachedUserRepository(NewUserRepository(db)),
+		Business:        NewBusinessRepository(db),
+		APIKey:          NewAPIKeyRepository(db),
+		Session:         NewSessionRepository(db),
+		Consent:         NewConsentRepository(db),
+		Verification:    NewVerificationRepository(db),
+		File:            NewFileRepository(db),
+		Curriculum:      NewCurriculumRepository(db),
+		CurriculumDraft: NewCurriculumDraftRepository(db),
+		Skill:           NewSkillRepository(db),
+		Job:             NewJobRepository(db),
+		JobMatch:        NewJobMatchRepository(db),
+		Email:           NewEmailRepository(db),
+		SavedSearch:     NewSavedSearchRepository(db),
+		Application:     NewApplicationRepository(db),
+		Interview:       NewInterviewRepository(db),
+		Message:         NewMessageRepository(db),
+		Notification:    NewNotificationRepository(db),
+		Webhook:         NewWebhookRepository(db),
+		WebhookDelivery: NewWebhookDeliveryRepository(db),
+		Audit:           NewAuditRepository(db),
 	}
 }
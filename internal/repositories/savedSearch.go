@@ -0,0 +1,380 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	e "meu_job/utils/errors"
+	"time"
+)
+
+type savedSearchRepository struct {
+	db *sql.DB
+}
+
+type SavedSearchRepositoryInterface interface {
+	GetByID(id, userID int64) (*models.SavedSearch, error)
+	GetAllByUser(userID int64) ([]*models.SavedSearch, error)
+	Insert(s *models.SavedSearch, userID int64, tx *sql.Tx) error
+	Update(s *models.SavedSearch, userID int64, tx *sql.Tx) error
+	Delete(id, userID int64, tx *sql.Tx) error
+	DisableAlerts(id int64, tx *sql.Tx) (*models.SavedSearch, error)
+	ClaimDue(limit int, tx *sql.Tx) ([]*models.SavedSearch, error)
+	MarkRun(s *models.SavedSearch, tx *sql.Tx) error
+	EraseAllByUser(userID int64, tx *sql.Tx) error
+}
+
+func NewSavedSearchRepository(db *sql.DB) *savedSearchRepository {
+	return &savedSearchRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataSavedSearch = `
+		s.id,
+		s.user_id,
+		s.name,
+		s.criteria,
+		s.frequency,
+		s.alerts_enabled,
+		s.checked_until,
+		s.next_run_at,
+		s.version,
+		s.deleted,
+		s.created_by,
+		s.created_at,
+		s.updated_by,
+		s.updated_at
+	`
+
+func scanSavedSearch(r scanner, s *models.SavedSearch) error {
+	var criteria []byte
+	err := r.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.Name,
+		&criteria,
+		&s.Frequency,
+		&s.AlertsEnabled,
+		&s.CheckedUntil,
+		&s.NextRunAt,
+		&s.Version,
+		&s.Deleted,
+		&s.CreatedBy,
+		&s.CreatedAt,
+		&s.UpdatedBy,
+		&s.UpdatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return json.Unmarshal(criteria, &s.Criteria)
+}
+
+func collectSavedSearches(rows *sql.Rows) ([]*models.SavedSearch, error) {
+	searches := []*models.SavedSearch{}
+	for rows.Next() {
+		s := models.SavedSearch{}
+		if err := scanSavedSearch(rows, &s); err != nil {
+			return nil, err
+		}
+		searches = append(searches, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+func (r *savedSearchRepository) GetByID(id, userID int64) (*models.SavedSearch, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from saved_searches s
+	where
+		s.id = $1
+		and s.user_id = $2
+		and s.deleted = false
+	`, SQLSelectDataSavedSearch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	s := models.SavedSearch{}
+	if err := scanSavedSearch(r.db.QueryRowContext(ctx, query, id, userID), &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *savedSearchRepository) GetAllByUser(userID int64) ([]*models.SavedSearch, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from saved_searches s
+	where
+		s.user_id = $1
+		and s.deleted = false
+	order by s.created_at, s.id
+	`, SQLSelectDataSavedSearch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectSavedSearches(rows)
+}
+
+// Insert starts the search from now: only jobs published afterwards are
+// alerted about.
+func (r *savedSearchRepository) Insert(s *models.SavedSearch, userID int64, tx *sql.Tx) error {
+	criteria, err := json.Marshal(s.Criteria)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	insert into saved_searches (
+		user_id,
+		name,
+		criteria,
+		frequency,
+		alerts_enabled,
+		next_run_at,
+		created_by
+	)
+	values ($1,$2,$3,$4,$5,now() + $6::int * interval '1 second',$1)
+	returning
+		id,
+		checked_until,
+		next_run_at,
+		version,
+		created_at
+	`
+
+	args := []any{
+		userID,
+		s.Name,
+		criteria,
+		s.Frequency,
+		s.AlertsEnabled,
+		int(s.Frequency.Interval().Seconds()),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	s.UserID = userID
+	return tx.QueryRowContext(ctx, query, args...).Scan(
+		&s.ID,
+		&s.CheckedUntil,
+		&s.NextRunAt,
+		&s.Version,
+		&s.CreatedAt,
+	)
+}
+
+// Update reschedules the next run from the last one when the frequency
+// changes. Turning alerts back on starts from now, so the jobs published
+// while they were off aren't sent all at once.
+func (r *savedSearchRepository) Update(s *models.SavedSearch, userID int64, tx *sql.Tx) error {
+	criteria, err := json.Marshal(s.Criteria)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	update saved_searches
+	set
+		name = $3,
+		criteria = $4,
+		frequency = $5,
+		checked_until = case when not alerts_enabled and $6 then now() else checked_until end,
+		next_run_at = case
+			when not alerts_enabled and $6 then now() + $7::int * interval '1 second'
+			when frequency <> $5 then checked_until + $7::int * interval '1 second'
+			else next_run_at
+		end,
+		alerts_enabled = $6,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and user_id = $2
+		and version = $8
+		and deleted = false
+	returning
+		checked_until,
+		next_run_at,
+		version,
+		created_at,
+		updated_at
+	`
+
+	args := []any{
+		s.ID,
+		userID,
+		s.Name,
+		criteria,
+		s.Frequency,
+		s.AlertsEnabled,
+		int(s.Frequency.Interval().Seconds()),
+		s.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	s.UserID = userID
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
+		&s.CheckedUntil,
+		&s.NextRunAt,
+		&s.Version,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *savedSearchRepository) Delete(id, userID int64, tx *sql.Tx) error {
+	query := `
+	update saved_searches
+	set
+		deleted = true,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and user_id = $2
+		and deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return e.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DisableAlerts is what unsubscribing does; the search itself is kept. It
+// succeeds again on a search already unsubscribed, as links get clicked
+// twice.
+func (r *savedSearchRepository) DisableAlerts(id int64, tx *sql.Tx) (*models.SavedSearch, error) {
+	query := fmt.Sprintf(`
+	update saved_searches s
+	set
+		alerts_enabled = false,
+		updated_at = case when s.alerts_enabled then now() else s.updated_at end,
+		version = case when s.alerts_enabled then s.version + 1 else s.version end
+	where
+		s.id = $1
+		and s.deleted = false
+	returning
+		%s
+	`, SQLSelectDataSavedSearch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	s := models.SavedSearch{}
+	if err := scanSavedSearch(tx.QueryRowContext(ctx, query, id), &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// ClaimDue locks the searches whose alert is due, skipping those another
+// replica is already running.
+func (r *savedSearchRepository) ClaimDue(limit int, tx *sql.Tx) ([]*models.SavedSearch, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from saved_searches s
+	where
+		s.alerts_enabled
+		and s.deleted = false
+		and s.next_run_at <= now()
+	order by s.next_run_at, s.id
+	limit $1
+	for update skip locked
+	`, SQLSelectDataSavedSearch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return collectSavedSearches(rows)
+}
+
+// MarkRun moves the search past the run that just checked it up to
+// s.CheckedUntil.
+func (r *savedSearchRepository) MarkRun(s *models.SavedSearch, tx *sql.Tx) error {
+	query := `
+	update saved_searches
+	set
+		checked_until = $2,
+		next_run_at = $2::timestamptz + $3::int * interval '1 second'
+	where id = $1
+	returning next_run_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, s.ID, s.CheckedUntil, int(s.Frequency.Interval().Seconds())).Scan(&s.NextRunAt)
+}
+
+func (r *savedSearchRepository) EraseAllByUser(userID int64, tx *sql.Tx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `delete from saved_searches where user_id = $1`, userID)
+	return err
+}
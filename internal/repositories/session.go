@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	e "meu_job/utils/errors"
+	"time"
+)
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+type SessionRepositoryInterface interface {
+	GetByID(id int64) (*models.Session, error)
+	GetAllByUser(userID int64) ([]*models.Session, error)
+	Insert(session *models.Session, tx *sql.Tx) error
+	Revoke(id, userID int64, tx *sql.Tx) error
+	RevokeAllExcept(userID, keepID int64, tx *sql.Tx) ([]int64, error)
+	TouchLastSeen(id int64) error
+}
+
+func NewSessionRepository(db *sql.DB) *sessionRepository {
+	return &sessionRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataSession = `
+		s.id,
+		s.user_id,
+		s.user_agent,
+		s.ip,
+		s.last_seen_at,
+		s.version,
+		s.deleted,
+		s.created_by,
+		s.created_at,
+		s.updated_by,
+		s.updated_at
+	`
+
+func scanSession(r scanner, session *models.Session) error {
+	err := r.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IP,
+		&session.LastSeenAt,
+		&session.Version,
+		&session.Deleted,
+		&session.CreatedBy,
+		&session.CreatedAt,
+		&session.UpdatedBy,
+		&session.UpdatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sessionRepository) GetByID(id int64) (*models.Session, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from sessions s
+	where
+		s.id = $1
+		and s.deleted = false
+	`, SQLSelectDataSession)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	session := models.Session{}
+	if err := scanSession(r.db.QueryRowContext(ctx, query, id), &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *sessionRepository) GetAllByUser(userID int64) ([]*models.Session, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from sessions s
+	where
+		s.user_id = $1
+		and s.deleted = false
+	order by s.last_seen_at desc, s.id
+	`, SQLSelectDataSession)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*models.Session{}
+	for rows.Next() {
+		session := models.Session{}
+		if err := scanSession(rows, &session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) Insert(session *models.Session, tx *sql.Tx) error {
+	query := `
+	insert into sessions (
+		user_id,
+		user_agent,
+		ip,
+		created_by
+	)
+	values ($1,$2,$3,$1)
+	returning
+		id,
+		created_at,
+		last_seen_at,
+		version
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, session.UserID, session.UserAgent, session.IP).Scan(
+		&session.ID,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.Version,
+	)
+}
+
+func (r *sessionRepository) Revoke(id, userID int64, tx *sql.Tx) error {
+	query := `
+	update sessions
+	set
+		deleted = true,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and user_id = $2
+		and deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return e.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RevokeAllExcept(userID, keepID int64, tx *sql.Tx) ([]int64, error) {
+	query := `
+	update sessions
+	set
+		deleted = true,
+		updated_by = $1,
+		updated_at = now(),
+		version = version + 1
+	where
+		user_id = $1
+		and id <> $2
+		and deleted = false
+	returning id
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, query, userID, keepID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *sessionRepository) TouchLastSeen(id int64) error {
+	query := `
+	update sessions
+	set last_seen_at = now()
+	where id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
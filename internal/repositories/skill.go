@@ -0,0 +1,405 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	e "meu_job/utils/errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type skillRepository struct {
+	db *sql.DB
+}
+
+func NewSkillRepository(db *sql.DB) *skillRepository {
+	return &skillRepository{
+		db: db,
+	}
+}
+
+type SkillRepositoryInterface interface {
+	Search(prefix string, category models.SkillCategory, limit int) ([]*models.Skill, error)
+	GetByID(id int64) (*models.Skill, error)
+	GetByKeys(keys []string) (map[string]*models.Skill, error)
+	Insert(s *models.Skill, userID int64, tx *sql.Tx) error
+	Update(s *models.Skill, userID int64, tx *sql.Tx) error
+	Merge(source, target *models.Skill, userID int64, tx *sql.Tx) error
+}
+
+const SQLSelectDataSkill = `
+		s.id,
+		s.name,
+		s.key,
+		s.category,
+		coalesce((
+			select array_agg(ss.name order by ss.name)
+			from skill_synonyms ss
+			where ss.skill_id = s.id
+		), '{}'),
+		s.merged_into,
+		s.version,
+		s.deleted,
+		s.created_by,
+		s.created_at,
+		s.updated_by,
+		s.updated_at
+	`
+
+// SQLSkillLookup pairs every key a skill is known by, its own and the
+// synonyms', with the skill.
+const SQLSkillLookup = `
+		select key, id as skill_id from skills where deleted = false
+		union all
+		select key, skill_id from skill_synonyms
+	`
+
+func skillDest(s *models.Skill) []any {
+	return []any{
+		&s.ID,
+		&s.Name,
+		&s.Key,
+		&s.Category,
+		pq.Array(&s.Synonyms),
+		&s.MergedInto,
+		&s.Version,
+		&s.Deleted,
+		&s.CreatedBy,
+		&s.CreatedAt,
+		&s.UpdatedBy,
+		&s.UpdatedAt,
+	}
+}
+
+func scanSkill(r scanner, s *models.Skill) error {
+	err := r.Scan(skillDest(s)...)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// Search finds the skills whose name or a synonym starts with the key
+// prefix. Exact matches come first, then the shorter names, so "go" lists
+// Go before Google Cloud.
+func (r *skillRepository) Search(prefix string, category models.SkillCategory, limit int) ([]*models.Skill, error) {
+	query := fmt.Sprintf(`
+		select
+			%s
+		from skills s
+		join (%s) l on l.skill_id = s.id
+		where
+			l.key like $1 || '%%'
+			and (s.category = $2 or $2 = '')
+		group by s.id
+		order by bool_or(l.key = $1) desc, length(s.key), s.name
+		limit $3
+	`, SQLSelectDataSkill, SQLSkillLookup)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, prefix, category, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	skills := []*models.Skill{}
+	for rows.Next() {
+		s := models.Skill{}
+		if err := scanSkill(rows, &s); err != nil {
+			return nil, err
+		}
+		skills = append(skills, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return skills, nil
+}
+
+func (r *skillRepository) GetByID(id int64) (*models.Skill, error) {
+	query := fmt.Sprintf(`
+		select
+			%s
+		from skills s
+		where
+			s.id = $1
+			and s.deleted = false
+	`, SQLSelectDataSkill)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	s := models.Skill{}
+	if err := scanSkill(r.db.QueryRowContext(ctx, query, id), &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// GetByKeys resolves names already turned into keys by models.SkillKey.
+// The keys not in the catalog are left out of the result.
+func (r *skillRepository) GetByKeys(keys []string) (map[string]*models.Skill, error) {
+	query := fmt.Sprintf(`
+		select
+			l.key,
+			%s
+		from skills s
+		join (%s) l on l.skill_id = s.id
+		where l.key = any($1)
+	`, SQLSelectDataSkill, SQLSkillLookup)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	skills := map[string]*models.Skill{}
+	for rows.Next() {
+		var key string
+		s := models.Skill{}
+		if err := rows.Scan(append([]any{&key}, skillDest(&s)...)...); err != nil {
+			return nil, err
+		}
+		skills[key] = &s
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return skills, nil
+}
+
+func (r *skillRepository) Insert(s *models.Skill, userID int64, tx *sql.Tx) error {
+	query := `
+	insert into skills (
+		name,
+		key,
+		category,
+		created_by
+	)
+	values ($1,$2,$3,$4)
+	returning
+		id,
+		created_at,
+		version
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := tx.QueryRowContext(ctx, query, s.Name, s.Key, s.Category, userID).Scan(
+		&s.ID,
+		&s.CreatedAt,
+		&s.Version,
+	)
+	if err != nil {
+		return r.uniqueErrors(err)
+	}
+
+	return r.insertSynonyms(s.ID, s.Synonyms, userID, tx)
+}
+
+// Update replaces the synonyms along with the skill. Curricula keep the
+// name they were saved with until they are saved again.
+func (r *skillRepository) Update(s *models.Skill, userID int64, tx *sql.Tx) error {
+	query := `
+	update skills
+	set
+		name = $3,
+		key = $4,
+		category = $5,
+		updated_by = $2,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and version = $6
+		and deleted = false
+	returning
+		version,
+		created_at,
+		updated_at
+	`
+
+	args := []any{
+		s.ID,
+		userID,
+		s.Name,
+		s.Key,
+		s.Category,
+		s.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := tx.QueryRowContext(ctx, query, args...).Scan(
+		&s.Version,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrEditConflict
+		default:
+			return r.uniqueErrors(err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `delete from skill_synonyms where skill_id = $1`, s.ID); err != nil {
+		return err
+	}
+
+	return r.insertSynonyms(s.ID, s.Synonyms, userID, tx)
+}
+
+func (r *skillRepository) insertSynonyms(skillID int64, synonyms []string, userID int64, tx *sql.Tx) error {
+	if len(synonyms) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(synonyms))
+	for i, synonym := range synonyms {
+		keys[i] = models.SkillKey(synonym)
+	}
+
+	query := `
+	insert into skill_synonyms (skill_id, name, key, created_by)
+	select $1, t.name, t.key, $4
+	from unnest($2::text[], $3::text[]) as t(name, key)
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, skillID, pq.Array(synonyms), pq.Array(keys), userID)
+	return r.uniqueErrors(err)
+}
+
+// Merge folds source into target: source's name and synonyms become
+// synonyms of target, source is deleted pointing at target, and the
+// curricula and jobs listing source are rewritten to target. They aren't
+// edited by their owners, so their versions stay.
+func (r *skillRepository) Merge(source, target *models.Skill, userID int64, tx *sql.Tx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := `
+	update skills
+	set
+		deleted = true,
+		merged_into = $2,
+		updated_by = $3,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and version = $4
+		and deleted = false
+	`
+
+	result, err := tx.ExecContext(ctx, query, source.ID, target.ID, userID, source.Version)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return e.ErrEditConflict
+	}
+
+	query = `
+	update skill_synonyms
+	set skill_id = $2
+	where skill_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, source.ID, target.ID); err != nil {
+		return err
+	}
+
+	query = `
+	insert into skill_synonyms (skill_id, name, key, created_by)
+	values ($1,$2,$3,$4)
+	on conflict (key) do nothing
+	`
+
+	if _, err := tx.ExecContext(ctx, query, target.ID, source.Name, source.Key, userID); err != nil {
+		return err
+	}
+
+	// A curriculum or job listing both skills keeps the first one listed,
+	// which may carry the level that was cared to set. Both are due to be
+	// matched again.
+	for _, table := range []string{"curricula", "jobs"} {
+		query = fmt.Sprintf(`
+		update %s t
+		set
+			skills = (
+				select coalesce(jsonb_agg(m.entry order by m.n), '[]')
+				from (
+					select distinct on (coalesce(nullif(r.entry->'skill_id', 'null'), r.entry->'name'))
+						r.entry,
+						r.n
+					from (
+						select
+							case
+								when (e.entry->>'skill_id')::bigint = $1
+								then e.entry || jsonb_build_object('skill_id', $2::bigint, 'name', $3::text)
+								else e.entry
+							end as entry,
+							e.n
+						from jsonb_array_elements(t.skills) with ordinality as e(entry, n)
+					) r
+					order by coalesce(nullif(r.entry->'skill_id', 'null'), r.entry->'name'), r.n
+				) m
+			),
+			matched_version = null
+		where
+			t.skills @> jsonb_build_array(jsonb_build_object('skill_id', $1::bigint))
+			and t.deleted = false
+		`, table)
+
+		if _, err := tx.ExecContext(ctx, query, source.ID, target.ID, target.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *skillRepository) uniqueErrors(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Constraint {
+		case "unique_skills_key", "unique_skill_synonyms_key":
+			return e.ErrDuplicateName
+		}
+	}
+	return err
+}
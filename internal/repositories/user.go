@@ -24,6 +24,11 @@ type UserRepositoryInterface interface {
 	UpdateCodByEmail(tx *sql.Tx, user *models.User) error
 	Update(tx *sql.Tx, user *models.User) error
 	Delete(tx *sql.Tx, idUser int64) error
+	ScheduleErasure(tx *sql.Tx, idUser int64, at *time.Time) error
+	GetDueForErasure(limit int) ([]int64, error)
+	Anonymize(tx *sql.Tx, idUser int64) error
+	RotateCalendarFeed(tx *sql.Tx, user *models.User) error
+	Forget(idUser int64)
 }
 
 const SqlSelectUser = `
@@ -37,7 +42,15 @@ const SqlSelectUser = `
 		password_hash, 
 		activated, 
 		version,
-		role
+		role,
+		token_version,
+		calendar_feed_version,
+		pending_email,
+		pending_email_cod,
+		erasure_scheduled_for,
+		terms_version,
+		privacy_version,
+		cpf
 	FROM users
 `
 
@@ -107,6 +120,14 @@ func (r *UserRepository) getUserByQuery(query string, args ...any) (*models.User
 		&user.Activated,
 		&user.Version,
 		&user.Role,
+		&user.TokenVersion,
+		&user.CalendarFeedVersion,
+		&user.PendingEmail,
+		&user.PendingEmailCod,
+		&user.ErasureScheduledFor,
+		&user.TermsVersion,
+		&user.PrivacyVersion,
+		&user.CPF,
 	)
 
 	if err != nil {
@@ -154,9 +175,9 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 
 func (r *UserRepository) Insert(tx *sql.Tx, user *models.User) error {
 	query := `
-	INSERT INTO users (name, email, phone,cod, password_hash, activated,deleted,role)
-	VALUES ($1, $2, $3, $4, $5, $6,false,1)
-	RETURNING id, created_at, version
+	INSERT INTO users (name, email, phone,cod, password_hash, activated,deleted,role, terms_version, privacy_version, cpf)
+	VALUES ($1, $2, $3, $4, $5, $6,false,1, $7, $8, $9)
+	RETURNING id, created_at, version, token_version
 	`
 	args := []any{
 		user.Name,
@@ -165,6 +186,9 @@ func (r *UserRepository) Insert(tx *sql.Tx, user *models.User) error {
 		user.Cod,
 		user.Password.Hash,
 		user.Activated,
+		user.TermsVersion,
+		user.PrivacyVersion,
+		user.CPF,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -174,6 +198,7 @@ func (r *UserRepository) Insert(tx *sql.Tx, user *models.User) error {
 		&user.ID,
 		&user.CreatedAt,
 		&user.Version,
+		&user.TokenVersion,
 	)
 
 	if err != nil {
@@ -187,6 +212,8 @@ func (r *UserRepository) Insert(tx *sql.Tx, user *models.User) error {
 				return e.ErrDuplicateEmail
 			case "users_phone_key":
 				return e.ErrDuplicatePhone
+			case "users_cpf_key":
+				return e.ErrDuplicateCPF
 			}
 		}
 
@@ -231,10 +258,16 @@ func (r *UserRepository) Update(tx *sql.Tx, user *models.User) error {
 		phone = $4, 
 		password_hash = $5,
 		activated = $6,
+		token_version = $7,
+		pending_email = $8,
+		pending_email_cod = $9,
+		terms_version = $10,
+		privacy_version = $11,
+		cpf = $12,
 		version = version + 1
 	WHERE 
-		id = $7 
-		AND version = $8
+		id = $13 
+		AND version = $14
 	RETURNING version`
 
 	args := []any{
@@ -244,6 +277,12 @@ func (r *UserRepository) Update(tx *sql.Tx, user *models.User) error {
 		user.Phone,
 		user.Password.Hash,
 		user.Activated,
+		user.TokenVersion,
+		user.PendingEmail,
+		user.PendingEmailCod,
+		user.TermsVersion,
+		user.PrivacyVersion,
+		user.CPF,
 		user.ID,
 		user.Version,
 	}
@@ -266,6 +305,8 @@ func (r *UserRepository) Update(tx *sql.Tx, user *models.User) error {
 				return e.ErrDuplicateEmail
 			case "users_phone_key":
 				return e.ErrDuplicatePhone
+			case "users_cpf_key":
+				return e.ErrDuplicateCPF
 			}
 		}
 
@@ -300,3 +341,146 @@ func (r *UserRepository) Delete(tx *sql.Tx, idUser int64) error {
 
 	return nil
 }
+
+func (r *UserRepository) ScheduleErasure(tx *sql.Tx, idUser int64, at *time.Time) error {
+	query := `
+	UPDATE users SET
+		erasure_requested_at = CASE WHEN $2::timestamptz IS NULL THEN NULL ELSE NOW() END,
+		erasure_scheduled_for = $2,
+		version = version + 1
+	WHERE
+		id = $1
+		AND deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, idUser, at)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return e.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RotateCalendarFeed raises the version signed into the user's calendar
+// feed URL, so the one handed out stops working.
+func (r *UserRepository) RotateCalendarFeed(tx *sql.Tx, user *models.User) error {
+	query := `
+	UPDATE users SET
+		calendar_feed_version = calendar_feed_version + 1,
+		version = version + 1
+	WHERE
+		id = $1
+		AND deleted = false
+	RETURNING calendar_feed_version, version
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := tx.QueryRowContext(ctx, query, user.ID).Scan(&user.CalendarFeedVersion, &user.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *UserRepository) GetDueForErasure(limit int) ([]int64, error) {
+	query := `
+	SELECT id
+	FROM users
+	WHERE
+		erasure_scheduled_for <= NOW()
+		AND anonymized_at IS NULL
+	ORDER BY erasure_scheduled_for
+	LIMIT $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Anonymize replaces every personal field in place. The row itself is kept so
+// foreign keys and aggregate statistics (created_at, role, memberships) stay
+// intact; email and phone get unique placeholders to satisfy their constraints.
+func (r *UserRepository) Anonymize(tx *sql.Tx, idUser int64) error {
+	query := `
+	UPDATE users SET
+		name = 'anonymized',
+		email = 'erased-' || id || '@anonymized.invalid',
+		phone = 'erased-' || id,
+		cpf = NULL,
+		password_hash = sha256(random()::text::bytea),
+		cod = NULL,
+		pending_email = NULL,
+		pending_email_cod = NULL,
+		activated = false,
+		deleted = true,
+		anonymized_at = NOW(),
+		token_version = token_version + 1,
+		version = version + 1
+	WHERE
+		id = $1
+		AND anonymized_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, idUser)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return e.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Forget drops any copy of the user kept in memory; this repository keeps
+// none.
+func (r *UserRepository) Forget(idUser int64) {}
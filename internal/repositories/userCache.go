@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"database/sql"
+	"expvar"
+	"meu_job/internal/cache"
+	"meu_job/internal/models"
+	"time"
+)
+
+const (
+	userCacheTTL      = time.Minute
+	userCacheMaxItems = 10_000
+)
+
+var (
+	userCacheHits   = expvar.NewInt("user_cache_hits")
+	userCacheMisses = expvar.NewInt("user_cache_misses")
+)
+
+func init() {
+	expvar.Publish("user_cache_hit_ratio", expvar.Func(func() any {
+		hits := userCacheHits.Value()
+		total := hits + userCacheMisses.Value()
+		if total == 0 {
+			return 0.0
+		}
+		return float64(hits) / float64(total)
+	}))
+}
+
+type cachedUserRepository struct {
+	UserRepositoryInterface
+	users *cache.TTLCache[int64, models.User]
+}
+
+func NewCachedUserRepository(repo UserRepositoryInterface) *cachedUserRepository {
+	return &cachedUserRepository{
+		UserRepositoryInterface: repo,
+		users:                   cache.New[int64, models.User](userCacheTTL, userCacheMaxItems),
+	}
+}
+
+func (r *cachedUserRepository) GetByID(id int64) (*models.User, error) {
+	if user, found := r.users.Get(id); found {
+		userCacheHits.Add(1)
+		return &user, nil
+	}
+
+	userCacheMisses.Add(1)
+	user, err := r.UserRepositoryInterface.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.users.Set(id, *user)
+	return user, nil
+}
+
+func (r *cachedUserRepository) UpdateCodByEmail(tx *sql.Tx, user *models.User) error {
+	r.users.Delete(user.ID)
+	return r.UserRepositoryInterface.UpdateCodByEmail(tx, user)
+}
+
+func (r *cachedUserRepository) Update(tx *sql.Tx, user *models.User) error {
+	r.users.Delete(user.ID)
+	return r.UserRepositoryInterface.Update(tx, user)
+}
+
+func (r *cachedUserRepository) Delete(tx *sql.Tx, idUser int64) error {
+	r.users.Delete(idUser)
+	return r.UserRepositoryInterface.Delete(tx, idUser)
+}
+
+func (r *cachedUserRepository) ScheduleErasure(tx *sql.Tx, idUser int64, at *time.Time) error {
+	r.users.Delete(idUser)
+	return r.UserRepositoryInterface.ScheduleErasure(tx, idUser, at)
+}
+
+func (r *cachedUserRepository) Anonymize(tx *sql.Tx, idUser int64) error {
+	r.users.Delete(idUser)
+	return r.UserRepositoryInterface.Anonymize(tx, idUser)
+}
+
+func (r *cachedUserRepository) RotateCalendarFeed(tx *sql.Tx, user *models.User) error {
+	r.users.Delete(user.ID)
+	return r.UserRepositoryInterface.RotateCalendarFeed(tx, user)
+}
+
+// Forget is called once a write to the user is committed. The writes evict
+// the user before running too, but a read made before their transaction
+// commits would cache the old row again until it expires.
+func (r *cachedUserRepository) Forget(idUser int64) {
+	r.users.Delete(idUser)
+}
@@ -0,0 +1,293 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	e "meu_job/utils/errors"
+	"time"
+)
+
+type verificationRepository struct {
+	db *sql.DB
+}
+
+type VerificationRepositoryInterface interface {
+	GetBusiness(businessID int64) (*models.Business, error)
+	GetQueue(status models.VerificationStatus, f filters.Filters) ([]*models.Business, filters.Metadata, error)
+	GetDocuments(businessID int64) ([]*models.VerificationDocument, error)
+	GetDocument(id, businessID int64) (*models.VerificationDocument, error)
+	InsertDocument(doc *models.VerificationDocument, userID int64, tx *sql.Tx) error
+	SetStatus(business *models.Business, from models.VerificationStatus, userID int64, tx *sql.Tx) error
+}
+
+func NewVerificationRepository(db *sql.DB) *verificationRepository {
+	return &verificationRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataVerificationDocument = `
+		d.id,
+		d.business_id,
+		d.file_id,
+		d.file_name,
+		d.content_type,
+		d.size,
+		d.version,
+		d.deleted,
+		d.created_by,
+		d.created_at,
+		d.updated_by,
+		d.updated_at
+	`
+
+func scanVerificationDocument(r scanner, doc *models.VerificationDocument, dest ...any) error {
+	err := r.Scan(append([]any{
+		&doc.ID,
+		&doc.BusinessID,
+		&doc.FileID,
+		&doc.FileName,
+		&doc.ContentType,
+		&doc.Size,
+		&doc.Version,
+		&doc.Deleted,
+		&doc.CreatedBy,
+		&doc.CreatedAt,
+		&doc.UpdatedBy,
+		&doc.UpdatedAt,
+	}, dest...)...)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBusiness loads a business regardless of membership, for the admins
+// reviewing it.
+func (r *verificationRepository) GetBusiness(businessID int64) (*models.Business, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from business b
+	where
+		b.id = $1
+		and b.deleted = false
+	`, SQLSelectDataBusiness)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	business := models.Business{}
+	if err := scanBusiness(r.db.QueryRowContext(ctx, query, businessID), &business); err != nil {
+		return nil, err
+	}
+
+	return &business, nil
+}
+
+// GetQueue lists businesses in the given status, oldest request first so
+// that nobody waits forever.
+func (r *verificationRepository) GetQueue(
+	status models.VerificationStatus,
+	f filters.Filters,
+) ([]*models.Business, filters.Metadata, error) {
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s
+		from business b
+		where
+			b.verification_status = $1
+			and b.deleted = false
+		order by b.verification_requested_at nulls last, b.id
+		limit $2 offset $3
+	`, SQLSelectDataBusiness)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, status, f.Limit(), f.Offset())
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	businessList := []*models.Business{}
+
+	for rows.Next() {
+		business := models.Business{}
+		if err := scanBusinessPage(rows, &totalRecords, &business); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		businessList = append(businessList, &business)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	metaData := filters.CalculateMetadata(totalRecords, f.Page, f.PageSize)
+	return businessList, metaData, nil
+}
+
+// GetDocuments leaves the content out; use GetDocument to download one.
+func (r *verificationRepository) GetDocuments(businessID int64) ([]*models.VerificationDocument, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from business_verification_documents d
+	where
+		d.business_id = $1
+		and d.deleted = false
+	order by d.created_at, d.id
+	`, SQLSelectDataVerificationDocument)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, businessID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []*models.VerificationDocument{}
+	for rows.Next() {
+		doc := models.VerificationDocument{}
+		if err := scanVerificationDocument(rows, &doc); err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+func (r *verificationRepository) GetDocument(id, businessID int64) (*models.VerificationDocument, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from business_verification_documents d
+	where
+		d.id = $1
+		and d.business_id = $2
+		and d.deleted = false
+	`, SQLSelectDataVerificationDocument)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	doc := models.VerificationDocument{}
+	row := r.db.QueryRowContext(ctx, query, id, businessID)
+	if err := scanVerificationDocument(row, &doc); err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+func (r *verificationRepository) InsertDocument(doc *models.VerificationDocument, userID int64, tx *sql.Tx) error {
+	query := `
+	insert into business_verification_documents (
+		business_id,
+		file_id,
+		file_name,
+		content_type,
+		size,
+		created_by
+	)
+	values ($1,$2,$3,$4,$5,$6)
+	returning
+		id,
+		created_at,
+		version
+	`
+
+	args := []any{
+		doc.BusinessID,
+		doc.FileID,
+		doc.FileName,
+		doc.ContentType,
+		doc.Size,
+		userID,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, args...).Scan(
+		&doc.ID,
+		&doc.CreatedAt,
+		&doc.Version,
+	)
+}
+
+// SetStatus moves the business to business.VerificationStatus only if it is
+// still in from, so two admins reviewing at once can't both win.
+func (r *verificationRepository) SetStatus(
+	business *models.Business,
+	from models.VerificationStatus,
+	userID int64,
+	tx *sql.Tx,
+) error {
+	query := `
+	update business
+	set
+		verification_status = $2,
+		verification_reason = $3,
+		verification_reviewed_by = $4,
+		verification_reviewed_at = $5,
+		verification_requested_at = case
+			when $2 = 'pending' then now()
+			else verification_requested_at
+		end,
+		updated_by = $6,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and verification_status = $7
+		and deleted = false
+	returning version
+	`
+
+	args := []any{
+		business.ID,
+		business.VerificationStatus,
+		business.VerificationReason,
+		business.VerificationReviewedBy,
+		business.VerificationReviewedAt,
+		userID,
+		from,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&business.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
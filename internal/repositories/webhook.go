@@ -0,0 +1,309 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	e "meu_job/utils/errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+type WebhookRepositoryInterface interface {
+	GetByID(id, businessID int64) (*models.Webhook, error)
+	GetAllByBusiness(businessID int64) ([]*models.Webhook, error)
+	Insert(w *models.Webhook, userID int64, tx *sql.Tx) error
+	Update(w *models.Webhook, userID int64, tx *sql.Tx) error
+	SetSecret(w *models.Webhook, userID int64, tx *sql.Tx) error
+	Delete(id, businessID, userID int64, tx *sql.Tx) error
+}
+
+func NewWebhookRepository(db *sql.DB) *webhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataWebhook = `
+		w.id,
+		w.business_id,
+		w.url,
+		w.description,
+		w.events,
+		w.secret,
+		w.active,
+		w.version,
+		w.deleted,
+		w.created_by,
+		w.created_at,
+		w.updated_by,
+		w.updated_at
+	`
+
+func scanWebhook(r scanner, w *models.Webhook) error {
+	var events []string
+	err := r.Scan(
+		&w.ID,
+		&w.BusinessID,
+		&w.URL,
+		&w.Description,
+		pq.Array(&events),
+		&w.Secret,
+		&w.Active,
+		&w.Version,
+		&w.Deleted,
+		&w.CreatedBy,
+		&w.CreatedAt,
+		&w.UpdatedBy,
+		&w.UpdatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	w.Events = make([]models.WebhookEvent, len(events))
+	for i, event := range events {
+		w.Events[i] = models.WebhookEvent(event)
+	}
+	return nil
+}
+
+func webhookEvents(w *models.Webhook) any {
+	events := make([]string, len(w.Events))
+	for i, event := range w.Events {
+		events[i] = string(event)
+	}
+	return pq.Array(events)
+}
+
+func (r *webhookRepository) GetByID(id, businessID int64) (*models.Webhook, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from webhooks w
+	where
+		w.id = $1
+		and w.business_id = $2
+		and w.deleted = false
+	`, SQLSelectDataWebhook)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	w := models.Webhook{}
+	if err := scanWebhook(r.db.QueryRowContext(ctx, query, id, businessID), &w); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (r *webhookRepository) GetAllByBusiness(businessID int64) ([]*models.Webhook, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from webhooks w
+	where
+		w.business_id = $1
+		and w.deleted = false
+	order by w.id
+	`, SQLSelectDataWebhook)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, businessID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*models.Webhook{}
+	for rows.Next() {
+		w := models.Webhook{}
+		if err := scanWebhook(rows, &w); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, &w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (r *webhookRepository) Insert(w *models.Webhook, userID int64, tx *sql.Tx) error {
+	query := `
+	insert into webhooks (
+		business_id,
+		url,
+		description,
+		events,
+		secret,
+		active,
+		created_by
+	)
+	values ($1,$2,$3,$4,$5,$6,$7)
+	returning
+		id,
+		version,
+		created_at
+	`
+
+	args := []any{
+		w.BusinessID,
+		w.URL,
+		w.Description,
+		webhookEvents(w),
+		w.Secret,
+		w.Active,
+		userID,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	w.CreatedBy = &userID
+	return tx.QueryRowContext(ctx, query, args...).Scan(
+		&w.ID,
+		&w.Version,
+		&w.CreatedAt,
+	)
+}
+
+// Update changes the endpoint and its subscriptions; the secret is only
+// changed by SetSecret.
+func (r *webhookRepository) Update(w *models.Webhook, userID int64, tx *sql.Tx) error {
+	query := `
+	update webhooks
+	set
+		url = $4,
+		description = $5,
+		events = $6,
+		active = $7,
+		updated_by = $3,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and business_id = $2
+		and version = $8
+		and deleted = false
+	returning
+		version,
+		created_at,
+		updated_at
+	`
+
+	args := []any{
+		w.ID,
+		w.BusinessID,
+		userID,
+		w.URL,
+		w.Description,
+		webhookEvents(w),
+		w.Active,
+		w.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	w.UpdatedBy = &userID
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&w.Version, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) SetSecret(w *models.Webhook, userID int64, tx *sql.Tx) error {
+	query := `
+	update webhooks
+	set
+		secret = $4,
+		updated_by = $3,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and business_id = $2
+		and version = $5
+		and deleted = false
+	returning
+		version,
+		updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	w.UpdatedBy = &userID
+	err := tx.QueryRowContext(ctx, query, w.ID, w.BusinessID, userID, w.Secret, w.Version).Scan(&w.Version, &w.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return e.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) Delete(id, businessID, userID int64, tx *sql.Tx) error {
+	query := `
+	update webhooks
+	set
+		deleted = true,
+		active = false,
+		updated_by = $3,
+		updated_at = now(),
+		version = version + 1
+	where
+		id = $1
+		and business_id = $2
+		and deleted = false
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, id, businessID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return e.ErrRecordNotFound
+	}
+
+	return nil
+}
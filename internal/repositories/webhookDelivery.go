@@ -0,0 +1,408 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	e "meu_job/utils/errors"
+	"time"
+)
+
+type webhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+type WebhookDeliveryRepositoryInterface interface {
+	GetByID(id, webhookID int64) (*models.WebhookDelivery, error)
+	GetAllByWebhook(webhookID int64, status string, f filters.Filters) ([]*models.WebhookDelivery, filters.Metadata, error)
+	GetAttempts(deliveryID int64) ([]*models.WebhookAttempt, error)
+	Enqueue(businessID int64, event models.WebhookEvent, payload []byte, tx *sql.Tx) error
+	Insert(d *models.WebhookDelivery, tx *sql.Tx) error
+	Requeue(id, webhookID int64, tx *sql.Tx) (*models.WebhookDelivery, error)
+	ClaimDue(limit int, lease time.Duration, tx *sql.Tx) ([]*models.WebhookDelivery, error)
+	RecordAttempt(a *models.WebhookAttempt, tx *sql.Tx) error
+	MarkSucceeded(id int64, statusCode int, tx *sql.Tx) error
+	MarkFailed(id int64, statusCode *int, reason string, retryAt *time.Time, tx *sql.Tx) error
+	EraseAllByCandidate(userID int64, tx *sql.Tx) error
+}
+
+func NewWebhookDeliveryRepository(db *sql.DB) *webhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		db: db,
+	}
+}
+
+const SQLSelectDataWebhookDelivery = `
+		d.id,
+		d.webhook_id,
+		d.event,
+		d.payload,
+		d.status,
+		d.attempts,
+		d.next_attempt_at,
+		d.last_status_code,
+		d.last_error,
+		d.delivered_at,
+		d.created_at
+	`
+
+func webhookDeliveryDest(d *models.WebhookDelivery) []any {
+	return []any{
+		&d.ID,
+		&d.WebhookID,
+		&d.Event,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastStatusCode,
+		&d.LastError,
+		&d.DeliveredAt,
+		&d.CreatedAt,
+	}
+}
+
+func (r *webhookDeliveryRepository) GetByID(id, webhookID int64) (*models.WebhookDelivery, error) {
+	query := fmt.Sprintf(`
+	select
+		%s
+	from webhook_deliveries d
+	where
+		d.id = $1
+		and d.webhook_id = $2
+	`, SQLSelectDataWebhookDelivery)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	d := models.WebhookDelivery{}
+	err := r.db.QueryRowContext(ctx, query, id, webhookID).Scan(webhookDeliveryDest(&d)...)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, e.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &d, nil
+}
+
+// GetAllByWebhook lists the deliveries of a webhook, of any status when
+// status is empty.
+func (r *webhookDeliveryRepository) GetAllByWebhook(
+	webhookID int64,
+	status string,
+	f filters.Filters,
+) ([]*models.WebhookDelivery, filters.Metadata, error) {
+	query := fmt.Sprintf(`
+		select
+			count(*) over(),
+			%s
+		from webhook_deliveries d
+		where
+			d.webhook_id = $1
+			and (d.status = $2 or $2 = '')
+		order by d.%s %s, d.id %s
+		limit $3 offset $4
+	`, SQLSelectDataWebhookDelivery, f.SortColumn(), f.SortDirection(), f.SortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, webhookID, status, f.Limit(), f.Offset())
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	deliveries := []*models.WebhookDelivery{}
+
+	for rows.Next() {
+		d := models.WebhookDelivery{}
+		dest := append([]any{&totalRecords}, webhookDeliveryDest(&d)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, filters.Metadata{}, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	metaData := filters.CalculateMetadata(totalRecords, f.Page, f.PageSize)
+	return deliveries, metaData, nil
+}
+
+// GetAttempts lists the tries at a delivery, oldest first.
+func (r *webhookDeliveryRepository) GetAttempts(deliveryID int64) ([]*models.WebhookAttempt, error) {
+	query := `
+	select
+		id,
+		delivery_id,
+		status_code,
+		error,
+		response_body,
+		duration_ms,
+		attempted_at
+	from webhook_attempts
+	where delivery_id = $1
+	order by id
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []*models.WebhookAttempt{}
+	for rows.Next() {
+		a := models.WebhookAttempt{}
+		var durationMS int64
+		err := rows.Scan(&a.ID, &a.DeliveryID, &a.StatusCode, &a.Error, &a.ResponseBody, &durationMS, &a.AttemptedAt)
+		if err != nil {
+			return nil, err
+		}
+		a.Duration = time.Duration(durationMS) * time.Millisecond
+		attempts = append(attempts, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// Enqueue queues the event for every active webhook of the business
+// subscribed to it.
+func (r *webhookDeliveryRepository) Enqueue(businessID int64, event models.WebhookEvent, payload []byte, tx *sql.Tx) error {
+	query := `
+	insert into webhook_deliveries (
+		webhook_id,
+		event,
+		payload
+	)
+	select w.id, $2::text, $3::jsonb
+	from webhooks w
+	where
+		w.business_id = $1
+		and w.active = true
+		and w.deleted = false
+		and $2::text = any(w.events)
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, businessID, event, payload)
+	return err
+}
+
+// Insert queues a delivery for one webhook, as the ping is.
+func (r *webhookDeliveryRepository) Insert(d *models.WebhookDelivery, tx *sql.Tx) error {
+	query := fmt.Sprintf(`
+	insert into webhook_deliveries as d (
+		webhook_id,
+		event,
+		payload
+	)
+	values ($1,$2,$3)
+	returning
+		%s
+	`, SQLSelectDataWebhookDelivery)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return tx.QueryRowContext(ctx, query, d.WebhookID, d.Event, []byte(d.Payload)).Scan(webhookDeliveryDest(d)...)
+}
+
+// Requeue sends a delivery that is done with, succeeded or dead, again as
+// soon as possible, with a full set of retries. The attempts made so far
+// are kept.
+func (r *webhookDeliveryRepository) Requeue(id, webhookID int64, tx *sql.Tx) (*models.WebhookDelivery, error) {
+	query := fmt.Sprintf(`
+	update webhook_deliveries d
+	set
+		status = 'pending',
+		attempts = 0,
+		next_attempt_at = now(),
+		delivered_at = null
+	where
+		d.id = $1
+		and d.webhook_id = $2
+		and d.status <> 'pending'
+	returning
+		%s
+	`, SQLSelectDataWebhookDelivery)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	d := models.WebhookDelivery{}
+	err := tx.QueryRowContext(ctx, query, id, webhookID).Scan(webhookDeliveryDest(&d)...)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, e.ErrEditConflict
+		default:
+			return nil, err
+		}
+	}
+
+	return &d, nil
+}
+
+// ClaimDue takes the deliveries due for sending, skipping those another
+// replica is taking, and puts their next attempt lease ahead. They're sent
+// after tx commits, so a slow endpoint holds no locks; if the replica
+// dies before recording the outcome, the delivery is tried again once the
+// lease is over. Webhooks turned off keep their deliveries for when
+// they're back on, except pings.
+func (r *webhookDeliveryRepository) ClaimDue(limit int, lease time.Duration, tx *sql.Tx) ([]*models.WebhookDelivery, error) {
+	query := fmt.Sprintf(`
+	update webhook_deliveries d
+	set next_attempt_at = now() + $2::int * interval '1 second'
+	from webhooks w
+	where
+		w.id = d.webhook_id
+		and d.id in (
+			select q.id
+			from webhook_deliveries q
+			join webhooks qw on qw.id = q.webhook_id
+			where
+				q.status = 'pending'
+				and q.next_attempt_at <= now()
+				and qw.deleted = false
+				and (qw.active = true or q.event = 'ping')
+			order by q.next_attempt_at, q.id
+			limit $1
+			for update of q skip locked
+		)
+	returning
+		%s,
+		w.url,
+		w.secret
+	`, SQLSelectDataWebhookDelivery)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, query, limit, int(lease.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		d := models.WebhookDelivery{}
+		dest := append(webhookDeliveryDest(&d), &d.URL, &d.Secret)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookDeliveryRepository) RecordAttempt(a *models.WebhookAttempt, tx *sql.Tx) error {
+	query := `
+	insert into webhook_attempts (
+		delivery_id,
+		status_code,
+		error,
+		response_body,
+		duration_ms
+	)
+	values ($1,$2,$3,$4,$5)
+	returning
+		id,
+		attempted_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := []any{a.DeliveryID, a.StatusCode, a.Error, a.ResponseBody, a.Duration.Milliseconds()}
+	return tx.QueryRowContext(ctx, query, args...).Scan(&a.ID, &a.AttemptedAt)
+}
+
+func (r *webhookDeliveryRepository) MarkSucceeded(id int64, statusCode int, tx *sql.Tx) error {
+	query := `
+	update webhook_deliveries
+	set
+		status = 'succeeded',
+		attempts = attempts + 1,
+		last_status_code = $2,
+		last_error = null,
+		delivered_at = now()
+	where id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, id, statusCode)
+	return err
+}
+
+// MarkFailed records a failed attempt. The delivery is tried again at
+// retryAt, or dead-lettered when it's nil.
+func (r *webhookDeliveryRepository) MarkFailed(
+	id int64,
+	statusCode *int,
+	reason string,
+	retryAt *time.Time,
+	tx *sql.Tx,
+) error {
+	query := `
+	update webhook_deliveries
+	set
+		status = case when $4::timestamptz is null then 'dead' else 'pending' end,
+		attempts = attempts + 1,
+		last_status_code = $2,
+		last_error = $3,
+		next_attempt_at = coalesce($4, next_attempt_at)
+	where id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, id, statusCode, reason, retryAt)
+	return err
+}
+
+// EraseAllByCandidate deletes the deliveries about the user's
+// applications, whose payloads have their name, email and cover letter.
+func (r *webhookDeliveryRepository) EraseAllByCandidate(userID int64, tx *sql.Tx) error {
+	query := `
+	delete from webhook_deliveries
+	where
+		event like 'application.%'
+		and (payload #>> '{application,candidate,user_id}')::bigint = $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
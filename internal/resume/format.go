@@ -0,0 +1,69 @@
+package resume
+
+import (
+	"fmt"
+	"meu_job/internal/models"
+	"time"
+)
+
+var months = [12]string{
+	"jan.", "fev.", "mar.", "abr.", "mai.", "jun.",
+	"jul.", "ago.", "set.", "out.", "nov.", "dez.",
+}
+
+// monthYear formats a date the way Brazilian résumés do: "mar. 2021".
+func monthYear(t time.Time) string {
+	return fmt.Sprintf("%s %d", months[t.Month()-1], t.Year())
+}
+
+// period formats a date range; open ranges end with ongoing.
+func period(start time.Time, end *time.Time, ongoing string) string {
+	if end == nil {
+		return monthYear(start) + " – " + ongoing
+	}
+	return monthYear(start) + " – " + monthYear(*end)
+}
+
+var degreeLabels = map[models.EducationDegree]string{
+	models.DegreeElementary:   "Ensino fundamental",
+	models.DegreeHighSchool:   "Ensino médio",
+	models.DegreeTechnical:    "Curso técnico",
+	models.DegreeAssociate:    "Tecnólogo",
+	models.DegreeBachelor:     "Bacharelado",
+	models.DegreeLicentiate:   "Licenciatura",
+	models.DegreePostgraduate: "Pós-graduação",
+	models.DegreeMaster:       "Mestrado",
+	models.DegreeDoctorate:    "Doutorado",
+	models.DegreeMBA:          "MBA",
+	models.DegreeCertificate:  "Certificação",
+}
+
+var levelLabels = map[models.LanguageLevel]string{
+	models.LanguageBasic:        "Básico",
+	models.LanguageIntermediate: "Intermediário",
+	models.LanguageAdvanced:     "Avançado",
+	models.LanguageFluent:       "Fluente",
+	models.LanguageNative:       "Nativo",
+}
+
+var skillLevelLabels = map[models.SkillLevel]string{
+	models.SkillBeginner:     "iniciante",
+	models.SkillIntermediate: "intermediário",
+	models.SkillAdvanced:     "avançado",
+	models.SkillExpert:       "especialista",
+}
+
+// skill reads "Go (avançado)", or just the name when no level was given.
+func skill(s models.CurriculumSkill) string {
+	if s.Level == "" {
+		return s.Name
+	}
+	return s.Name + " (" + label(skillLevelLabels, s.Level) + ")"
+}
+
+func label[K ~string](labels map[K]string, key K) string {
+	if l, ok := labels[key]; ok {
+		return l
+	}
+	return string(key)
+}
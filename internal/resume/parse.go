@@ -0,0 +1,576 @@
+package resume
+
+import (
+	"fmt"
+	"meu_job/internal/models"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Draft is a curriculum read from a résumé's text. Confidence tells, per
+// field, how sure the parser is of what it filled in, from 0 to 1; fields
+// it left empty have none. Keys are the JSON paths of the field in a
+// CurriculumDTO, such as "experience[0].role".
+type Draft struct {
+	Curriculum *models.Curriculum
+	Confidence map[string]float64
+	// Unparsed keeps the text of sections that were found but couldn't be
+	// broken into entries, so the candidate can copy from it.
+	Unparsed map[string]string
+}
+
+type section int
+
+const (
+	sectionHeader section = iota
+	sectionSummary
+	sectionExperience
+	sectionEducation
+	sectionSkills
+	sectionLanguages
+	// sectionOther is for the headings we recognize but don't map, such as
+	// "Cursos" or "Projetos", so their lines don't leak into the section
+	// before them.
+	sectionOther
+)
+
+var sectionNames = map[section]string{
+	sectionSummary:    "summary",
+	sectionExperience: "experience",
+	sectionEducation:  "education",
+	sectionSkills:     "skills",
+	sectionLanguages:  "languages",
+}
+
+var headings = map[string]section{
+	"resumo": sectionSummary, "resumo-profissional": sectionSummary, "perfil": sectionSummary,
+	"perfil-profissional": sectionSummary, "objetivo": sectionSummary, "objetivo-profissional": sectionSummary,
+	"sobre": sectionSummary, "sobre-mim": sectionSummary, "summary": sectionSummary,
+	"professional-summary": sectionSummary, "profile": sectionSummary, "about": sectionSummary,
+	"about-me": sectionSummary, "objective": sectionSummary,
+
+	"experiencia": sectionExperience, "experiencias": sectionExperience, "experiencia-profissional": sectionExperience,
+	"experiencias-profissionais": sectionExperience, "historico-profissional": sectionExperience,
+	"experience": sectionExperience, "work-experience": sectionExperience, "professional-experience": sectionExperience,
+	"employment": sectionExperience, "employment-history": sectionExperience, "work-history": sectionExperience,
+
+	"formacao": sectionEducation, "formacao-academica": sectionEducation, "educacao": sectionEducation,
+	"escolaridade": sectionEducation, "education": sectionEducation, "academic-background": sectionEducation,
+
+	"habilidades": sectionSkills, "competencias": sectionSkills, "conhecimentos": sectionSkills,
+	"conhecimentos-tecnicos": sectionSkills, "habilidades-tecnicas": sectionSkills, "tecnologias": sectionSkills,
+	"skills": sectionSkills, "technical-skills": sectionSkills, "technologies": sectionSkills,
+	"competencies": sectionSkills,
+
+	"idiomas": sectionLanguages, "linguas": sectionLanguages, "languages": sectionLanguages,
+
+	"cursos": sectionOther, "cursos-complementares": sectionOther, "certificacoes": sectionOther,
+	"certificados": sectionOther, "projetos": sectionOther, "referencias": sectionOther,
+	"interesses": sectionOther, "voluntariado": sectionOther, "premios": sectionOther,
+	"publicacoes": sectionOther, "informacoes-adicionais": sectionOther, "contato": sectionOther,
+	"dados-pessoais": sectionOther, "courses": sectionOther, "certifications": sectionOther,
+	"projects": sectionOther, "references": sectionOther, "interests": sectionOther,
+	"volunteering": sectionOther, "awards": sectionOther, "publications": sectionOther,
+	"additional-information": sectionOther, "contact": sectionOther, "personal-information": sectionOther,
+}
+
+// heading tells whether a line is a section heading. Besides the exact
+// names, an all-caps line that starts with one counts, like
+// "EXPERIÊNCIA PROFISSIONAL RELEVANTE".
+func heading(line string) (section, bool) {
+	if len(line) > 60 {
+		return 0, false
+	}
+	slug := models.Slugify(strings.TrimRight(line, ":"))
+	if s, ok := headings[slug]; ok {
+		return s, true
+	}
+
+	if line != strings.ToUpper(line) || strings.Count(slug, "-") > 3 {
+		return 0, false
+	}
+	for name, s := range headings {
+		if strings.HasPrefix(slug, name+"-") {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+var (
+	emailRX = regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
+	phoneRX = regexp.MustCompile(`(?:\+?55\s*)?\(?\d{2}\)?[\s.-]*9?\s*\d{4}[\s.-]*\d{4}`)
+	urlRX   = regexp.MustCompile(`(?i)https?://|www\.|linkedin|github\.com`)
+)
+
+// Parse reads a résumé's text as extracted from a document. The result is
+// a starting point for the candidate to review, never something to save
+// as is.
+//
+// CPF and birth date are left out on purpose: they are sensitive and the
+// candidate can add them when reviewing.
+func Parse(text string) *Draft {
+	d := &Draft{
+		Curriculum: &models.Curriculum{},
+		Confidence: map[string]float64{},
+		Unparsed:   map[string]string{},
+	}
+
+	sections := map[section][]string{}
+	current := sectionHeader
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if s, ok := heading(line); ok {
+			current = s
+			continue
+		}
+		sections[current] = append(sections[current], line)
+	}
+
+	d.parseContact(text, sections[sectionHeader])
+	if lines := sections[sectionSummary]; len(lines) > 0 {
+		d.Curriculum.Summary = reflow(lines)
+		d.Confidence["summary"] = 0.8
+	}
+	d.parseExperience(sections[sectionExperience])
+	d.parseEducation(sections[sectionEducation])
+	d.parseSkills(sections[sectionSkills])
+	d.parseLanguages(sections[sectionLanguages])
+
+	return d
+}
+
+func (d *Draft) parseContact(text string, header []string) {
+	c := d.Curriculum
+
+	if email := emailRX.FindString(text); email != "" {
+		c.Email = strings.ToLower(email)
+		d.Confidence["email"] = 0.95
+	}
+
+	for _, m := range phoneRX.FindAllString(text, -1) {
+		if phone := models.NormalizePhone(m); phone.IsValid() {
+			c.Phone = phone
+			d.Confidence["phone"] = 0.9
+			break
+		}
+	}
+
+	// The name is the first line that looks like one; the profession, when
+	// given, usually comes right under it.
+	nameAt := -1
+	for i, line := range header {
+		if looksLikeName(line) {
+			c.FullName = line
+			d.Confidence["full_name"] = 0.7
+			nameAt = i
+			break
+		}
+	}
+	if nameAt < 0 && len(header) > 0 && !isContact(header[0]) {
+		c.FullName = header[0]
+		d.Confidence["full_name"] = 0.3
+		nameAt = 0
+	}
+
+	if nameAt >= 0 && nameAt+1 < len(header) {
+		next := header[nameAt+1]
+		if !isContact(next) && len(next) <= 80 && !strings.ContainsAny(next, "0123456789") {
+			c.Profession = next
+			d.Confidence["profession"] = 0.5
+		}
+	}
+}
+
+func isContact(line string) bool {
+	return emailRX.MatchString(line) || phoneRX.MatchString(line) || urlRX.MatchString(line)
+}
+
+func looksLikeName(line string) bool {
+	if isContact(line) || len(line) > 80 {
+		return false
+	}
+	words := strings.Fields(line)
+	if len(words) < 2 || len(words) > 6 {
+		return false
+	}
+	for _, r := range line {
+		if !unicode.IsLetter(r) && r != ' ' && r != '\'' && r != '.' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// entry is the lines of one job or course: the heading lines that name it,
+// the period and what's left of the line it was on, and the body.
+type entry struct {
+	header []string
+	period *span
+	body   []string
+}
+
+// splitEntries breaks a section at the lines that carry a period. When the
+// period is alone on its line, the one or two short lines right above it
+// name the entry; when it shares the line with a single name, the line
+// right below holds the other. The lines after that, up to the next entry,
+// are its body.
+func splitEntries(lines []string) []entry {
+	var anchors []int
+	for i, line := range lines {
+		if findPeriod(line) != nil {
+			anchors = append(anchors, i)
+		}
+	}
+	if len(anchors) == 0 {
+		return nil
+	}
+
+	entries := make([]entry, len(anchors))
+	starts := make([]int, len(anchors))
+	for k, at := range anchors {
+		p := findPeriod(lines[at])
+		rest := trimSeparators(lines[at][:p.loc[0]] + " " + lines[at][p.loc[1]:])
+		e := entry{period: p}
+
+		floor := 0
+		if k > 0 {
+			floor = anchors[k-1] + 1
+		}
+		start := at
+		if rest != "" {
+			e.header = []string{rest}
+		}
+		for rest == "" && start > floor && len(e.header) < 2 && isTitle(lines[start-1]) {
+			start--
+			e.header = append([]string{lines[start]}, e.header...)
+		}
+		entries[k], starts[k] = e, start
+	}
+
+	for k, at := range anchors {
+		end := len(lines)
+		if k+1 < len(anchors) {
+			end = starts[k+1]
+		}
+		body := lines[at+1 : max(at+1, end)]
+		// "Role  mar. 2019 – atual" followed by the company is common, and
+		// is what Render writes.
+		header := entries[k].header
+		if at == starts[k] && len(header) == 1 && len(splitTitle(header[0])) == 1 && len(body) > 0 && isTitle(body[0]) {
+			entries[k].header = append(entries[k].header, body[0])
+			body = body[1:]
+		}
+		entries[k].body = body
+	}
+
+	return entries
+}
+
+// isTitle tells a short naming line from a line of description.
+func isTitle(line string) bool {
+	if len(line) > 70 || isBullet(line) || findPeriod(line) != nil {
+		return false
+	}
+	// A final period is allowed on short lines, for "Exemplo S.A." and alike.
+	switch line[len(line)-1] {
+	case ';', ':', ',':
+		return false
+	case '.':
+		return len(strings.Fields(line)) <= 4
+	}
+	return true
+}
+
+func isBullet(line string) bool {
+	return strings.HasPrefix(line, "•") || strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") ||
+		strings.HasPrefix(line, "▪") || strings.HasPrefix(line, "●") || strings.HasPrefix(line, "◦") ||
+		strings.HasPrefix(line, "– ") || strings.HasPrefix(line, "·")
+}
+
+// reflow joins lines wrapped by the document's layout back into
+// paragraphs, keeping bullets on lines of their own.
+func reflow(lines []string) string {
+	var out []string
+	for _, line := range lines {
+		if len(out) == 0 || isBullet(line) {
+			out = append(out, line)
+			continue
+		}
+		out[len(out)-1] += " " + line
+	}
+	return strings.Join(out, "\n")
+}
+
+var separatorRX = regexp.MustCompile(`\s+(?:\||—|–|-|@|·|•|at|na|no)\s+|\s*[|,]\s*`)
+
+func trimSeparators(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("|-–—,·•()[]", r)
+	})
+}
+
+// splitTitle breaks "Desenvolvedor — ACME" and alike in two.
+func splitTitle(line string) []string {
+	var parts []string
+	for _, p := range separatorRX.Split(line, 3) {
+		if p = trimSeparators(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+var roleWords = []string{
+	"desenvolvedor", "desenvolvedora", "engenheiro", "engenheira", "analista", "gerente", "coordenador",
+	"coordenadora", "diretor", "diretora", "assistente", "auxiliar", "estagiario", "estagiaria", "tecnico",
+	"tecnica", "consultor", "consultora", "supervisor", "supervisora", "vendedor", "vendedora", "atendente",
+	"professor", "professora", "designer", "arquiteto", "arquiteta", "especialista", "lider", "programador",
+	"programadora", "operador", "operadora", "administrador", "administradora", "developer", "engineer",
+	"analyst", "manager", "director", "assistant", "intern", "consultant", "lead", "head", "architect",
+	"specialist", "coordinator", "administrator", "scientist", "cientista", "junior", "pleno", "senior",
+}
+
+var companyWords = []string{
+	"ltda", "ltd", "s-a", "sa", "eireli", "me", "inc", "corp", "llc", "gmbh", "grupo", "group", "banco",
+	"bank", "tecnologia", "technologies", "solucoes", "solutions", "consultoria", "servicos", "industria",
+	"comercio", "company", "companhia", "cia", "holding", "labs", "startup", "prefeitura", "hospital",
+	"universidade", "university", "faculdade", "college", "instituto", "institute", "escola", "school",
+	"fundacao", "centro", "fatec", "etec", "senai", "senac", "usp", "unicamp", "unesp", "puc", "fgv",
+	"mackenzie",
+}
+
+func hasWord(text string, words []string) bool {
+	slug := "-" + models.Slugify(text) + "-"
+	for _, w := range words {
+		if strings.Contains(slug, "-"+w+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// assign decides which of two names is the role and which the company. It
+// goes by the words in them and, failing that, by order: role first.
+func assign(parts []string) (role, company string, sure bool) {
+	switch len(parts) {
+	case 0:
+		return "", "", false
+	case 1:
+		if hasWord(parts[0], companyWords) && !hasWord(parts[0], roleWords) {
+			return "", parts[0], true
+		}
+		return parts[0], "", hasWord(parts[0], roleWords)
+	}
+
+	a, b := parts[0], parts[1]
+	switch {
+	case hasWord(a, roleWords) && !hasWord(a, companyWords), hasWord(b, companyWords) && !hasWord(b, roleWords):
+		return a, b, true
+	case hasWord(b, roleWords) && !hasWord(b, companyWords), hasWord(a, companyWords) && !hasWord(a, roleWords):
+		return b, a, true
+	}
+	return a, b, false
+}
+
+func (d *Draft) setPeriod(key string, p *span, start *models.Date, end **models.Date) {
+	*start = models.NewDate(p.start)
+	confidence := 0.9
+	if !p.precise {
+		confidence = 0.6
+	}
+	d.Confidence[key+".start_date"] = confidence
+	if p.end != nil {
+		e := models.NewDate(*p.end)
+		*end = &e
+	}
+	d.Confidence[key+".end_date"] = confidence
+}
+
+func (d *Draft) parseExperience(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	entries := splitEntries(lines)
+	if len(entries) == 0 {
+		d.Unparsed[sectionNames[sectionExperience]] = strings.Join(lines, "\n")
+		return
+	}
+
+	for i, e := range entries {
+		key := fmt.Sprintf("experience[%d]", i)
+
+		var parts []string
+		for _, line := range e.header {
+			parts = append(parts, splitTitle(line)...)
+		}
+		role, company, sure := assign(parts)
+
+		var dto models.ExperienceDTO
+		d.setPeriod(key, e.period, &dto.StartDate, &dto.EndDate)
+
+		confidence := 0.4
+		if sure {
+			confidence = 0.8
+		}
+		if role != "" {
+			dto.Role = role
+			d.Confidence[key+".role"] = confidence
+		}
+		if company != "" {
+			dto.Company = company
+			d.Confidence[key+".company"] = confidence
+		}
+		if len(e.body) > 0 {
+			dto.Description = reflow(e.body)
+			d.Confidence[key+".description"] = 0.6
+		}
+
+		d.Curriculum.Experience = append(d.Curriculum.Experience, dto.ToModel())
+	}
+}
+
+// parseEducation splits at periods, like experience, or else at the lines
+// naming a degree, since courses are often listed with the year of
+// graduation only.
+func (d *Draft) parseEducation(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+
+	entries := splitEntries(lines)
+	if len(entries) == 0 {
+		for _, line := range lines {
+			if _, ok := models.ParseEducationDegree(line); !ok {
+				continue
+			}
+			e := entry{header: []string{line}}
+			if t, loc, ok := findDate(line); ok {
+				e.header[0] = trimSeparators(line[:loc[0]] + " " + line[loc[1]:])
+				e.period = &span{start: t, end: &t}
+			}
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		d.Unparsed[sectionNames[sectionEducation]] = strings.Join(lines, "\n")
+		return
+	}
+
+	for i, e := range entries {
+		key := fmt.Sprintf("education[%d]", i)
+		var dto models.EducationDTO
+
+		var parts []string
+		for _, line := range append(e.header, e.body...) {
+			parts = append(parts, splitTitle(line)...)
+		}
+
+		degreeAt := -1
+		for j, part := range parts {
+			if degree, ok := models.ParseEducationDegree(part); ok {
+				dto.Degree = degree
+				d.Confidence[key+".degree"] = 0.8
+				degreeAt = j
+				break
+			}
+		}
+
+		for j, part := range parts {
+			if j != degreeAt && hasWord(part, companyWords) {
+				dto.Institution = part
+				d.Confidence[key+".institution"] = 0.8
+				break
+			}
+		}
+		if dto.Institution == "" {
+			for j, part := range parts {
+				if j != degreeAt {
+					dto.Institution = part
+					d.Confidence[key+".institution"] = 0.4
+					break
+				}
+			}
+		}
+
+		if e.period != nil {
+			d.setPeriod(key, e.period, &dto.StartDate, &dto.EndDate)
+			if e.period.end != nil && e.period.start.Equal(*e.period.end) {
+				// Only the year of graduation was given.
+				d.Confidence[key+".start_date"] = 0.2
+			}
+		}
+
+		d.Curriculum.Education = append(d.Curriculum.Education, dto.ToModel())
+	}
+}
+
+var (
+	listRX       = regexp.MustCompile(`\s*[,;|•·▪●◦/]\s*|\s+-\s+`)
+	skillLevelRX = regexp.MustCompile(`^(.+?)\s*\(([^()]+)\)$`)
+)
+
+func (d *Draft) parseSkills(lines []string) {
+	seen := map[string]bool{}
+	for _, line := range lines {
+		// "Linguagens: Go, Python" lists under a label.
+		if i := strings.Index(line, ":"); i >= 0 && i <= 30 {
+			line = line[i+1:]
+		}
+		for _, name := range listRX.Split(line, -1) {
+			skill := models.CurriculumSkill{Name: strings.TrimSpace(strings.TrimRight(name, "."))}
+			// "Go (avançado)" is how the level is written, by us and by most.
+			if m := skillLevelRX.FindStringSubmatch(skill.Name); m != nil {
+				if level, ok := models.ParseSkillLevel(m[2]); ok {
+					skill.Name, skill.Level = m[1], level
+				}
+			}
+			skill.Name = trimSeparators(skill.Name)
+
+			key := models.SkillKey(skill.Name)
+			if key == "" || len(skill.Name) > 50 || len(strings.Fields(skill.Name)) > 5 || seen[key] {
+				continue
+			}
+			seen[key] = true
+			d.Curriculum.Skills = append(d.Curriculum.Skills, skill)
+		}
+	}
+	if len(d.Curriculum.Skills) > 0 {
+		d.Confidence["skills"] = 0.7
+	}
+}
+
+var (
+	itemRX         = regexp.MustCompile(`\s*[,;|•·]\s*`)
+	languageNameRX = regexp.MustCompile(`^[\p{L} ]+?\s*(?:[-–—:(]|$)`)
+)
+
+func (d *Draft) parseLanguages(lines []string) {
+	for _, line := range lines {
+		for _, item := range itemRX.Split(line, -1) {
+			name := strings.TrimRight(strings.TrimSpace(languageNameRX.FindString(item)), "-–—:( ")
+			if name == "" {
+				continue
+			}
+
+			level, ok := models.ParseLanguageLevel(item)
+			if ok && strings.EqualFold(name, item) {
+				// "Inglês avançado": the level is part of the name.
+				if words := strings.Fields(name); len(words) > 1 {
+					name = words[0]
+				}
+			}
+
+			key := fmt.Sprintf("languages[%d]", len(d.Curriculum.Languages))
+			d.Curriculum.Languages = append(d.Curriculum.Languages, models.LanguageEntry{Name: name, Level: level})
+			d.Confidence[key+".name"] = 0.8
+			if ok {
+				d.Confidence[key+".level"] = 0.8
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package resume
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const monthPattern = `(?:jan(?:eiro|uary)?|fev(?:ereiro)?|feb(?:ruary)?|mar(?:ço|co|ch)?|abr(?:il)?|apr(?:il)?|mai(?:o)?|may|jun(?:ho|e)?|jul(?:ho|y)?|ago(?:sto)?|aug(?:ust)?|set(?:embro)?|sep(?:t(?:ember)?)?|out(?:ubro)?|oct(?:ober)?|nov(?:embro|ember)?|dez(?:embro)?|dec(?:ember)?)\.?`
+
+const datePattern = `(?:\b` + monthPattern + `\s*(?:de\s+|/\s*|-\s*|\s)\d{4}|\b\d{1,2}\s*[/.-]\s*\d{4}|\b(?:19|20)\d{2})\b`
+
+const presentPattern = `(?:atualmente|atual|presente|o\s+momento|hoje|em\s+andamento|cursando|present|current|now|today)`
+
+var (
+	rangeRX   = regexp.MustCompile(`(?i)(` + datePattern + `)\s*(?:-|–|—|~|\ba\b|\baté\b|\bate\b|\bto\b|\buntil\b)\s*(` + datePattern + `|` + presentPattern + `)`)
+	sinceRX   = regexp.MustCompile(`(?i)\b(?:desde|since)\s+(` + datePattern + `)`)
+	dateRX    = regexp.MustCompile(`(?i)` + datePattern)
+	presentRX = regexp.MustCompile(`(?i)^` + presentPattern + `$`)
+	monthRX   = regexp.MustCompile(`(?i)^` + monthPattern)
+	numericRX = regexp.MustCompile(`^(\d{1,2})\s*[/.-]\s*(\d{4})$`)
+	yearRX    = regexp.MustCompile(`\d{4}`)
+)
+
+var monthPrefixes = map[string]time.Month{
+	"jan": time.January, "fev": time.February, "feb": time.February,
+	"mar": time.March, "abr": time.April, "apr": time.April,
+	"mai": time.May, "may": time.May, "jun": time.June, "jul": time.July,
+	"ago": time.August, "aug": time.August, "set": time.September,
+	"sep": time.September, "out": time.October, "oct": time.October,
+	"nov": time.November, "dez": time.December, "dec": time.December,
+}
+
+// span is a date range found in a line. Precise is false when only
+// years were given.
+type span struct {
+	start   time.Time
+	end     *time.Time
+	precise bool
+	// loc is where the range sits in the line, so it can be cut out.
+	loc []int
+}
+
+// findPeriod looks for "mar. 2019 – atual", "03/2019 a 12/2021",
+// "2015 - 2019", "desde 2020" and alike, in Portuguese or English.
+func findPeriod(line string) *span {
+	if m := rangeRX.FindStringSubmatchIndex(line); m != nil {
+		start, precise, ok := parseDate(line[m[2]:m[3]])
+		if !ok {
+			return nil
+		}
+		p := &span{start: start, precise: precise, loc: m[:2]}
+
+		endText := line[m[4]:m[5]]
+		if !presentRX.MatchString(strings.TrimSpace(endText)) {
+			end, endPrecise, ok := parseDate(endText)
+			if !ok {
+				return nil
+			}
+			p.end = &end
+			p.precise = p.precise && endPrecise
+		}
+		return p
+	}
+
+	if m := sinceRX.FindStringSubmatchIndex(line); m != nil {
+		start, precise, ok := parseDate(line[m[2]:m[3]])
+		if !ok {
+			return nil
+		}
+		return &span{start: start, precise: precise, loc: m[:2]}
+	}
+
+	return nil
+}
+
+// findDate looks for a single date, such as a graduation year.
+func findDate(line string) (time.Time, []int, bool) {
+	loc := dateRX.FindStringIndex(line)
+	if loc == nil {
+		return time.Time{}, nil, false
+	}
+	t, _, ok := parseDate(line[loc[0]:loc[1]])
+	return t, loc, ok
+}
+
+func parseDate(s string) (time.Time, bool, bool) {
+	s = strings.TrimSpace(strings.ToLower(s))
+
+	year, err := strconv.Atoi(yearRX.FindString(s))
+	if err != nil || year < 1950 || year > time.Now().Year()+10 {
+		return time.Time{}, false, false
+	}
+
+	if m := numericRX.FindStringSubmatch(s); m != nil {
+		month, _ := strconv.Atoi(m[1])
+		if month < 1 || month > 12 {
+			return time.Time{}, false, false
+		}
+		return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC), true, true
+	}
+
+	if monthRX.MatchString(s) && len(s) >= 3 {
+		if month, ok := monthPrefixes[s[:3]]; ok {
+			return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC), true, true
+		}
+	}
+
+	return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), false, true
+}
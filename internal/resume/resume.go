@@ -0,0 +1,201 @@
+// Package resume lays a curriculum out as a printable PDF.
+package resume
+
+import (
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/pdf"
+	"strings"
+)
+
+const (
+	margin      = 50.0
+	bodySize    = 10.0
+	lineSpacing = 1.35
+)
+
+// Render writes the curriculum with the given template. CPF and birth date
+// are left out on purpose: they are not needed to evaluate a candidate.
+func Render(c *models.Curriculum, tpl Template) ([]byte, error) {
+	st, ok := styles[tpl]
+	if !ok {
+		return nil, fmt.Errorf("unknown template %q", tpl)
+	}
+
+	w := &writer{
+		doc:   pdf.New("Currículo - " + c.FullName),
+		style: st,
+	}
+	w.newPage()
+
+	w.header(c)
+
+	if c.Summary != "" {
+		w.heading("Resumo")
+		w.paragraph(pdf.Helvetica, bodySize, w.style.text, c.Summary)
+	}
+
+	if len(c.Experience) > 0 {
+		w.heading("Experiência profissional")
+		for _, entry := range c.Experience {
+			w.entry(entry.Role, entry.Company, period(entry.StartDate, entry.EndDate, "atual"))
+			if entry.Description != "" {
+				w.paragraph(pdf.Helvetica, bodySize, w.style.text, entry.Description)
+			}
+			w.y -= 6
+		}
+	}
+
+	if len(c.Education) > 0 {
+		w.heading("Formação acadêmica")
+		for _, entry := range c.Education {
+			w.entry(label(degreeLabels, entry.Degree), entry.Institution, period(entry.StartDate, entry.EndDate, "em andamento"))
+			w.y -= 6
+		}
+	}
+
+	if len(c.Skills) > 0 {
+		w.heading("Habilidades")
+		skills := make([]string, len(c.Skills))
+		for i, s := range c.Skills {
+			skills[i] = skill(s)
+		}
+		w.paragraph(pdf.Helvetica, bodySize, w.style.text, strings.Join(skills, "  •  "))
+	}
+
+	if len(c.Languages) > 0 {
+		w.heading("Idiomas")
+		for _, language := range c.Languages {
+			w.paragraph(pdf.Helvetica, bodySize, w.style.text, language.Name+" – "+label(levelLabels, language.Level))
+		}
+	}
+
+	w.footer()
+	return w.doc.Bytes()
+}
+
+// writer flows content down the pages, starting a new one whenever the
+// next block doesn't fit.
+type writer struct {
+	doc   *pdf.Document
+	page  *pdf.Page
+	style style
+	y     float64
+}
+
+func (w *writer) newPage() {
+	w.page = w.doc.AddPage()
+	w.y = pdf.PageHeight - margin
+}
+
+func (w *writer) ensure(height float64) {
+	if w.y-height < margin+20 {
+		w.newPage()
+	}
+}
+
+func (w *writer) width() float64 {
+	return pdf.PageWidth - 2*margin
+}
+
+func (w *writer) line(font pdf.Font, size float64, color pdf.Color, text string) {
+	w.ensure(size * lineSpacing)
+	w.y -= size
+	w.page.Text(margin, w.y, font, size, color, text)
+	w.y -= size * (lineSpacing - 1)
+}
+
+func (w *writer) centered(font pdf.Font, size float64, color pdf.Color, text string) {
+	w.ensure(size * lineSpacing)
+	w.y -= size
+	x := (pdf.PageWidth - pdf.TextWidth(font, size, text)) / 2
+	w.page.Text(x, w.y, font, size, color, text)
+	w.y -= size * (lineSpacing - 1)
+}
+
+func (w *writer) paragraph(font pdf.Font, size float64, color pdf.Color, text string) {
+	for _, l := range pdf.Wrap(font, size, w.width(), text) {
+		w.line(font, size, color, l)
+	}
+}
+
+func contactLine(c *models.Curriculum) string {
+	parts := []string{}
+	location := ""
+	if c.City != "" {
+		location = c.City + " - " + c.UF
+	}
+	for _, part := range []string{location, c.Email, c.Phone.Format()} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "  •  ")
+}
+
+func (w *writer) header(c *models.Curriculum) {
+	if w.style.banner {
+		const height = 105.0
+		w.page.Rect(0, pdf.PageHeight-height, pdf.PageWidth, height, w.style.accent)
+		w.y = pdf.PageHeight - 30
+		w.line(pdf.HelveticaBold, 24, pdf.White, c.FullName)
+		if c.Profession != "" {
+			w.line(pdf.Helvetica, 12, pdf.White, c.Profession)
+		}
+		w.line(pdf.Helvetica, 9.5, pdf.White, contactLine(c))
+		w.y = pdf.PageHeight - height - 10
+		return
+	}
+
+	w.centered(pdf.HelveticaBold, 22, w.style.accent, c.FullName)
+	if c.Profession != "" {
+		w.centered(pdf.Helvetica, 12, w.style.muted, c.Profession)
+	}
+	w.centered(pdf.Helvetica, 9.5, w.style.muted, contactLine(c))
+	w.y -= 4
+	w.page.Line(margin, w.y, pdf.PageWidth-margin, w.y, 0.8, w.style.accent)
+	w.y -= 4
+}
+
+// heading keeps the title together with at least a couple of lines below it.
+func (w *writer) heading(title string) {
+	if w.style.upperHeadings {
+		title = strings.ToUpper(title)
+	}
+
+	w.ensure(60)
+	w.y -= 12
+	w.line(pdf.HelveticaBold, 12, w.style.accent, title)
+	w.page.Line(margin, w.y+2, pdf.PageWidth-margin, w.y+2, 0.5, w.style.muted)
+	w.y -= 6
+}
+
+// entry writes a title with its date range aligned right and a subtitle.
+func (w *writer) entry(title, subtitle, dates string) {
+	const size = 11.0
+	w.ensure(size*lineSpacing + bodySize*lineSpacing)
+
+	datesWidth := pdf.TextWidth(pdf.Helvetica, 9, dates)
+	titleLines := pdf.Wrap(pdf.HelveticaBold, size, w.width()-datesWidth-12, title)
+
+	w.y -= size
+	w.page.Text(pdf.PageWidth-margin-datesWidth, w.y, pdf.Helvetica, 9, w.style.muted, dates)
+	w.page.Text(margin, w.y, pdf.HelveticaBold, size, w.style.text, titleLines[0])
+	w.y -= size * (lineSpacing - 1)
+	for _, l := range titleLines[1:] {
+		w.line(pdf.HelveticaBold, size, w.style.text, l)
+	}
+
+	if subtitle != "" {
+		w.paragraph(pdf.HelveticaOblique, bodySize, w.style.muted, subtitle)
+	}
+}
+
+func (w *writer) footer() {
+	pages := w.doc.Pages()
+	for i, page := range pages {
+		text := fmt.Sprintf("Página %d de %d", i+1, len(pages))
+		x := pdf.PageWidth - margin - pdf.TextWidth(pdf.Helvetica, 8, text)
+		page.Text(x, margin/2, pdf.Helvetica, 8, w.style.muted, text)
+	}
+}
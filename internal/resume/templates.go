@@ -0,0 +1,45 @@
+package resume
+
+import "meu_job/internal/pdf"
+
+type Template string
+
+const (
+	Classic Template = "classic"
+	Modern  Template = "modern"
+)
+
+// Templates lists the accepted template names, the first being the default.
+var Templates = []string{string(Classic), string(Modern)}
+
+func (t Template) IsValid() bool {
+	_, ok := styles[t]
+	return ok
+}
+
+type style struct {
+	accent pdf.Color
+	text   pdf.Color
+	muted  pdf.Color
+
+	// banner draws the header as a filled band in the accent color;
+	// otherwise it is centered over a rule.
+	banner bool
+	// upperHeadings prints section titles in capitals.
+	upperHeadings bool
+}
+
+var styles = map[Template]style{
+	Classic: {
+		accent:        pdf.RGB(40, 40, 40),
+		text:          pdf.RGB(30, 30, 30),
+		muted:         pdf.RGB(100, 100, 100),
+		upperHeadings: true,
+	},
+	Modern: {
+		accent: pdf.RGB(22, 94, 131),
+		text:   pdf.RGB(33, 37, 41),
+		muted:  pdf.RGB(108, 117, 125),
+		banner: true,
+	},
+}
@@ -0,0 +1,55 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+	"meu_job/internal/middleware"
+	"meu_job/internal/models"
+
+	"github.com/go-chi/chi"
+)
+
+type adminRouter struct {
+	verification handlers.VerificationHandlerInterface
+	skill        handlers.SkillHandlerInterface
+	m            middleware.MiddlewareInterface
+}
+
+type AdminRouterInterface interface {
+	AdminRoutes(r chi.Router)
+}
+
+func NewAdminRouter(
+	verification handlers.VerificationHandlerInterface,
+	skill handlers.SkillHandlerInterface,
+	m middleware.MiddlewareInterface,
+) *adminRouter {
+	return &adminRouter{
+		verification: verification,
+		skill:        skill,
+		m:            m,
+	}
+}
+
+func (a *adminRouter) AdminRoutes(r chi.Router) {
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(a.m.RequireActivatedUser)
+		r.Use(a.m.RequirePermission([]models.Role{models.ADMIN}))
+		r.Use(a.m.RequireScope(models.ScopeAccount))
+
+		r.Route("/business-verifications", func(r chi.Router) {
+			r.Get("/", a.verification.Queue)
+			r.Get("/{id}", a.verification.ReviewDetails)
+			r.Get("/{id}/documents/{documentID}", a.verification.Document)
+			r.Post("/{id}/approve", a.verification.Approve)
+			r.Post("/{id}/reject", a.verification.Reject)
+		})
+
+		r.Route("/skills", func(r chi.Router) {
+			r.Get("/", a.skill.Search)
+			r.Post("/", a.skill.Save)
+			r.Get("/{id}", a.skill.FindByID)
+			r.Put("/{id}", a.skill.Update)
+			r.Post("/{id}/merge", a.skill.Merge)
+		})
+	})
+}
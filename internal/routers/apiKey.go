@@ -0,0 +1,40 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+	"meu_job/internal/middleware"
+	"meu_job/internal/models"
+
+	"github.com/go-chi/chi"
+)
+
+type apiKeyRouter struct {
+	apiKey handlers.APIKeyHandlerInterface
+	m      middleware.MiddlewareInterface
+}
+
+type APIKeyRouterInterface interface {
+	APIKeyRoutes(r chi.Router)
+}
+
+func NewAPIKeyRouter(
+	apiKey handlers.APIKeyHandlerInterface,
+	m middleware.MiddlewareInterface,
+) *apiKeyRouter {
+	return &apiKeyRouter{
+		apiKey: apiKey,
+		m:      m,
+	}
+}
+
+func (a *apiKeyRouter) APIKeyRoutes(r chi.Router) {
+	r.Route("/api-keys", func(r chi.Router) {
+		r.Use(a.m.RequireActivatedUser)
+		r.Use(a.m.RequireCurrentConsent)
+		r.Use(a.m.RequireScope(models.ScopeAccount))
+
+		r.Get("/", a.apiKey.FindAll)
+		r.Post("/", a.apiKey.Create)
+		r.Delete("/{id}", a.apiKey.Revoke)
+	})
+}
@@ -0,0 +1,62 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+	"meu_job/internal/middleware"
+	"meu_job/internal/models"
+
+	"github.com/go-chi/chi"
+)
+
+type applicationRouter struct {
+	application handlers.ApplicationHandlerInterface
+	interview   handlers.InterviewHandlerInterface
+	message     handlers.MessageHandlerInterface
+	m           middleware.MiddlewareInterface
+}
+
+type ApplicationRouterInterface interface {
+	ApplicationRoutes(r chi.Router)
+}
+
+func NewApplicationRouter(
+	application handlers.ApplicationHandlerInterface,
+	interview handlers.InterviewHandlerInterface,
+	message handlers.MessageHandlerInterface,
+	m middleware.MiddlewareInterface,
+) *applicationRouter {
+	return &applicationRouter{
+		application: application,
+		interview:   interview,
+		message:     message,
+		m:           m,
+	}
+}
+
+func (a *applicationRouter) ApplicationRoutes(r chi.Router) {
+	r.Get("/calendar/{token}.ics", a.interview.CalendarFeed)
+
+	r.Route("/applications", func(r chi.Router) {
+		r.Use(a.m.RequireActivatedUser)
+		r.Use(a.m.RequireCurrentConsent)
+		r.Use(a.m.RequireScope(models.ScopeAccount))
+
+		r.Get("/", a.application.FindAll)
+		r.Post("/", a.application.Apply)
+		r.Get("/{id}", a.application.FindByID)
+		r.Post("/{id}/withdraw", a.application.Withdraw)
+
+		r.Route("/{id}/interviews", func(r chi.Router) {
+			r.Get("/", a.interview.FindAllByApplication)
+			r.Get("/{interviewID}.ics", a.interview.InviteForCandidate)
+			r.Post("/{interviewID}/accept", a.interview.Accept)
+			r.Post("/{interviewID}/cancel", a.interview.CancelByCandidate)
+		})
+
+		r.Route("/{id}/messages", func(r chi.Router) {
+			r.Get("/", a.message.FindAllByApplication)
+			r.Post("/", a.message.SendAsCandidate)
+			r.Post("/read", a.message.MarkReadByCandidate)
+		})
+	})
+}
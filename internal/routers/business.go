@@ -9,8 +9,14 @@ import (
 )
 
 type businessRouter struct {
-	business handlers.BusinessHandlerInterface
-	m        middleware.MiddlewareInterface
+	business     handlers.BusinessHandlerInterface
+	verification handlers.VerificationHandlerInterface
+	job          handlers.JobHandlerInterface
+	application  handlers.ApplicationHandlerInterface
+	interview    handlers.InterviewHandlerInterface
+	message      handlers.MessageHandlerInterface
+	webhook      handlers.WebhookHandlerInterface
+	m            middleware.MiddlewareInterface
 }
 
 type BusinessRouterInterface interface {
@@ -19,25 +25,94 @@ type BusinessRouterInterface interface {
 
 func NewBusinessRouter(
 	business handlers.BusinessHandlerInterface,
+	verification handlers.VerificationHandlerInterface,
+	job handlers.JobHandlerInterface,
+	application handlers.ApplicationHandlerInterface,
+	interview handlers.InterviewHandlerInterface,
+	message handlers.MessageHandlerInterface,
+	webhook handlers.WebhookHandlerInterface,
 	m middleware.MiddlewareInterface,
 ) *businessRouter {
 	return &businessRouter{
-		business: business,
-		m:        m,
+		business:     business,
+		verification: verification,
+		job:          job,
+		application:  application,
+		interview:    interview,
+		message:      message,
+		webhook:      webhook,
+		m:            m,
 	}
 }
 
 func (b *businessRouter) BusinessRoutes(r chi.Router) {
 	r.Route("/business", func(r chi.Router) {
 		r.Use(b.m.RequireActivatedUser)
+		r.Use(b.m.RequireCurrentConsent)
 
-		r.Get("/{id}", b.business.FindByID)
-		r.Get("/", b.business.FindAll)
+		read := b.m.RequireScope(models.ScopeBusinessRead)
+		write := b.m.RequireScope(models.ScopeBusinessWrite)
+
+		r.With(read).Get("/{id}", b.business.FindByID)
+		r.With(read).Get("/", b.business.FindAll)
 		adminOnly := b.m.RequirePermission([]models.Role{models.ADMIN})
 
-		r.With(adminOnly).Post("/add_user/{businessID}/{userID}", b.business.AddUserInBusiness)
-		r.With(adminOnly).Post("/", b.business.Save)
-		r.With(adminOnly).Put("/", b.business.Update)
-		r.With(adminOnly).Delete("/{id}", b.business.Delete)
+		r.With(adminOnly, write).Post("/add_user/{businessID}/{userID}", b.business.AddUserInBusiness)
+		r.With(adminOnly, write).Post("/", b.business.Save)
+		r.With(adminOnly, write).Put("/", b.business.Update)
+		r.With(adminOnly, write).Delete("/{id}", b.business.Delete)
+
+		members := b.m.RequirePermission([]models.Role{models.BUSINESS, models.ADMIN})
+		r.With(members, write).Put("/{id}/visibility", b.business.SetVisibility)
+		r.With(members, write).Post("/{id}/logo", b.business.UploadLogo)
+
+		r.Route("/{id}/verification", func(r chi.Router) {
+			r.With(members, read).Get("/", b.verification.Status)
+			r.With(members, write).Post("/", b.verification.Submit)
+			r.With(members, write).Post("/documents", b.verification.UploadDocument)
+		})
+
+		r.Route("/{id}/jobs", func(r chi.Router) {
+			r.With(members, read).Get("/", b.job.FindAll)
+			r.With(members, write).Post("/", b.job.Save)
+			r.With(members, read).Get("/{jobID}", b.job.FindByID)
+			r.With(members, write).Put("/{jobID}", b.job.Update)
+			r.With(members, write).Delete("/{jobID}", b.job.Delete)
+			r.With(members, write).Post("/{jobID}/publish", b.job.Publish)
+			r.With(members, write).Post("/{jobID}/close", b.job.Close)
+			r.With(members, read).Get("/{jobID}/candidates", b.job.Candidates)
+			r.With(members, read).Get("/{jobID}/applications", b.application.FindAllByJob)
+		})
+
+		r.Route("/{id}/applications/{applicationID}", func(r chi.Router) {
+			r.With(members, read).Get("/", b.application.FindForBusiness)
+			r.With(members, write).Post("/stage", b.application.SetStage)
+			r.With(members, read).Get("/interviews", b.interview.FindAllForBusiness)
+			r.With(members, write).Post("/interviews", b.interview.Propose)
+			r.With(members, read).Get("/messages", b.message.FindAllForBusiness)
+			r.With(members, write).Post("/messages", b.message.SendAsBusiness)
+			r.With(members, read).Post("/messages/read", b.message.MarkReadByBusiness)
+		})
+
+		r.Route("/{id}/interviews", func(r chi.Router) {
+			r.With(members, read).Get("/", b.interview.FindUpcoming)
+			r.With(members, read).Get("/{interviewID}.ics", b.interview.InviteForBusiness)
+			r.With(members, read).Get("/{interviewID}", b.interview.FindForBusiness)
+			r.With(members, write).Post("/{interviewID}/reschedule", b.interview.Reschedule)
+			r.With(members, write).Post("/{interviewID}/cancel", b.interview.CancelByBusiness)
+		})
+
+		r.Route("/{id}/webhooks", func(r chi.Router) {
+			r.With(members, read).Get("/", b.webhook.FindAll)
+			r.With(members, write).Post("/", b.webhook.Save)
+			r.With(members, read).Get("/{webhookID}", b.webhook.FindByID)
+			r.With(members, write).Put("/{webhookID}", b.webhook.Update)
+			r.With(members, write).Delete("/{webhookID}", b.webhook.Delete)
+			r.With(members, write).Post("/{webhookID}/secret", b.webhook.RotateSecret)
+			r.With(members, write).Post("/{webhookID}/ping", b.webhook.Ping)
+			r.With(members, read).Get("/{webhookID}/deliveries", b.webhook.FindDeliveries)
+			r.With(members, read).Get("/{webhookID}/deliveries/{deliveryID}", b.webhook.FindDelivery)
+			r.With(members, write).Post("/{webhookID}/deliveries/{deliveryID}/redeliver", b.webhook.Redeliver)
+		})
 	})
 }
@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+
+	"github.com/go-chi/chi"
+)
+
+type companyRouter struct {
+	company handlers.CompanyHandlerInterface
+}
+
+type CompanyRouterInterface interface {
+	CompanyRoutes(r chi.Router)
+}
+
+func NewCompanyRouter(company handlers.CompanyHandlerInterface) *companyRouter {
+	return &companyRouter{
+		company: company,
+	}
+}
+
+func (c *companyRouter) CompanyRoutes(r chi.Router) {
+	r.Route("/companies", func(r chi.Router) {
+		r.Get("/", c.company.FindAll)
+		r.Get("/{slug}", c.company.FindBySlug)
+		r.Get("/{slug}/logo", c.company.Logo)
+	})
+}
@@ -0,0 +1,37 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+	"meu_job/internal/middleware"
+	"meu_job/internal/models"
+
+	"github.com/go-chi/chi"
+)
+
+type consentRouter struct {
+	consent handlers.ConsentHandlerInterface
+	m       middleware.MiddlewareInterface
+}
+
+type ConsentRouterInterface interface {
+	ConsentRoutes(r chi.Router)
+}
+
+func NewConsentRouter(
+	consent handlers.ConsentHandlerInterface,
+	m middleware.MiddlewareInterface,
+) *consentRouter {
+	return &consentRouter{
+		consent: consent,
+		m:       m,
+	}
+}
+
+func (c *consentRouter) ConsentRoutes(r chi.Router) {
+	r.Route("/legal-documents", func(r chi.Router) {
+		r.Get("/", c.consent.CurrentDocuments)
+
+		adminOnly := c.m.RequirePermission([]models.Role{models.ADMIN})
+		r.With(adminOnly, c.m.RequireScope(models.ScopeAccount)).Post("/", c.consent.Publish)
+	})
+}
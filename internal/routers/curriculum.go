@@ -0,0 +1,60 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+	"meu_job/internal/middleware"
+	"meu_job/internal/models"
+
+	"github.com/go-chi/chi"
+)
+
+type curriculumRouter struct {
+	curriculum handlers.CurriculumHandlerInterface
+	draft      handlers.CurriculumDraftHandlerInterface
+	m          middleware.MiddlewareInterface
+}
+
+type CurriculumRouterInterface interface {
+	CurriculumRoutes(r chi.Router)
+}
+
+func NewCurriculumRouter(
+	curriculum handlers.CurriculumHandlerInterface,
+	draft handlers.CurriculumDraftHandlerInterface,
+	m middleware.MiddlewareInterface,
+) *curriculumRouter {
+	return &curriculumRouter{
+		curriculum: curriculum,
+		draft:      draft,
+		m:          m,
+	}
+}
+
+func (c *curriculumRouter) CurriculumRoutes(r chi.Router) {
+	r.Route("/curricula", func(r chi.Router) {
+		r.Use(c.m.RequireActivatedUser)
+		r.Use(c.m.RequireCurrentConsent)
+		r.Use(c.m.RequireScope(models.ScopeAccount))
+
+		r.Get("/", c.curriculum.FindAll)
+		r.Post("/", c.curriculum.Save)
+		r.Put("/", c.curriculum.Update)
+		r.Post("/import", c.curriculum.ImportJSONResume)
+		r.Route("/drafts", func(r chi.Router) {
+			r.Get("/", c.draft.FindAll)
+			r.Post("/", c.draft.Upload)
+			r.Get("/{id}", c.draft.FindByID)
+			r.Post("/{id}/confirm", c.draft.Confirm)
+			r.Delete("/{id}", c.draft.Discard)
+		})
+		r.Get("/{id}.pdf", c.curriculum.PDF)
+		r.Get("/{id}", c.curriculum.FindByID)
+		r.Post("/{id}/default", c.curriculum.SetDefault)
+		r.Post("/{id}/clone", c.curriculum.Clone)
+		r.Get("/{id}/revisions", c.curriculum.Revisions)
+		r.Get("/{id}/revisions/{version}", c.curriculum.Revision)
+		r.Get("/{id}/diff", c.curriculum.Diff)
+		r.Get("/{id}/json-resume", c.curriculum.ExportJSONResume)
+		r.Delete("/{id}", c.curriculum.Delete)
+	})
+}
@@ -0,0 +1,46 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+	"meu_job/internal/middleware"
+	"meu_job/internal/models"
+
+	"github.com/go-chi/chi"
+)
+
+type fileRouter struct {
+	file handlers.FileHandlerInterface
+	m    middleware.MiddlewareInterface
+}
+
+type FileRouterInterface interface {
+	FileRoutes(r chi.Router)
+}
+
+func NewFileRouter(
+	file handlers.FileHandlerInterface,
+	m middleware.MiddlewareInterface,
+) *fileRouter {
+	return &fileRouter{
+		file: file,
+		m:    m,
+	}
+}
+
+func (f *fileRouter) FileRoutes(r chi.Router) {
+	r.Route("/files", func(r chi.Router) {
+		// Signed URLs carry their own authorization.
+		r.Get("/download", f.file.Download)
+
+		r.Group(func(r chi.Router) {
+			r.Use(f.m.RequireActivatedUser)
+			r.Use(f.m.RequireCurrentConsent)
+			r.Use(f.m.RequireScope(models.ScopeAccount))
+
+			r.Get("/", f.file.FindAll)
+			r.Post("/", f.file.Upload)
+			r.Get("/{id}", f.file.FindByID)
+			r.Delete("/{id}", f.file.Delete)
+		})
+	})
+}
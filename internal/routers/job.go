@@ -0,0 +1,28 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+
+	"github.com/go-chi/chi"
+)
+
+type jobRouter struct {
+	job handlers.JobHandlerInterface
+}
+
+type JobRouterInterface interface {
+	JobRoutes(r chi.Router)
+}
+
+func NewJobRouter(job handlers.JobHandlerInterface) *jobRouter {
+	return &jobRouter{
+		job: job,
+	}
+}
+
+func (j *jobRouter) JobRoutes(r chi.Router) {
+	r.Route("/jobs", func(r chi.Router) {
+		r.Get("/", j.job.Search)
+		r.Get("/{id}", j.job.FindPublic)
+	})
+}
@@ -0,0 +1,91 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+	"meu_job/internal/middleware"
+	"meu_job/internal/models"
+
+	"github.com/go-chi/chi"
+)
+
+type meRouter struct {
+	user      handlers.UserHandlerInterface
+	session   handlers.SessionHandlerInterface
+	business  handlers.BusinessHandlerInterface
+	privacy   handlers.PrivacyHandlerInterface
+	consent   handlers.ConsentHandlerInterface
+	jobMatch  handlers.JobMatchHandlerInterface
+	interview handlers.InterviewHandlerInterface
+	message   handlers.MessageHandlerInterface
+	m         middleware.MiddlewareInterface
+}
+
+type MeRouterInterface interface {
+	MeRoutes(r chi.Router)
+}
+
+func NewMeRouter(
+	user handlers.UserHandlerInterface,
+	session handlers.SessionHandlerInterface,
+	business handlers.BusinessHandlerInterface,
+	privacy handlers.PrivacyHandlerInterface,
+	consent handlers.ConsentHandlerInterface,
+	jobMatch handlers.JobMatchHandlerInterface,
+	interview handlers.InterviewHandlerInterface,
+	message handlers.MessageHandlerInterface,
+	m middleware.MiddlewareInterface,
+) *meRouter {
+	return &meRouter{
+		user:      user,
+		session:   session,
+		business:  business,
+		privacy:   privacy,
+		consent:   consent,
+		jobMatch:  jobMatch,
+		interview: interview,
+		message:   message,
+		m:         m,
+	}
+}
+
+func (me *meRouter) MeRoutes(r chi.Router) {
+	r.Route("/me", func(r chi.Router) {
+		r.Use(me.m.RequireAuthenticatedUser)
+
+		r.With(
+			me.m.RequireActivatedUser,
+			me.m.RequireCurrentConsent,
+			me.m.RequireScope(models.ScopeBusinessRead),
+		).Get("/businesses", me.business.FindAll)
+
+		r.Group(func(r chi.Router) {
+			r.Use(me.m.RequireScope(models.ScopeAccount))
+
+			r.Get("/", me.user.GetMe)
+			r.Patch("/", me.user.UpdateMe)
+			r.Put("/password", me.user.ChangePassword)
+			r.Post("/email", me.user.RequestEmailChange)
+			r.Post("/email/confirm", me.user.ConfirmEmailChange)
+
+			r.Get("/sessions", me.session.FindAll)
+			r.Delete("/sessions", me.session.RevokeOthers)
+			r.Delete("/sessions/{id}", me.session.Revoke)
+
+			r.Get("/data-export", me.privacy.Export)
+			r.Post("/erasure", me.privacy.RequestErasure)
+			r.Delete("/erasure", me.privacy.CancelErasure)
+
+			r.Get("/consents", me.consent.History)
+			r.Post("/consents/accept", me.consent.Accept)
+			r.Put("/consents/purposes", me.consent.SetPurpose)
+
+			r.With(me.m.RequireActivatedUser, me.m.RequireCurrentConsent).Group(func(r chi.Router) {
+				r.Get("/job-matches", me.jobMatch.FindAll)
+				r.Get("/job-matches/{jobID}", me.jobMatch.Explain)
+				r.Get("/calendar", me.interview.CalendarURL)
+				r.Post("/calendar/rotate", me.interview.RotateCalendarURL)
+				r.Get("/unread-messages", me.message.Unread)
+			})
+		})
+	})
+}
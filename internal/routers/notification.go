@@ -0,0 +1,43 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+	"meu_job/internal/middleware"
+	"meu_job/internal/models"
+
+	"github.com/go-chi/chi"
+)
+
+type notificationRouter struct {
+	notification handlers.NotificationHandlerInterface
+	m            middleware.MiddlewareInterface
+}
+
+type NotificationRouterInterface interface {
+	NotificationRoutes(r chi.Router)
+}
+
+func NewNotificationRouter(
+	notification handlers.NotificationHandlerInterface,
+	m middleware.MiddlewareInterface,
+) *notificationRouter {
+	return &notificationRouter{
+		notification: notification,
+		m:            m,
+	}
+}
+
+func (n *notificationRouter) NotificationRoutes(r chi.Router) {
+	r.Route("/notifications", func(r chi.Router) {
+		r.Use(n.m.RequireActivatedUser)
+		r.Use(n.m.RequireCurrentConsent)
+		r.Use(n.m.RequireScope(models.ScopeAccount))
+
+		r.Get("/", n.notification.FindAll)
+		r.Get("/stream", n.notification.Stream)
+		r.Post("/read-all", n.notification.MarkAllRead)
+		r.Get("/preferences", n.notification.Preferences)
+		r.Put("/preferences", n.notification.SetPreferences)
+		r.Post("/{id}/read", n.notification.MarkRead)
+	})
+}
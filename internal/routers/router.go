@@ -7,6 +7,7 @@ import (
 	"meu_job/internal/handlers"
 	"meu_job/internal/jsonlog"
 	"meu_job/internal/middleware"
+	"meu_job/internal/services"
 	"meu_job/utils/errors"
 	"net/http"
 
@@ -14,11 +15,24 @@ import (
 )
 
 type Router struct {
-	errResp  errors.ErrorResponseInterface
-	m        middleware.MiddlewareInterface
-	user     UserRoutesInterface
-	auth     AuthRoutesInterface
-	business BusinessRouterInterface
+	Service      *services.Service
+	errResp      errors.ErrorResponseInterface
+	m            middleware.MiddlewareInterface
+	user         UserRoutesInterface
+	auth         AuthRoutesInterface
+	business     BusinessRouterInterface
+	apiKey       APIKeyRouterInterface
+	me           MeRouterInterface
+	consent      ConsentRouterInterface
+	admin        AdminRouterInterface
+	company      CompanyRouterInterface
+	file         FileRouterInterface
+	curriculum   CurriculumRouterInterface
+	skill        SkillRouterInterface
+	job          JobRouterInterface
+	saved        SavedSearchRouterInterface
+	application  ApplicationRouterInterface
+	notification NotificationRouterInterface
 }
 
 func NewRouter(
@@ -32,14 +46,30 @@ func NewRouter(
 		e,
 		h.Service.User,
 		h.Service.Auth,
+		h.Service.APIKey,
+		h.Service.Session,
+		h.Service.Consent,
 		config,
 	)
 	return &Router{
-		errResp:  e,
-		m:        m,
-		user:     NewUserRouter(h.User),
-		auth:     NewAuthRouter(h.Auth),
-		business: NewBusinessRouter(h.Business, m),
+		Service:      h.Service,
+		errResp:      e,
+		m:            m,
+		user:         NewUserRouter(h.User),
+		auth:         NewAuthRouter(h.Auth),
+		business:     NewBusinessRouter(h.Business, h.Verification, h.Job, h.Application, h.Interview, h.Message, h.Webhook, m),
+		apiKey:       NewAPIKeyRouter(h.APIKey, m),
+		me:           NewMeRouter(h.User, h.Session, h.Business, h.Privacy, h.Consent, h.JobMatch, h.Interview, h.Message, m),
+		consent:      NewConsentRouter(h.Consent, m),
+		admin:        NewAdminRouter(h.Verification, h.Skill, m),
+		company:      NewCompanyRouter(h.Company),
+		file:         NewFileRouter(h.File, m),
+		curriculum:   NewCurriculumRouter(h.Curriculum, h.CurriculumDraft, m),
+		skill:        NewSkillRouter(h.Skill),
+		job:          NewJobRouter(h.Job),
+		saved:        NewSavedSearchRouter(h.SavedSearch, m),
+		application:  NewApplicationRouter(h.Application, h.Interview, h.Message, m),
+		notification: NewNotificationRouter(h.Notification, m),
 	}
 }
 
@@ -64,6 +94,18 @@ func (router *Router) RegisterRoutes() *chi.Mux {
 		router.user.UserRoutes(r)
 		router.auth.AuthRoutes(r)
 		router.business.BusinessRoutes(r)
+		router.apiKey.APIKeyRoutes(r)
+		router.me.MeRoutes(r)
+		router.consent.ConsentRoutes(r)
+		router.admin.AdminRoutes(r)
+		router.company.CompanyRoutes(r)
+		router.file.FileRoutes(r)
+		router.curriculum.CurriculumRoutes(r)
+		router.skill.SkillRoutes(r)
+		router.job.JobRoutes(r)
+		router.saved.SavedSearchRoutes(r)
+		router.application.ApplicationRoutes(r)
+		router.notification.NotificationRoutes(r)
 	})
 
 	return r
@@ -0,0 +1,47 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+	"meu_job/internal/middleware"
+	"meu_job/internal/models"
+
+	"github.com/go-chi/chi"
+)
+
+type savedSearchRouter struct {
+	savedSearch handlers.SavedSearchHandlerInterface
+	m           middleware.MiddlewareInterface
+}
+
+type SavedSearchRouterInterface interface {
+	SavedSearchRoutes(r chi.Router)
+}
+
+func NewSavedSearchRouter(
+	savedSearch handlers.SavedSearchHandlerInterface,
+	m middleware.MiddlewareInterface,
+) *savedSearchRouter {
+	return &savedSearchRouter{
+		savedSearch: savedSearch,
+		m:           m,
+	}
+}
+
+func (s *savedSearchRouter) SavedSearchRoutes(r chi.Router) {
+	r.Route("/saved-searches", func(r chi.Router) {
+		r.Get("/unsubscribe", s.savedSearch.ConfirmUnsubscribe)
+		r.Post("/unsubscribe", s.savedSearch.Unsubscribe)
+
+		r.Group(func(r chi.Router) {
+			r.Use(s.m.RequireActivatedUser)
+			r.Use(s.m.RequireCurrentConsent)
+			r.Use(s.m.RequireScope(models.ScopeAccount))
+
+			r.Get("/", s.savedSearch.FindAll)
+			r.Post("/", s.savedSearch.Save)
+			r.Get("/{id}", s.savedSearch.FindByID)
+			r.Put("/{id}", s.savedSearch.Update)
+			r.Delete("/{id}", s.savedSearch.Delete)
+		})
+	})
+}
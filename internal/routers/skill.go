@@ -0,0 +1,30 @@
+package routers
+
+import (
+	"meu_job/internal/handlers"
+
+	"github.com/go-chi/chi"
+)
+
+type skillRouter struct {
+	skill handlers.SkillHandlerInterface
+}
+
+type SkillRouterInterface interface {
+	SkillRoutes(r chi.Router)
+}
+
+func NewSkillRouter(skill handlers.SkillHandlerInterface) *skillRouter {
+	return &skillRouter{
+		skill: skill,
+	}
+}
+
+// SkillRoutes are public, the autocomplete serves sign up forms too. The
+// catalog is edited under /admin/skills.
+func (s *skillRouter) SkillRoutes(r chi.Router) {
+	r.Route("/skills", func(r chi.Router) {
+		r.Get("/", s.skill.Search)
+		r.Get("/{id}", s.skill.FindByID)
+	})
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"meu_job/internal/models"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+)
+
+type apiKeyService struct {
+	apiKey repositories.APIKeyRepositoryInterface
+	user   UserServiceInterface
+	db     *sql.DB
+}
+
+type APIKeyServiceInterface interface {
+	Create(key *models.APIKey, user *models.User, v *validator.Validator) error
+	FindAll(userID int64) ([]*models.APIKey, error)
+	Revoke(id, userID int64) error
+	Authenticate(plaintext string) (*models.User, *models.APIKey, error)
+}
+
+func NewAPIKeyService(
+	apiKeyRepository repositories.APIKeyRepositoryInterface,
+	userService UserServiceInterface,
+	db *sql.DB,
+) *apiKeyService {
+	return &apiKeyService{
+		apiKey: apiKeyRepository,
+		user:   userService,
+		db:     db,
+	}
+}
+
+func (s *apiKeyService) Create(key *models.APIKey, user *models.User, v *validator.Validator) error {
+	if key.ValidateAPIKey(v, user.Role); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	if err := key.Generate(); err != nil {
+		return err
+	}
+	key.UserID = user.ID
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.apiKey.Insert(key, tx)
+	})
+}
+
+func (s *apiKeyService) FindAll(userID int64) ([]*models.APIKey, error) {
+	return s.apiKey.GetAllByUser(userID)
+}
+
+func (s *apiKeyService) Revoke(id, userID int64) error {
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.apiKey.Revoke(id, userID, tx)
+	})
+}
+
+func (s *apiKeyService) Authenticate(plaintext string) (*models.User, *models.APIKey, error) {
+	prefix, ok := models.ParseAPIKeyPrefix(plaintext)
+	if !ok {
+		return nil, nil, e.ErrInvalidCredentials
+	}
+
+	key, err := s.apiKey.GetByPrefix(prefix)
+	if err != nil {
+		switch {
+		case errors.Is(err, e.ErrRecordNotFound):
+			return nil, nil, e.ErrInvalidCredentials
+		default:
+			return nil, nil, err
+		}
+	}
+
+	if !key.Matches(plaintext) || key.IsExpired() {
+		return nil, nil, e.ErrInvalidCredentials
+	}
+
+	user, err := s.user.GetUserByID(key.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, e.ErrRecordNotFound):
+			return nil, nil, e.ErrInvalidCredentials
+		default:
+			return nil, nil, err
+		}
+	}
+
+	if err := s.apiKey.TouchLastUsed(key.ID); err != nil {
+		return nil, nil, err
+	}
+
+	return user, key, nil
+}
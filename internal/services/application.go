@@ -0,0 +1,290 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"strings"
+)
+
+type applicationService struct {
+	application  repositories.ApplicationRepositoryInterface
+	job          repositories.JobRepositoryInterface
+	curriculum   repositories.CurriculumRepositoryInterface
+	business     repositories.BusinessRepositoryInterface
+	interview    InterviewServiceInterface
+	notification NotificationServiceInterface
+	webhook      WebhookServiceInterface
+	db           *sql.DB
+}
+
+type ApplicationServiceInterface interface {
+	FindAll(userID int64) ([]*models.Application, error)
+	FindByID(id, userID int64) (*models.Application, error)
+	Apply(a *models.Application, userID int64, v *validator.Validator) error
+	Withdraw(id, userID int64, reason string, version int, v *validator.Validator) (*models.Application, error)
+	FindAllByJob(jobID, businessID, userID int64, stage string, f filters.Filters) ([]*models.Application, filters.Metadata, error)
+	FindForBusiness(id, businessID, userID int64) (*models.Application, error)
+	SetStage(id, businessID, userID int64, change models.StageChangeDTO, v *validator.Validator) (*models.Application, error)
+}
+
+func NewApplicationService(
+	applicationRepository repositories.ApplicationRepositoryInterface,
+	jobRepository repositories.JobRepositoryInterface,
+	curriculumRepository repositories.CurriculumRepositoryInterface,
+	businessRepository repositories.BusinessRepositoryInterface,
+	interviewService InterviewServiceInterface,
+	notificationService NotificationServiceInterface,
+	webhookService WebhookServiceInterface,
+	db *sql.DB,
+) *applicationService {
+	return &applicationService{
+		application:  applicationRepository,
+		job:          jobRepository,
+		curriculum:   curriculumRepository,
+		business:     businessRepository,
+		interview:    interviewService,
+		notification: notificationService,
+		webhook:      webhookService,
+		db:           db,
+	}
+}
+
+func (s *applicationService) FindAll(userID int64) ([]*models.Application, error) {
+	return s.application.GetAllByUser(userID)
+}
+
+func (s *applicationService) FindByID(id, userID int64) (*models.Application, error) {
+	return s.application.GetByUser(id, userID)
+}
+
+// Apply sends the curriculum, the default one unless another is picked, as
+// it is now; later edits don't reach the business.
+func (s *applicationService) Apply(a *models.Application, userID int64, v *validator.Validator) error {
+	if a.ValidateApplication(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	job, err := s.job.GetPublished(a.JobID)
+	if err != nil {
+		if errors.Is(err, e.ErrRecordNotFound) {
+			v.AddError("job_id", "must be a job open for applications")
+			return e.ErrInvalidData
+		}
+		return err
+	}
+
+	c, err := s.pickCurriculum(a.CurriculumID, userID)
+	if err != nil {
+		if errors.Is(err, e.ErrRecordNotFound) {
+			v.AddError("curriculum_id", "must be one of your curricula")
+			return e.ErrInvalidData
+		}
+		return err
+	}
+
+	a.BusinessID = job.BusinessID
+	a.UserID = userID
+	a.CurriculumID = &c.ID
+	a.CurriculumVersion = c.Version
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		if err := s.application.Insert(a, tx); err != nil {
+			return err
+		}
+		if err := s.webhook.Enqueue(models.EventApplicationCreated, a.BusinessID, models.NewApplicationEvent(a, ""), tx); err != nil {
+			return err
+		}
+		return s.notification.NotifyMembers(&models.Notification{
+			Type:  models.NotifyApplicationReceived,
+			Title: "Nova candidatura: " + job.Title,
+			Body:  "Uma nova candidatura chegou para a vaga \"" + job.Title + "\".",
+			Data:  applicationData(a),
+		}, a.BusinessID, 0, tx)
+	})
+}
+
+func applicationData(a *models.Application) map[string]int64 {
+	return map[string]int64{
+		"application_id": a.ID,
+		"job_id":         a.JobID,
+		"business_id":    a.BusinessID,
+	}
+}
+
+// stageChanged tells the business's webhooks. The curriculum, loaded when
+// the business moves the application, isn't sent along.
+func (s *applicationService) stageChanged(a *models.Application, previous models.ApplicationStage, tx *sql.Tx) error {
+	return s.webhook.Enqueue(models.EventApplicationStageChanged, a.BusinessID, models.NewApplicationEvent(a, previous), tx)
+}
+
+func (s *applicationService) pickCurriculum(id *int64, userID int64) (*models.Curriculum, error) {
+	if id != nil {
+		return s.curriculum.GetByOwner(*id, userID)
+	}
+
+	curricula, err := s.curriculum.GetAllByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range curricula {
+		if c.IsDefault {
+			return c, nil
+		}
+	}
+	return nil, e.ErrRecordNotFound
+}
+
+// Withdraw closes the application on the candidate's side, calling off its
+// interviews.
+func (s *applicationService) Withdraw(
+	id,
+	userID int64,
+	reason string,
+	version int,
+	v *validator.Validator,
+) (*models.Application, error) {
+	a, err := s.application.GetByUser(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	v.Check(version > 0, "version", "must be provided")
+	v.Check(a.Stage.IsOpen(), "stage", "the application is already closed")
+	models.ValidateStageReason(v, reason)
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	previous := a.Stage
+	a.Stage = models.StageWithdrawn
+	a.StageReason = reason
+	a.Version = version
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		if err := s.application.SetStage(a, userID, tx); err != nil {
+			return err
+		}
+		if err := s.interview.CancelOpen(a, reason, userID, tx); err != nil {
+			return err
+		}
+		if err := s.stageChanged(a, previous, tx); err != nil {
+			return err
+		}
+		return s.notification.NotifyMembers(&models.Notification{
+			Type:  models.NotifyApplicationStage,
+			Title: "Candidatura retirada: " + a.JobTitle,
+			Body:  "Uma candidatura à vaga \"" + a.JobTitle + "\" foi retirada pelo candidato.",
+			Data:  applicationData(a),
+		}, a.BusinessID, 0, tx)
+	})
+	return a, err
+}
+
+func (s *applicationService) FindAllByJob(
+	jobID,
+	businessID,
+	userID int64,
+	stage string,
+	f filters.Filters,
+) ([]*models.Application, filters.Metadata, error) {
+	if _, err := s.business.GetByID(businessID, userID); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	if _, err := s.job.GetByBusiness(jobID, businessID); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	return s.application.GetAllByJob(jobID, stage, f)
+}
+
+// FindForBusiness loads the curriculum as it was sent. It's left out if the
+// revision is gone, as it is once the candidate's account is erased.
+func (s *applicationService) FindForBusiness(id, businessID, userID int64) (*models.Application, error) {
+	if _, err := s.business.GetByID(businessID, userID); err != nil {
+		return nil, err
+	}
+
+	a, err := s.application.GetByBusiness(id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.CurriculumID == nil {
+		return a, nil
+	}
+
+	rev, err := s.curriculum.GetRevision(*a.CurriculumID, a.CurriculumVersion)
+	switch {
+	case err == nil:
+		a.Curriculum = rev.Curriculum
+	case !errors.Is(err, e.ErrRecordNotFound):
+		return nil, err
+	}
+	return a, nil
+}
+
+// SetStage moves the application along. Closing it calls off the
+// interviews still to happen.
+func (s *applicationService) SetStage(
+	id,
+	businessID,
+	userID int64,
+	change models.StageChangeDTO,
+	v *validator.Validator,
+) (*models.Application, error) {
+	a, err := s.FindForBusiness(id, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	change.Reason = strings.TrimSpace(change.Reason)
+	v.Check(change.Version > 0, "version", "must be provided")
+	v.Check(validator.In(string(change.Stage), models.ApplicationStages...), "stage", "must be one of "+strings.Join(models.ApplicationStages, ", "))
+	v.Check(a.Stage.IsOpen(), "stage", "the application is already closed")
+	models.ValidateStageReason(v, change.Reason)
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	v.Check(a.Stage.CanMoveTo(change.Stage), "stage", "can't move from "+string(a.Stage)+" to "+string(change.Stage))
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	previous := a.Stage
+	a.Stage = change.Stage
+	a.StageReason = change.Reason
+	a.Version = change.Version
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		if err := s.application.SetStage(a, userID, tx); err != nil {
+			return err
+		}
+		if !a.Stage.IsOpen() {
+			if err := s.interview.CancelOpen(a, change.Reason, userID, tx); err != nil {
+				return err
+			}
+		}
+		if err := s.stageChanged(a, previous, tx); err != nil {
+			return err
+		}
+		return s.notification.Notify(&models.Notification{
+			UserID: a.UserID,
+			Type:   models.NotifyApplicationStage,
+			Title:  "Sua candidatura mudou de etapa: " + a.JobTitle,
+			Body: fmt.Sprintf(
+				"Sua candidatura à vaga \"%s\" na %s agora está na etapa: %s.",
+				a.JobTitle, a.BusinessName, a.Stage.Label(),
+			),
+			Data: applicationData(a),
+		}, tx)
+	})
+	return a, err
+}
@@ -6,25 +6,46 @@ import (
 	"meu_job/internal/models"
 	e "meu_job/utils/errors"
 	"meu_job/utils/validator"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type AuthService struct {
-	user   UserServiceInterface
-	config config.Config
+	user    UserServiceInterface
+	session SessionServiceInterface
+	config  config.Config
 }
 
 type AuthServiceInterface interface {
-	Login(v *validator.Validator, email, password string) (string, error)
-	ExtractUsername(tokenString string) (string, error)
+	Login(v *validator.Validator, email, password string, session *models.Session) (string, error)
+	ParseToken(tokenString string) (*TokenClaims, error)
+	IssueToken(user *models.User, sessionID int64) (string, error)
 }
 
-func NewAuthService(userService UserServiceInterface, config config.Config) *AuthService {
+const tokenTTL = 24 * time.Hour
+
+type TokenClaims struct {
+	Role         models.Role `json:"role"`
+	TokenVersion int         `json:"tv"`
+	SessionID    int64       `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+func (c *TokenClaims) UserID() (int64, error) {
+	return strconv.ParseInt(c.Subject, 10, 64)
+}
+
+func NewAuthService(
+	userService UserServiceInterface,
+	sessionService SessionServiceInterface,
+	config config.Config,
+) *AuthService {
 	return &AuthService{
-		user:   userService,
-		config: config,
+		user:    userService,
+		session: sessionService,
+		config:  config,
 	}
 }
 
@@ -32,6 +53,7 @@ func (s *AuthService) Login(
 	v *validator.Validator,
 	email,
 	password string,
+	session *models.Session,
 ) (string, error) {
 	models.ValidateEmail(v, email)
 	models.ValidatePasswordPlaintext(v, password)
@@ -63,7 +85,12 @@ func (s *AuthService) Login(
 		return "", e.ErrInvalidCredentials
 	}
 
-	token, err := s.createToken(user.Email)
+	session.UserID = user.ID
+	if err := s.session.Create(session); err != nil {
+		return "", err
+	}
+
+	token, err := s.createToken(user, session.ID)
 	if err != nil {
 		return "", err
 	}
@@ -71,12 +98,26 @@ func (s *AuthService) Login(
 	return token, nil
 }
 
-func (s *AuthService) createToken(username string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
-		jwt.MapClaims{
-			"username": username,
-			"exp":      time.Now().Add(time.Hour * 24).Unix(),
-		})
+// IssueToken signs a new token for a session the user already has, once
+// their token version has changed.
+func (s *AuthService) IssueToken(user *models.User, sessionID int64) (string, error) {
+	return s.createToken(user, sessionID)
+}
+
+func (s *AuthService) createToken(user *models.User, sessionID int64) (string, error) {
+	now := time.Now()
+	claims := TokenClaims{
+		Role:         user.Role,
+		TokenVersion: user.TokenVersion,
+		SessionID:    sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(user.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenTTL)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenStr, err := token.SignedString([]byte(s.config.Security.SecretKey))
 
 	if err != nil {
@@ -86,28 +127,25 @@ func (s *AuthService) createToken(username string) (string, error) {
 	return tokenStr, nil
 }
 
-func (s *AuthService) ExtractUsername(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		return []byte(s.config.Security.SecretKey), nil
-	})
+func (s *AuthService) ParseToken(tokenString string) (*TokenClaims, error) {
+	var claims TokenClaims
+	token, err := jwt.ParseWithClaims(
+		tokenString,
+		&claims,
+		func(token *jwt.Token) (any, error) {
+			return []byte(s.config.Security.SecretKey), nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if !token.Valid {
-		return "", nil
-	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return "", nil
-	}
-
-	username, ok := claims["username"].(string)
-	if !ok {
-		return "", nil
+		return nil, e.ErrInvalidCredentials
 	}
 
-	return username, nil
+	return &claims, nil
 }
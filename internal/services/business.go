@@ -2,16 +2,25 @@ package services
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/geo"
 	"meu_job/internal/models"
 	"meu_job/internal/models/filters"
+	"meu_job/internal/registry"
 	"meu_job/internal/repositories"
 	"meu_job/utils"
-	"meu_job/utils/errors"
+	e "meu_job/utils/errors"
 	"meu_job/utils/validator"
+	"strings"
+	"time"
 )
 
 type businessService struct {
 	business repositories.BusinessRepositoryInterface
+	registry registry.CNPJRegistry
+	geo      geo.CEPResolver
+	file     FileServiceInterface
 	db       *sql.DB
 }
 
@@ -28,14 +37,24 @@ type BusinessServiceInterface interface {
 	Update(b *models.Business, userID int64, v *validator.Validator) error
 	Delete(id, userID int64) error
 	AddUserInBusiness(businessID, userID, userLogadoID int64) error
+	SetPublic(id, userID int64, public bool) error
+	SetLogo(id, userID int64, file *models.File, content []byte, v *validator.Validator) (*models.Business, error)
+	FindPublicBySlug(slug string) (*models.Business, error)
+	FindAllPublic(name, sector, city string, f filters.Filters) ([]*models.Business, filters.Metadata, error)
 }
 
 func NewBusinessService(
 	businessRepository repositories.BusinessRepositoryInterface,
+	cnpjRegistry registry.CNPJRegistry,
+	cepResolver geo.CEPResolver,
+	fileService FileServiceInterface,
 	db *sql.DB,
 ) *businessService {
 	return &businessService{
 		business: businessRepository,
+		registry: cnpjRegistry,
+		geo:      cepResolver,
+		file:     fileService,
 		db:       db,
 	}
 }
@@ -47,6 +66,9 @@ func (s *businessService) FindAll(
 	userID int64,
 	f filters.Filters,
 ) ([]*models.Business, filters.Metadata, error) {
+	if cnpj != "" {
+		cnpj = models.NormalizeCNPJ(cnpj).String()
+	}
 	return s.business.GetAll(name, email, cnpj, userID, f)
 }
 
@@ -57,30 +79,205 @@ func (s *businessService) AddUserInBusiness(businessID, userID, userLogadoID int
 }
 
 func (s *businessService) Save(b *models.Business, userID int64, v *validator.Validator) error {
+	if b.CNPJ.IsValid() {
+		if err := s.enrich(b, v); err != nil {
+			return err
+		}
+	}
+	if err := geocode(s.geo, &b.Address.Place); err != nil {
+		return err
+	}
+
 	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
 		b.ValidateBusiness(v)
 		if !v.Valid() {
-			return errors.ErrInvalidData
+			return e.ErrInvalidData
+		}
+
+		if b.Profile.Slug == "" {
+			slug, err := s.uniqueSlug(b.Name)
+			if err != nil {
+				return err
+			}
+			b.Profile.Slug = slug
 		}
 
 		return s.business.Insert(b, userID, tx)
 	})
 }
 
+// enrich fills the business with what the CNPJ registry knows about it. An
+// unreachable registry is not fatal: the business is saved unverified.
+func (s *businessService) enrich(b *models.Business, v *validator.Validator) error {
+	company, err := s.registry.Lookup(b.CNPJ)
+	if err != nil {
+		switch {
+		case errors.Is(err, registry.ErrNotFound):
+			v.AddError("cnpj", "not found in the CNPJ registry")
+			return nil
+		case errors.Is(err, registry.ErrUnavailable):
+			return nil
+		default:
+			return err
+		}
+	}
+
+	if company.Status == registry.StatusClosed {
+		v.AddError("cnpj", "company is closed (BAIXADA) in the CNPJ registry")
+		return nil
+	}
+
+	if b.Name == "" {
+		b.Name = company.TradeName
+		if b.Name == "" {
+			b.Name = company.LegalName
+		}
+	}
+
+	if b.Address.IsZero() {
+		b.Address = company.Address
+	}
+
+	now := time.Now()
+	b.LegalName = company.LegalName
+	b.TradeName = company.TradeName
+	b.RegistrationStatus = company.Status
+	b.RegistrySnapshot = company.Raw
+	b.RegistryCheckedAt = &now
+	b.Verified = company.IsActive() &&
+		(sameCompanyName(b.Name, company.LegalName) || sameCompanyName(b.Name, company.TradeName))
+
+	return nil
+}
+
+// uniqueSlug derives a slug from the name, numbering it when taken. A race
+// with another insert is still caught by the unique index.
+func (s *businessService) uniqueSlug(name string) (string, error) {
+	base := models.Slugify(name)
+	if base == "" {
+		base = "empresa"
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		exists, err := s.business.SlugExists(slug)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+func sameCompanyName(a, b string) bool {
+	normalize := func(s string) string {
+		return strings.Join(strings.Fields(strings.ToUpper(s)), " ")
+	}
+	return b != "" && normalize(a) == normalize(b)
+}
+
 func (s *businessService) FindByID(id, userID int64) (*models.Business, error) {
 	return s.business.GetByID(id, userID)
 }
 
+// Update looks a new CNPJ up in the registry as Save does, so the registry
+// data never belongs to another company. Otherwise the registry data is
+// kept, and only whether it matches the name is checked again.
 func (s *businessService) Update(b *models.Business, userID int64, v *validator.Validator) error {
+	current, err := s.business.GetByID(b.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	if b.CNPJ != current.CNPJ {
+		if b.CNPJ.IsValid() {
+			if err := s.enrich(b, v); err != nil {
+				return err
+			}
+		}
+	} else {
+		b.LegalName = current.LegalName
+		b.TradeName = current.TradeName
+		b.RegistrationStatus = current.RegistrationStatus
+		b.RegistrySnapshot = current.RegistrySnapshot
+		b.RegistryCheckedAt = current.RegistryCheckedAt
+		b.Verified = current.Verified &&
+			(sameCompanyName(b.Name, current.LegalName) || sameCompanyName(b.Name, current.TradeName))
+	}
+
+	if err := geocode(s.geo, &b.Address.Place); err != nil {
+		return err
+	}
+
 	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
 		if b.ValidateBusiness(v); !v.Valid() {
-			return errors.ErrInvalidData
+			return e.ErrInvalidData
 		}
 
 		return s.business.Update(b, userID, tx)
 	})
 }
 
+func (s *businessService) SetPublic(id, userID int64, public bool) error {
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.business.SetPublic(id, userID, public, tx)
+	})
+}
+
+// SetLogo stores the uploaded image and points the business at it. The
+// previous logo, if any, is removed from the storage.
+func (s *businessService) SetLogo(
+	id,
+	userID int64,
+	file *models.File,
+	content []byte,
+	v *validator.Validator,
+) (*models.Business, error) {
+	business, err := s.business.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	refType := models.RefBusinessLogo
+	file.OwnerID = userID
+	file.Kind = models.FileLogo
+	file.RefType = &refType
+	file.RefID = &id
+	if err := s.file.Upload(file, content, v); err != nil {
+		return nil, err
+	}
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.business.SetLogo(id, userID, file.ID, tx)
+	})
+	if err != nil {
+		s.file.Delete(file.ID, userID)
+		return nil, err
+	}
+
+	if business.LogoFileID != nil {
+		s.file.DeleteByID(*business.LogoFileID)
+	}
+
+	business.LogoFileID = &file.ID
+	return business, nil
+}
+
+func (s *businessService) FindPublicBySlug(slug string) (*models.Business, error) {
+	return s.business.GetPublicBySlug(slug)
+}
+
+func (s *businessService) FindAllPublic(
+	name,
+	sector,
+	city string,
+	f filters.Filters,
+) ([]*models.Business, filters.Metadata, error) {
+	return s.business.GetAllPublic(name, sector, city, f)
+}
+
 func (s *businessService) Delete(id, userID int64) error {
 	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
 		return s.business.Delete(id, userID, tx)
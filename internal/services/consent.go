@@ -0,0 +1,209 @@
+package services
+
+import (
+	"database/sql"
+	"meu_job/internal/cache"
+	"meu_job/internal/models"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"slices"
+	"time"
+)
+
+const currentDocumentsTTL = time.Minute
+
+type consentService struct {
+	consent repositories.ConsentRepositoryInterface
+	user    repositories.UserRepositoryInterface
+	current *cache.TTLCache[struct{}, map[models.DocumentKind]*models.LegalDocument]
+	db      *sql.DB
+}
+
+type ConsentServiceInterface interface {
+	CurrentDocuments() (map[models.DocumentKind]*models.LegalDocument, error)
+	Publish(doc *models.LegalDocument, userID int64, v *validator.Validator) error
+	PendingDocuments(user *models.User) ([]models.DocumentKind, error)
+	Record(tx *sql.Tx, userID int64, acceptance *models.ConsentAcceptance) error
+	Accept(user *models.User, acceptance *models.ConsentAcceptance, v *validator.Validator) error
+	SetPurpose(consent *models.Consent, v *validator.Validator) error
+	History(userID int64) ([]*models.Consent, map[models.ConsentPurpose]bool, error)
+	HasPurpose(userID int64, purpose models.ConsentPurpose) (bool, error)
+}
+
+func NewConsentService(
+	consentRepository repositories.ConsentRepositoryInterface,
+	userRepository repositories.UserRepositoryInterface,
+	db *sql.DB,
+) *consentService {
+	return &consentService{
+		consent: consentRepository,
+		user:    userRepository,
+		current: cache.New[struct{}, map[models.DocumentKind]*models.LegalDocument](currentDocumentsTTL, 1),
+		db:      db,
+	}
+}
+
+func (s *consentService) CurrentDocuments() (map[models.DocumentKind]*models.LegalDocument, error) {
+	if current, found := s.current.Get(struct{}{}); found {
+		return current, nil
+	}
+
+	docs, err := s.consent.GetCurrentDocuments()
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[models.DocumentKind]*models.LegalDocument, len(docs))
+	for _, doc := range docs {
+		current[doc.Kind] = doc
+	}
+
+	s.current.Set(struct{}{}, current)
+	return current, nil
+}
+
+func (s *consentService) Publish(doc *models.LegalDocument, userID int64, v *validator.Validator) error {
+	if doc.ValidateLegalDocument(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	doc.CreatedBy = &userID
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.consent.InsertDocument(doc, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.current.Delete(struct{}{})
+	return nil
+}
+
+func (s *consentService) PendingDocuments(user *models.User) ([]models.DocumentKind, error) {
+	current, err := s.CurrentDocuments()
+	if err != nil {
+		return nil, err
+	}
+
+	return user.PendingDocuments(current), nil
+}
+
+// Record stores one consent row per accepted document and one per known
+// purpose, so the log shows both what was granted and what was declined.
+func (s *consentService) Record(tx *sql.Tx, userID int64, acceptance *models.ConsentAcceptance) error {
+	current, err := s.CurrentDocuments()
+	if err != nil {
+		return err
+	}
+
+	accepted := map[models.DocumentKind]int{
+		models.DocumentTerms:   acceptance.TermsVersion,
+		models.DocumentPrivacy: acceptance.PrivacyVersion,
+	}
+
+	for kind, version := range accepted {
+		doc, ok := current[kind]
+		if !ok || doc.Version != version {
+			continue
+		}
+
+		err := s.consent.Insert(&models.Consent{
+			UserID:     userID,
+			Subject:    string(kind),
+			DocumentID: &doc.ID,
+			Granted:    true,
+			IP:         acceptance.IP,
+			UserAgent:  acceptance.UserAgent,
+		}, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, purpose := range models.ConsentPurposes {
+		err := s.consent.Insert(&models.Consent{
+			UserID:    userID,
+			Subject:   string(purpose),
+			Granted:   slices.Contains(acceptance.OptIns, purpose),
+			IP:        acceptance.IP,
+			UserAgent: acceptance.UserAgent,
+		}, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *consentService) Accept(user *models.User, acceptance *models.ConsentAcceptance, v *validator.Validator) error {
+	current, err := s.CurrentDocuments()
+	if err != nil {
+		return err
+	}
+
+	if acceptance.ValidateAcceptance(v, current); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		for kind, doc := range current {
+			err := s.consent.Insert(&models.Consent{
+				UserID:     user.ID,
+				Subject:    string(kind),
+				DocumentID: &doc.ID,
+				Granted:    true,
+				IP:         acceptance.IP,
+				UserAgent:  acceptance.UserAgent,
+			}, tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		user.TermsVersion = acceptance.TermsVersion
+		user.PrivacyVersion = acceptance.PrivacyVersion
+		return s.user.Update(tx, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.user.Forget(user.ID)
+	return nil
+}
+
+func (s *consentService) SetPurpose(consent *models.Consent, v *validator.Validator) error {
+	if models.ValidateConsentPurpose(v, models.ConsentPurpose(consent.Subject)); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.consent.Insert(consent, tx)
+	})
+}
+
+func (s *consentService) History(userID int64) ([]*models.Consent, map[models.ConsentPurpose]bool, error) {
+	consents, err := s.consent.GetAllByUser(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	purposes, err := s.consent.GetPurposes(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return consents, purposes, nil
+}
+
+func (s *consentService) HasPurpose(userID int64, purpose models.ConsentPurpose) (bool, error) {
+	purposes, err := s.consent.GetPurposes(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return purposes[purpose], nil
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"meu_job/internal/cache"
+	"meu_job/internal/geo"
+	"meu_job/internal/jsonresume"
+	"meu_job/internal/models"
+	"meu_job/internal/repositories"
+	"meu_job/internal/resume"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	curriculumPDFCacheTTL      = time.Hour
+	curriculumPDFCacheMaxItems = 500
+)
+
+// curriculumPDFKey includes the version, so a saved change makes the old
+// rendering unreachable; it just waits for its TTL to be evicted.
+type curriculumPDFKey struct {
+	id       int64
+	version  int
+	template resume.Template
+}
+
+type curriculumService struct {
+	curriculum repositories.CurriculumRepositoryInterface
+	consent    ConsentServiceInterface
+	skill      SkillServiceInterface
+	geo        geo.CEPResolver
+	pdf        *cache.TTLCache[curriculumPDFKey, []byte]
+	db         *sql.DB
+}
+
+type CurriculumServiceInterface interface {
+	Save(c *models.Curriculum, userID int64, v *validator.Validator) error
+	FindByID(id, userID int64) (*models.Curriculum, error)
+	FindAll(userID int64) ([]*models.Curriculum, error)
+	Update(c *models.Curriculum, userID int64, v *validator.Validator) error
+	Delete(id, userID int64) error
+	SetDefault(id, userID int64) (*models.Curriculum, error)
+	Clone(id, userID int64, name string, v *validator.Validator) (*models.Curriculum, error)
+	Revisions(id, userID int64) ([]*models.CurriculumRevision, error)
+	Revision(id, userID int64, version int) (*models.CurriculumRevision, error)
+	Diff(id, userID int64, from, to int, v *validator.Validator) ([]models.CurriculumChange, error)
+	RenderPDF(id int64, viewer *models.User, template string, v *validator.Validator) (*models.Curriculum, []byte, error)
+	ImportJSONResume(raw []byte, user *models.User, dryRun bool, v *validator.Validator) (*models.Curriculum, []jsonresume.Warning, error)
+	ExportJSONResume(id, userID int64) (*models.Curriculum, *jsonresume.Resume, error)
+}
+
+func NewCurriculumService(
+	curriculumRepository repositories.CurriculumRepositoryInterface,
+	consentService ConsentServiceInterface,
+	skillService SkillServiceInterface,
+	cepResolver geo.CEPResolver,
+	db *sql.DB,
+) *curriculumService {
+	return &curriculumService{
+		curriculum: curriculumRepository,
+		consent:    consentService,
+		skill:      skillService,
+		geo:        cepResolver,
+		pdf:        cache.New[curriculumPDFKey, []byte](curriculumPDFCacheTTL, curriculumPDFCacheMaxItems),
+		db:         db,
+	}
+}
+
+// Save names the curriculum after the profession when no name is given.
+// The user's first curriculum becomes their default.
+func (s *curriculumService) Save(c *models.Curriculum, userID int64, v *validator.Validator) error {
+	c.DefaultName()
+	if err := s.normalizeSkills(c); err != nil {
+		return err
+	}
+	if err := locate(s.geo, &c.Place, v); err != nil {
+		return err
+	}
+	if c.ValidateCurriculum(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.curriculum.Insert(c, userID, tx)
+	})
+}
+
+func (s *curriculumService) FindByID(id, userID int64) (*models.Curriculum, error) {
+	return s.curriculum.GetByOwner(id, userID)
+}
+
+func (s *curriculumService) FindAll(userID int64) ([]*models.Curriculum, error) {
+	return s.curriculum.GetAllByUser(userID)
+}
+
+func (s *curriculumService) Update(c *models.Curriculum, userID int64, v *validator.Validator) error {
+	if c.KeepCPF {
+		current, err := s.curriculum.GetByOwner(c.ID, userID)
+		if err != nil {
+			return err
+		}
+		c.CPF = current.CPF
+	}
+	if err := s.normalizeSkills(c); err != nil {
+		return err
+	}
+	if err := locate(s.geo, &c.Place, v); err != nil {
+		return err
+	}
+	if c.ValidateCurriculum(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.curriculum.Update(c, userID, tx)
+	})
+}
+
+// normalizeSkills links the skills to the catalog before they are
+// validated, so "Go" and "golang" count as the same skill.
+func (s *curriculumService) normalizeSkills(c *models.Curriculum) error {
+	skills, err := s.skill.Normalize(c.Skills)
+	if err != nil {
+		return err
+	}
+	c.Skills = skills
+	return nil
+}
+
+// Delete hands the default over to another curriculum when needed.
+func (s *curriculumService) Delete(id, userID int64) error {
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.curriculum.Delete(id, userID, tx)
+	})
+}
+
+func (s *curriculumService) SetDefault(id, userID int64) (*models.Curriculum, error) {
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.curriculum.SetDefault(id, userID, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.curriculum.GetByOwner(id, userID)
+}
+
+// Clone copies a curriculum under a new name, as a starting point for one
+// tailored to another kind of role. The copy starts its own history.
+func (s *curriculumService) Clone(id, userID int64, name string, v *validator.Validator) (*models.Curriculum, error) {
+	source, err := s.curriculum.GetByOwner(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c := *source
+	c.ID = 0
+	c.IsDefault = false
+	c.Name = strings.TrimSpace(name)
+	if c.Name == "" {
+		c.Name = source.Name + " (cópia)"
+	}
+
+	if err := s.Save(&c, userID, v); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *curriculumService) Revisions(id, userID int64) ([]*models.CurriculumRevision, error) {
+	if _, err := s.curriculum.GetByOwner(id, userID); err != nil {
+		return nil, err
+	}
+	return s.curriculum.GetRevisions(id)
+}
+
+func (s *curriculumService) Revision(id, userID int64, version int) (*models.CurriculumRevision, error) {
+	if _, err := s.curriculum.GetByOwner(id, userID); err != nil {
+		return nil, err
+	}
+	return s.curriculum.GetRevision(id, version)
+}
+
+// Diff compares two revisions. Without to, the current one is used; without
+// from, the one before to.
+func (s *curriculumService) Diff(id, userID int64, from, to int, v *validator.Validator) ([]models.CurriculumChange, error) {
+	c, err := s.curriculum.GetByOwner(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if to == 0 {
+		to = c.Version
+	}
+	if from == 0 {
+		from = to - 1
+	}
+	v.Check(from >= 1 && from <= c.Version, "from", fmt.Sprintf("must be a version between 1 and %d", c.Version))
+	v.Check(to >= 1 && to <= c.Version, "to", fmt.Sprintf("must be a version between 1 and %d", c.Version))
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	a, err := s.curriculum.GetRevision(id, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.curriculum.GetRevision(id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.DiffCurricula(a.Curriculum, b.Curriculum)
+}
+
+// RenderPDF lets the owner download their curriculum, and recruiters read
+// it once the candidate agreed to share it with them. Anybody else gets
+// "not found" so the ids can't be probed.
+func (s *curriculumService) RenderPDF(
+	id int64,
+	viewer *models.User,
+	template string,
+	v *validator.Validator,
+) (*models.Curriculum, []byte, error) {
+	tpl := resume.Template(template)
+	if tpl == "" {
+		tpl = resume.Template(resume.Templates[0])
+	}
+	v.Check(tpl.IsValid(), "template", "must be one of "+strings.Join(resume.Templates, ", "))
+	if !v.Valid() {
+		return nil, nil, e.ErrInvalidData
+	}
+
+	c, err := s.curriculum.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.User.ID != viewer.ID {
+		if viewer.Role != models.BUSINESS && viewer.Role != models.ADMIN {
+			return nil, nil, e.ErrRecordNotFound
+		}
+
+		shared, err := s.consent.HasPurpose(c.User.ID, models.PurposeShareCVWithRecruiters)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !shared {
+			return nil, nil, e.ErrRecordNotFound
+		}
+	}
+
+	key := curriculumPDFKey{id: c.ID, version: c.Version, template: tpl}
+	if content, found := s.pdf.Get(key); found {
+		return c, content, nil
+	}
+
+	content, err := resume.Render(c, tpl)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.pdf.Set(key, content)
+	return c, content, nil
+}
+
+// ImportJSONResume maps a JSON Resume document and saves it as a new
+// curriculum. Contact details missing from the document are taken from the
+// account. A dry run saves nothing and reports what would block the save
+// as warnings, so the whole outcome can be reviewed at once.
+func (s *curriculumService) ImportJSONResume(
+	raw []byte,
+	user *models.User,
+	dryRun bool,
+	v *validator.Validator,
+) (*models.Curriculum, []jsonresume.Warning, error) {
+	c, warnings, err := jsonresume.Import(raw)
+	if err != nil {
+		v.AddError("body", err.Error())
+		return nil, nil, e.ErrInvalidData
+	}
+
+	fill := func(field string, value *string, fallback string) {
+		if *value == "" && fallback != "" {
+			*value = fallback
+			warnings = append(warnings, jsonresume.Warning{Field: field, Message: "was missing and was taken from your account"})
+		}
+	}
+	fill("basics.name", &c.FullName, user.Name)
+	fill("basics.email", &c.Email, user.Email)
+	fill("basics.phone", (*string)(&c.Phone), string(user.Phone))
+	c.DefaultName()
+
+	if !dryRun {
+		if err := s.Save(c, user.ID, v); err != nil {
+			return nil, nil, err
+		}
+		return c, warnings, nil
+	}
+
+	if err := s.normalizeSkills(c); err != nil {
+		return nil, nil, err
+	}
+	if err := locate(s.geo, &c.Place, v); err != nil {
+		return nil, nil, err
+	}
+	c.ValidateCurriculum(v)
+	fields := make([]string, 0, len(v.Errors))
+	for field := range v.Errors {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	for _, field := range fields {
+		warnings = append(warnings, jsonresume.Warning{Field: field, Message: v.Errors[field]})
+	}
+	v.Errors = map[string]string{}
+
+	return c, warnings, nil
+}
+
+func (s *curriculumService) ExportJSONResume(id, userID int64) (*models.Curriculum, *jsonresume.Resume, error) {
+	c, err := s.curriculum.GetByOwner(id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, jsonresume.Export(c), nil
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/extract"
+	"meu_job/internal/geo"
+	"meu_job/internal/models"
+	"meu_job/internal/repositories"
+	"meu_job/internal/resume"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"time"
+)
+
+const (
+	draftBatchSize   = 10
+	draftMaxAttempts = 3
+	// draftLease is how long a claimed draft is left alone before another
+	// run takes it; well over what parsing a batch takes.
+	draftLease = 5 * time.Minute
+)
+
+type curriculumDraftService struct {
+	draft      repositories.CurriculumDraftRepositoryInterface
+	curriculum repositories.CurriculumRepositoryInterface
+	user       repositories.UserRepositoryInterface
+	file       FileServiceInterface
+	skill      SkillServiceInterface
+	geo        geo.CEPResolver
+	db         *sql.DB
+}
+
+type CurriculumDraftServiceInterface interface {
+	Upload(file *models.File, content []byte, userID int64, v *validator.Validator) (*models.CurriculumDraft, error)
+	FindByID(id, userID int64) (*models.CurriculumDraft, error)
+	FindAll(userID int64) ([]*models.CurriculumDraft, error)
+	Confirm(id, userID int64, version int, c *models.Curriculum, v *validator.Validator) (*models.CurriculumDraft, *models.Curriculum, error)
+	Discard(id, userID int64) error
+	ProcessPending() error
+}
+
+func NewCurriculumDraftService(
+	draftRepository repositories.CurriculumDraftRepositoryInterface,
+	curriculumRepository repositories.CurriculumRepositoryInterface,
+	userRepository repositories.UserRepositoryInterface,
+	fileService FileServiceInterface,
+	skillService SkillServiceInterface,
+	cepResolver geo.CEPResolver,
+	db *sql.DB,
+) *curriculumDraftService {
+	return &curriculumDraftService{
+		draft:      draftRepository,
+		curriculum: curriculumRepository,
+		user:       userRepository,
+		file:       fileService,
+		skill:      skillService,
+		geo:        cepResolver,
+		db:         db,
+	}
+}
+
+// Upload stores the résumé and queues it for parsing; the draft comes back
+// pending. FileName must be set by the caller.
+func (s *curriculumDraftService) Upload(
+	file *models.File,
+	content []byte,
+	userID int64,
+	v *validator.Validator,
+) (*models.CurriculumDraft, error) {
+	refType := models.RefCurriculumDraft
+	file.OwnerID = userID
+	file.Kind = models.FileResume
+	file.RefType = &refType
+	if err := s.file.Upload(file, content, v); err != nil {
+		return nil, err
+	}
+
+	d := &models.CurriculumDraft{
+		UserID: userID,
+		FileID: file.ID,
+	}
+
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.draft.Insert(d, tx)
+	})
+	if err != nil {
+		s.file.Delete(file.ID, userID)
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (s *curriculumDraftService) FindByID(id, userID int64) (*models.CurriculumDraft, error) {
+	return s.draft.GetByOwner(id, userID)
+}
+
+func (s *curriculumDraftService) FindAll(userID int64) ([]*models.CurriculumDraft, error) {
+	return s.draft.GetAllByUser(userID)
+}
+
+// Confirm saves the draft as a curriculum. The candidate may send the
+// curriculum as they corrected it; otherwise the parsed one is used as is.
+func (s *curriculumDraftService) Confirm(
+	id,
+	userID int64,
+	version int,
+	c *models.Curriculum,
+	v *validator.Validator,
+) (*models.CurriculumDraft, *models.Curriculum, error) {
+	d, err := s.draft.GetByOwner(id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch d.Status {
+	case models.DraftPending:
+		v.AddError("status", "the document is still being read, try again shortly")
+	case models.DraftConfirmed:
+		v.AddError("status", "draft was already confirmed")
+	}
+	v.Check(version > 0, "version", "must be provided")
+	if c == nil {
+		c = d.Curriculum
+	} else if c.KeepCPF && d.Curriculum != nil {
+		c.CPF = d.Curriculum.CPF
+	}
+	if c == nil {
+		v.AddError("curriculum", "must be provided, the document could not be read")
+	}
+	if !v.Valid() {
+		return nil, nil, e.ErrInvalidData
+	}
+
+	c.DefaultName()
+	if c.Skills, err = s.skill.Normalize(c.Skills); err != nil {
+		return nil, nil, err
+	}
+	if err := locate(s.geo, &c.Place, v); err != nil {
+		return nil, nil, err
+	}
+	if c.ValidateCurriculum(v); !v.Valid() {
+		return nil, nil, e.ErrInvalidData
+	}
+
+	d.Version = version
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		if err := s.curriculum.Insert(c, userID, tx); err != nil {
+			return err
+		}
+
+		d.Curriculum = c
+		d.CurriculumID = &c.ID
+		return s.draft.Confirm(d, userID, tx)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return d, c, nil
+}
+
+// Discard drops the draft along with the uploaded document, which was
+// only sent to be read.
+func (s *curriculumDraftService) Discard(id, userID int64) error {
+	d, err := s.draft.GetByOwner(id, userID)
+	if err != nil {
+		return err
+	}
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.draft.Delete(id, userID, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.file.Delete(d.FileID, userID); err != nil && !errors.Is(err, e.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ProcessPending parses a batch of uploaded résumés. The batch is claimed
+// in a transaction of its own, and the documents are downloaded and parsed
+// outside of any. Documents that can't be read fail right away with the
+// reason; errors reaching the storage are retried on the next runs, up to
+// draftMaxAttempts.
+func (s *curriculumDraftService) ProcessPending() error {
+	var drafts []*models.CurriculumDraft
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		var err error
+		drafts, err = s.draft.ClaimPending(draftBatchSize, draftLease, tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, d := range drafts {
+		if err := s.process(d); err != nil {
+			errs = append(errs, fmt.Errorf("draft %d: %w", d.ID, err))
+		}
+		err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+			return s.draft.SaveResult(d, tx)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("draft %d: %w", d.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// process fills in the outcome of parsing d, already counted as an attempt
+// when claimed. The error returned is only for the logs; what the candidate
+// sees goes in d.Error. A panic fails the draft rather than the batch.
+func (s *curriculumDraftService) process(d *models.CurriculumDraft) (err error) {
+	now := time.Now()
+
+	fail := func(reason string) {
+		d.Status = models.DraftFailed
+		d.Error = reason
+		d.ProcessedAt = &now
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			fail("the document could not be read, it may be damaged")
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	// Only a run that died with the draft claimed goes past the limit.
+	if d.Attempts > draftMaxAttempts {
+		fail("the document could not be read")
+		return nil
+	}
+
+	file, content, err := s.file.Content(d.FileID)
+	if err != nil {
+		switch {
+		case errors.Is(err, e.ErrRecordNotFound):
+			fail("the uploaded document was deleted")
+			return nil
+		case d.Attempts >= draftMaxAttempts:
+			fail("the document could not be read")
+		}
+		return err
+	}
+
+	text, err := extract.Text(content, file.ContentType)
+	if err != nil {
+		switch {
+		case errors.Is(err, extract.ErrUnsupported), errors.Is(err, extract.ErrEncrypted), errors.Is(err, extract.ErrNoText):
+			fail(err.Error())
+			return nil
+		default:
+			fail("the document could not be read, it may be damaged")
+			return err
+		}
+	}
+
+	parsed := resume.Parse(text)
+
+	// The account is known to be right, so whatever it fills in needs no
+	// review.
+	if user, err := s.user.GetByID(d.UserID); err == nil {
+		fill := func(field string, value *string, fallback string) {
+			if *value == "" && fallback != "" {
+				*value = fallback
+				parsed.Confidence[field] = 1
+			}
+		}
+		fill("full_name", &parsed.Curriculum.FullName, user.Name)
+		fill("email", &parsed.Curriculum.Email, user.Email)
+		fill("phone", (*string)(&parsed.Curriculum.Phone), string(user.Phone))
+	}
+
+	d.Status = models.DraftReady
+	d.Curriculum = parsed.Curriculum
+	d.Confidence = parsed.Confidence
+	d.Unparsed = parsed.Unparsed
+	d.Error = ""
+	d.ProcessedAt = &now
+	return nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/mailer"
+	"meu_job/internal/models"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	"time"
+)
+
+const (
+	emailBatchSize   = 20
+	emailMaxAttempts = 8
+)
+
+type emailService struct {
+	email  repositories.EmailRepositoryInterface
+	mailer mailer.Mailer
+	db     *sql.DB
+}
+
+type EmailServiceInterface interface {
+	Enqueue(m *models.Email, tx *sql.Tx) error
+	SendPending() error
+}
+
+func NewEmailService(
+	emailRepository repositories.EmailRepositoryInterface,
+	m mailer.Mailer,
+	db *sql.DB,
+) *emailService {
+	return &emailService{
+		email:  emailRepository,
+		mailer: m,
+		db:     db,
+	}
+}
+
+// Enqueue adds the email to the outbox within tx, so it's only sent if
+// whatever prompted it is committed.
+func (s *emailService) Enqueue(m *models.Email, tx *sql.Tx) error {
+	return s.email.Insert(m, tx)
+}
+
+// SendPending hands the due emails to the mailer. A failed email is
+// retried with a growing delay, from a minute up to about an hour, and
+// given up on after emailMaxAttempts.
+func (s *emailService) SendPending() error {
+	var errs []error
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		emails, err := s.email.ClaimPending(emailBatchSize, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range emails {
+			message := mailer.Message{
+				To:      m.To,
+				Subject: m.Subject,
+				Body:    m.Body,
+				Headers: m.Headers,
+			}
+			for _, a := range m.Attachments {
+				message.Attachments = append(message.Attachments, mailer.Attachment(a))
+			}
+
+			err := s.mailer.Send(message)
+			if err == nil {
+				if err := s.email.MarkSent(m.ID, tx); err != nil {
+					return err
+				}
+				continue
+			}
+
+			errs = append(errs, fmt.Errorf("email %d: %w", m.ID, err))
+
+			var retryAt *time.Time
+			if m.Attempts+1 < emailMaxAttempts && !errors.Is(err, mailer.ErrInvalidMessage) {
+				at := time.Now().Add(time.Minute << m.Attempts)
+				retryAt = &at
+			}
+			if err := s.email.MarkFailed(m.ID, err.Error(), retryAt, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return errors.Join(append(errs, err)...)
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"meu_job/internal/models"
+	"meu_job/internal/repositories"
+	"meu_job/internal/storage"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	fileURLTTL        = 15 * time.Minute
+	fileUploadTimeout = 30 * time.Second
+)
+
+type fileService struct {
+	file    repositories.FileRepositoryInterface
+	storage storage.Storage
+	db      *sql.DB
+}
+
+type FileServiceInterface interface {
+	Upload(file *models.File, content []byte, v *validator.Validator) error
+	FindByID(id, ownerID int64) (*models.File, error)
+	FindAll(ownerID int64) ([]*models.File, error)
+	FindAllByRef(refType string, refIDs []int64) ([]*models.File, error)
+	Attach(ids []int64, ownerID int64, refType string, refID int64, tx *sql.Tx) error
+	DetachAll(ownerID int64, refType string, tx *sql.Tx) error
+	Content(id int64) (*models.File, []byte, error)
+	SignedURL(file *models.File) (*models.FileDTO, error)
+	SignedURLByID(id int64) (*models.FileDTO, error)
+	OpenSigned(key string, expires int64, signature string) (*models.File, io.ReadCloser, error)
+	Delete(id, ownerID int64) error
+	DeleteByID(id int64) error
+	DeleteAll(ownerID int64, kind models.FileKind) error
+}
+
+func NewFileService(
+	fileRepository repositories.FileRepositoryInterface,
+	store storage.Storage,
+	db *sql.DB,
+) *fileService {
+	return &fileService{
+		file:    fileRepository,
+		storage: store,
+		db:      db,
+	}
+}
+
+// Upload sniffs, checks and stores the content, then records it. Kind,
+// FileName, OwnerID and the reference must be set by the caller.
+func (s *fileService) Upload(file *models.File, content []byte, v *validator.Validator) error {
+	file.Size = int64(len(content))
+	file.ContentType = models.DetectContentType(content)
+	if file.ValidateFile(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	sum := sha256.Sum256(content)
+	file.Checksum = hex.EncodeToString(sum[:])
+
+	key, err := storageKey(file)
+	if err != nil {
+		return err
+	}
+	file.StorageKey = key
+
+	ctx, cancel := context.WithTimeout(context.Background(), fileUploadTimeout)
+	defer cancel()
+
+	if err := s.storage.Put(ctx, key, bytes.NewReader(content), file.Size, file.ContentType); err != nil {
+		return err
+	}
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.file.Insert(file, tx)
+	})
+	if err != nil {
+		s.storage.Delete(ctx, key)
+		return err
+	}
+
+	return nil
+}
+
+// storageKey spreads objects by kind and month and never reuses the
+// client's file name, which only survives as metadata.
+func storageKey(file *models.File) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	ext := strings.ToLower(path.Ext(file.FileName))
+	if len(ext) > 10 {
+		ext = ""
+	}
+
+	return string(file.Kind) + "/" + time.Now().UTC().Format("2006/01") + "/" + hex.EncodeToString(random) + ext, nil
+}
+
+func (s *fileService) FindByID(id, ownerID int64) (*models.File, error) {
+	return s.file.GetByOwner(id, ownerID)
+}
+
+func (s *fileService) FindAll(ownerID int64) ([]*models.File, error) {
+	return s.file.GetAllByOwner(ownerID)
+}
+
+// FindAllByRef ignores ownership, like GetByID; callers must have
+// authorized access to what the files are attached to.
+func (s *fileService) FindAllByRef(refType string, refIDs []int64) ([]*models.File, error) {
+	if len(refIDs) == 0 {
+		return []*models.File{}, nil
+	}
+	return s.file.GetAllByRef(refType, refIDs)
+}
+
+// Attach references files uploaded beforehand from what they were sent
+// with, within the caller's transaction.
+func (s *fileService) Attach(ids []int64, ownerID int64, refType string, refID int64, tx *sql.Tx) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.file.Attach(ids, ownerID, refType, refID, tx)
+}
+
+// DetachAll takes the owner's files off whatever of refType they were
+// sent with, within the caller's transaction.
+func (s *fileService) DetachAll(ownerID int64, refType string, tx *sql.Tx) error {
+	return s.file.DetachAll(ownerID, refType, tx)
+}
+
+// Content reads a file back for processing on the server, regardless of
+// who uploaded it.
+func (s *fileService) Content(id int64) (*models.File, []byte, error) {
+	file, err := s.file.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fileUploadTimeout)
+	defer cancel()
+
+	body, err := s.storage.Get(ctx, file.StorageKey)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			return nil, nil, e.ErrRecordNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+	defer body.Close()
+
+	content, err := io.ReadAll(io.LimitReader(body, models.MaxFileSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, content, nil
+}
+
+func (s *fileService) SignedURL(file *models.File) (*models.FileDTO, error) {
+	url, err := s.storage.SignedURL(file.StorageKey, fileURLTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	expires := time.Now().Add(fileURLTTL)
+	dto := file.ToDTO()
+	dto.URL = url
+	dto.URLExpires = &expires
+	return dto, nil
+}
+
+func (s *fileService) SignedURLByID(id int64) (*models.FileDTO, error) {
+	file, err := s.file.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.SignedURL(file)
+}
+
+// OpenSigned serves the download URLs of drivers that point back at the
+// API. Drivers that sign their own URLs never reach it.
+func (s *fileService) OpenSigned(key string, expires int64, signature string) (*models.File, io.ReadCloser, error) {
+	verifier, ok := s.storage.(storage.Verifier)
+	if !ok {
+		return nil, nil, e.ErrRecordNotFound
+	}
+
+	if err := verifier.Verify(key, expires, signature); err != nil {
+		return nil, nil, e.ErrRecordNotFound
+	}
+
+	file, err := s.file.GetByKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := s.storage.Get(context.Background(), key)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			return nil, nil, e.ErrRecordNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	return file, body, nil
+}
+
+// Delete removes the object right away; the row stays, soft deleted, so
+// references to it keep resolving to "not found" instead of breaking.
+func (s *fileService) Delete(id, ownerID int64) error {
+	file, err := s.file.GetByOwner(id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.file.Delete(id, ownerID, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fileUploadTimeout)
+	defer cancel()
+
+	return s.storage.Delete(ctx, file.StorageKey)
+}
+
+// DeleteByID removes a file regardless of who uploaded it, for files that
+// belong to a shared resource such as a business logo.
+func (s *fileService) DeleteByID(id int64) error {
+	file, err := s.file.GetByID(id)
+	if err != nil {
+		return err
+	}
+	return s.Delete(file.ID, file.OwnerID)
+}
+
+func (s *fileService) DeleteAll(ownerID int64, kind models.FileKind) error {
+	files, err := s.file.GetAllByOwner(ownerID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, file := range files {
+		if file.Kind == kind {
+			errs = append(errs, s.Delete(file.ID, ownerID))
+		}
+	}
+	return errors.Join(errs...)
+}
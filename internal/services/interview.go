@@ -0,0 +1,706 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"meu_job/internal/ical"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// calendarHistory is how far back the calendar feeds go.
+const calendarHistory = 30 * 24 * time.Hour
+
+// brasilia is the time emails are written in. Brazil has had no daylight
+// saving time since 2019, so a fixed zone is exact.
+var brasilia = time.FixedZone("BRT", -3*60*60)
+
+type interviewService struct {
+	interview    repositories.InterviewRepositoryInterface
+	application  repositories.ApplicationRepositoryInterface
+	business     repositories.BusinessRepositoryInterface
+	user         repositories.UserRepositoryInterface
+	email        EmailServiceInterface
+	notification NotificationServiceInterface
+	secret       []byte
+	publicURL    string
+	db           *sql.DB
+}
+
+type InterviewServiceInterface interface {
+	FindAllByApplication(applicationID, userID int64) ([]*models.Interview, error)
+	FindAllForBusiness(applicationID, businessID, userID int64) ([]*models.Interview, error)
+	FindUpcoming(businessID, userID int64, f filters.Filters) ([]*models.Interview, filters.Metadata, error)
+	FindForCandidate(id, applicationID, userID int64) (*models.Interview, error)
+	FindForBusiness(id, businessID, userID int64) (*models.Interview, error)
+	Propose(i *models.Interview, applicationID, businessID, userID int64, v *validator.Validator) error
+	Reschedule(i *models.Interview, businessID, userID int64, reason string, v *validator.Validator) (*models.Interview, error)
+	Accept(id, applicationID, userID int64, startsAt *time.Time, version int, v *validator.Validator) (*models.Interview, error)
+	CancelByCandidate(id, applicationID, userID int64, reason string, version int, v *validator.Validator) (*models.Interview, error)
+	CancelByBusiness(id, businessID, userID int64, reason string, version int, v *validator.Validator) (*models.Interview, error)
+	CancelOpen(a *models.Application, reason string, userID int64, tx *sql.Tx) error
+	Invite(i *models.Interview) []byte
+	CalendarURL(user *models.User) string
+	RotateCalendarURL(user *models.User) (string, error)
+	CalendarFeed(token string) ([]byte, error)
+}
+
+func NewInterviewService(
+	interviewRepository repositories.InterviewRepositoryInterface,
+	applicationRepository repositories.ApplicationRepositoryInterface,
+	businessRepository repositories.BusinessRepositoryInterface,
+	userRepository repositories.UserRepositoryInterface,
+	emailService EmailServiceInterface,
+	notificationService NotificationServiceInterface,
+	secret string,
+	publicURL string,
+	db *sql.DB,
+) *interviewService {
+	return &interviewService{
+		interview:    interviewRepository,
+		application:  applicationRepository,
+		business:     businessRepository,
+		user:         userRepository,
+		email:        emailService,
+		notification: notificationService,
+		secret:       []byte(secret),
+		publicURL:    strings.TrimRight(publicURL, "/"),
+		db:           db,
+	}
+}
+
+func (s *interviewService) FindAllByApplication(applicationID, userID int64) ([]*models.Interview, error) {
+	if _, err := s.application.GetByUser(applicationID, userID); err != nil {
+		return nil, err
+	}
+	return s.interview.GetAllByApplication(applicationID)
+}
+
+func (s *interviewService) FindAllForBusiness(applicationID, businessID, userID int64) ([]*models.Interview, error) {
+	if _, err := s.business.GetByID(businessID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.application.GetByBusiness(applicationID, businessID); err != nil {
+		return nil, err
+	}
+	return s.interview.GetAllByApplication(applicationID)
+}
+
+// FindUpcoming lists the business's interviews still to happen, scheduled
+// or waiting for the candidate to pick a time.
+func (s *interviewService) FindUpcoming(businessID, userID int64, f filters.Filters) ([]*models.Interview, filters.Metadata, error) {
+	if _, err := s.business.GetByID(businessID, userID); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	return s.interview.GetAllByBusiness(businessID, time.Now(), f)
+}
+
+func (s *interviewService) FindForCandidate(id, applicationID, userID int64) (*models.Interview, error) {
+	i, err := s.interview.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if i.ApplicationID != applicationID || i.Candidate.UserID != userID {
+		return nil, e.ErrRecordNotFound
+	}
+	return i, nil
+}
+
+func (s *interviewService) FindForBusiness(id, businessID, userID int64) (*models.Interview, error) {
+	if _, err := s.business.GetByID(businessID, userID); err != nil {
+		return nil, err
+	}
+
+	i, err := s.interview.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if i.BusinessID != businessID {
+		return nil, e.ErrRecordNotFound
+	}
+	return i, nil
+}
+
+// Propose offers the candidate times for an interview, with userID as the
+// interviewer. Onsite interviews without a location are held at the
+// business's address.
+func (s *interviewService) Propose(
+	i *models.Interview,
+	applicationID,
+	businessID,
+	userID int64,
+	v *validator.Validator,
+) error {
+	b, err := s.business.GetByID(businessID, userID)
+	if err != nil {
+		return err
+	}
+	a, err := s.application.GetByBusiness(applicationID, businessID)
+	if err != nil {
+		return err
+	}
+	interviewer, err := s.user.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if i.Mode == models.InterviewOnsite && i.Location == "" {
+		i.Location = b.Address.String()
+	}
+
+	v.Check(a.Stage == models.StageInterview, "stage", "the application must be at the interview stage")
+	if i.ValidateInterview(v, time.Now()); !v.Valid() {
+		return e.ErrInvalidData
+	}
+	if err := s.checkBusy(i, userID, v); err != nil {
+		return err
+	}
+	if !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	i.ApplicationID = a.ID
+	i.BusinessID = businessID
+	i.JobTitle = a.JobTitle
+	i.BusinessName = a.BusinessName
+	i.Candidate = a.Candidate
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		if err := s.interview.Insert(i, userID, tx); err != nil {
+			return err
+		}
+		i.Interviewer = models.Participant{UserID: userID, Name: interviewer.Name, Email: interviewer.Email}
+
+		body := fmt.Sprintf("%s quer entrevistar você para a vaga \"%s\".\n\n", i.BusinessName, i.JobTitle)
+		if err := s.send(i.Candidate, "Convite para entrevista: "+i.JobTitle, body+s.proposal(i), nil, tx); err != nil {
+			return err
+		}
+		return s.notify(i.Candidate, models.NotifyInterviewProposed, "Convite para entrevista: "+i.JobTitle, fmt.Sprintf(
+			"%s quer entrevistar você para a vaga \"%s\". Escolha um dos horários propostos.",
+			i.BusinessName, i.JobTitle,
+		), i, tx)
+	})
+}
+
+// checkBusy reports the slots overlapping the interviewer's scheduled
+// interviews. Two proposals may offer the same time; whichever candidate
+// picks it first gets it.
+func (s *interviewService) checkBusy(i *models.Interview, interviewerID int64, v *validator.Validator) error {
+	first, last := i.Slots[0], i.Slots[len(i.Slots)-1]
+	busy, err := s.interview.GetBusy(interviewerID, first.StartsAt, last.EndsAt, i.ID)
+	if err != nil {
+		return err
+	}
+
+	for n, slot := range i.Slots {
+		for _, taken := range busy {
+			if slot.Overlaps(taken) {
+				v.AddError(fmt.Sprintf("slots[%d]", n), "overlaps another interview of the interviewer")
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Reschedule proposes new times, and may change how the interview is
+// held. A scheduled interview is called off in the calendars until the
+// candidate picks one of them.
+func (s *interviewService) Reschedule(
+	proposal *models.Interview,
+	businessID,
+	userID int64,
+	reason string,
+	v *validator.Validator,
+) (*models.Interview, error) {
+	i, err := s.FindForBusiness(proposal.ID, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if proposal.Mode == models.InterviewOnsite && proposal.Location == "" {
+		proposal.Location = i.Location
+	}
+
+	v.Check(proposal.Version > 0, "version", "must be provided")
+	v.Check(i.Status != models.InterviewCancelled, "status", "cancelled interviews can't be rescheduled")
+	models.ValidateInterviewReason(v, reason)
+	if proposal.ValidateInterview(v, time.Now()); !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+	if err := s.checkBusy(proposal, i.Interviewer.UserID, v); err != nil {
+		return nil, err
+	}
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	previous := *i
+	wasScheduled := i.Status == models.InterviewScheduled
+
+	i.Mode = proposal.Mode
+	i.VideoURL = proposal.VideoURL
+	i.Location = proposal.Location
+	i.Notes = proposal.Notes
+	i.DurationMinutes = proposal.DurationMinutes
+	i.Slots = proposal.Slots
+	i.Status = models.InterviewProposed
+	i.StartsAt, i.EndsAt = nil, nil
+	i.Reason = reason
+	i.Version = proposal.Version
+	if wasScheduled {
+		i.Sequence++
+		previous.Sequence = i.Sequence
+	}
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		if err := s.interview.Update(i, userID, tx); err != nil {
+			return err
+		}
+
+		body := fmt.Sprintf(
+			"%s precisa reagendar a entrevista para a vaga \"%s\".\nMotivo: %s\n\n",
+			i.BusinessName, i.JobTitle, reason,
+		)
+		var cancelled *ical.Calendar
+		if wasScheduled {
+			body = fmt.Sprintf(
+				"%s precisa reagendar a entrevista para a vaga \"%s\", que estava marcada para %s.\nMotivo: %s\n\n",
+				i.BusinessName, i.JobTitle, formatBrasilia(*previous.StartsAt), reason,
+			)
+			cancelled = s.calendar(ical.MethodCancel, &previous, false)
+		}
+		if err := s.send(i.Candidate, "Entrevista reagendada: "+i.JobTitle, body+s.proposal(i), cancelled, tx); err != nil {
+			return err
+		}
+		err := s.notify(i.Candidate, models.NotifyInterviewProposed, "Entrevista reagendada: "+i.JobTitle, fmt.Sprintf(
+			"%s propôs novos horários para a entrevista da vaga \"%s\".",
+			i.BusinessName, i.JobTitle,
+		), i, tx)
+		if err != nil {
+			return err
+		}
+
+		if !wasScheduled {
+			return nil
+		}
+		return s.send(
+			previous.Interviewer,
+			"Entrevista desmarcada para reagendamento: "+i.Candidate.Name,
+			fmt.Sprintf(
+				"A entrevista com %s para a vaga \"%s\", marcada para %s, foi desmarcada para reagendamento.\nMotivo: %s\n",
+				i.Candidate.Name, i.JobTitle, formatBrasilia(*previous.StartsAt), reason,
+			),
+			s.calendar(ical.MethodCancel, &previous, true),
+			tx,
+		)
+	})
+	return i, err
+}
+
+// Accept schedules the interview at the slot the candidate picked, and
+// sends both sides the invite.
+func (s *interviewService) Accept(
+	id,
+	applicationID,
+	userID int64,
+	startsAt *time.Time,
+	version int,
+	v *validator.Validator,
+) (*models.Interview, error) {
+	i, err := s.FindForCandidate(id, applicationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.application.GetByUser(applicationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Check(version > 0, "version", "must be provided")
+	v.Check(a.Stage.IsOpen(), "stage", "the application is closed")
+	v.Check(i.Status == models.InterviewProposed, "status", "only proposed interviews can be accepted")
+	v.Check(startsAt != nil, "starts_at", "must be provided")
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	slot, ok := i.Slot(*startsAt)
+	v.Check(ok, "starts_at", "must be one of the times proposed")
+	v.Check(!ok || slot.StartsAt.After(time.Now()), "starts_at", "must be in the future")
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	busy, err := s.interview.GetBusy(i.Interviewer.UserID, slot.StartsAt, slot.EndsAt, i.ID)
+	if err != nil {
+		return nil, err
+	}
+	v.Check(len(busy) == 0, "starts_at", "overlaps another interview of the interviewer")
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	i.Status = models.InterviewScheduled
+	i.StartsAt, i.EndsAt = &slot.StartsAt, &slot.EndsAt
+	i.Sequence++
+	i.Version = version
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		if err := s.interview.Update(i, userID, tx); err != nil {
+			return err
+		}
+
+		when := formatBrasilia(*i.StartsAt)
+		err := s.send(
+			i.Candidate,
+			"Entrevista marcada: "+i.JobTitle,
+			fmt.Sprintf(
+				"Sua entrevista para a vaga \"%s\" na %s está marcada para %s.\n\n%s\nO convite para a sua agenda está em anexo.\n",
+				i.JobTitle, i.BusinessName, when, s.details(i),
+			),
+			s.calendar(ical.MethodRequest, i, false),
+			tx,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = s.send(
+			i.Interviewer,
+			"Entrevista marcada: "+i.Candidate.Name,
+			fmt.Sprintf(
+				"%s escolheu %s para a entrevista da vaga \"%s\".\n\n%s\nO convite para a sua agenda está em anexo.\n",
+				i.Candidate.Name, when, i.JobTitle, s.details(i),
+			),
+			s.calendar(ical.MethodPublish, i, true),
+			tx,
+		)
+		if err != nil {
+			return err
+		}
+
+		return s.notify(i.Interviewer, models.NotifyInterviewScheduled, "Entrevista marcada: "+i.JobTitle, fmt.Sprintf(
+			"A entrevista para a vaga \"%s\" foi marcada para %s.",
+			i.JobTitle, when,
+		), i, tx)
+	})
+	return i, err
+}
+
+func (s *interviewService) CancelByCandidate(
+	id,
+	applicationID,
+	userID int64,
+	reason string,
+	version int,
+	v *validator.Validator,
+) (*models.Interview, error) {
+	i, err := s.FindForCandidate(id, applicationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return i, s.cancel(i, userID, reason, version, v)
+}
+
+func (s *interviewService) CancelByBusiness(
+	id,
+	businessID,
+	userID int64,
+	reason string,
+	version int,
+	v *validator.Validator,
+) (*models.Interview, error) {
+	i, err := s.FindForBusiness(id, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return i, s.cancel(i, userID, reason, version, v)
+}
+
+func (s *interviewService) cancel(i *models.Interview, userID int64, reason string, version int, v *validator.Validator) error {
+	v.Check(version > 0, "version", "must be provided")
+	v.Check(i.Status != models.InterviewCancelled, "status", "interview is already cancelled")
+	models.ValidateInterviewReason(v, reason)
+	if !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	i.Version = version
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.cancelInTx(i, userID, reason, tx)
+	})
+}
+
+// CancelOpen calls off the interviews of an application being closed,
+// except those already held.
+func (s *interviewService) CancelOpen(a *models.Application, reason string, userID int64, tx *sql.Tx) error {
+	interviews, err := s.interview.GetAllByApplication(a.ID)
+	if err != nil {
+		return err
+	}
+
+	if reason == "" {
+		reason = "A candidatura foi encerrada."
+	}
+	now := time.Now()
+	for _, i := range interviews {
+		if i.Status == models.InterviewCancelled || (i.EndsAt != nil && i.EndsAt.Before(now)) {
+			continue
+		}
+		if err := s.cancelInTx(i, userID, reason, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelInTx cancels the interview and lets the other side know. Once
+// scheduled, both sides are sent the cancellation for their calendars.
+func (s *interviewService) cancelInTx(i *models.Interview, userID int64, reason string, tx *sql.Tx) error {
+	wasScheduled := i.Status == models.InterviewScheduled
+	i.Status = models.InterviewCancelled
+	i.Reason = reason
+	i.CancelledBy = &userID
+	if wasScheduled {
+		i.Sequence++
+	}
+
+	if err := s.interview.Update(i, userID, tx); err != nil {
+		return err
+	}
+
+	byCandidate := userID == i.Candidate.UserID
+	who := i.BusinessName
+	if byCandidate {
+		who = i.Candidate.Name
+	}
+	subject := "Entrevista cancelada: " + i.JobTitle
+	body := fmt.Sprintf("A entrevista para a vaga \"%s\" foi cancelada por %s.\nMotivo: %s\n", i.JobTitle, who, reason)
+	if wasScheduled {
+		body = fmt.Sprintf(
+			"A entrevista para a vaga \"%s\", marcada para %s, foi cancelada por %s.\nMotivo: %s\n",
+			i.JobTitle, formatBrasilia(*i.StartsAt), who, reason,
+		)
+	}
+
+	if wasScheduled || !byCandidate {
+		var invite *ical.Calendar
+		if wasScheduled {
+			invite = s.calendar(ical.MethodCancel, i, false)
+		}
+		if err := s.send(i.Candidate, subject, body, invite, tx); err != nil {
+			return err
+		}
+	}
+	if wasScheduled || byCandidate {
+		var invite *ical.Calendar
+		if wasScheduled {
+			invite = s.calendar(ical.MethodCancel, i, true)
+		}
+		if err := s.send(i.Interviewer, subject, body, invite, tx); err != nil {
+			return err
+		}
+	}
+
+	to, notice := i.Candidate, fmt.Sprintf("%s cancelou a entrevista para a vaga \"%s\".", i.BusinessName, i.JobTitle)
+	if byCandidate {
+		to, notice = i.Interviewer, fmt.Sprintf("A entrevista para a vaga \"%s\" foi cancelada pelo candidato.", i.JobTitle)
+	}
+	return s.notify(to, models.NotifyInterviewCancelled, subject, notice, i, tx)
+}
+
+// notify tells one side about the interview in the app, as the emails do.
+func (s *interviewService) notify(
+	to models.Participant,
+	t models.NotificationType,
+	title,
+	body string,
+	i *models.Interview,
+	tx *sql.Tx,
+) error {
+	return s.notification.Notify(&models.Notification{
+		UserID: to.UserID,
+		Type:   t,
+		Title:  title,
+		Body:   body,
+		Data: map[string]int64{
+			"application_id": i.ApplicationID,
+			"interview_id":   i.ID,
+			"business_id":    i.BusinessID,
+		},
+	}, tx)
+}
+
+// Invite is the interview as an .ics file to download. It has no event
+// until a time is picked.
+func (s *interviewService) Invite(i *models.Interview) []byte {
+	c := ical.Calendar{Method: ical.MethodPublish}
+	if i.StartsAt != nil {
+		c.Events = []ical.Event{s.event(i, false)}
+	}
+	return c.Encode()
+}
+
+// calendarToken identifies the user in the URL of their calendar feed,
+// which calendar apps fetch without logging in. It's signed rather than
+// stored, and stops working when the user rotates it or changes their
+// password, both of which raise a version signed into it.
+func (s *interviewService) calendarToken(user *models.User) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "interview-calendar\n%d\n%d\n%d", user.ID, user.TokenVersion, user.CalendarFeedVersion)
+	return strconv.FormatInt(user.ID, 10) + "-" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *interviewService) CalendarURL(user *models.User) string {
+	return s.publicURL + "/v1/calendar/" + s.calendarToken(user) + ".ics"
+}
+
+// RotateCalendarURL revokes the URL of the user's calendar feed, for when
+// it got shared, and hands out a new one.
+func (s *interviewService) RotateCalendarURL(user *models.User) (string, error) {
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.user.RotateCalendarFeed(tx, user)
+	})
+	if err != nil {
+		return "", err
+	}
+	s.user.Forget(user.ID)
+
+	return s.CalendarURL(user), nil
+}
+
+// CalendarFeed lists the user's interviews, as candidate and as
+// interviewer alike. Tokens that don't check out are reported as not
+// found.
+func (s *interviewService) CalendarFeed(token string) ([]byte, error) {
+	raw, _, _ := strings.Cut(token, "-")
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, e.ErrRecordNotFound
+	}
+
+	user, err := s.user.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(token), []byte(s.calendarToken(user))) {
+		return nil, e.ErrRecordNotFound
+	}
+
+	interviews, err := s.interview.GetCalendar(user.ID, time.Now().Add(-calendarHistory))
+	if err != nil {
+		return nil, err
+	}
+
+	c := ical.Calendar{Method: ical.MethodPublish, Name: "Entrevistas - Meu Job"}
+	for _, i := range interviews {
+		c.Events = append(c.Events, s.event(i, i.Interviewer.UserID == user.ID))
+	}
+	return c.Encode(), nil
+}
+
+func (s *interviewService) calendar(method ical.Method, i *models.Interview, forInterviewer bool) *ical.Calendar {
+	return &ical.Calendar{Method: method, Events: []ical.Event{s.event(i, forInterviewer)}}
+}
+
+// event is the interview in a calendar, titled for whoever it's sent to.
+func (s *interviewService) event(i *models.Interview, forInterviewer bool) ical.Event {
+	summary := fmt.Sprintf("Entrevista: %s - %s", i.JobTitle, i.BusinessName)
+	if forInterviewer {
+		summary = fmt.Sprintf("Entrevista com %s: %s", i.Candidate.Name, i.JobTitle)
+	}
+
+	host := "meujob"
+	if u, err := url.Parse(s.publicURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+
+	event := ical.Event{
+		UID:         fmt.Sprintf("interview-%d@%s", i.ID, host),
+		Sequence:    i.Sequence,
+		Stamp:       time.Now(),
+		Start:       *i.StartsAt,
+		End:         *i.EndsAt,
+		Summary:     summary,
+		Description: s.details(i),
+		Location:    i.Location,
+		Organizer:   ical.Person{Name: i.Interviewer.Name, Email: i.Interviewer.Email},
+		Attendees:   []ical.Person{{Name: i.Candidate.Name, Email: i.Candidate.Email}},
+		Cancelled:   i.Status == models.InterviewCancelled,
+	}
+	switch i.Mode {
+	case models.InterviewVideo:
+		event.Location = i.VideoURL
+		event.Conference = i.VideoURL
+	case models.InterviewPhone:
+		event.Location = "Por telefone"
+	}
+	return event
+}
+
+// details says how the interview is held, for emails and invites alike.
+func (s *interviewService) details(i *models.Interview) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Duração: %d minutos\n", i.DurationMinutes)
+	switch i.Mode {
+	case models.InterviewVideo:
+		fmt.Fprintf(&b, "Por vídeo: %s\n", i.VideoURL)
+	case models.InterviewOnsite:
+		fmt.Fprintf(&b, "Presencial: %s\n", i.Location)
+	case models.InterviewPhone:
+		fmt.Fprintf(&b, "Por telefone, com %s\n", i.Interviewer.Name)
+	}
+	if i.Notes != "" {
+		fmt.Fprintf(&b, "\n%s\n", i.Notes)
+	}
+	return b.String()
+}
+
+// proposal lists the times to pick from.
+func (s *interviewService) proposal(i *models.Interview) string {
+	var b strings.Builder
+	b.WriteString("Escolha um dos horários (horário de Brasília):\n\n")
+	for _, slot := range i.Slots {
+		fmt.Fprintf(&b, "- %s\n", formatBrasilia(slot.StartsAt))
+	}
+	fmt.Fprintf(&b, "\n%s\nPara escolher, acesse:\n%s/v1/applications/%d/interviews\n", s.details(i), s.publicURL, i.ApplicationID)
+	return b.String()
+}
+
+func (s *interviewService) send(to models.Participant, subject, body string, invite *ical.Calendar, tx *sql.Tx) error {
+	name, _, _ := strings.Cut(strings.TrimSpace(to.Name), " ")
+	m := &models.Email{
+		UserID:  &to.UserID,
+		To:      to.Email,
+		Subject: subject,
+		Body:    fmt.Sprintf("Olá, %s!\n\n%s", name, body),
+	}
+	if invite != nil {
+		m.Attachments = []models.EmailAttachment{{
+			Filename:    "convite.ics",
+			ContentType: "text/calendar; charset=utf-8; method=" + string(invite.Method),
+			Content:     invite.Encode(),
+		}}
+	}
+	return s.email.Enqueue(m, tx)
+}
+
+var weekdays = [...]string{"dom", "seg", "ter", "qua", "qui", "sex", "sáb"}
+
+// formatBrasilia writes the time as in "seg, 20/10/2026 às 14:00".
+func formatBrasilia(t time.Time) string {
+	t = t.In(brasilia)
+	return weekdays[t.Weekday()] + ", " + t.Format("02/01/2006 às 15:04")
+}
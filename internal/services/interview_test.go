@@ -0,0 +1,86 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"meu_job/internal/models"
+	"meu_job/internal/repositories"
+	e "meu_job/utils/errors"
+	"testing"
+	"time"
+)
+
+// Only the lookups the calendar feed makes are implemented; anything else
+// panics on the nil interface.
+type feedUsers struct {
+	repositories.UserRepositoryInterface
+	users map[int64]*models.User
+}
+
+func (r *feedUsers) GetByID(id int64) (*models.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, e.ErrRecordNotFound
+}
+
+type feedInterviews struct {
+	repositories.InterviewRepositoryInterface
+}
+
+func (feedInterviews) GetCalendar(userID int64, since time.Time) ([]*models.Interview, error) {
+	return nil, nil
+}
+
+func TestCalendarToken(t *testing.T) {
+	user := &models.User{ID: 7, TokenVersion: 2}
+	users := &feedUsers{users: map[int64]*models.User{7: user}}
+	s := NewInterviewService(feedInterviews{}, nil, nil, users, nil, nil, "segredo", "https://meujob.com.br/", nil)
+
+	token := s.calendarToken(user)
+	// HMAC-SHA256 of "interview-calendar\n7\n2\n0" keyed with "segredo".
+	want := "7-5508a43ec95302ac9ad4b2aefdf18a4419246cd0516fbc2324188389c02db129"
+	if token != want {
+		t.Fatalf("calendarToken = %q, want %q", token, want)
+	}
+	if got := s.CalendarURL(user); got != "https://meujob.com.br/v1/calendar/"+want+".ics" {
+		t.Errorf("CalendarURL = %q", got)
+	}
+
+	feed, err := s.CalendarFeed(token)
+	if err != nil {
+		t.Fatalf("CalendarFeed: %v", err)
+	}
+	if !bytes.HasPrefix(feed, []byte("BEGIN:VCALENDAR\r\n")) {
+		t.Errorf("CalendarFeed = %q, want a calendar", feed)
+	}
+
+	invalid := map[string]string{
+		"empty":        "",
+		"no signature": "7",
+		"unknown user": "8" + want[1:],
+		"tampered":     want[:len(want)-1] + "8",
+		"other secret": NewInterviewService(nil, nil, nil, nil, nil, nil, "outro", "", nil).calendarToken(user),
+	}
+	for name, token := range invalid {
+		if _, err := s.CalendarFeed(token); !errors.Is(err, e.ErrRecordNotFound) {
+			t.Errorf("%s: CalendarFeed(%q) error = %v, want %v", name, token, err, e.ErrRecordNotFound)
+		}
+	}
+
+	// Rotating the feed or changing the password revokes the URL.
+	for _, change := range []func(){
+		func() { user.CalendarFeedVersion++ },
+		func() { user.TokenVersion++ },
+	} {
+		change()
+		if _, err := s.CalendarFeed(token); !errors.Is(err, e.ErrRecordNotFound) {
+			t.Errorf("revoked token: CalendarFeed error = %v, want %v", err, e.ErrRecordNotFound)
+		}
+		if next := s.calendarToken(user); next == token {
+			t.Errorf("calendarToken didn't change")
+		} else {
+			token = next
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"meu_job/internal/geo"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+)
+
+type jobService struct {
+	job          repositories.JobRepositoryInterface
+	business     repositories.BusinessRepositoryInterface
+	verification VerificationServiceInterface
+	skill        SkillServiceInterface
+	geo          geo.CEPResolver
+	db           *sql.DB
+}
+
+type JobServiceInterface interface {
+	FindAll(businessID, userID int64, status string, f filters.Filters) ([]*models.Job, filters.Metadata, error)
+	FindByID(id, businessID, userID int64) (*models.Job, error)
+	Save(j *models.Job, businessID, userID int64, v *validator.Validator) error
+	Update(j *models.Job, businessID, userID int64, v *validator.Validator) error
+	Publish(id, businessID, userID int64, version int, v *validator.Validator) (*models.Job, error)
+	Close(id, businessID, userID int64, version int, v *validator.Validator) (*models.Job, error)
+	Delete(id, businessID, userID int64) error
+	Search(search models.JobSearch, f filters.Filters, v *validator.Validator) ([]*models.Job, *models.JobFacets, filters.Metadata, error)
+	FindPublic(id int64) (*models.Job, error)
+}
+
+func NewJobService(
+	jobRepository repositories.JobRepositoryInterface,
+	businessRepository repositories.BusinessRepositoryInterface,
+	verificationService VerificationServiceInterface,
+	skillService SkillServiceInterface,
+	cepResolver geo.CEPResolver,
+	db *sql.DB,
+) *jobService {
+	return &jobService{
+		job:          jobRepository,
+		business:     businessRepository,
+		verification: verificationService,
+		skill:        skillService,
+		geo:          cepResolver,
+		db:           db,
+	}
+}
+
+// member makes sure the user belongs to the business; jobs of businesses
+// they aren't part of are reported as not found.
+func (s *jobService) member(businessID, userID int64) error {
+	_, err := s.business.GetByID(businessID, userID)
+	return err
+}
+
+func (s *jobService) FindAll(
+	businessID,
+	userID int64,
+	status string,
+	f filters.Filters,
+) ([]*models.Job, filters.Metadata, error) {
+	if err := s.member(businessID, userID); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	return s.job.GetAllByBusiness(businessID, status, f)
+}
+
+func (s *jobService) FindByID(id, businessID, userID int64) (*models.Job, error) {
+	if err := s.member(businessID, userID); err != nil {
+		return nil, err
+	}
+	return s.job.GetByBusiness(id, businessID)
+}
+
+// Save writes the job as a draft; any member may, verified or not.
+func (s *jobService) Save(j *models.Job, businessID, userID int64, v *validator.Validator) error {
+	if err := s.member(businessID, userID); err != nil {
+		return err
+	}
+
+	j.BusinessID = businessID
+	if err := s.normalizeSkills(j); err != nil {
+		return err
+	}
+	if err := locate(s.geo, &j.Place, v); err != nil {
+		return err
+	}
+	if j.ValidateJob(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.job.Insert(j, userID, tx)
+	})
+}
+
+// Update edits drafts and published jobs alike; the latter are matched
+// again with the changes.
+func (s *jobService) Update(j *models.Job, businessID, userID int64, v *validator.Validator) error {
+	current, err := s.FindByID(j.ID, businessID, userID)
+	if err != nil {
+		return err
+	}
+
+	v.Check(current.Status != models.JobClosed, "status", "closed jobs can't be edited")
+	if !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	j.BusinessID = businessID
+	if err := s.normalizeSkills(j); err != nil {
+		return err
+	}
+	if err := locate(s.geo, &j.Place, v); err != nil {
+		return err
+	}
+	if j.ValidateJob(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.job.Update(j, userID, tx)
+	})
+}
+
+// Publish opens the job to candidates, drafts and closed jobs alike. Only
+// verified businesses may publish.
+func (s *jobService) Publish(id, businessID, userID int64, version int, v *validator.Validator) (*models.Job, error) {
+	j, err := s.FindByID(id, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verification.RequireVerified(businessID, userID); err != nil {
+		return nil, err
+	}
+
+	v.Check(version > 0, "version", "must be provided")
+	v.Check(j.Status != models.JobPublished, "status", "job is already published")
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	return j, s.setStatus(j, models.JobPublished, userID, version)
+}
+
+func (s *jobService) Close(id, businessID, userID int64, version int, v *validator.Validator) (*models.Job, error) {
+	j, err := s.FindByID(id, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Check(version > 0, "version", "must be provided")
+	v.Check(j.Status == models.JobPublished, "status", "only published jobs can be closed")
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	return j, s.setStatus(j, models.JobClosed, userID, version)
+}
+
+func (s *jobService) setStatus(j *models.Job, status models.JobStatus, userID int64, version int) error {
+	j.Version = version
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.job.SetStatus(j, status, userID, tx)
+	})
+}
+
+func (s *jobService) Delete(id, businessID, userID int64) error {
+	if err := s.member(businessID, userID); err != nil {
+		return err
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.job.Delete(id, businessID, userID, tx)
+	})
+}
+
+// Search lists the published jobs for candidates, with the facet counts
+// of the whole search.
+func (s *jobService) Search(
+	search models.JobSearch,
+	f filters.Filters,
+	v *validator.Validator,
+) ([]*models.Job, *models.JobFacets, filters.Metadata, error) {
+	if err := locateSearch(s.geo, &search, v); err != nil {
+		return nil, nil, filters.Metadata{}, err
+	}
+	v.Check(f.Sort != "distance" || search.Origin() != nil, "sort", "distance needs near_cep, or lat and lng")
+	if search.ValidateJobSearch(v); !v.Valid() {
+		return nil, nil, filters.Metadata{}, e.ErrInvalidData
+	}
+
+	jobs, metadata, err := s.job.Search(search, f)
+	if err != nil {
+		return nil, nil, filters.Metadata{}, err
+	}
+
+	facets, err := s.job.Facets(search)
+	if err != nil {
+		return nil, nil, filters.Metadata{}, err
+	}
+
+	return jobs, facets, metadata, nil
+}
+
+func (s *jobService) FindPublic(id int64) (*models.Job, error) {
+	return s.job.GetPublic(id)
+}
+
+// normalizeSkills links the skills asked to the catalog, like the
+// candidates' are, so both sides compare by skill. A skill listed twice
+// is kept once, required if either listing was.
+func (s *jobService) normalizeSkills(j *models.Job) error {
+	names := make([]string, len(j.Skills))
+	for i, skill := range j.Skills {
+		names[i] = skill.Name
+	}
+
+	catalog, err := s.skill.Resolve(names)
+	if err != nil {
+		return err
+	}
+
+	normalized := make([]models.JobSkill, 0, len(j.Skills))
+	seen := map[string]int{}
+	for _, skill := range j.Skills {
+		key := models.SkillKey(skill.Name)
+		skill.SkillID = nil
+		if found, ok := catalog[key]; ok {
+			skill.SkillID = &found.ID
+			skill.Name = found.Name
+			key = fmt.Sprintf("id:%d", found.ID)
+		}
+
+		if i, ok := seen[key]; ok {
+			normalized[i].Required = normalized[i].Required || skill.Required
+			if normalized[i].Level == "" {
+				normalized[i].Level = skill.Level
+			}
+			continue
+		}
+		seen[key] = len(normalized)
+		normalized = append(normalized, skill)
+	}
+
+	j.Skills = normalized
+	return nil
+}
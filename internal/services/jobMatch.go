@@ -0,0 +1,215 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/matching"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"time"
+)
+
+const (
+	matchJobsPerRun      = 5
+	matchCurriculaPerRun = 50
+	matchPageSize        = 500
+)
+
+type jobMatchService struct {
+	match      repositories.JobMatchRepositoryInterface
+	job        repositories.JobRepositoryInterface
+	curriculum repositories.CurriculumRepositoryInterface
+	business   repositories.BusinessRepositoryInterface
+	db         *sql.DB
+}
+
+type JobMatchServiceInterface interface {
+	Candidates(jobID, businessID, userID int64, mf models.MatchFilter, f filters.Filters) ([]*models.JobMatch, filters.Metadata, error)
+	JobsForUser(userID int64, mf models.MatchFilter, f filters.Filters) ([]*models.JobMatch, filters.Metadata, error)
+	Explain(jobID, userID, curriculumID int64) (*models.JobMatch, error)
+	ProcessUnmatched() error
+}
+
+func NewJobMatchService(
+	matchRepository repositories.JobMatchRepositoryInterface,
+	jobRepository repositories.JobRepositoryInterface,
+	curriculumRepository repositories.CurriculumRepositoryInterface,
+	businessRepository repositories.BusinessRepositoryInterface,
+	db *sql.DB,
+) *jobMatchService {
+	return &jobMatchService{
+		match:      matchRepository,
+		job:        jobRepository,
+		curriculum: curriculumRepository,
+		business:   businessRepository,
+		db:         db,
+	}
+}
+
+// Candidates ranks the candidates for one of the business's jobs, as last
+// computed.
+func (s *jobMatchService) Candidates(
+	jobID,
+	businessID,
+	userID int64,
+	mf models.MatchFilter,
+	f filters.Filters,
+) ([]*models.JobMatch, filters.Metadata, error) {
+	if _, err := s.business.GetByID(businessID, userID); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	if _, err := s.job.GetByBusiness(jobID, businessID); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	return s.match.GetCandidates(jobID, mf, f)
+}
+
+func (s *jobMatchService) JobsForUser(userID int64, mf models.MatchFilter, f filters.Filters) ([]*models.JobMatch, filters.Metadata, error) {
+	return s.match.GetJobsForUser(userID, mf, f)
+}
+
+// Explain scores a published job against one of the user's curricula
+// right away, the default one unless curriculumID is given. It's how a
+// candidate checks a job before the precomputed score is in, or tries
+// another curriculum.
+func (s *jobMatchService) Explain(jobID, userID, curriculumID int64) (*models.JobMatch, error) {
+	j, err := s.job.GetPublished(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var c *models.Curriculum
+	if curriculumID != 0 {
+		if c, err = s.curriculum.GetByOwner(curriculumID, userID); err != nil {
+			return nil, err
+		}
+	} else {
+		curricula, err := s.curriculum.GetAllByUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(curricula) == 0 || !curricula[0].IsDefault {
+			return nil, e.ErrRecordNotFound
+		}
+		c = curricula[0]
+	}
+
+	m := score(j, c, time.Now())
+	m.Job = j
+	return m, nil
+}
+
+func score(j *models.Job, c *models.Curriculum, now time.Time) *models.JobMatch {
+	m := &models.JobMatch{
+		JobID:        j.ID,
+		CurriculumID: c.ID,
+		UserID:       c.User.ID,
+		ComputedAt:   now,
+	}
+	m.Score, m.Breakdown = matching.Score(j, c, now)
+	return m
+}
+
+// ProcessUnmatched brings the precomputed matches up to date: jobs
+// published or edited since they were last matched are scored against
+// every default curriculum, and curricula changed or made default against
+// every published job. Matches no longer relevant are dropped first.
+func (s *jobMatchService) ProcessUnmatched() error {
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.match.Prune(tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		jobs, err := s.job.ClaimUnmatched(matchJobsPerRun, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, j := range jobs {
+			if err := s.matchJob(j, tx); err != nil {
+				errs = append(errs, fmt.Errorf("job %d: %w", j.ID, err))
+				continue
+			}
+			if err := s.job.MarkMatched(j.ID, j.Version, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	errs = append(errs, err)
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		curricula, err := s.curriculum.ClaimUnmatched(matchCurriculaPerRun, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range curricula {
+			if err := s.matchCurriculum(c, tx); err != nil {
+				errs = append(errs, fmt.Errorf("curriculum %d: %w", c.ID, err))
+				continue
+			}
+			if err := s.curriculum.MarkMatched(c.ID, c.Version, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return errors.Join(append(errs, err)...)
+}
+
+func (s *jobMatchService) matchJob(j *models.Job, tx *sql.Tx) error {
+	now := time.Now()
+	var afterID int64
+	for {
+		curricula, err := s.curriculum.GetDefaultsAfter(afterID, matchPageSize)
+		if err != nil {
+			return err
+		}
+		if len(curricula) == 0 {
+			return nil
+		}
+
+		matches := make([]*models.JobMatch, len(curricula))
+		for i, c := range curricula {
+			matches[i] = score(j, c, now)
+		}
+		if err := s.match.Upsert(matches, tx); err != nil {
+			return err
+		}
+		afterID = curricula[len(curricula)-1].ID
+	}
+}
+
+func (s *jobMatchService) matchCurriculum(c *models.Curriculum, tx *sql.Tx) error {
+	now := time.Now()
+	var afterID int64
+	for {
+		jobs, err := s.job.GetPublishedAfter(afterID, matchPageSize)
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		matches := make([]*models.JobMatch, len(jobs))
+		for i, j := range jobs {
+			matches[i] = score(j, c, now)
+		}
+		if err := s.match.Upsert(matches, tx); err != nil {
+			return err
+		}
+		afterID = jobs[len(jobs)-1].ID
+	}
+}
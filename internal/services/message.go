@@ -0,0 +1,331 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// messageQuietPeriod is how long a thread must go without new messages
+	// before the other side is emailed about the unread ones, so a burst of
+	// messages makes a single email.
+	messageQuietPeriod  = 10 * time.Minute
+	messageNoticeBatch  = 50
+	messageExcerptBytes = 500
+)
+
+type messageService struct {
+	message      repositories.MessageRepositoryInterface
+	application  repositories.ApplicationRepositoryInterface
+	business     repositories.BusinessRepositoryInterface
+	file         FileServiceInterface
+	email        EmailServiceInterface
+	notification NotificationServiceInterface
+	publicURL    string
+	db           *sql.DB
+}
+
+type MessageServiceInterface interface {
+	FindAllByApplication(applicationID, userID int64, f filters.Filters) ([]*models.Message, filters.Metadata, error)
+	FindAllForBusiness(applicationID, businessID, userID int64, f filters.Filters) ([]*models.Message, filters.Metadata, error)
+	SendAsCandidate(m *models.Message, applicationID, userID int64, v *validator.Validator) error
+	SendAsBusiness(m *models.Message, applicationID, businessID, userID int64, v *validator.Validator) error
+	MarkReadByCandidate(applicationID, userID int64) (int64, error)
+	MarkReadByBusiness(applicationID, businessID, userID int64) (int64, error)
+	Unread(userID int64) ([]*models.UnreadThread, error)
+	NotifyUnread() error
+}
+
+func NewMessageService(
+	messageRepository repositories.MessageRepositoryInterface,
+	applicationRepository repositories.ApplicationRepositoryInterface,
+	businessRepository repositories.BusinessRepositoryInterface,
+	fileService FileServiceInterface,
+	emailService EmailServiceInterface,
+	notificationService NotificationServiceInterface,
+	publicURL string,
+	db *sql.DB,
+) *messageService {
+	return &messageService{
+		message:      messageRepository,
+		application:  applicationRepository,
+		business:     businessRepository,
+		file:         fileService,
+		email:        emailService,
+		notification: notificationService,
+		publicURL:    strings.TrimRight(publicURL, "/"),
+		db:           db,
+	}
+}
+
+// forBusiness loads the application for a member of its business; both
+// missing and foreign applications are reported as not found.
+func (s *messageService) forBusiness(applicationID, businessID, userID int64) (*models.Application, error) {
+	if _, err := s.business.GetByID(businessID, userID); err != nil {
+		return nil, err
+	}
+	return s.application.GetByBusiness(applicationID, businessID)
+}
+
+func (s *messageService) FindAllByApplication(
+	applicationID,
+	userID int64,
+	f filters.Filters,
+) ([]*models.Message, filters.Metadata, error) {
+	if _, err := s.application.GetByUser(applicationID, userID); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	return s.list(applicationID, f)
+}
+
+func (s *messageService) FindAllForBusiness(
+	applicationID,
+	businessID,
+	userID int64,
+	f filters.Filters,
+) ([]*models.Message, filters.Metadata, error) {
+	if _, err := s.forBusiness(applicationID, businessID, userID); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	return s.list(applicationID, f)
+}
+
+func (s *messageService) list(applicationID int64, f filters.Filters) ([]*models.Message, filters.Metadata, error) {
+	messages, metadata, err := s.message.GetAllByApplication(applicationID, f)
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+
+	ids := make([]int64, len(messages))
+	byID := make(map[int64]*models.Message, len(messages))
+	for i, m := range messages {
+		ids[i] = m.ID
+		byID[m.ID] = m
+	}
+
+	files, err := s.file.FindAllByRef(models.RefMessage, ids)
+	if err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	for _, file := range files {
+		if m, ok := byID[*file.RefID]; ok {
+			m.Attachments = append(m.Attachments, file)
+		}
+	}
+
+	return messages, metadata, nil
+}
+
+func (s *messageService) SendAsCandidate(m *models.Message, applicationID, userID int64, v *validator.Validator) error {
+	a, err := s.application.GetByUser(applicationID, userID)
+	if err != nil {
+		return err
+	}
+
+	m.FromCandidate = true
+	return s.send(m, a, userID, v)
+}
+
+func (s *messageService) SendAsBusiness(
+	m *models.Message,
+	applicationID,
+	businessID,
+	userID int64,
+	v *validator.Validator,
+) error {
+	a, err := s.forBusiness(applicationID, businessID, userID)
+	if err != nil {
+		return err
+	}
+
+	m.FromCandidate = false
+	return s.send(m, a, userID, v)
+}
+
+// send posts the message with the files the sender uploaded for it, and
+// notifies the other side in the app; the email waits for the quiet
+// period. Withdrawn applications take no more messages; the other closed
+// ones do, to follow up on an offer or a rejection.
+func (s *messageService) send(m *models.Message, a *models.Application, userID int64, v *validator.Validator) error {
+	v.Check(a.Stage != models.StageWithdrawn, "application_id", "the application was withdrawn")
+	if m.ValidateMessage(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	for n, id := range m.FileIDs {
+		key := fmt.Sprintf("file_ids[%d]", n)
+		file, err := s.file.FindByID(id, userID)
+		if err != nil {
+			if errors.Is(err, e.ErrRecordNotFound) {
+				v.AddError(key, "must be a file you uploaded")
+				continue
+			}
+			return err
+		}
+
+		v.Check(file.Kind == models.FileMessageAttachment, key, "must be uploaded as "+string(models.FileMessageAttachment))
+		v.Check(file.RefType == nil, key, "is already attached to something else")
+		m.Attachments = append(m.Attachments, file)
+	}
+	if !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	m.ApplicationID = a.ID
+	m.Sender.UserID = userID
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		if err := s.message.Insert(m, tx); err != nil {
+			return err
+		}
+		if err := s.file.Attach(m.FileIDs, userID, models.RefMessage, m.ID, tx); err != nil {
+			return err
+		}
+
+		n := &models.Notification{
+			Type:  models.NotifyMessageReceived,
+			Title: "Nova mensagem: " + a.JobTitle,
+			Data: map[string]int64{
+				"application_id": a.ID,
+				"business_id":    a.BusinessID,
+				"message_id":     m.ID,
+			},
+		}
+		if m.FromCandidate {
+			n.Body = fmt.Sprintf("O candidato enviou uma mensagem sobre a candidatura à vaga \"%s\".", a.JobTitle)
+			return s.notification.NotifyMembers(n, a.BusinessID, 0, tx)
+		}
+		n.UserID = a.UserID
+		n.Body = fmt.Sprintf("%s enviou uma mensagem sobre a sua candidatura à vaga \"%s\".", a.BusinessName, a.JobTitle)
+		return s.notification.Notify(n, tx)
+	})
+}
+
+// MarkReadByCandidate marks the business's messages as read, and reports
+// how many were unread.
+func (s *messageService) MarkReadByCandidate(applicationID, userID int64) (int64, error) {
+	if _, err := s.application.GetByUser(applicationID, userID); err != nil {
+		return 0, err
+	}
+	return s.markRead(applicationID, true, userID)
+}
+
+// MarkReadByBusiness marks the candidate's messages as read on behalf of
+// the whole business.
+func (s *messageService) MarkReadByBusiness(applicationID, businessID, userID int64) (int64, error) {
+	if _, err := s.forBusiness(applicationID, businessID, userID); err != nil {
+		return 0, err
+	}
+	return s.markRead(applicationID, false, userID)
+}
+
+func (s *messageService) markRead(applicationID int64, asCandidate bool, userID int64) (int64, error) {
+	var read int64
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		var err error
+		read, err = s.message.MarkRead(applicationID, !asCandidate, userID, tx)
+		return err
+	})
+	return read, err
+}
+
+func (s *messageService) Unread(userID int64) ([]*models.UnreadThread, error) {
+	return s.message.GetUnread(userID)
+}
+
+// NotifyUnread emails the other side of the threads whose messages went
+// unread through the quiet period. The candidate is emailed about the
+// business's messages, and every member of the business about the
+// candidate's.
+func (s *messageService) NotifyUnread() error {
+	var errs []error
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		notices, err := s.message.ClaimUnnotified(time.Now().Add(-messageQuietPeriod), messageNoticeBatch, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, n := range notices {
+			if err := s.notify(n, tx); err != nil {
+				errs = append(errs, fmt.Errorf("application %d: %w", n.ApplicationID, err))
+			}
+		}
+		return nil
+	})
+
+	return errors.Join(append(errs, err)...)
+}
+
+func (s *messageService) notify(n *models.MessageNotice, tx *sql.Tx) error {
+	a, err := s.application.GetByID(n.ApplicationID)
+	if err != nil {
+		return err
+	}
+
+	f := filters.Filters{Page: 1, PageSize: 1, Sort: "-created_at", SortSafelist: []string{"-created_at"}}
+	last, _, err := s.message.GetAllByApplication(a.ID, f)
+	if err != nil {
+		return err
+	}
+
+	count := "1 nova mensagem"
+	if n.Count > 1 {
+		count = fmt.Sprintf("%d novas mensagens", n.Count)
+	}
+
+	from, recipients := a.BusinessName, []models.Participant{a.Candidate}
+	link := fmt.Sprintf("%s/v1/applications/%d/messages", s.publicURL, a.ID)
+	if n.FromCandidate {
+		from = a.Candidate.Name
+		link = fmt.Sprintf("%s/v1/business/%d/applications/%d/messages", s.publicURL, a.BusinessID, a.ID)
+		if recipients, err = s.business.GetMembers(a.BusinessID); err != nil {
+			return err
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s enviou %s sobre a candidatura à vaga \"%s\".\n\n", from, count, a.JobTitle)
+	if len(last) > 0 && last[0].FromCandidate == n.FromCandidate && last[0].Body != "" {
+		fmt.Fprintf(&b, "%s\n\n", excerpt(last[0].Body, messageExcerptBytes))
+	}
+	fmt.Fprintf(&b, "Para ler e responder, acesse:\n%s\n", link)
+
+	for _, to := range recipients {
+		name, _, _ := strings.Cut(strings.TrimSpace(to.Name), " ")
+		err := s.email.Enqueue(&models.Email{
+			UserID:  &to.UserID,
+			To:      to.Email,
+			Subject: fmt.Sprintf("%s: %s", strings.ToUpper(count[:1])+count[1:], a.JobTitle),
+			Body:    fmt.Sprintf("Olá, %s!\n\n%s", name, b.String()),
+		}, tx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// excerpt cuts the text at a space before max bytes.
+func excerpt(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+
+	cut := strings.LastIndex(text[:max], " ")
+	if cut <= 0 {
+		cut = max
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+	}
+	return strings.TrimSpace(text[:cut]) + "..."
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"database/sql"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/pubsub"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+)
+
+type notificationService struct {
+	notification repositories.NotificationRepositoryInterface
+	hub          *pubsub.Hub
+	db           *sql.DB
+}
+
+type NotificationServiceInterface interface {
+	FindAll(userID int64, unreadOnly bool, f filters.Filters) ([]*models.Notification, filters.Metadata, error)
+	FindAfter(userID, afterID int64, limit int) ([]*models.Notification, error)
+	LatestID(userID int64) (int64, error)
+	UnreadCount(userID int64) (int, error)
+	MarkRead(id, userID int64) (*models.Notification, error)
+	MarkAllRead(userID int64) (int64, error)
+	Preferences(userID int64) (models.NotificationPreferences, error)
+	SetPreferences(userID int64, prefs models.NotificationPreferences, v *validator.Validator) (models.NotificationPreferences, error)
+	Notify(n *models.Notification, tx *sql.Tx) error
+	NotifyMembers(n *models.Notification, businessID, exceptUserID int64, tx *sql.Tx) error
+	Subscribe(userID int64) (<-chan struct{}, func())
+	Close()
+}
+
+func NewNotificationService(
+	notificationRepository repositories.NotificationRepositoryInterface,
+	hub *pubsub.Hub,
+	db *sql.DB,
+) *notificationService {
+	return &notificationService{
+		notification: notificationRepository,
+		hub:          hub,
+		db:           db,
+	}
+}
+
+func (s *notificationService) FindAll(
+	userID int64,
+	unreadOnly bool,
+	f filters.Filters,
+) ([]*models.Notification, filters.Metadata, error) {
+	return s.notification.GetForUser(userID, unreadOnly, f)
+}
+
+// FindAfter is what a stream missed since afterID, oldest first.
+func (s *notificationService) FindAfter(userID, afterID int64, limit int) ([]*models.Notification, error) {
+	return s.notification.GetAfter(userID, afterID, limit)
+}
+
+func (s *notificationService) LatestID(userID int64) (int64, error) {
+	return s.notification.GetLatestID(userID)
+}
+
+func (s *notificationService) UnreadCount(userID int64) (int, error) {
+	return s.notification.CountUnread(userID)
+}
+
+func (s *notificationService) MarkRead(id, userID int64) (*models.Notification, error) {
+	var n *models.Notification
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		var err error
+		n, err = s.notification.MarkRead(id, userID, tx)
+		return err
+	})
+	return n, err
+}
+
+func (s *notificationService) MarkAllRead(userID int64) (int64, error) {
+	var read int64
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		var err error
+		read, err = s.notification.MarkAllRead(userID, tx)
+		return err
+	})
+	return read, err
+}
+
+// Preferences has every type, enabled unless the user turned it off.
+func (s *notificationService) Preferences(userID int64) (models.NotificationPreferences, error) {
+	changed, err := s.notification.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := make(models.NotificationPreferences, len(models.NotificationTypes))
+	for _, t := range models.NotificationTypes {
+		enabled, ok := changed[models.NotificationType(t)]
+		prefs[models.NotificationType(t)] = enabled || !ok
+	}
+	return prefs, nil
+}
+
+// SetPreferences changes the types given and reports them all. Turning a
+// type off stops new notifications of it; those already sent stay.
+func (s *notificationService) SetPreferences(
+	userID int64,
+	prefs models.NotificationPreferences,
+	v *validator.Validator,
+) (models.NotificationPreferences, error) {
+	if models.ValidatePreferences(v, prefs); !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.notification.SetPreferences(userID, prefs, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Preferences(userID)
+}
+
+// Notify creates the notification along with what it's about, in tx. The
+// streams of the user are told once tx commits.
+func (s *notificationService) Notify(n *models.Notification, tx *sql.Tx) error {
+	if n.Data == nil {
+		n.Data = map[string]int64{}
+	}
+	return s.notification.Insert(n, tx)
+}
+
+// NotifyMembers notifies the members of the business but exceptUserID, the
+// member who caused it, if any.
+func (s *notificationService) NotifyMembers(n *models.Notification, businessID, exceptUserID int64, tx *sql.Tx) error {
+	if n.Data == nil {
+		n.Data = map[string]int64{}
+	}
+	return s.notification.InsertForMembers(n, businessID, exceptUserID, tx)
+}
+
+func (s *notificationService) Subscribe(userID int64) (<-chan struct{}, func()) {
+	return s.hub.Subscribe(userID)
+}
+
+// Close ends the streams open on this instance, which is shutting down.
+func (s *notificationService) Close() {
+	s.hub.Close()
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"meu_job/internal/geo"
+	"meu_job/internal/models"
+	"meu_job/utils/validator"
+)
+
+// locate geocodes a place the user typed. A CEP has to be found: the city
+// and state follow from it, and so do the street and district when left
+// blank. Without one the city is located, if the dataset knows it at all.
+func locate(resolver geo.CEPResolver, p *models.Place, v *validator.Validator) error {
+	if p.CEP == "" {
+		return geocode(resolver, p)
+	}
+
+	p.Coordinates = nil
+	// A malformed CEP is reported by the validation.
+	if !validator.Matches(p.CEP, models.CEPRX) {
+		return nil
+	}
+
+	found, err := resolver.Resolve(p.CEP)
+	switch {
+	case errors.Is(err, geo.ErrNotFound):
+		v.AddError("cep", "was not found")
+		return nil
+	case err != nil:
+		return err
+	}
+
+	if p.Street == "" {
+		p.Street = found.Street
+	}
+	if p.District == "" {
+		p.District = found.District
+	}
+	p.City, p.UF, p.Coordinates = found.City, found.UF, found.Coordinates
+	return nil
+}
+
+// geocode only finds the coordinates of a place taken as it is, such as a
+// business's address from the CNPJ registry. A CEP that isn't found falls
+// back to the city.
+func geocode(resolver geo.CEPResolver, p *models.Place) error {
+	p.Coordinates = nil
+
+	if p.CEP != "" {
+		found, err := resolver.Resolve(models.NormalizeCEP(p.CEP))
+		switch {
+		case err == nil:
+			p.Coordinates = found.Coordinates
+			return nil
+		case !errors.Is(err, geo.ErrNotFound):
+			return err
+		}
+	}
+
+	if p.City == "" || p.UF == "" {
+		return nil
+	}
+
+	coordinates, err := resolver.Locate(p.City, p.UF)
+	switch {
+	case errors.Is(err, geo.ErrNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+	p.Coordinates = coordinates
+	return nil
+}
+
+// locateSearch resolves the near_cep of a job search to the point it's
+// searched around.
+func locateSearch(resolver geo.CEPResolver, s *models.JobSearch, v *validator.Validator) error {
+	if s.NearCEP == "" || !validator.Matches(s.NearCEP, models.CEPRX) {
+		return nil
+	}
+
+	found, err := resolver.Resolve(s.NearCEP)
+	switch {
+	case errors.Is(err, geo.ErrNotFound):
+		v.AddError("near_cep", "was not found")
+		return nil
+	case err != nil:
+		return err
+	}
+
+	s.Latitude = &found.Coordinates.Latitude
+	s.Longitude = &found.Coordinates.Longitude
+	return nil
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"meu_job/internal/models"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"time"
+)
+
+const (
+	erasureGracePeriod = 30 * 24 * time.Hour
+	erasureBatchSize   = 100
+)
+
+type privacyService struct {
+	user         repositories.UserRepositoryInterface
+	business     repositories.BusinessRepositoryInterface
+	session      repositories.SessionRepositoryInterface
+	apiKey       repositories.APIKeyRepositoryInterface
+	consent      repositories.ConsentRepositoryInterface
+	curriculum   repositories.CurriculumRepositoryInterface
+	draft        repositories.CurriculumDraftRepositoryInterface
+	jobMatch     repositories.JobMatchRepositoryInterface
+	saved        repositories.SavedSearchRepositoryInterface
+	email        repositories.EmailRepositoryInterface
+	application  repositories.ApplicationRepositoryInterface
+	interview    repositories.InterviewRepositoryInterface
+	message      repositories.MessageRepositoryInterface
+	notification repositories.NotificationRepositoryInterface
+	delivery     repositories.WebhookDeliveryRepositoryInterface
+	audit        repositories.AuditRepositoryInterface
+	file         FileServiceInterface
+	db           *sql.DB
+}
+
+type PrivacyServiceInterface interface {
+	Export(user *models.User) (*models.DataExport, error)
+	RequestErasure(user *models.User, password string, v *validator.Validator) error
+	CancelErasure(user *models.User) error
+	EraseDue() error
+}
+
+func NewPrivacyService(
+	userRepository repositories.UserRepositoryInterface,
+	businessRepository repositories.BusinessRepositoryInterface,
+	sessionRepository repositories.SessionRepositoryInterface,
+	apiKeyRepository repositories.APIKeyRepositoryInterface,
+	consentRepository repositories.ConsentRepositoryInterface,
+	curriculumRepository repositories.CurriculumRepositoryInterface,
+	draftRepository repositories.CurriculumDraftRepositoryInterface,
+	jobMatchRepository repositories.JobMatchRepositoryInterface,
+	savedSearchRepository repositories.SavedSearchRepositoryInterface,
+	emailRepository repositories.EmailRepositoryInterface,
+	applicationRepository repositories.ApplicationRepositoryInterface,
+	interviewRepository repositories.InterviewRepositoryInterface,
+	messageRepository repositories.MessageRepositoryInterface,
+	notificationRepository repositories.NotificationRepositoryInterface,
+	deliveryRepository repositories.WebhookDeliveryRepositoryInterface,
+	auditRepository repositories.AuditRepositoryInterface,
+	fileService FileServiceInterface,
+	db *sql.DB,
+) *privacyService {
+	return &privacyService{
+		user:         userRepository,
+		business:     businessRepository,
+		session:      sessionRepository,
+		apiKey:       apiKeyRepository,
+		consent:      consentRepository,
+		curriculum:   curriculumRepository,
+		draft:        draftRepository,
+		jobMatch:     jobMatchRepository,
+		saved:        savedSearchRepository,
+		email:        emailRepository,
+		application:  applicationRepository,
+		interview:    interviewRepository,
+		message:      messageRepository,
+		notification: notificationRepository,
+		delivery:     deliveryRepository,
+		audit:        auditRepository,
+		file:         fileService,
+		db:           db,
+	}
+}
+
+func (s *privacyService) Export(user *models.User) (*models.DataExport, error) {
+	export := &models.DataExport{
+		GeneratedAt: time.Now().UTC(),
+		Profile:     user.ToProfileExport(),
+	}
+
+	businessList, err := s.business.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.Businesses = make([]*models.BusinessDTO, len(businessList))
+	for i, business := range businessList {
+		export.Businesses[i] = business.ToDTO()
+	}
+
+	sessions, err := s.session.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.Sessions = make([]*models.SessionDTO, len(sessions))
+	for i, session := range sessions {
+		export.Sessions[i] = session.ToDTO()
+	}
+
+	keys, err := s.apiKey.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.APIKeys = make([]*models.APIKeyDTO, len(keys))
+	for i, key := range keys {
+		export.APIKeys[i] = key.ToDTO()
+	}
+
+	consents, err := s.consent.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.Consents = make([]*models.ConsentDTO, len(consents))
+	for i, consent := range consents {
+		export.Consents[i] = consent.ToDTO()
+	}
+
+	files, err := s.file.FindAll(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.Files = make([]*models.FileDTO, len(files))
+	for i, file := range files {
+		if export.Files[i], err = s.file.SignedURL(file); err != nil {
+			return nil, err
+		}
+	}
+
+	curricula, err := s.curriculum.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.Curricula = make([]*models.CurriculumDTO, len(curricula))
+	export.CurriculumRevisions = []*models.CurriculumRevisionDTO{}
+	for i, c := range curricula {
+		export.Curricula[i] = c.ToDTO()
+
+		revisions, err := s.curriculum.GetRevisions(c.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rev := range revisions {
+			export.CurriculumRevisions = append(export.CurriculumRevisions, rev.ToDTO(true))
+		}
+	}
+
+	drafts, err := s.draft.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.CurriculumDrafts = make([]*models.CurriculumDraftDTO, len(drafts))
+	for i, d := range drafts {
+		export.CurriculumDrafts[i] = d.ToDTO()
+	}
+
+	matches, err := s.jobMatch.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.JobMatches = make([]*models.JobMatchDTO, len(matches))
+	for i, m := range matches {
+		export.JobMatches[i] = m.ToDTO()
+	}
+
+	searches, err := s.saved.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.SavedSearches = make([]*models.SavedSearchDTO, len(searches))
+	for i, search := range searches {
+		export.SavedSearches[i] = search.ToDTO()
+	}
+
+	applications, err := s.application.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.Applications = make([]*models.ApplicationDTO, len(applications))
+	export.Interviews = []*models.InterviewDTO{}
+	for i, a := range applications {
+		export.Applications[i] = a.ToDTO()
+
+		interviews, err := s.interview.GetAllByApplication(a.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, interview := range interviews {
+			export.Interviews = append(export.Interviews, interview.ToDTO())
+		}
+	}
+
+	messages, err := s.message.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.Messages = make([]*models.MessageDTO, len(messages))
+	for i, m := range messages {
+		export.Messages[i] = m.ToDTO()
+	}
+
+	notifications, err := s.notification.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.Notifications = make([]*models.NotificationDTO, len(notifications))
+	for i, n := range notifications {
+		export.Notifications[i] = n.ToDTO()
+	}
+
+	entries, err := s.audit.GetAllByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.AuditEntries = make([]*models.AuditEntryDTO, len(entries))
+	for i, entry := range entries {
+		export.AuditEntries[i] = entry.ToDTO()
+	}
+
+	return export, nil
+}
+
+func (s *privacyService) RequestErasure(user *models.User, password string, v *validator.Validator) error {
+	match, err := user.Password.Matches(password)
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		v.AddError("password", "is incorrect")
+		return e.ErrInvalidData
+	}
+
+	at := time.Now().Add(erasureGracePeriod)
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.user.ScheduleErasure(tx, user.ID, &at)
+	})
+	if err != nil {
+		return err
+	}
+	s.user.Forget(user.ID)
+
+	user.ErasureScheduledFor = &at
+	return nil
+}
+
+func (s *privacyService) CancelErasure(user *models.User) error {
+	if user.ErasureScheduledFor == nil {
+		return e.ErrRecordNotFound
+	}
+
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.user.ScheduleErasure(tx, user.ID, nil)
+	})
+	if err != nil {
+		return err
+	}
+	s.user.Forget(user.ID)
+
+	user.ErasureScheduledFor = nil
+	return nil
+}
+
+// EraseDue anonymizes every account whose grace period has ended. Each user is
+// erased in its own transaction so one failure doesn't block the rest.
+// Curricula, their drafts, résumés, job matches, saved searches, queued emails, notifications and the
+// webhook deliveries about the user's applications go with the account, and so do the files the user
+// attached to messages. The applications, their interviews and messages are the businesses' hiring
+// history and stay, with the cover letters, stage reasons and the user's messages blanked and the
+// attachments detached; so do logos and verification documents.
+func (s *privacyService) EraseDue() error {
+	ids, err := s.user.GetDueForErasure(erasureBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, id := range ids {
+		err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+			if err := s.user.Anonymize(tx, id); err != nil {
+				return err
+			}
+
+			if _, err := s.session.RevokeAllExcept(id, 0, tx); err != nil {
+				return err
+			}
+
+			if err := s.apiKey.RevokeAllByUser(id, tx); err != nil {
+				return err
+			}
+
+			if err := s.saved.EraseAllByUser(id, tx); err != nil {
+				return err
+			}
+
+			if err := s.email.EraseAllByUser(id, tx); err != nil {
+				return err
+			}
+
+			if err := s.notification.EraseAllByUser(id, tx); err != nil {
+				return err
+			}
+
+			if err := s.delivery.EraseAllByCandidate(id, tx); err != nil {
+				return err
+			}
+
+			if err := s.application.EraseAllByUser(id, tx); err != nil {
+				return err
+			}
+
+			if err := s.message.EraseAllBySender(id, tx); err != nil {
+				return err
+			}
+
+			if err := s.file.DetachAll(id, models.RefMessage, tx); err != nil {
+				return err
+			}
+
+			// Drafts point at the curricula confirmed from them.
+			if err := s.draft.EraseAllByUser(id, tx); err != nil {
+				return err
+			}
+
+			return s.curriculum.EraseAllByUser(id, tx)
+		})
+		s.user.Forget(id)
+		// The files only go once nothing points at them any more; a user
+		// whose erasure failed is taken again on the next run.
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, s.file.DeleteAll(id, models.FileResume))
+		errs = append(errs, s.file.DeleteAll(id, models.FileMessageAttachment))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"meu_job/internal/geo"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	savedSearchesPerUser = 20
+	alertBatchSize       = 50
+	// alertJobsListed is how many new jobs a digest lists; the rest are
+	// only counted.
+	alertJobsListed = 20
+)
+
+type savedSearchService struct {
+	savedSearch repositories.SavedSearchRepositoryInterface
+	job         repositories.JobRepositoryInterface
+	user        repositories.UserRepositoryInterface
+	email       EmailServiceInterface
+	geo         geo.CEPResolver
+	secret      []byte
+	publicURL   string
+	db          *sql.DB
+}
+
+type SavedSearchServiceInterface interface {
+	FindAll(userID int64) ([]*models.SavedSearch, error)
+	FindByID(id, userID int64) (*models.SavedSearch, error)
+	Save(s *models.SavedSearch, userID int64, v *validator.Validator) error
+	Update(s *models.SavedSearch, userID int64, v *validator.Validator) error
+	Delete(id, userID int64) error
+	CheckUnsubscribe(token string) error
+	Unsubscribe(token string) (*models.SavedSearch, error)
+	SendDue() error
+}
+
+func NewSavedSearchService(
+	savedSearchRepository repositories.SavedSearchRepositoryInterface,
+	jobRepository repositories.JobRepositoryInterface,
+	userRepository repositories.UserRepositoryInterface,
+	emailService EmailServiceInterface,
+	cepResolver geo.CEPResolver,
+	secret string,
+	publicURL string,
+	db *sql.DB,
+) *savedSearchService {
+	return &savedSearchService{
+		savedSearch: savedSearchRepository,
+		job:         jobRepository,
+		user:        userRepository,
+		email:       emailService,
+		geo:         cepResolver,
+		secret:      []byte(secret),
+		publicURL:   strings.TrimRight(publicURL, "/"),
+		db:          db,
+	}
+}
+
+func (s *savedSearchService) FindAll(userID int64) ([]*models.SavedSearch, error) {
+	return s.savedSearch.GetAllByUser(userID)
+}
+
+func (s *savedSearchService) FindByID(id, userID int64) (*models.SavedSearch, error) {
+	return s.savedSearch.GetByID(id, userID)
+}
+
+func (s *savedSearchService) Save(search *models.SavedSearch, userID int64, v *validator.Validator) error {
+	searches, err := s.savedSearch.GetAllByUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := locateSearch(s.geo, &search.Criteria, v); err != nil {
+		return err
+	}
+	v.Check(len(searches) < savedSearchesPerUser, "name", fmt.Sprintf("no more than %d searches may be saved", savedSearchesPerUser))
+	if search.ValidateSavedSearch(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.savedSearch.Insert(search, userID, tx)
+	})
+}
+
+func (s *savedSearchService) Update(search *models.SavedSearch, userID int64, v *validator.Validator) error {
+	if _, err := s.savedSearch.GetByID(search.ID, userID); err != nil {
+		return err
+	}
+
+	if err := locateSearch(s.geo, &search.Criteria, v); err != nil {
+		return err
+	}
+	v.Check(search.Version > 0, "version", "must be provided")
+	if search.ValidateSavedSearch(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.savedSearch.Update(search, userID, tx)
+	})
+}
+
+func (s *savedSearchService) Delete(id, userID int64) error {
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.savedSearch.Delete(id, userID, tx)
+	})
+}
+
+// unsubscribeToken identifies the search in the links of its digests. It
+// is signed rather than stored, so it never expires; it only ever turns
+// the alerts off.
+func (s *savedSearchService) unsubscribeToken(id int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "saved-search-unsubscribe\n%d", id)
+	return strconv.FormatInt(id, 10) + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *savedSearchService) unsubscribeURL(id int64) string {
+	return s.publicURL + "/v1/saved-searches/unsubscribe?token=" + url.QueryEscape(s.unsubscribeToken(id))
+}
+
+// unsubscribeSearch is the search the token was issued for. Tokens that
+// don't check out are reported as not found.
+func (s *savedSearchService) unsubscribeSearch(token string) (int64, error) {
+	raw, _, _ := strings.Cut(token, ".")
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || !hmac.Equal([]byte(token), []byte(s.unsubscribeToken(id))) {
+		return 0, e.ErrRecordNotFound
+	}
+	return id, nil
+}
+
+// CheckUnsubscribe only tells whether the token is good, for the page
+// asking to confirm; nothing changes.
+func (s *savedSearchService) CheckUnsubscribe(token string) error {
+	_, err := s.unsubscribeSearch(token)
+	return err
+}
+
+// Unsubscribe turns off the alerts of the search the token was issued for.
+func (s *savedSearchService) Unsubscribe(token string) (*models.SavedSearch, error) {
+	id, err := s.unsubscribeSearch(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var search *models.SavedSearch
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		search, err = s.savedSearch.DisableAlerts(id, tx)
+		return err
+	})
+	return search, err
+}
+
+// SendDue runs the job alerts that are due: each looks for the jobs
+// published since it last ran and, when there are any, queues a digest to
+// its owner.
+func (s *savedSearchService) SendDue() error {
+	var errs []error
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		searches, err := s.savedSearch.ClaimDue(alertBatchSize, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, search := range searches {
+			if err := s.alert(search, tx); err != nil {
+				errs = append(errs, fmt.Errorf("saved search %d: %w", search.ID, err))
+				continue
+			}
+			if err := s.savedSearch.MarkRun(search, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return errors.Join(append(errs, err)...)
+}
+
+func (s *savedSearchService) alert(search *models.SavedSearch, tx *sql.Tx) error {
+	now := time.Now()
+	criteria := search.Criteria
+	criteria.PublishedAfter = &search.CheckedUntil
+	criteria.PublishedUntil = &now
+
+	f := filters.Filters{
+		Page:         1,
+		PageSize:     alertJobsListed,
+		Sort:         "-published_at",
+		SortSafelist: []string{"-published_at"},
+	}
+	jobs, metadata, err := s.job.Search(criteria, f)
+	if err != nil {
+		return err
+	}
+
+	search.CheckedUntil = now
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	user, err := s.user.GetByID(search.UserID)
+	if err != nil {
+		return err
+	}
+	// Accounts not yet activated haven't confirmed the address.
+	if !user.Activated {
+		return nil
+	}
+
+	unsubscribe := s.unsubscribeURL(search.ID)
+	return s.email.Enqueue(&models.Email{
+		UserID:  &user.ID,
+		To:      user.Email,
+		Subject: fmt.Sprintf("Novas vagas para \"%s\"", search.Name),
+		Body:    s.digest(user, search, jobs, metadata.TotalRecords, unsubscribe),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribe + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, tx)
+}
+
+func (s *savedSearchService) digest(
+	user *models.User,
+	search *models.SavedSearch,
+	jobs []*models.Job,
+	total int,
+	unsubscribe string,
+) string {
+	var b strings.Builder
+	name, _, _ := strings.Cut(strings.TrimSpace(user.Name), " ")
+	fmt.Fprintf(&b, "Olá, %s!\n\n", name)
+	if total == 1 {
+		fmt.Fprintf(&b, "Encontramos 1 vaga nova para a sua busca \"%s\":\n\n", search.Name)
+	} else {
+		fmt.Fprintf(&b, "Encontramos %d vagas novas para a sua busca \"%s\":\n\n", total, search.Name)
+	}
+
+	for _, j := range jobs {
+		place := "Remoto"
+		if j.RemoteMode != models.RemoteFull {
+			place = strings.TrimPrefix(j.City+"/"+j.UF, "/")
+		}
+		fmt.Fprintf(&b, "- %s, %s (%s)\n  %s/v1/jobs/%d\n", j.Title, j.Company.Name, place, s.publicURL, j.ID)
+	}
+	if rest := total - len(jobs); rest > 0 {
+		fmt.Fprintf(&b, "\n... e mais %d.\n", rest)
+	}
+
+	fmt.Fprintf(&b, "\nPara não receber mais os alertas desta busca, acesse:\n%s\n", unsubscribe)
+	return b.String()
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+	e "meu_job/utils/errors"
+	"testing"
+)
+
+func TestUnsubscribeToken(t *testing.T) {
+	s := NewSavedSearchService(nil, nil, nil, nil, nil, "segredo", "https://meujob.com.br/", nil)
+
+	token := s.unsubscribeToken(42)
+	// HMAC-SHA256 of "saved-search-unsubscribe\n42" keyed with "segredo".
+	want := "42.5259c9fb8a568ae7daeaa855080bbf1f6c35d3f3b2dcfcde56eb181985f85ec3"
+	if token != want {
+		t.Fatalf("unsubscribeToken(42) = %q, want %q", token, want)
+	}
+
+	if got := s.unsubscribeURL(42); got != "https://meujob.com.br/v1/saved-searches/unsubscribe?token="+want {
+		t.Errorf("unsubscribeURL(42) = %q", got)
+	}
+
+	if id, err := s.unsubscribeSearch(token); err != nil || id != 42 {
+		t.Errorf("unsubscribeSearch(%q) = %d, %v, want 42", token, id, err)
+	}
+
+	other := NewSavedSearchService(nil, nil, nil, nil, nil, "outro segredo", "", nil)
+	invalid := []string{
+		"",
+		"42",
+		"42.",
+		"43" + want[2:],
+		want[:len(want)-1] + "4",
+		want + "00",
+		"x" + want,
+		"-42" + want[2:],
+		other.unsubscribeToken(42),
+	}
+	for _, token := range invalid {
+		if err := s.CheckUnsubscribe(token); !errors.Is(err, e.ErrRecordNotFound) {
+			t.Errorf("CheckUnsubscribe(%q) = %v, want %v", token, err, e.ErrRecordNotFound)
+		}
+	}
+}
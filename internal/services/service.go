@@ -2,16 +2,44 @@ package services
 
 import (
 	"database/sql"
+	"fmt"
+	"log"
 	"meu_job/internal/config"
+	"meu_job/internal/geo"
+	"meu_job/internal/mailer"
 	"meu_job/internal/models"
+	"meu_job/internal/pubsub"
+	"meu_job/internal/registry"
 	"meu_job/internal/repositories"
+	"meu_job/internal/storage"
+	"meu_job/internal/webhook"
 	"meu_job/utils/validator"
+	"os"
+	"time"
 )
 
 type Service struct {
-	User     UserServiceInterface
-	Auth     AuthServiceInterface
-	Business BusinessServiceInterface
+	User            UserServiceInterface
+	Auth            AuthServiceInterface
+	Business        BusinessServiceInterface
+	APIKey          APIKeyServiceInterface
+	Session         SessionServiceInterface
+	Privacy         PrivacyServiceInterface
+	Consent         ConsentServiceInterface
+	Verification    VerificationServiceInterface
+	File            FileServiceInterface
+	Curriculum      CurriculumServiceInterface
+	CurriculumDraft CurriculumDraftServiceInterface
+	Skill           SkillServiceInterface
+	Job             JobServiceInterface
+	JobMatch        JobMatchServiceInterface
+	Email           EmailServiceInterface
+	SavedSearch     SavedSearchServiceInterface
+	Application     ApplicationServiceInterface
+	Interview       InterviewServiceInterface
+	Message         MessageServiceInterface
+	Notification    NotificationServiceInterface
+	Webhook         WebhookServiceInterface
 }
 
 type GenericServiceInterface[
@@ -26,10 +54,141 @@ type GenericServiceInterface[
 
 func New(db *sql.DB, config config.Config) *Service {
 	r := repositories.New(db)
-	userService := NewUserService(r.User, db)
+	sessionService := NewSessionService(r.Session, db)
+	consentService := NewConsentService(r.Consent, r.User, db)
+	m, err := newMailer(config)
+	if err != nil {
+		log.Fatalf("Failed to configure mailer: %s", err)
+	}
+	emailService := NewEmailService(r.Email, m, db)
+
+	userService := NewUserService(r.User, sessionService, consentService, emailService, db)
+
+	var cnpjRegistry registry.CNPJRegistry = registry.NewBrasilAPIRegistry(config.Registry.URL, 5*time.Second)
+	if config.Registry.Fake {
+		cnpjRegistry = registry.NewFakeRegistry()
+	}
+
+	store, err := newStorage(config)
+	if err != nil {
+		log.Fatalf("Failed to configure storage: %s", err)
+	}
+	fileService := NewFileService(r.File, store, db)
+
+	cepResolver, err := newCEPResolver(config)
+	if err != nil {
+		log.Fatalf("Failed to configure CEP resolver: %s", err)
+	}
+	hub, err := pubsub.NewHub(config.DB.DSN, "notifications", func(err error) {
+		log.Printf("Notification listener: %s", err)
+	})
+	if err != nil {
+		log.Fatalf("Failed to listen for notifications: %s", err)
+	}
+	notificationService := NewNotificationService(r.Notification, hub, db)
+	webhookService := NewWebhookService(
+		r.Webhook,
+		r.WebhookDelivery,
+		r.Business,
+		webhook.NewHTTPSender(webhookTimeout, config.Webhook.AllowPrivate),
+		config.Webhook.AllowPrivate,
+		db,
+	)
+
+	skillService := NewSkillService(r.Skill, db)
+	verificationService := NewVerificationService(r.Business, r.Verification, fileService, db)
+	interviewService := NewInterviewService(
+		r.Interview,
+		r.Application,
+		r.Business,
+		r.User,
+		emailService,
+		notificationService,
+		config.Security.SecretKey,
+		config.PublicURL,
+		db,
+	)
+
 	return &Service{
-		User:     userService,
-		Auth:     NewAuthService(userService, config),
-		Business: NewBusinessService(r.Business, db),
+		User:            userService,
+		Auth:            NewAuthService(userService, sessionService, config),
+		Business:        NewBusinessService(r.Business, cnpjRegistry, cepResolver, fileService, db),
+		APIKey:          NewAPIKeyService(r.APIKey, userService, db),
+		Session:         sessionService,
+		Privacy:         NewPrivacyService(r.User, r.Business, r.Session, r.APIKey, r.Consent, r.Curriculum, r.CurriculumDraft, r.JobMatch, r.SavedSearch, r.Email, r.Application, r.Interview, r.Message, r.Notification, r.WebhookDelivery, r.Audit, fileService, db),
+		Consent:         consentService,
+		Verification:    verificationService,
+		File:            fileService,
+		Curriculum:      NewCurriculumService(r.Curriculum, consentService, skillService, cepResolver, db),
+		CurriculumDraft: NewCurriculumDraftService(r.CurriculumDraft, r.Curriculum, r.User, fileService, skillService, cepResolver, db),
+		Skill:           skillService,
+		Job:             NewJobService(r.Job, r.Business, verificationService, skillService, cepResolver, db),
+		JobMatch:        NewJobMatchService(r.JobMatch, r.Job, r.Curriculum, r.Business, db),
+		Email:           emailService,
+		SavedSearch: NewSavedSearchService(
+			r.SavedSearch,
+			r.Job,
+			r.User,
+			emailService,
+			cepResolver,
+			config.Security.SecretKey,
+			config.PublicURL,
+			db,
+		),
+		Application:  NewApplicationService(r.Application, r.Job, r.Curriculum, r.Business, interviewService, notificationService, webhookService, db),
+		Interview:    interviewService,
+		Message:      NewMessageService(r.Message, r.Application, r.Business, fileService, emailService, notificationService, config.PublicURL, db),
+		Notification: notificationService,
+		Webhook:      webhookService,
+	}
+}
+
+func newCEPResolver(config config.Config) (geo.CEPResolver, error) {
+	switch config.Geo.Driver {
+	case "dataset":
+		return geo.NewDatasetResolver(config.Geo.DatasetPath)
+	case "fake", "":
+		return geo.NewFakeResolver(), nil
+	default:
+		return nil, fmt.Errorf("unknown CEP resolver %q", config.Geo.Driver)
+	}
+}
+
+func newMailer(config config.Config) (mailer.Mailer, error) {
+	switch config.Mail.Driver {
+	case "smtp":
+		return mailer.NewSMTPMailer(
+			config.Mail.Host,
+			config.Mail.Port,
+			config.Mail.Username,
+			config.Mail.Password,
+			config.Mail.Sender,
+		)
+	case "log", "":
+		return mailer.NewLogMailer(os.Stdout), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", config.Mail.Driver)
+	}
+}
+
+func newStorage(config config.Config) (storage.Storage, error) {
+	switch config.Storage.Driver {
+	case "s3":
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:  config.Storage.S3.Endpoint,
+			Region:    config.Storage.S3.Region,
+			Bucket:    config.Storage.S3.Bucket,
+			AccessKey: config.Storage.S3.AccessKey,
+			SecretKey: config.Storage.S3.SecretKey,
+			PathStyle: config.Storage.S3.PathStyle,
+		}, time.Minute)
+	case "local", "":
+		return storage.NewLocalStorage(
+			config.Storage.LocalPath,
+			config.Storage.PublicURL,
+			config.Security.SecretKey,
+		)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", config.Storage.Driver)
 	}
 }
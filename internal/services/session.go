@@ -0,0 +1,118 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"meu_job/internal/cache"
+	"meu_job/internal/models"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"time"
+)
+
+const (
+	sessionCacheTTL       = 30 * time.Second
+	sessionCacheMaxItems  = 10_000
+	sessionTouchThreshold = time.Minute
+)
+
+type sessionService struct {
+	session repositories.SessionRepositoryInterface
+	// active keeps recently validated sessions so Authenticate doesn't hit
+	// the database on every request. Revocations made by other replicas
+	// are picked up once the entry expires.
+	active *cache.TTLCache[int64, models.Session]
+	db     *sql.DB
+}
+
+type SessionServiceInterface interface {
+	Create(session *models.Session) error
+	Authenticate(id, userID int64) (*models.Session, error)
+	FindAll(userID int64) ([]*models.Session, error)
+	Revoke(id, userID int64) error
+	RevokeOthers(userID, currentID int64) error
+}
+
+func NewSessionService(
+	sessionRepository repositories.SessionRepositoryInterface,
+	db *sql.DB,
+) *sessionService {
+	return &sessionService{
+		session: sessionRepository,
+		active:  cache.New[int64, models.Session](sessionCacheTTL, sessionCacheMaxItems),
+		db:      db,
+	}
+}
+
+func (s *sessionService) Create(session *models.Session) error {
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.session.Insert(session, tx)
+	})
+}
+
+func (s *sessionService) Authenticate(id, userID int64) (*models.Session, error) {
+	session, found := s.active.Get(id)
+	if !found {
+		stored, err := s.session.GetByID(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, e.ErrRecordNotFound):
+				return nil, e.ErrInvalidCredentials
+			default:
+				return nil, err
+			}
+		}
+		session = *stored
+
+		if time.Since(session.LastSeenAt) > sessionTouchThreshold {
+			if err := s.session.TouchLastSeen(id); err != nil {
+				return nil, err
+			}
+			session.LastSeenAt = time.Now()
+		}
+
+		// Only a miss stores the entry, so hits don't keep it alive past
+		// the TTL and a revocation elsewhere is seen within it.
+		s.active.Set(id, session)
+	}
+
+	if session.UserID != userID {
+		return nil, e.ErrInvalidCredentials
+	}
+
+	return &session, nil
+}
+
+func (s *sessionService) FindAll(userID int64) ([]*models.Session, error) {
+	return s.session.GetAllByUser(userID)
+}
+
+func (s *sessionService) Revoke(id, userID int64) error {
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.session.Revoke(id, userID, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.active.Delete(id)
+	return nil
+}
+
+func (s *sessionService) RevokeOthers(userID, currentID int64) error {
+	var revoked []int64
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		var err error
+		revoked, err = s.session.RevokeAllExcept(userID, currentID, tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range revoked {
+		s.active.Delete(id)
+	}
+	return nil
+}
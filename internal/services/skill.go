@@ -0,0 +1,204 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"strings"
+)
+
+const (
+	skillSearchDefaultLimit = 10
+	skillSearchMaxLimit     = 50
+)
+
+type skillService struct {
+	skill repositories.SkillRepositoryInterface
+	db    *sql.DB
+}
+
+type SkillServiceInterface interface {
+	Search(q, category string, limit int, v *validator.Validator) ([]*models.Skill, error)
+	FindByID(id int64) (*models.Skill, error)
+	Save(s *models.Skill, userID int64, v *validator.Validator) error
+	Update(s *models.Skill, userID int64, v *validator.Validator) error
+	Merge(sourceID, targetID, userID int64, v *validator.Validator) (*models.Skill, error)
+	Resolve(names []string) (map[string]*models.Skill, error)
+	Normalize(skills []models.CurriculumSkill) ([]models.CurriculumSkill, error)
+}
+
+func NewSkillService(skillRepository repositories.SkillRepositoryInterface, db *sql.DB) *skillService {
+	return &skillService{
+		skill: skillRepository,
+		db:    db,
+	}
+}
+
+// Search backs the autocomplete: q is matched as typed against the start
+// of the names and synonyms, ignoring case, accents and punctuation.
+func (s *skillService) Search(q, category string, limit int, v *validator.Validator) ([]*models.Skill, error) {
+	if limit == 0 {
+		limit = skillSearchDefaultLimit
+	}
+	v.Check(category == "" || models.SkillCategory(category).IsValid(), "category", "must be one of "+strings.Join(models.SkillCategories, ", "))
+	v.Check(limit > 0 && limit <= skillSearchMaxLimit, "limit", fmt.Sprintf("must be between 1 and %d", skillSearchMaxLimit))
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	return s.skill.Search(models.SkillKey(q), models.SkillCategory(category), limit)
+}
+
+func (s *skillService) FindByID(id int64) (*models.Skill, error) {
+	return s.skill.GetByID(id)
+}
+
+func (s *skillService) Save(skill *models.Skill, userID int64, v *validator.Validator) error {
+	if err := s.validate(skill, v); err != nil {
+		return err
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.skill.Insert(skill, userID, tx)
+	})
+}
+
+func (s *skillService) Update(skill *models.Skill, userID int64, v *validator.Validator) error {
+	if _, err := s.skill.GetByID(skill.ID); err != nil {
+		return err
+	}
+
+	if err := s.validate(skill, v); err != nil {
+		return err
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.skill.Update(skill, userID, tx)
+	})
+}
+
+// validate also makes sure the name and synonyms don't already point at
+// another skill; two skills known by the same name would make
+// normalization ambiguous.
+func (s *skillService) validate(skill *models.Skill, v *validator.Validator) error {
+	if skill.ValidateSkill(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	keys := append([]string{skill.Key}, make([]string, len(skill.Synonyms))...)
+	for i, synonym := range skill.Synonyms {
+		keys[i+1] = models.SkillKey(synonym)
+	}
+
+	taken, err := s.skill.GetByKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		other, found := taken[key]
+		if !found || other.ID == skill.ID {
+			continue
+		}
+		if i == 0 {
+			v.AddError("name", fmt.Sprintf("already names the skill %q", other.Name))
+		} else {
+			v.AddError("synonyms", fmt.Sprintf("%q already names the skill %q", skill.Synonyms[i-1], other.Name))
+		}
+	}
+	if !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	return nil
+}
+
+// Merge folds a duplicate into the skill it duplicates. Whatever named the
+// duplicate now names the target, and the curricula and jobs listing it
+// are moved over.
+func (s *skillService) Merge(sourceID, targetID, userID int64, v *validator.Validator) (*models.Skill, error) {
+	v.Check(targetID > 0, "into", "must be provided")
+	v.Check(sourceID != targetID, "into", "must be another skill")
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	source, err := s.skill.GetByID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.skill.GetByID(targetID)
+	if err != nil {
+		v.AddError("into", "skill not found")
+		return nil, e.ErrInvalidData
+	}
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.skill.Merge(source, target, userID, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.skill.GetByID(targetID)
+}
+
+// Resolve looks the names up in the catalog. The result is keyed by
+// models.SkillKey of the names; the ones not found are left out.
+func (s *skillService) Resolve(names []string) (map[string]*models.Skill, error) {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		if key := models.SkillKey(name); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return map[string]*models.Skill{}, nil
+	}
+
+	return s.skill.GetByKeys(keys)
+}
+
+// Normalize links the skills to the catalog by name, writing the catalog
+// name in place of the synonym used: "golang" becomes Go. The ids sent by
+// clients aren't trusted, the name decides. Skills that turn out to be
+// the same are kept once, with the first level given.
+func (s *skillService) Normalize(skills []models.CurriculumSkill) ([]models.CurriculumSkill, error) {
+	names := make([]string, len(skills))
+	for i, skill := range skills {
+		names[i] = skill.Name
+	}
+
+	catalog, err := s.Resolve(names)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make([]models.CurriculumSkill, 0, len(skills))
+	seen := map[string]int{}
+	for _, skill := range skills {
+		key := models.SkillKey(skill.Name)
+		skill.SkillID = nil
+		if found, ok := catalog[key]; ok {
+			skill.SkillID = &found.ID
+			skill.Name = found.Name
+			key = fmt.Sprintf("id:%d", found.ID)
+		}
+
+		if i, ok := seen[key]; ok {
+			if normalized[i].Level == "" {
+				normalized[i].Level = skill.Level
+			}
+			continue
+		}
+		seen[key] = len(normalized)
+		normalized = append(normalized, skill)
+	}
+
+	return normalized, nil
+}
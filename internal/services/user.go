@@ -3,37 +3,57 @@ package services
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"meu_job/internal/models"
 	"meu_job/internal/repositories"
 	"meu_job/utils"
 	e "meu_job/utils/errors"
 	"meu_job/utils/validator"
+	"strings"
 )
 
 type UserService struct {
-	user repositories.UserRepositoryInterface
-	db   *sql.DB
+	user    repositories.UserRepositoryInterface
+	session SessionServiceInterface
+	consent ConsentServiceInterface
+	email   EmailServiceInterface
+	db      *sql.DB
 }
 
 type UserServiceInterface interface {
+	GetUserByID(id int64) (*models.User, error)
 	GetUserByEmail(email string, v *validator.Validator) (*models.User, error)
 	ActivateUser(cod int, email string, v *validator.Validator) (*models.User, error)
 	Update(user *models.User) error
 	GetUserByCodAndEmail(cod int, email string, v *validator.Validator) (*models.User, error)
-	RegisterUserHandler(user *models.User, v *validator.Validator) error
+	RegisterUserHandler(user *models.User, acceptance *models.ConsentAcceptance, v *validator.Validator) error
 	Insert(user *models.User, v *validator.Validator) error
+	UpdateProfile(user *models.User, dto *models.UserUpdateDTO, v *validator.Validator) error
+	ChangePassword(user *models.User, currentPassword, newPassword string, sessionID int64, v *validator.Validator) error
+	RequestEmailChange(user *models.User, email, password string, v *validator.Validator) error
+	ConfirmEmailChange(user *models.User, cod int, v *validator.Validator) error
 }
 
 func NewUserService(
 	userRepository repositories.UserRepositoryInterface,
+	sessionService SessionServiceInterface,
+	consentService ConsentServiceInterface,
+	emailService EmailServiceInterface,
 	db *sql.DB,
 ) *UserService {
 	return &UserService{
-		user: userRepository,
-		db:   db,
+		user:    userRepository,
+		session: sessionService,
+		consent: consentService,
+		email:   emailService,
+		db:      db,
 	}
 }
 
+func (s *UserService) GetUserByID(id int64) (*models.User, error) {
+	return s.user.GetByID(id)
+}
+
 func (s *UserService) GetUserByEmail(email string, v *validator.Validator) (*models.User, error) {
 	user, err := s.user.GetByEmail(email)
 	if err != nil {
@@ -64,13 +84,19 @@ func (s *UserService) ActivateUser(cod int, email string, v *validator.Validator
 }
 
 func (s *UserService) Update(user *models.User) error {
-	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
 		err := s.user.Update(tx, user)
 		if err != nil {
 			return err
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.user.Forget(user.ID)
+	return nil
 }
 
 func (s *UserService) GetUserByCodAndEmail(cod int, email string, v *validator.Validator) (*models.User, error) {
@@ -88,10 +114,31 @@ func (s *UserService) GetUserByCodAndEmail(cod int, email string, v *validator.V
 	return user, nil
 }
 
-func (s *UserService) RegisterUserHandler(user *models.User, v *validator.Validator) error {
+func (s *UserService) RegisterUserHandler(
+	user *models.User,
+	acceptance *models.ConsentAcceptance,
+	v *validator.Validator,
+) error {
+	current, err := s.consent.CurrentDocuments()
+	if err != nil {
+		return err
+	}
+
+	user.ValidateUser(v)
+	if acceptance.ValidateAcceptance(v, current); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
 	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
 		user.Cod = utils.GenerateRandomCode()
-		return s.Insert(user, v)
+		user.TermsVersion = acceptance.TermsVersion
+		user.PrivacyVersion = acceptance.PrivacyVersion
+
+		if err := s.user.Insert(tx, user); err != nil {
+			return err
+		}
+
+		return s.consent.Record(tx, user.ID, acceptance)
 	})
 }
 
@@ -106,7 +153,142 @@ func (s *UserService) Insert(user *models.User, v *validator.Validator) error {
 }
 
 func (s *UserService) Delete(idUser int64) error {
-	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
 		return s.user.Delete(tx, idUser)
 	})
+	if err != nil {
+		return err
+	}
+
+	s.user.Forget(idUser)
+	return nil
+}
+
+func (s *UserService) UpdateProfile(user *models.User, dto *models.UserUpdateDTO, v *validator.Validator) error {
+	if dto.Name != nil {
+		user.Name = *dto.Name
+	}
+
+	if dto.Phone != nil {
+		user.Phone = models.NormalizePhone(*dto.Phone)
+	}
+
+	if dto.CPF != nil {
+		user.CPF = nil
+		if *dto.CPF != "" {
+			cpf := models.NormalizeCPF(*dto.CPF)
+			user.CPF = &cpf
+		}
+	}
+
+	if user.ValidateUser(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	return s.Update(user)
+}
+
+// ChangePassword signs out every other session of the user, keeping only the
+// one that made the request. The token version goes up, so tokens already
+// issued stop working; the session keeping on needs a new one.
+func (s *UserService) ChangePassword(
+	user *models.User,
+	currentPassword,
+	newPassword string,
+	sessionID int64,
+	v *validator.Validator,
+) error {
+	match, err := user.Password.Matches(currentPassword)
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		v.AddError("current_password", "is incorrect")
+		return e.ErrInvalidData
+	}
+
+	if err := user.Password.Set(newPassword); err != nil {
+		return err
+	}
+	user.TokenVersion++
+
+	if user.ValidateUser(v); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	if err := s.Update(user); err != nil {
+		return err
+	}
+
+	return s.session.RevokeOthers(user.ID, sessionID)
+}
+
+func (s *UserService) RequestEmailChange(
+	user *models.User,
+	email,
+	password string,
+	v *validator.Validator,
+) error {
+	if models.ValidateEmail(v, email); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	match, err := user.Password.Matches(password)
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		v.AddError("password", "is incorrect")
+		return e.ErrInvalidData
+	}
+
+	_, err = s.user.GetByEmail(email)
+	switch {
+	case err == nil:
+		return e.ErrDuplicateEmail
+	case !errors.Is(err, e.ErrRecordNotFound):
+		return err
+	}
+
+	cod := utils.GenerateRandomCode()
+	user.PendingEmail = &email
+	user.PendingEmailCod = &cod
+
+	// The code goes to the new address, proving it's the user's.
+	name, _, _ := strings.Cut(strings.TrimSpace(user.Name), " ")
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		if err := s.user.Update(tx, user); err != nil {
+			return err
+		}
+		return s.email.Enqueue(&models.Email{
+			UserID:  &user.ID,
+			To:      email,
+			Subject: "Confirme o seu novo e-mail",
+			Body: fmt.Sprintf(
+				"Olá, %s!\n\nPara confirmar este endereço como o seu novo e-mail, use o código %d.\n\nSe você não pediu a troca, ignore esta mensagem; o seu e-mail atual continua valendo.\n",
+				name, cod,
+			),
+		}, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.user.Forget(user.ID)
+	return nil
+}
+
+func (s *UserService) ConfirmEmailChange(user *models.User, cod int, v *validator.Validator) error {
+	if user.PendingEmail == nil || user.PendingEmailCod == nil || *user.PendingEmailCod != cod {
+		v.AddError("code", "invalid validation code")
+		return e.ErrInvalidData
+	}
+
+	user.Email = *user.PendingEmail
+	user.PendingEmail = nil
+	user.PendingEmailCod = nil
+
+	return s.Update(user)
 }
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/repositories"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"time"
+)
+
+type verificationService struct {
+	business     repositories.BusinessRepositoryInterface
+	verification repositories.VerificationRepositoryInterface
+	file         FileServiceInterface
+	db           *sql.DB
+}
+
+type VerificationServiceInterface interface {
+	Status(businessID, userID int64) (*models.Business, []*models.VerificationDocument, error)
+	UploadDocument(businessID, userID int64, file *models.File, content []byte, v *validator.Validator) (*models.VerificationDocument, error)
+	Submit(businessID, userID int64, v *validator.Validator) (*models.Business, error)
+	Queue(status models.VerificationStatus, f filters.Filters) ([]*models.Business, filters.Metadata, error)
+	Review(businessID, adminID int64, approve bool, reason string, v *validator.Validator) (*models.Business, error)
+	ReviewDetails(businessID int64) (*models.Business, []*models.VerificationDocument, error)
+	Document(businessID, documentID int64) (*models.FileDTO, error)
+	RequireVerified(businessID, userID int64) error
+}
+
+func NewVerificationService(
+	businessRepository repositories.BusinessRepositoryInterface,
+	verificationRepository repositories.VerificationRepositoryInterface,
+	fileService FileServiceInterface,
+	db *sql.DB,
+) *verificationService {
+	return &verificationService{
+		business:     businessRepository,
+		verification: verificationRepository,
+		file:         fileService,
+		db:           db,
+	}
+}
+
+func (s *verificationService) Status(businessID, userID int64) (*models.Business, []*models.VerificationDocument, error) {
+	business, err := s.business.GetByID(businessID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	docs, err := s.verification.GetDocuments(businessID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return business, docs, nil
+}
+
+func (s *verificationService) UploadDocument(
+	businessID,
+	userID int64,
+	file *models.File,
+	content []byte,
+	v *validator.Validator,
+) (*models.VerificationDocument, error) {
+	business, err := s.business.GetByID(businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if business.VerificationStatus == models.VerificationVerified {
+		v.AddError("document", "business is already verified")
+		return nil, e.ErrInvalidData
+	}
+
+	refType := models.RefBusinessVerification
+	file.OwnerID = userID
+	file.Kind = models.FileVerificationDocument
+	file.RefType = &refType
+	file.RefID = &businessID
+	if err := s.file.Upload(file, content, v); err != nil {
+		return nil, err
+	}
+
+	doc := &models.VerificationDocument{
+		BusinessID:  businessID,
+		FileID:      file.ID,
+		FileName:    file.FileName,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+	}
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.verification.InsertDocument(doc, userID, tx)
+	})
+	if err != nil {
+		s.file.Delete(file.ID, userID)
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// Submit puts the business in the admin review queue. At least one document
+// must have been uploaded first.
+func (s *verificationService) Submit(businessID, userID int64, v *validator.Validator) (*models.Business, error) {
+	business, docs, err := s.Status(businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := business.VerificationStatus
+	v.Check(from.CanTransitionTo(models.VerificationPending), "status", fmt.Sprintf("cannot request verification while %s", from))
+	v.Check(len(docs) > 0, "documents", "upload at least one proof of ownership before requesting verification")
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	business.VerificationStatus = models.VerificationPending
+	business.VerificationReason = ""
+	business.VerificationReviewedBy = nil
+	business.VerificationReviewedAt = nil
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.verification.SetStatus(business, from, userID, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return business, nil
+}
+
+func (s *verificationService) Queue(status models.VerificationStatus, f filters.Filters) ([]*models.Business, filters.Metadata, error) {
+	return s.verification.GetQueue(status, f)
+}
+
+func (s *verificationService) ReviewDetails(businessID int64) (*models.Business, []*models.VerificationDocument, error) {
+	business, err := s.verification.GetBusiness(businessID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	docs, err := s.verification.GetDocuments(businessID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return business, docs, nil
+}
+
+// Document returns a signed URL for the stored file.
+func (s *verificationService) Document(businessID, documentID int64) (*models.FileDTO, error) {
+	doc, err := s.verification.GetDocument(documentID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.file.SignedURLByID(doc.FileID)
+}
+
+// Review approves or rejects a pending business. A rejection must say why,
+// since the reason is what the members see.
+func (s *verificationService) Review(
+	businessID,
+	adminID int64,
+	approve bool,
+	reason string,
+	v *validator.Validator,
+) (*models.Business, error) {
+	business, err := s.verification.GetBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := models.VerificationRejected
+	if approve {
+		to = models.VerificationVerified
+	}
+
+	from := business.VerificationStatus
+	v.Check(from.CanTransitionTo(to), "status", fmt.Sprintf("cannot move from %s to %s", from, to))
+	v.Check(approve || reason != "", "reason", "must be provided when rejecting")
+	v.Check(len(reason) <= 1000, "reason", "must not be more than 1000 bytes long")
+	if !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	now := time.Now()
+	business.VerificationStatus = to
+	business.VerificationReason = reason
+	business.VerificationReviewedBy = &adminID
+	business.VerificationReviewedAt = &now
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.verification.SetStatus(business, from, adminID, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return business, nil
+}
+
+// RequireVerified is the gate for actions reserved to verified businesses,
+// such as publishing job postings.
+func (s *verificationService) RequireVerified(businessID, userID int64) error {
+	business, err := s.business.GetByID(businessID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !business.CanPublishJobs() {
+		return e.ErrBusinessNotVerified
+	}
+
+	return nil
+}
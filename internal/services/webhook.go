@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"meu_job/internal/models"
+	"meu_job/internal/models/filters"
+	"meu_job/internal/repositories"
+	"meu_job/internal/webhook"
+	"meu_job/utils"
+	e "meu_job/utils/errors"
+	"meu_job/utils/validator"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	webhookBatchSize   = 20
+	webhookMaxAttempts = 10
+	webhookTimeout     = 10 * time.Second
+	// webhookLease is how long a claimed delivery is left alone before
+	// another run takes it; well over what sending a batch takes.
+	webhookLease = time.Minute
+)
+
+type webhookService struct {
+	webhook      repositories.WebhookRepositoryInterface
+	delivery     repositories.WebhookDeliveryRepositoryInterface
+	business     repositories.BusinessRepositoryInterface
+	sender       webhook.Sender
+	allowPrivate bool
+	db           *sql.DB
+}
+
+type WebhookServiceInterface interface {
+	FindAll(businessID, userID int64) ([]*models.Webhook, error)
+	FindByID(id, businessID, userID int64) (*models.Webhook, error)
+	Save(w *models.Webhook, businessID, userID int64, v *validator.Validator) error
+	Update(w *models.Webhook, businessID, userID int64, v *validator.Validator) error
+	Delete(id, businessID, userID int64) error
+	RotateSecret(id, businessID, userID int64, version int, v *validator.Validator) (*models.Webhook, error)
+	Ping(id, businessID, userID int64) (*models.WebhookDelivery, error)
+	FindDeliveries(webhookID, businessID, userID int64, status string, f filters.Filters) ([]*models.WebhookDelivery, filters.Metadata, error)
+	FindDelivery(id, webhookID, businessID, userID int64) (*models.WebhookDelivery, error)
+	Redeliver(id, webhookID, businessID, userID int64, v *validator.Validator) (*models.WebhookDelivery, error)
+	Enqueue(event models.WebhookEvent, businessID int64, data any, tx *sql.Tx) error
+	DeliverPending() error
+}
+
+func NewWebhookService(
+	webhookRepository repositories.WebhookRepositoryInterface,
+	deliveryRepository repositories.WebhookDeliveryRepositoryInterface,
+	businessRepository repositories.BusinessRepositoryInterface,
+	sender webhook.Sender,
+	allowPrivate bool,
+	db *sql.DB,
+) *webhookService {
+	return &webhookService{
+		webhook:      webhookRepository,
+		delivery:     deliveryRepository,
+		business:     businessRepository,
+		sender:       sender,
+		allowPrivate: allowPrivate,
+		db:           db,
+	}
+}
+
+func (s *webhookService) member(businessID, userID int64) error {
+	_, err := s.business.GetByID(businessID, userID)
+	return err
+}
+
+func (s *webhookService) FindAll(businessID, userID int64) ([]*models.Webhook, error) {
+	if err := s.member(businessID, userID); err != nil {
+		return nil, err
+	}
+	return s.webhook.GetAllByBusiness(businessID)
+}
+
+func (s *webhookService) FindByID(id, businessID, userID int64) (*models.Webhook, error) {
+	if err := s.member(businessID, userID); err != nil {
+		return nil, err
+	}
+	return s.webhook.GetByID(id, businessID)
+}
+
+// Save registers the endpoint with a new secret, shown only now and when
+// it's rotated.
+func (s *webhookService) Save(w *models.Webhook, businessID, userID int64, v *validator.Validator) error {
+	if err := s.member(businessID, userID); err != nil {
+		return err
+	}
+
+	w.BusinessID = businessID
+	if w.ValidateWebhook(v, s.allowPrivate); !v.Valid() {
+		return e.ErrInvalidData
+	}
+	if err := w.GenerateSecret(); err != nil {
+		return err
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.webhook.Insert(w, userID, tx)
+	})
+}
+
+// Update changes the endpoint for the deliveries still to be sent too.
+func (s *webhookService) Update(w *models.Webhook, businessID, userID int64, v *validator.Validator) error {
+	if _, err := s.FindByID(w.ID, businessID, userID); err != nil {
+		return err
+	}
+
+	w.BusinessID = businessID
+	v.Check(w.Version > 0, "version", "must be provided")
+	if w.ValidateWebhook(v, s.allowPrivate); !v.Valid() {
+		return e.ErrInvalidData
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.webhook.Update(w, userID, tx)
+	})
+}
+
+// Delete stops the deliveries to the endpoint, including those queued.
+func (s *webhookService) Delete(id, businessID, userID int64) error {
+	if err := s.member(businessID, userID); err != nil {
+		return err
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.webhook.Delete(id, businessID, userID, tx)
+	})
+}
+
+// RotateSecret replaces the secret at once; deliveries sent from then on,
+// retries included, are signed with the new one.
+func (s *webhookService) RotateSecret(
+	id,
+	businessID,
+	userID int64,
+	version int,
+	v *validator.Validator,
+) (*models.Webhook, error) {
+	w, err := s.FindByID(id, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Check(version > 0, "version", "must be provided"); !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+	if err := w.GenerateSecret(); err != nil {
+		return nil, err
+	}
+	w.Version = version
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.webhook.SetSecret(w, userID, tx)
+	})
+	return w, err
+}
+
+// Ping queues a ping to the endpoint, turned off or not, to try it out.
+// It's sent within seconds; the delivery tells how it went.
+func (s *webhookService) Ping(id, businessID, userID int64) (*models.WebhookDelivery, error) {
+	w, err := s.FindByID(id, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(map[string]int64{
+		"webhook_id":  w.ID,
+		"business_id": w.BusinessID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d := &models.WebhookDelivery{
+		WebhookID: w.ID,
+		Event:     models.EventPing,
+		Payload:   payload,
+	}
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		return s.delivery.Insert(d, tx)
+	})
+	return d, err
+}
+
+func (s *webhookService) FindDeliveries(
+	webhookID,
+	businessID,
+	userID int64,
+	status string,
+	f filters.Filters,
+) ([]*models.WebhookDelivery, filters.Metadata, error) {
+	if _, err := s.FindByID(webhookID, businessID, userID); err != nil {
+		return nil, filters.Metadata{}, err
+	}
+	return s.delivery.GetAllByWebhook(webhookID, status, f)
+}
+
+// FindDelivery loads the delivery with every attempt at it.
+func (s *webhookService) FindDelivery(id, webhookID, businessID, userID int64) (*models.WebhookDelivery, error) {
+	if _, err := s.FindByID(webhookID, businessID, userID); err != nil {
+		return nil, err
+	}
+
+	d, err := s.delivery.GetByID(id, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	d.AttemptLog, err = s.delivery.GetAttempts(d.ID)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Redeliver queues a delivery again, dead or succeeded, with the payload
+// it first had.
+func (s *webhookService) Redeliver(
+	id,
+	webhookID,
+	businessID,
+	userID int64,
+	v *validator.Validator,
+) (*models.WebhookDelivery, error) {
+	if _, err := s.FindByID(webhookID, businessID, userID); err != nil {
+		return nil, err
+	}
+
+	d, err := s.delivery.GetByID(id, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Check(d.Status != models.DeliveryPending, "status", "the delivery is already queued"); !v.Valid() {
+		return nil, e.ErrInvalidData
+	}
+
+	err = utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		d, err = s.delivery.Requeue(id, webhookID, tx)
+		return err
+	})
+	return d, err
+}
+
+// Enqueue queues the event for the webhooks of the business subscribed to
+// it, within tx, so it's only sent if whatever caused it is committed.
+func (s *webhookService) Enqueue(event models.WebhookEvent, businessID int64, data any, tx *sql.Tx) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return s.delivery.Enqueue(businessID, event, payload, tx)
+}
+
+// DeliverPending sends the due deliveries at once. A failed delivery is
+// retried with a growing delay, from 30 seconds up to about four hours, and
+// given up on as dead after webhookMaxAttempts. Endpoints failing are
+// recorded with the delivery rather than returned; only errors of our own
+// are.
+func (s *webhookService) DeliverPending() error {
+	var deliveries []*models.WebhookDelivery
+	err := utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		var err error
+		deliveries, err = s.delivery.ClaimDue(webhookBatchSize, webhookLease, tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, len(deliveries))
+	var wg sync.WaitGroup
+	for i, d := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.deliver(d); err != nil {
+				errs[i] = fmt.Errorf("webhook delivery %d: %w", d.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (s *webhookService) deliver(d *models.WebhookDelivery) error {
+	body, err := d.Body()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, sendErr := s.sender.Send(ctx, webhook.Request{
+		URL:        d.URL,
+		Event:      string(d.Event),
+		DeliveryID: d.ID,
+		Secret:     d.Secret,
+		Body:       body,
+	})
+
+	a := &models.WebhookAttempt{
+		DeliveryID: d.ID,
+		Duration:   time.Since(start),
+	}
+	var reason string
+	switch {
+	case sendErr != nil:
+		reason = sendErr.Error()
+	case !resp.OK():
+		reason = fmt.Sprintf("endpoint answered %d", resp.StatusCode)
+	}
+	if resp != nil {
+		a.StatusCode = &resp.StatusCode
+		// Postgres text takes neither invalid UTF-8 nor NUL bytes.
+		a.ResponseBody = strings.ReplaceAll(strings.ToValidUTF8(resp.Body, ""), "\x00", "")
+	}
+	if reason != "" {
+		a.Error = &reason
+	}
+
+	return utils.RunInTx(s.db, func(tx *sql.Tx) error {
+		if err := s.delivery.RecordAttempt(a, tx); err != nil {
+			return err
+		}
+		if reason == "" {
+			return s.delivery.MarkSucceeded(d.ID, resp.StatusCode, tx)
+		}
+
+		var retryAt *time.Time
+		if d.Attempts+1 < webhookMaxAttempts && !errors.Is(sendErr, webhook.ErrForbiddenAddress) {
+			at := time.Now().Add((30 * time.Second) << d.Attempts)
+			retryAt = &at
+		}
+		return s.delivery.MarkFailed(d.ID, a.StatusCode, reason, retryAt, tx)
+	})
+}
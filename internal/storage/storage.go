@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrNotFound         = errors.New("object not found")
+	ErrInvalidKey       = errors.New("invalid object key")
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+)
+
+// Storage keeps the uploaded blobs. The database only tracks metadata (see
+// the files table); objects are addressed by an opaque key the service
+// generates.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that lets anyone holding it download the
+	// object until it expires.
+	SignedURL(key string, expires time.Duration) (string, error)
+}
+
+// Verifier is implemented by drivers whose signed URLs point back at the
+// API instead of at the object store itself.
+type Verifier interface {
+	Verify(key string, expires int64, signature string) error
+}
@@ -0,0 +1,63 @@
+// Package webhook calls the endpoints businesses register to hear about
+// their applications. Events are not sent from request handlers: services
+// queue them in the webhook_deliveries table and a background task hands
+// them to a Sender, retrying those that fail.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader carries "t=<unix time>,v1=<signature>"; see Sign.
+	SignatureHeader = "X-MeuJob-Signature"
+	EventHeader     = "X-MeuJob-Event"
+	DeliveryHeader  = "X-MeuJob-Delivery"
+)
+
+// Request is one attempt at a delivery.
+type Request struct {
+	URL        string
+	Event      string
+	DeliveryID int64
+	Secret     string
+	Body       []byte
+}
+
+// Response is what the endpoint answered, Body cut short.
+type Response struct {
+	StatusCode int
+	Body       string
+}
+
+// OK is whether the endpoint took the event. Redirects aren't followed, so
+// they count as failures.
+func (r *Response) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+type Sender interface {
+	// Send makes the request, signed at the time it's sent. The error is
+	// only for requests that got no response.
+	Send(ctx context.Context, req Request) (*Response, error)
+}
+
+// Sign is the value of SignatureHeader: the time of signing and the
+// HMAC-SHA256, in hex, of "<unix time>.<body>" keyed with the webhook's
+// secret. Receivers compute it again to check the payload came from us,
+// and refuse old timestamps so it can't be replayed.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
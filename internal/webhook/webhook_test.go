@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	at := time.Date(2025, 10, 19, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"event":"application.created"}`)
+
+	// HMAC-SHA256 of "1760875200.<body>" keyed with "whsec_teste".
+	want := "t=1760875200,v1=dc7e8b26941b1c758e16d9680aae1d96f91cd141ed3a535b3da9f29c5ec90b6c"
+	if got := Sign("whsec_teste", at, body); got != want {
+		t.Fatalf("Sign = %q, want %q", got, want)
+	}
+
+	if Sign("whsec_teste", at.Add(time.Second), body) == want {
+		t.Error("signature doesn't depend on the time")
+	}
+	if Sign("whsec_outro", at, body) == want {
+		t.Error("signature doesn't depend on the secret")
+	}
+	if Sign("whsec_teste", at, []byte(`{"event":"application.created" }`)) == want {
+		t.Error("signature doesn't depend on the body")
+	}
+}
+
+func TestHTTPSender(t *testing.T) {
+	var got *http.Request
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, strings.Repeat("x", 2*maxResponseBody))
+	}))
+	defer server.Close()
+
+	req := Request{
+		URL:        server.URL,
+		Event:      "application.created",
+		DeliveryID: 42,
+		Secret:     "whsec_teste",
+		Body:       []byte(`{"id":1}`),
+	}
+
+	t.Run("private address refused", func(t *testing.T) {
+		_, err := NewHTTPSender(time.Second, false).Send(context.Background(), req)
+		if !errors.Is(err, ErrForbiddenAddress) {
+			t.Fatalf("Send to %s: error = %v, want %v", server.URL, err, ErrForbiddenAddress)
+		}
+	})
+
+	t.Run("private address allowed", func(t *testing.T) {
+		before := time.Now()
+		resp, err := NewHTTPSender(time.Second, true).Send(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+
+		if !resp.OK() || resp.StatusCode != http.StatusAccepted || len(resp.Body) != maxResponseBody {
+			t.Errorf("response = %d with %d bytes", resp.StatusCode, len(resp.Body))
+		}
+		if string(gotBody) != `{"id":1}` {
+			t.Errorf("body = %q", gotBody)
+		}
+		if got.Header.Get(EventHeader) != "application.created" || got.Header.Get(DeliveryHeader) != "42" {
+			t.Errorf("headers = %v", got.Header)
+		}
+
+		sig := got.Header.Get(SignatureHeader)
+		ok := false
+		for at := before.Truncate(time.Second); !at.After(time.Now()); at = at.Add(time.Second) {
+			ok = ok || sig == Sign(req.Secret, at, req.Body)
+		}
+		if !ok {
+			t.Errorf("%s = %q doesn't match the body signed at the time of sending", SignatureHeader, sig)
+		}
+	})
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN pending_email citext,
+    ADD COLUMN pending_email_cod INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS pending_email,
+    DROP COLUMN IF EXISTS pending_email_cod;
+-- +goose StatementEnd
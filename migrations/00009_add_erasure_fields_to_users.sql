@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN erasure_requested_at TIMESTAMPTZ,
+    ADD COLUMN erasure_scheduled_for TIMESTAMPTZ,
+    ADD COLUMN anonymized_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_erasure_scheduled_for
+    ON users(erasure_scheduled_for)
+    WHERE erasure_scheduled_for IS NOT NULL AND anonymized_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_erasure_scheduled_for;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS erasure_requested_at,
+    DROP COLUMN IF EXISTS erasure_scheduled_for,
+    DROP COLUMN IF EXISTS anonymized_at;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS legal_documents (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL CHECK (kind IN ('terms', 'privacy')),
+    version INT NOT NULL,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_legal_documents_kind_version UNIQUE (kind, version)
+);
+
+CREATE TABLE IF NOT EXISTS consents (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    document_id BIGINT REFERENCES legal_documents(id),
+    granted BOOLEAN NOT NULL,
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_consents_user ON consents(user_id, subject, created_at DESC);
+
+ALTER TABLE users
+    ADD COLUMN terms_version INT NOT NULL DEFAULT 0,
+    ADD COLUMN privacy_version INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS terms_version,
+    DROP COLUMN IF EXISTS privacy_version;
+
+DROP TABLE IF EXISTS consents;
+DROP TABLE IF EXISTS legal_documents;
+-- +goose StatementEnd
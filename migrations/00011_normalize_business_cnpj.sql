@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Store only the canonical form (no mask, upper case) so that
+-- unique_business_cnpj_deleted can't be bypassed with punctuation.
+-- Rows that collide after normalization must be merged by hand first.
+UPDATE business
+SET cnpj = upper(regexp_replace(cnpj, '[./ -]', '', 'g'))
+WHERE cnpj <> upper(regexp_replace(cnpj, '[./ -]', '', 'g'));
+
+ALTER TABLE business
+    ADD CONSTRAINT business_cnpj_canonical
+    CHECK (cnpj ~ '^[0-9A-Z]{12}[0-9]{2}$') NOT VALID;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE business DROP CONSTRAINT IF EXISTS business_cnpj_canonical;
+-- +goose StatementEnd
@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN cpf TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_cpf_key ON users(cpf) WHERE deleted = false;
+
+-- Rewrite phones to E.164 (+55 + DDD + number). When two rows would end up
+-- with the same number only the oldest is rewritten; the others keep their
+-- current value for manual review instead of breaking users_phone_key.
+WITH digits AS (
+    SELECT id, phone, regexp_replace(phone, '[^0-9]', '', 'g') AS d
+    FROM users
+),
+national AS (
+    SELECT
+        id,
+        phone,
+        CASE
+            WHEN phone LIKE '+55%' THEN substr(d, 3)
+            WHEN d LIKE '55%' AND length(d) IN (12, 13) THEN substr(d, 3)
+            WHEN d LIKE '0%' AND length(d) IN (13, 14) THEN substr(d, 4)
+            WHEN d LIKE '0%' AND length(d) IN (11, 12) THEN substr(d, 2)
+            WHEN phone LIKE '+%' THEN NULL
+            ELSE d
+        END AS n
+    FROM digits
+),
+normalized AS (
+    SELECT
+        id,
+        phone,
+        '+55' || n AS e164,
+        row_number() OVER (PARTITION BY n ORDER BY id) AS rn
+    FROM national
+    WHERE length(n) IN (10, 11)
+)
+UPDATE users u
+SET phone = nz.e164
+FROM normalized nz
+WHERE
+    u.id = nz.id
+    AND nz.rn = 1
+    AND u.phone <> nz.e164
+    AND NOT EXISTS (
+        SELECT 1 FROM users o WHERE o.phone = nz.e164 AND o.id <> u.id
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Phone normalization is not reversible; only the CPF column is dropped.
+DROP INDEX IF EXISTS users_cpf_key;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS cpf;
+-- +goose StatementEnd
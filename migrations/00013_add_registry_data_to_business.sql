@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE business
+    ADD COLUMN legal_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN trade_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN address_cep TEXT NOT NULL DEFAULT '',
+    ADD COLUMN address_street TEXT NOT NULL DEFAULT '',
+    ADD COLUMN address_number TEXT NOT NULL DEFAULT '',
+    ADD COLUMN address_complement TEXT NOT NULL DEFAULT '',
+    ADD COLUMN address_district TEXT NOT NULL DEFAULT '',
+    ADD COLUMN address_city TEXT NOT NULL DEFAULT '',
+    ADD COLUMN address_uf TEXT NOT NULL DEFAULT '',
+    ADD COLUMN registration_status TEXT NOT NULL DEFAULT '',
+    ADD COLUMN verified BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN registry_snapshot JSONB,
+    ADD COLUMN registry_checked_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE business
+    DROP COLUMN IF EXISTS legal_name,
+    DROP COLUMN IF EXISTS trade_name,
+    DROP COLUMN IF EXISTS address_cep,
+    DROP COLUMN IF EXISTS address_street,
+    DROP COLUMN IF EXISTS address_number,
+    DROP COLUMN IF EXISTS address_complement,
+    DROP COLUMN IF EXISTS address_district,
+    DROP COLUMN IF EXISTS address_city,
+    DROP COLUMN IF EXISTS address_uf,
+    DROP COLUMN IF EXISTS registration_status,
+    DROP COLUMN IF EXISTS verified,
+    DROP COLUMN IF EXISTS registry_snapshot,
+    DROP COLUMN IF EXISTS registry_checked_at;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE business
+    ADD COLUMN verification_status TEXT NOT NULL DEFAULT 'unverified'
+        CHECK (verification_status IN ('unverified', 'pending', 'verified', 'rejected')),
+    ADD COLUMN verification_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN verification_requested_at TIMESTAMPTZ,
+    ADD COLUMN verification_reviewed_by BIGINT REFERENCES users(id),
+    ADD COLUMN verification_reviewed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_business_verification_queue
+    ON business(verification_requested_at)
+    WHERE verification_status = 'pending' AND NOT deleted;
+
+CREATE TABLE IF NOT EXISTS business_verification_documents (
+    id BIGSERIAL PRIMARY KEY,
+    business_id BIGINT NOT NULL REFERENCES business(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    content BYTEA NOT NULL,
+
+    version INT NOT NULL DEFAULT 1,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by BIGINT,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_business_verification_documents_business
+    ON business_verification_documents(business_id) WHERE NOT deleted;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS business_verification_documents;
+
+DROP INDEX IF EXISTS idx_business_verification_queue;
+
+ALTER TABLE business
+    DROP COLUMN IF EXISTS verification_status,
+    DROP COLUMN IF EXISTS verification_reason,
+    DROP COLUMN IF EXISTS verification_requested_at,
+    DROP COLUMN IF EXISTS verification_reviewed_by,
+    DROP COLUMN IF EXISTS verification_reviewed_at;
+-- +goose StatementEnd
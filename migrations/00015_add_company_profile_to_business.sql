@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE business
+    ADD COLUMN slug TEXT,
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN sector TEXT NOT NULL DEFAULT '',
+    ADD COLUMN size TEXT NOT NULL DEFAULT ''
+        CHECK (size IN ('', '1-10', '11-50', '51-200', '201-1000', '1000+')),
+    ADD COLUMN logo_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN website TEXT NOT NULL DEFAULT '',
+    ADD COLUMN social_links JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN public BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Existing businesses get a slug from their name, suffixed with the id so
+-- that the backfill can never collide.
+UPDATE business
+SET slug = trim(both '-' from regexp_replace(
+        lower(translate(name,
+            'áàâãäéèêëíìîïóòôõöúùûüçñÁÀÂÃÄÉÈÊËÍÌÎÏÓÒÔÕÖÚÙÛÜÇÑ',
+            'aaaaaeeeeiiiiooooouuuucnaaaaaeeeeiiiiooooouuuucn')),
+        '[^a-z0-9]+', '-', 'g')) || '-' || id;
+
+UPDATE business SET slug = 'empresa-' || id WHERE slug = '-' || id;
+
+ALTER TABLE business ALTER COLUMN slug SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_business_slug ON business(slug) WHERE NOT deleted;
+CREATE INDEX IF NOT EXISTS idx_business_public ON business(id) WHERE public AND NOT deleted;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_business_public;
+DROP INDEX IF EXISTS unique_business_slug;
+
+ALTER TABLE business
+    DROP COLUMN IF EXISTS slug,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS sector,
+    DROP COLUMN IF EXISTS size,
+    DROP COLUMN IF EXISTS logo_url,
+    DROP COLUMN IF EXISTS website,
+    DROP COLUMN IF EXISTS social_links,
+    DROP COLUMN IF EXISTS public;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS files (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id BIGINT NOT NULL REFERENCES users(id),
+    kind TEXT NOT NULL CHECK (kind IN ('resume', 'logo', 'verification_document')),
+    storage_key TEXT NOT NULL UNIQUE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    checksum TEXT NOT NULL,
+    ref_type TEXT,
+    ref_id BIGINT,
+
+    version INT NOT NULL DEFAULT 1,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by BIGINT,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_files_owner ON files(owner_id) WHERE NOT deleted;
+CREATE INDEX IF NOT EXISTS idx_files_ref ON files(ref_type, ref_id) WHERE NOT deleted;
+
+-- Verification documents uploaded from now on live in the storage; the
+-- content column only keeps the ones sent before it existed.
+ALTER TABLE business_verification_documents
+    ADD COLUMN file_id BIGINT REFERENCES files(id),
+    ALTER COLUMN content DROP NOT NULL;
+
+ALTER TABLE business
+    ADD COLUMN logo_file_id BIGINT REFERENCES files(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE business
+    DROP COLUMN IF EXISTS logo_file_id;
+
+DELETE FROM business_verification_documents WHERE content IS NULL;
+
+ALTER TABLE business_verification_documents
+    DROP COLUMN IF EXISTS file_id,
+    ALTER COLUMN content SET NOT NULL;
+
+DROP TABLE IF EXISTS files;
+-- +goose StatementEnd
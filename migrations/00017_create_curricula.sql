@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS curricula (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    full_name TEXT NOT NULL,
+    email CITEXT NOT NULL,
+    phone TEXT NOT NULL,
+    cpf TEXT,
+    birth_date DATE,
+    summary TEXT NOT NULL DEFAULT '',
+    profession TEXT NOT NULL DEFAULT '',
+    experience JSONB NOT NULL DEFAULT '[]',
+    education JSONB NOT NULL DEFAULT '[]',
+    skills TEXT[] NOT NULL DEFAULT '{}',
+    languages JSONB NOT NULL DEFAULT '[]',
+
+    version INT NOT NULL DEFAULT 1,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by BIGINT,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_curricula_user ON curricula(user_id) WHERE NOT deleted;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS curricula;
+-- +goose StatementEnd
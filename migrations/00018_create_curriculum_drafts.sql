@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS curriculum_drafts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    file_id BIGINT NOT NULL REFERENCES files(id),
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'ready', 'failed', 'confirmed')),
+    curriculum JSONB,
+    confidence JSONB NOT NULL DEFAULT '{}',
+    unparsed JSONB NOT NULL DEFAULT '{}',
+    error TEXT NOT NULL DEFAULT '',
+    attempts INT NOT NULL DEFAULT 0,
+    processed_at TIMESTAMPTZ,
+    curriculum_id BIGINT REFERENCES curricula(id),
+
+    version INT NOT NULL DEFAULT 1,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by BIGINT,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_curriculum_drafts_user ON curriculum_drafts(user_id) WHERE NOT deleted;
+
+CREATE INDEX IF NOT EXISTS idx_curriculum_drafts_pending
+    ON curriculum_drafts(created_at)
+    WHERE status = 'pending' AND NOT deleted;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS curriculum_drafts;
+-- +goose StatementEnd
@@ -0,0 +1,68 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE curricula
+    ADD COLUMN name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN is_default BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE curricula
+SET name = CASE WHEN profession <> '' THEN left(profession, 100) ELSE 'Currículo' END;
+
+UPDATE curricula c
+SET is_default = TRUE
+WHERE
+    NOT c.deleted
+    AND c.id = (SELECT min(o.id) FROM curricula o WHERE o.user_id = c.user_id AND NOT o.deleted);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_curricula_default
+    ON curricula(user_id) WHERE is_default AND NOT deleted;
+
+CREATE TABLE IF NOT EXISTS curriculum_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    curriculum_id BIGINT NOT NULL REFERENCES curricula(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    snapshot JSONB NOT NULL,
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT unique_curriculum_revisions_version UNIQUE (curriculum_id, version)
+);
+
+-- Curricula saved before revisions existed start with their current state.
+INSERT INTO curriculum_revisions (curriculum_id, version, snapshot, created_by, created_at)
+SELECT
+    id,
+    version,
+    jsonb_build_object(
+        'curriculum_id', id,
+        'user_id', user_id,
+        'name', name,
+        'is_default', is_default,
+        'full_name', full_name,
+        'email', email,
+        'phone', phone,
+        'cpf', cpf,
+        'birth_date', birth_date,
+        'summary', summary,
+        'profession', profession,
+        'experience', experience,
+        'education', education,
+        'skills', to_jsonb(skills),
+        'languages', languages,
+        'version', version,
+        'created_at', created_at,
+        'updated_at', updated_at
+    ),
+    coalesce(updated_by, created_by),
+    coalesce(updated_at, created_at)
+FROM curricula;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS curriculum_revisions;
+
+DROP INDEX IF EXISTS idx_curricula_default;
+
+ALTER TABLE curricula
+    DROP COLUMN IF EXISTS name,
+    DROP COLUMN IF EXISTS is_default;
+-- +goose StatementEnd
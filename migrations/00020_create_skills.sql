@@ -0,0 +1,249 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS skills (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    key TEXT NOT NULL,
+    category TEXT NOT NULL CHECK (category IN (
+        'programming_language', 'framework', 'database', 'cloud', 'tool',
+        'methodology', 'soft_skill', 'domain', 'other'
+    )),
+    merged_into BIGINT REFERENCES skills(id),
+
+    version INT NOT NULL DEFAULT 1,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by BIGINT,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_skills_key
+    ON skills(key) WHERE NOT deleted;
+
+-- Autocomplete matches on key prefixes.
+CREATE INDEX IF NOT EXISTS idx_skills_key_prefix
+    ON skills(key text_pattern_ops) WHERE NOT deleted;
+
+CREATE TABLE IF NOT EXISTS skill_synonyms (
+    id BIGSERIAL PRIMARY KEY,
+    skill_id BIGINT NOT NULL REFERENCES skills(id),
+    name TEXT NOT NULL,
+    key TEXT NOT NULL,
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT unique_skill_synonyms_key UNIQUE (key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_skill_synonyms_skill ON skill_synonyms(skill_id);
+CREATE INDEX IF NOT EXISTS idx_skill_synonyms_key_prefix ON skill_synonyms(key text_pattern_ops);
+
+-- Mirrors models.SkillKey; only needed while this migration runs.
+CREATE FUNCTION pg_temp.skill_key(name TEXT) RETURNS TEXT AS $$
+    SELECT regexp_replace(
+        translate(lower(name), 'áàâãäéèêëíìîïóòôõöúùûüçñ', 'aaaaaeeeeiiiiooooouuuucn'),
+        '[^a-z0-9+#]', '', 'g'
+    )
+$$ LANGUAGE SQL IMMUTABLE;
+
+INSERT INTO skills (name, key, category)
+SELECT s.name, pg_temp.skill_key(s.name), s.category
+FROM (VALUES
+    ('Go', 'programming_language'),
+    ('Java', 'programming_language'),
+    ('Kotlin', 'programming_language'),
+    ('Python', 'programming_language'),
+    ('JavaScript', 'programming_language'),
+    ('TypeScript', 'programming_language'),
+    ('PHP', 'programming_language'),
+    ('Ruby', 'programming_language'),
+    ('C', 'programming_language'),
+    ('C++', 'programming_language'),
+    ('C#', 'programming_language'),
+    ('Rust', 'programming_language'),
+    ('Swift', 'programming_language'),
+    ('Dart', 'programming_language'),
+    ('Scala', 'programming_language'),
+    ('Elixir', 'programming_language'),
+    ('SQL', 'programming_language'),
+    ('HTML', 'programming_language'),
+    ('CSS', 'programming_language'),
+    ('Shell Script', 'programming_language'),
+    ('React', 'framework'),
+    ('React Native', 'framework'),
+    ('Angular', 'framework'),
+    ('Vue.js', 'framework'),
+    ('Next.js', 'framework'),
+    ('Node.js', 'framework'),
+    ('Express', 'framework'),
+    ('NestJS', 'framework'),
+    ('Spring Boot', 'framework'),
+    ('Django', 'framework'),
+    ('Flask', 'framework'),
+    ('FastAPI', 'framework'),
+    ('Laravel', 'framework'),
+    ('Ruby on Rails', 'framework'),
+    ('.NET', 'framework'),
+    ('Flutter', 'framework'),
+    ('PostgreSQL', 'database'),
+    ('MySQL', 'database'),
+    ('SQL Server', 'database'),
+    ('Oracle Database', 'database'),
+    ('MongoDB', 'database'),
+    ('Redis', 'database'),
+    ('Elasticsearch', 'database'),
+    ('DynamoDB', 'database'),
+    ('AWS', 'cloud'),
+    ('Google Cloud', 'cloud'),
+    ('Microsoft Azure', 'cloud'),
+    ('Docker', 'tool'),
+    ('Kubernetes', 'tool'),
+    ('Terraform', 'tool'),
+    ('Git', 'tool'),
+    ('Linux', 'tool'),
+    ('Kafka', 'tool'),
+    ('RabbitMQ', 'tool'),
+    ('Jenkins', 'tool'),
+    ('GitHub Actions', 'tool'),
+    ('Figma', 'tool'),
+    ('Excel', 'tool'),
+    ('Power BI', 'tool'),
+    ('SAP', 'tool'),
+    ('Scrum', 'methodology'),
+    ('Kanban', 'methodology'),
+    ('Agile', 'methodology'),
+    ('DevOps', 'methodology'),
+    ('TDD', 'methodology'),
+    ('Microservices', 'methodology'),
+    ('REST APIs', 'methodology'),
+    ('Communication', 'soft_skill'),
+    ('Leadership', 'soft_skill'),
+    ('Teamwork', 'soft_skill'),
+    ('Problem Solving', 'soft_skill'),
+    ('Customer Service', 'soft_skill'),
+    ('Machine Learning', 'domain'),
+    ('Data Analysis', 'domain'),
+    ('Information Security', 'domain'),
+    ('UX Design', 'domain'),
+    ('Digital Marketing', 'domain'),
+    ('Accounting', 'domain'),
+    ('Sales', 'domain'),
+    ('Logistics', 'domain')
+) AS s(name, category);
+
+INSERT INTO skill_synonyms (skill_id, name, key)
+SELECT k.id, s.synonym, pg_temp.skill_key(s.synonym)
+FROM (VALUES
+    ('Go', 'Golang'),
+    ('JavaScript', 'JS'),
+    ('JavaScript', 'ECMAScript'),
+    ('TypeScript', 'TS'),
+    ('Python', 'Python 3'),
+    ('C#', 'CSharp'),
+    ('C++', 'CPP'),
+    ('HTML', 'HTML5'),
+    ('CSS', 'CSS3'),
+    ('Shell Script', 'Bash'),
+    ('Shell Script', 'Shell'),
+    ('React', 'ReactJS'),
+    ('Angular', 'AngularJS'),
+    ('Vue.js', 'Vue'),
+    ('Node.js', 'Node'),
+    ('Express', 'Express.js'),
+    ('Spring Boot', 'Spring'),
+    ('Ruby on Rails', 'Rails'),
+    ('.NET', 'dotnet'),
+    ('.NET', '.NET Core'),
+    ('.NET', 'ASP.NET'),
+    ('PostgreSQL', 'Postgres'),
+    ('PostgreSQL', 'psql'),
+    ('SQL Server', 'MSSQL'),
+    ('Oracle Database', 'Oracle'),
+    ('MongoDB', 'Mongo'),
+    ('Elasticsearch', 'Elastic Search'),
+    ('AWS', 'Amazon Web Services'),
+    ('Google Cloud', 'GCP'),
+    ('Google Cloud', 'Google Cloud Platform'),
+    ('Microsoft Azure', 'Azure'),
+    ('Kubernetes', 'K8s'),
+    ('Kafka', 'Apache Kafka'),
+    ('GitHub Actions', 'GH Actions'),
+    ('Excel', 'Microsoft Excel'),
+    ('Excel', 'MS Excel'),
+    ('Agile', 'Ágil'),
+    ('Agile', 'Metodologias Ágeis'),
+    ('Microservices', 'Microsserviços'),
+    ('REST APIs', 'REST'),
+    ('REST APIs', 'RESTful'),
+    ('REST APIs', 'API REST'),
+    ('Communication', 'Comunicação'),
+    ('Leadership', 'Liderança'),
+    ('Teamwork', 'Trabalho em equipe'),
+    ('Problem Solving', 'Resolução de problemas'),
+    ('Customer Service', 'Atendimento ao cliente'),
+    ('Machine Learning', 'ML'),
+    ('Machine Learning', 'Aprendizado de máquina'),
+    ('Data Analysis', 'Análise de dados'),
+    ('Information Security', 'Segurança da informação'),
+    ('Information Security', 'Cybersecurity'),
+    ('UX Design', 'UX'),
+    ('UX Design', 'UI/UX'),
+    ('Digital Marketing', 'Marketing digital'),
+    ('Accounting', 'Contabilidade'),
+    ('Sales', 'Vendas'),
+    ('Logistics', 'Logística')
+) AS s(skill, synonym)
+JOIN skills k ON k.name = s.skill;
+
+-- Curriculum skills become entries pointing at the catalog, in the shape
+-- the API uses. The names that match are written as the catalog has them,
+-- and the ones that turn out to be the same skill are kept once.
+ALTER TABLE curricula ADD COLUMN skill_entries JSONB NOT NULL DEFAULT '[]';
+
+UPDATE curricula c
+SET skill_entries = coalesce((
+    SELECT jsonb_agg(jsonb_build_object('skill_id', e.skill_id, 'name', e.name) ORDER BY e.n)
+    FROM (
+        SELECT DISTINCT ON (coalesce(k.id::text, pg_temp.skill_key(t.name)))
+            k.id AS skill_id,
+            coalesce(k.name, t.name) AS name,
+            t.n
+        FROM unnest(c.skills) WITH ORDINALITY AS t(name, n)
+        LEFT JOIN (
+            SELECT key, id AS skill_id FROM skills
+            UNION ALL
+            SELECT key, skill_id FROM skill_synonyms
+        ) l ON l.key = pg_temp.skill_key(t.name)
+        LEFT JOIN skills k ON k.id = l.skill_id
+        ORDER BY coalesce(k.id::text, pg_temp.skill_key(t.name)), t.n
+    ) e
+), '[]');
+
+ALTER TABLE curricula DROP COLUMN skills;
+ALTER TABLE curricula RENAME COLUMN skill_entries TO skills;
+
+CREATE INDEX IF NOT EXISTS idx_curricula_skills
+    ON curricula USING GIN (skills jsonb_path_ops) WHERE NOT deleted;
+
+DROP FUNCTION pg_temp.skill_key(TEXT);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_curricula_skills;
+
+ALTER TABLE curricula ADD COLUMN skill_names TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE curricula c
+SET skill_names = coalesce((
+    SELECT array_agg(s.entry->>'name' ORDER BY s.n)
+    FROM jsonb_array_elements(c.skills) WITH ORDINALITY AS s(entry, n)
+), '{}');
+
+ALTER TABLE curricula DROP COLUMN skills;
+ALTER TABLE curricula RENAME COLUMN skill_names TO skills;
+
+DROP TABLE IF EXISTS skill_synonyms;
+DROP TABLE IF EXISTS skills;
+-- +goose StatementEnd
@@ -0,0 +1,69 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGSERIAL PRIMARY KEY,
+    business_id BIGINT NOT NULL REFERENCES business(id),
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'published', 'closed')),
+    seniority TEXT NOT NULL CHECK (seniority IN ('intern', 'junior', 'mid', 'senior', 'lead')),
+    contract_type TEXT NOT NULL CHECK (contract_type IN ('clt', 'pj', 'internship', 'temporary', 'freelance')),
+    remote_mode TEXT NOT NULL CHECK (remote_mode IN ('onsite', 'hybrid', 'remote')),
+    city TEXT NOT NULL DEFAULT '',
+    uf TEXT NOT NULL DEFAULT '',
+    salary_min INT,
+    salary_max INT,
+    min_degree TEXT,
+    skills JSONB NOT NULL DEFAULT '[]',
+    languages JSONB NOT NULL DEFAULT '[]',
+    published_at TIMESTAMPTZ,
+    closed_at TIMESTAMPTZ,
+    -- matched_version is the version last scored against the candidates;
+    -- the job is due for scoring while it differs from version.
+    matched_version INT,
+
+    version INT NOT NULL DEFAULT 1,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by BIGINT,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_business ON jobs(business_id) WHERE NOT deleted;
+CREATE INDEX IF NOT EXISTS idx_jobs_published ON jobs(published_at DESC) WHERE status = 'published' AND NOT deleted;
+CREATE INDEX IF NOT EXISTS idx_jobs_skills ON jobs USING GIN (skills jsonb_path_ops) WHERE NOT deleted;
+
+ALTER TABLE curricula
+    ADD COLUMN city TEXT NOT NULL DEFAULT '',
+    ADD COLUMN uf TEXT NOT NULL DEFAULT '',
+    ADD COLUMN salary_expectation INT,
+    ADD COLUMN matched_version INT;
+
+CREATE TABLE IF NOT EXISTS job_matches (
+    job_id BIGINT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    curriculum_id BIGINT NOT NULL REFERENCES curricula(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    score INT NOT NULL,
+    breakdown JSONB NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (job_id, curriculum_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_matches_job_score ON job_matches(job_id, score DESC);
+CREATE INDEX IF NOT EXISTS idx_job_matches_user_score ON job_matches(user_id, score DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS job_matches;
+
+ALTER TABLE curricula
+    DROP COLUMN IF EXISTS city,
+    DROP COLUMN IF EXISTS uf,
+    DROP COLUMN IF EXISTS salary_expectation,
+    DROP COLUMN IF EXISTS matched_version;
+
+DROP TABLE IF EXISTS jobs;
+-- +goose StatementEnd
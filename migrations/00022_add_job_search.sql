@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- search weighs the title and the skills asked above the description.
+ALTER TABLE jobs
+    ADD COLUMN search TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('portuguese', title), 'A') ||
+        setweight(jsonb_to_tsvector('portuguese', jsonb_path_query_array(skills, '$[*].name'), '["string"]'), 'A') ||
+        setweight(to_tsvector('portuguese', description), 'B')
+    ) STORED,
+    -- salary_top is what the salary bands are cut by.
+    ADD COLUMN salary_top INT GENERATED ALWAYS AS (coalesce(salary_max, salary_min)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_search ON jobs USING GIN (search) WHERE status = 'published' AND NOT deleted;
+CREATE INDEX IF NOT EXISTS idx_jobs_location ON jobs(uf, lower(city)) WHERE status = 'published' AND NOT deleted;
+CREATE INDEX IF NOT EXISTS idx_jobs_salary_top ON jobs(salary_top) WHERE status = 'published' AND NOT deleted;
+CREATE INDEX IF NOT EXISTS idx_jobs_facets ON jobs(remote_mode, contract_type, seniority) WHERE status = 'published' AND NOT deleted;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_jobs_facets;
+DROP INDEX IF EXISTS idx_jobs_salary_top;
+DROP INDEX IF EXISTS idx_jobs_location;
+DROP INDEX IF EXISTS idx_jobs_search;
+
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS search,
+    DROP COLUMN IF EXISTS salary_top;
+-- +goose StatementEnd
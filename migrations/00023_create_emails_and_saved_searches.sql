@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- emails is the outbox: messages are written in the same transaction as
+-- whatever caused them and sent by a background task.
+CREATE TABLE IF NOT EXISTS emails (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    to_address TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    send_after TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_emails_pending ON emails(send_after) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_emails_user ON emails(user_id);
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    criteria JSONB NOT NULL DEFAULT '{}',
+    frequency TEXT NOT NULL CHECK (frequency IN ('instant', 'daily', 'weekly')),
+    alerts_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    -- checked_until is when the search last ran; jobs published after it
+    -- are the new ones.
+    checked_until TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    next_run_at TIMESTAMPTZ NOT NULL,
+
+    version INT NOT NULL DEFAULT 1,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by BIGINT,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user ON saved_searches(user_id) WHERE NOT deleted;
+CREATE INDEX IF NOT EXISTS idx_saved_searches_due ON saved_searches(next_run_at) WHERE alerts_enabled AND NOT deleted;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS saved_searches;
+DROP TABLE IF EXISTS emails;
+-- +goose StatementEnd
@@ -0,0 +1,65 @@
+-- +goose Up
+-- +goose StatementBegin
+-- haversine_km is the great-circle distance between two points, in km.
+-- Distance filters narrow with a bounding box on the indexes first.
+CREATE OR REPLACE FUNCTION haversine_km(lat1 DOUBLE PRECISION, lng1 DOUBLE PRECISION, lat2 DOUBLE PRECISION, lng2 DOUBLE PRECISION)
+RETURNS DOUBLE PRECISION
+LANGUAGE SQL IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT 2 * 6371 * asin(least(1, sqrt(
+        power(sin(radians(lat2 - lat1) / 2), 2) +
+        cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lng2 - lng1) / 2), 2)
+    )))
+$$;
+
+ALTER TABLE business
+    ADD COLUMN address_latitude DOUBLE PRECISION,
+    ADD COLUMN address_longitude DOUBLE PRECISION,
+    ADD CONSTRAINT business_address_coordinates CHECK ((address_latitude IS NULL) = (address_longitude IS NULL));
+
+ALTER TABLE jobs
+    ADD COLUMN cep TEXT NOT NULL DEFAULT '',
+    ADD COLUMN street TEXT NOT NULL DEFAULT '',
+    ADD COLUMN district TEXT NOT NULL DEFAULT '',
+    ADD COLUMN latitude DOUBLE PRECISION,
+    ADD COLUMN longitude DOUBLE PRECISION,
+    ADD CONSTRAINT jobs_coordinates CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+ALTER TABLE curricula
+    ADD COLUMN cep TEXT NOT NULL DEFAULT '',
+    ADD COLUMN street TEXT NOT NULL DEFAULT '',
+    ADD COLUMN district TEXT NOT NULL DEFAULT '',
+    ADD COLUMN latitude DOUBLE PRECISION,
+    ADD COLUMN longitude DOUBLE PRECISION,
+    ADD CONSTRAINT curricula_coordinates CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_jobs_coordinates ON jobs(latitude, longitude) WHERE status = 'published' AND NOT deleted AND latitude IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_jobs_coordinates;
+
+ALTER TABLE curricula
+    DROP CONSTRAINT IF EXISTS curricula_coordinates,
+    DROP COLUMN IF EXISTS cep,
+    DROP COLUMN IF EXISTS street,
+    DROP COLUMN IF EXISTS district,
+    DROP COLUMN IF EXISTS latitude,
+    DROP COLUMN IF EXISTS longitude;
+
+ALTER TABLE jobs
+    DROP CONSTRAINT IF EXISTS jobs_coordinates,
+    DROP COLUMN IF EXISTS cep,
+    DROP COLUMN IF EXISTS street,
+    DROP COLUMN IF EXISTS district,
+    DROP COLUMN IF EXISTS latitude,
+    DROP COLUMN IF EXISTS longitude;
+
+ALTER TABLE business
+    DROP CONSTRAINT IF EXISTS business_address_coordinates,
+    DROP COLUMN IF EXISTS address_latitude,
+    DROP COLUMN IF EXISTS address_longitude;
+
+DROP FUNCTION IF EXISTS haversine_km(DOUBLE PRECISION, DOUBLE PRECISION, DOUBLE PRECISION, DOUBLE PRECISION);
+-- +goose StatementEnd
@@ -0,0 +1,87 @@
+-- +goose Up
+-- +goose StatementBegin
+-- btree_gist lets the exclusion constraint below compare the interviewer
+-- by equality alongside the time ranges.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE IF NOT EXISTS applications (
+    id BIGSERIAL PRIMARY KEY,
+    job_id BIGINT NOT NULL REFERENCES jobs(id),
+    business_id BIGINT NOT NULL REFERENCES business(id),
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- The curriculum is sent as it was at curriculum_version; the business
+    -- reads that revision, not later edits.
+    curriculum_id BIGINT NOT NULL REFERENCES curricula(id) ON DELETE CASCADE,
+    curriculum_version INT NOT NULL,
+    cover_letter TEXT NOT NULL DEFAULT '',
+    stage TEXT NOT NULL DEFAULT 'applied' CHECK (stage IN ('applied', 'screening', 'interview', 'offer', 'hired', 'rejected', 'withdrawn')),
+    stage_reason TEXT NOT NULL DEFAULT '',
+    stage_changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    version INT NOT NULL DEFAULT 1,
+
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by BIGINT,
+    updated_at TIMESTAMPTZ
+);
+
+-- A candidate may apply again only after withdrawing.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_applications_job_user ON applications(job_id, user_id) WHERE stage <> 'withdrawn';
+CREATE INDEX IF NOT EXISTS idx_applications_job ON applications(job_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_applications_user ON applications(user_id, created_at DESC);
+
+ALTER TABLE emails
+    ADD COLUMN attachments JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS interviews (
+    id BIGSERIAL PRIMARY KEY,
+    application_id BIGINT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    business_id BIGINT NOT NULL REFERENCES business(id),
+    interviewer_id BIGINT NOT NULL REFERENCES users(id),
+    status TEXT NOT NULL DEFAULT 'proposed' CHECK (status IN ('proposed', 'scheduled', 'cancelled')),
+    mode TEXT NOT NULL CHECK (mode IN ('video', 'onsite', 'phone')),
+    video_url TEXT NOT NULL DEFAULT '',
+    location TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    duration_minutes INT NOT NULL CHECK (duration_minutes > 0),
+    -- slots are the times proposed to the candidate, who picks one of them
+    -- as starts_at.
+    slots JSONB NOT NULL DEFAULT '[]',
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    -- reason is the one given for the last reschedule or the cancellation.
+    reason TEXT NOT NULL DEFAULT '',
+    cancelled_by BIGINT REFERENCES users(id),
+    -- sequence is the iCalendar SEQUENCE, raised with every invite or
+    -- cancellation sent.
+    sequence INT NOT NULL DEFAULT 0,
+
+    version INT NOT NULL DEFAULT 1,
+
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by BIGINT,
+    updated_at TIMESTAMPTZ,
+
+    CONSTRAINT interviews_scheduled_time CHECK (status <> 'scheduled' OR (starts_at IS NOT NULL AND ends_at > starts_at)),
+    CONSTRAINT interviews_no_overlap EXCLUDE USING gist (
+        interviewer_id WITH =,
+        tstzrange(starts_at, ends_at) WITH &&
+    ) WHERE (status = 'scheduled')
+);
+
+CREATE INDEX IF NOT EXISTS idx_interviews_application ON interviews(application_id);
+CREATE INDEX IF NOT EXISTS idx_interviews_business ON interviews(business_id, starts_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS interviews;
+
+ALTER TABLE emails
+    DROP COLUMN IF EXISTS attachments;
+
+DROP TABLE IF EXISTS applications;
+DROP EXTENSION IF EXISTS btree_gist;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS messages (
+    id BIGSERIAL PRIMARY KEY,
+    application_id BIGINT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    sender_id BIGINT NOT NULL REFERENCES users(id),
+    -- from_candidate tells the sides apart: the candidate, or any member of
+    -- the business.
+    from_candidate BOOLEAN NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    -- read_at is when the other side first read it, by read_by.
+    read_at TIMESTAMPTZ,
+    read_by BIGINT REFERENCES users(id),
+    -- notified_at is when the other side was emailed about it, if it went
+    -- unread through the quiet period.
+    notified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_application ON messages(application_id, id);
+CREATE INDEX IF NOT EXISTS idx_messages_unread ON messages(application_id, from_candidate) WHERE read_at IS NULL;
+
+ALTER TABLE files
+    DROP CONSTRAINT IF EXISTS files_kind_check,
+    ADD CONSTRAINT files_kind_check CHECK (kind IN ('resume', 'logo', 'verification_document', 'message_attachment'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM files WHERE kind = 'message_attachment';
+
+ALTER TABLE files
+    DROP CONSTRAINT IF EXISTS files_kind_check,
+    ADD CONSTRAINT files_kind_check CHECK (kind IN ('resume', 'logo', 'verification_document'));
+
+DROP TABLE IF EXISTS messages;
+-- +goose StatementEnd
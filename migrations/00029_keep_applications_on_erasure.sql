@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Erasing an account deletes its curricula; the applications sent with
+-- them stay with the businesses, without the curriculum.
+ALTER TABLE applications
+    ALTER COLUMN curriculum_id DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS applications_curriculum_id_fkey,
+    ADD CONSTRAINT applications_curriculum_id_fkey FOREIGN KEY (curriculum_id) REFERENCES curricula(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM applications WHERE curriculum_id IS NULL;
+
+ALTER TABLE applications
+    DROP CONSTRAINT IF EXISTS applications_curriculum_id_fkey,
+    ADD CONSTRAINT applications_curriculum_id_fkey FOREIGN KEY (curriculum_id) REFERENCES curricula(id) ON DELETE CASCADE,
+    ALTER COLUMN curriculum_id SET NOT NULL;
+-- +goose StatementEnd
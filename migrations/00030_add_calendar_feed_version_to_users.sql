@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- calendar_feed_version is signed into the URL of the user's calendar feed;
+-- raising it revokes the URL handed out.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS calendar_feed_version INT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS calendar_feed_version;
+-- +goose StatementEnd
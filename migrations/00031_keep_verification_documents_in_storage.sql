@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Verification documents live only in the storage now. Those sent before
+-- it existed, with the content inline, were never released and are dropped
+-- rather than carried along.
+DELETE FROM business_verification_documents WHERE file_id IS NULL;
+
+ALTER TABLE business_verification_documents
+    DROP COLUMN IF EXISTS content,
+    ALTER COLUMN file_id SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE business_verification_documents
+    ADD COLUMN IF NOT EXISTS content BYTEA,
+    ALTER COLUMN file_id DROP NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- claimed_until keeps other runs off a draft being parsed; a draft whose
+-- run died is taken again once it's past.
+ALTER TABLE curriculum_drafts
+    ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE curriculum_drafts
+    DROP COLUMN IF EXISTS claimed_until;
+-- +goose StatementEnd
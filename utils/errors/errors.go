@@ -16,11 +16,16 @@ var (
 	ErrDuplicateName         = errors.New("duplicate name")
 	ErrDuplicateCNPJ         = errors.New("duplicate CNPJ")
 	ErrDuplicatePhone        = errors.New("duplicate phone")
+	ErrDuplicateCPF          = errors.New("duplicate CPF")
+	ErrDuplicateSlug         = errors.New("duplicate slug")
 	ErrInvalidData           = errors.New("invalid data")
 	ErrInvalidCredentials    = errors.New("invalid authentication credentials")
 	ErrInactiveAccount       = errors.New("your user account must be activated to access this resource")
 	ErrStartDateAfterEndDate = errors.New("start date must be before end date")
 	ErrInvalidRole           = errors.New("invalid role")
+	ErrBusinessNotVerified   = errors.New("business is not verified")
+	ErrDuplicateApplication  = errors.New("duplicate application")
+	ErrScheduleConflict      = errors.New("schedule conflict")
 )
 
 type errorResponse struct {
@@ -42,6 +47,8 @@ type ErrorResponseInterface interface {
 	FailedValidationResponse(w http.ResponseWriter, r *http.Request, errors map[string]string)
 	EditConflictResponse(w http.ResponseWriter, r *http.Request)
 	HandlerErrorResponse(w http.ResponseWriter, r *http.Request, err error, v *validator.Validator)
+	ConsentRequiredResponse(w http.ResponseWriter, r *http.Request, pending any)
+	BusinessNotVerifiedResponse(w http.ResponseWriter, r *http.Request)
 }
 
 func NewErrorResponse(logger *jsonlog.Logger) *errorResponse {
@@ -72,6 +79,22 @@ func (e *errorResponse) HandlerErrorResponse(w http.ResponseWriter, r *http.Requ
 		v.AddError("phone", "a register with this phone number already exists")
 		e.FailedValidationResponse(w, r, v.Errors)
 
+	case errors.Is(err, ErrDuplicateCPF) && v != nil:
+		v.AddError("cpf", "a register with this cpf already exists")
+		e.FailedValidationResponse(w, r, v.Errors)
+
+	case errors.Is(err, ErrDuplicateSlug) && v != nil:
+		v.AddError("slug", "a register with this slug already exists")
+		e.FailedValidationResponse(w, r, v.Errors)
+
+	case errors.Is(err, ErrDuplicateApplication) && v != nil:
+		v.AddError("job_id", "you have already applied to this job")
+		e.FailedValidationResponse(w, r, v.Errors)
+
+	case errors.Is(err, ErrScheduleConflict) && v != nil:
+		v.AddError("starts_at", "overlaps another interview of the interviewer")
+		e.FailedValidationResponse(w, r, v.Errors)
+
 	case errors.Is(err, ErrEditConflict):
 		e.EditConflictResponse(w, r)
 
@@ -81,6 +104,9 @@ func (e *errorResponse) HandlerErrorResponse(w http.ResponseWriter, r *http.Requ
 	case errors.Is(err, ErrInactiveAccount):
 		e.InvalidRoleResponse(w, r)
 
+	case errors.Is(err, ErrBusinessNotVerified):
+		e.BusinessNotVerifiedResponse(w, r)
+
 	default:
 		e.ServerErrorResponse(w, r, err)
 	}
@@ -150,6 +176,23 @@ func (e *errorResponse) EditConflictResponse(w http.ResponseWriter, r *http.Requ
 	e.errorResponse(w, r, http.StatusConflict, message)
 }
 
+func (e *errorResponse) ConsentRequiredResponse(w http.ResponseWriter, r *http.Request, pending any) {
+	message := map[string]any{
+		"code":    "consent_required",
+		"message": "you must accept the current version of the terms to access this resource",
+		"pending": pending,
+	}
+	e.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+func (e *errorResponse) BusinessNotVerifiedResponse(w http.ResponseWriter, r *http.Request) {
+	message := map[string]any{
+		"code":    "business_not_verified",
+		"message": "the business must be verified by an admin to perform this action",
+	}
+	e.errorResponse(w, r, http.StatusForbidden, message)
+}
+
 func (e *errorResponse) errorResponse(w http.ResponseWriter, r *http.Request, status int, message any) {
 	env := utils.Envelope{"error": message}
 	err := utils.WriteJSON(w, status, env, nil)
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"io"
 	"maps"
+	"math"
 	"math/rand"
 	"meu_job/utils/validator"
+	"net"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -36,6 +38,16 @@ func ReadIntPathVariable(r *http.Request, key string) (int64, error) {
 	return value, nil
 }
 
+func ReadStringPathVariable(r *http.Request, key string) (string, error) {
+	s := chi.URLParam(r, key)
+
+	if s == "" {
+		return "", fmt.Errorf("missing path parameter: %s", key)
+	}
+
+	return s, nil
+}
+
 func ReadString(qs url.Values, key, defaultValue string) string {
 	s := qs.Get(key)
 
@@ -60,6 +72,38 @@ func ReadInt(qs url.Values, key string, defaultValue int, v *validator.Validator
 	return i
 }
 
+// ReadFloat returns nil when the parameter is left out, as zero is a
+// meaningful value for most floats read, such as coordinates.
+func ReadFloat(qs url.Values, key string, v *validator.Validator) *float64 {
+	s := qs.Get(key)
+
+	if s == "" {
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		v.AddError(key, "must be a number")
+		return nil
+	}
+	return &f
+}
+
+func ReadBool(qs url.Values, key string, defaultValue bool, v *validator.Validator) bool {
+	s := qs.Get(key)
+
+	if s == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		v.AddError(key, "must be a boolean value")
+		return defaultValue
+	}
+	return b
+}
+
 func ReadJSON(
 	w http.ResponseWriter,
 	r *http.Request,
@@ -132,6 +176,14 @@ func WriteJSON(w http.ResponseWriter, status int, data Envelope, headers http.He
 	return nil
 }
 
+func ClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
 func GenerateRandomCode() int {
 	return rand.Intn(900000) + 100000
 }